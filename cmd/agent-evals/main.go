@@ -2,40 +2,109 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/spf13/cobra"
+	"github.com/thinkwright/agent-evals/eval"
 	"github.com/thinkwright/agent-evals/internal/analysis"
 	"github.com/thinkwright/agent-evals/internal/config"
 	"github.com/thinkwright/agent-evals/internal/loader"
 	"github.com/thinkwright/agent-evals/internal/probes"
 	"github.com/thinkwright/agent-evals/internal/provider"
 	"github.com/thinkwright/agent-evals/internal/report"
-	"github.com/spf13/cobra"
+	"github.com/thinkwright/agent-evals/internal/tui"
 	"golang.org/x/term"
 )
 
 var version = "dev"
 
+// chatter receives progress and informational messages; --pre-commit
+// discards them so a passing hook prints nothing, and --progress json so
+// stderr holds only JSON lines.
+var chatter io.Writer = os.Stderr
+
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes the command line args and returns the exit code (see
+// exitCode). The first SIGINT or SIGTERM cancels the command's context, so
+// a live run stops and reports the probes it completed; a second one
+// terminates the process.
+func run(args []string) int {
+	chatter = os.Stderr
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	root := newRootCmd()
+	root.SetArgs(args)
+	return exitCode(root.ExecuteContext(ctx))
+}
+
+// newRootCmd builds the agent-evals command and its subcommands.
+func newRootCmd() *cobra.Command {
 	root := &cobra.Command{
 		Use:     "agent-evals",
 		Short:   "Overlap analysis, boundary testing, and metacognitive scoring for LLM agents",
 		Version: version,
 	}
 
+	var flagDebug bool
+	root.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Log debug messages, such as the response field a provider's answer was read from, to stderr")
+	root.PersistentPreRun = func(*cobra.Command, []string) {
+		if flagDebug {
+			slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		}
+	}
+
 	// Shared flags
 	var (
-		flagCI        bool
-		flagFormat    string
-		flagConfig    string
-		flagOutput    string
-		flagNoPager   bool
-		flagRecursive bool
-		flagNoDedup   bool
+		flagCI          bool
+		flagFormat      string
+		flagConfig      string
+		flagOutput      string
+		flagNoPager     bool
+		flagRecursive   bool
+		flagNoDedup     bool
+		flagIncremental bool
+		flagNoCache     bool
+		flagCacheDir    string
+
+		flagSkipIDs           []string
+		flagAgents            []string
+		flagDomains           []string
+		flagInclude           []string
+		flagExclude           []string
+		flagRespectGitignore  bool
+		flagKeywordStats      bool
+		flagWarnUnusedDomains bool
+		flagClaimsFromSkills  bool
+		flagTiming            bool
+		flagPreCommit         bool
+		flagWarningsAsErrors  bool
+		flagFailOn            string
+		flagFailOnDuplicates  bool
+		flagWrapWidth         int
+		flagTUI               bool
 	)
 
 	// ── check command ────────────────────────────────────────────
@@ -46,53 +115,165 @@ func main() {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			applyCIDefaults(cmd, &flagFormat, &flagNoPager, flagCI)
 			agentsPath := args[0]
+			timer := newPhaseTimer()
+			runInfo := eval.NewRunInfo()
+			if flagPreCommit {
+				chatter = io.Discard
+				cmd.SilenceUsage = true
+			}
+
+			if flagTUI && !tui.Interactive() {
+				return fmt.Errorf("--tui needs an interactive terminal")
+			}
 
 			cfg, err := config.Load(flagConfig, agentsPath)
 			if err != nil {
-				return fmt.Errorf("load config: %w", err)
+				return exitErrorf(exitConfig, "load config: %w", err)
+			}
+			if flagWarnUnusedDomains {
+				enableConfigOption(cfg, "checks", "warn_unused_domains")
+			}
+			if flagClaimsFromSkills {
+				enableConfigOption(cfg, "claims", "from_skills")
+			}
+			if flagWarningsAsErrors {
+				enableConfigOption(cfg, "thresholds", "warnings_as_errors")
+			}
+			if flagFailOnDuplicates {
+				enableConfigOption(cfg, "scan", "fail_on_duplicates")
+			}
+			if err := applyFailOn(cfg, flagFailOn); err != nil {
+				return exitErrorf(exitConfig, "%w", err)
 			}
 
-			agents, err := loadAgents(agentsPath, flagRecursive, flagNoDedup)
+			analyzer := analysis.NewStaticAnalyzer(cfg)
+			cache := openCache(analyzer, agentsPath, flagCacheDir, flagNoCache)
+			loadOpts := loadOptions(cfg, flagRecursive, flagNoDedup, flagInclude, flagExclude, flagRespectGitignore)
+			var loadStats loader.LoadStats
+			loadOpts.Stats = &loadStats
+			var agents []loader.AgentDefinition
+			if flagIncremental {
+				agents, err = streamAgents(agentsPath, loadOpts, analyzer, cfg)
+			} else {
+				agents, err = loadAgents(agentsPath, loadOpts)
+			}
+			if err != nil {
+				return exitErrorf(exitConfig, "load agents: %w", err)
+			}
+			agents, err = skipAgents(agents, cfg, flagSkipIDs)
 			if err != nil {
-				return fmt.Errorf("load agents: %w", err)
+				return exitErrorf(exitConfig, "%w", err)
 			}
 			if len(agents) == 0 {
-				return fmt.Errorf("no agent definitions found in %s", agentsPath)
+				return exitErrorf(exitConfig, "no agent definitions found in %s", agentsPath)
+			}
+			agents, subset, err := selectAgents(agents, cfg, flagAgents, flagDomains)
+			if err != nil {
+				return exitErrorf(exitConfig, "%w", err)
 			}
 
-			printLoadSummary(agents, agentsPath, flagRecursive)
+			printLoadSummary(agents, agentsPath, flagRecursive, loadStats.Files)
+			timer.lap("load")
+
+			if flagKeywordStats {
+				mergeSkillClaims(agents, cfg)
+				stats := analysis.KeywordStats(agents, analysis.ResolveDomains(cfg), analysis.ResolveExtractOptions(cfg))
+				timer.lap("keyword stats")
+				err := writeOutput(report.FormatKeywordStats(stats, flagFormat), flagOutput, flagFormat, flagNoPager)
+				timer.lap("report")
+				timer.print(flagTiming)
+				return err
+			}
 
-			staticReport := analysis.RunStaticAnalysis(agents, cfg)
+			staticReport := eval.RunStaticWith(analyzer, agents, cfg)
+			staticReport.Subset = subset
+			staticReport.Run = runInfo
+			saveCache(cache)
+			timer.add(staticReport.Timings)
 
-			output := formatReport(staticReport, nil, flagFormat)
-			if err := writeOutput(output, flagOutput, flagFormat, flagNoPager); err != nil {
+			if flagPreCommit {
+				if failures := report.FormatPreCommit(staticReport, agentsPath); failures != "" {
+					fmt.Print(failures)
+					cmd.SilenceErrors = true // the lines above are the whole message
+					return exitErrorf(exitStatic, "pre-commit check failed")
+				}
+				return nil
+			}
+
+			runCfg := buildRunConfig(staticReport, cfg, config.Resolve(flagConfig, agentsPath), flagRecursive, flagNoDedup)
+			if err := showReport(staticReport, nil, flagFormat, runCfg, flagWrapWidth, flagOutput, flagNoPager, flagTUI); err != nil {
 				return err
 			}
+			timer.lap("report")
+			timer.print(flagTiming)
 
 			if flagCI {
 				return checkCIResult(staticReport, nil, cfg)
 			}
-			return nil
+			return checkDuplicates(staticReport.Agents, cfg)
 		},
 	}
-	checkCmd.Flags().BoolVar(&flagCI, "ci", false, "CI mode: JSON output, no pager, exit 1 on failure")
-	checkCmd.Flags().StringVar(&flagFormat, "format", "terminal", "Output format: terminal, json, markdown")
+	checkCmd.Flags().BoolVar(&flagCI, "ci", false, "CI mode: JSON output, no pager, exit 2-4 on failure (see README)")
+	checkCmd.Flags().StringVar(&flagFormat, "format", "terminal", "Output format: terminal, json, markdown, html, gitlab, junit, sarif")
 	checkCmd.Flags().StringVar(&flagConfig, "config", "", "Path to agent-evals.yaml config")
 	checkCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Write report to file")
 	checkCmd.Flags().BoolVar(&flagNoPager, "no-pager", false, "Disable automatic paging")
+	checkCmd.Flags().BoolVar(&flagTUI, "tui", false, "Browse the results interactively instead of printing the report (the report is still written with -o)")
 	checkCmd.Flags().BoolVarP(&flagRecursive, "recursive", "r", false, "Recursively scan nested directories for agent definitions")
 	checkCmd.Flags().BoolVar(&flagNoDedup, "no-dedup", false, "Disable content-hash deduplication (only with --recursive)")
+	checkCmd.Flags().BoolVar(&flagIncremental, "incremental", false, "Analyze each agent's domains as its file is read, reporting each file, instead of after loading (only with --recursive)")
+	checkCmd.Flags().BoolVar(&flagFailOnDuplicates, "fail-on-duplicates", false, "Exit 2 when deduplication finds agents with identical content (only with --recursive)")
+	checkCmd.Flags().BoolVar(&flagNoCache, "no-cache", false, "Analyze every agent instead of reusing cached results for unchanged agents")
+	checkCmd.Flags().StringVar(&flagCacheDir, "cache-dir", "", "Directory for "+analysis.CacheFileName+" (default: agent-evals/ under the user cache directory)")
+	checkCmd.Flags().StringArrayVar(&flagSkipIDs, "skip-id", nil, "Skip agents whose ID matches this regex (repeatable)")
+	checkCmd.Flags().StringArrayVar(&flagAgents, "agent", nil, "Evaluate only the agent with this ID, qualified or not (repeatable); overlaps and gaps then cover the subset only")
+	checkCmd.Flags().StringArrayVar(&flagDomains, "domain", nil, "Evaluate only agents that claim this domain (repeatable)")
+	checkCmd.Flags().StringArrayVar(&flagInclude, "include", nil, "Load only files whose path relative to <path> matches this glob, e.g. 'agents/**' (repeatable)")
+	checkCmd.Flags().StringArrayVar(&flagExclude, "exclude", nil, "Skip files and directories whose path relative to <path> matches this glob, e.g. '**/vendor/**' (repeatable)")
+	checkCmd.Flags().BoolVar(&flagRespectGitignore, "respect-gitignore", false, "Skip files and directories ignored by .gitignore files")
+	checkCmd.Flags().BoolVar(&flagWarnUnusedDomains, "warn-unused-domains", false, "Report configured custom domains that no agent matched")
+	checkCmd.Flags().BoolVar(&flagClaimsFromSkills, "claims-from-skills", false, "Treat domains named by skills/rules as claimed domains")
+	checkCmd.Flags().BoolVar(&flagWarningsAsErrors, "warnings-as-errors", false, "Count warnings as errors in the overall score and CI result")
+	checkCmd.Flags().StringVar(&flagFailOn, "fail-on", "", "Least severe issue that fails the CI result: error, warning or info (default: thresholds.fail_on, or error)")
+	checkCmd.Flags().BoolVar(&flagTiming, "timing", false, "Print a wall-clock breakdown of each phase to stderr")
+	checkCmd.Flags().IntVar(&flagWrapWidth, "wrap-width", 0, "Terminal report width in columns (default: terminal width, or 80; minimum 60)")
+	checkCmd.Flags().BoolVar(&flagPreCommit, "pre-commit", false, "Git hook mode: print nothing on success, only \"file: severity: message\" lines on failure, exit 2 (5 if the config or agents cannot be loaded)")
+	checkCmd.Flags().BoolVar(&flagKeywordStats, "keyword-stats", false, "Report per-keyword hit counts across agents instead of the analysis report")
 
 	// ── test command ─────────────────────────────────────────────
 	var (
-		flagProvider       string
-		flagModel          string
-		flagBaseURL        string
-		flagAPIKeyEnv      string
-		flagProbeBudget    int
-		flagStochasticRuns int
-		flagConcurrency    int
-		flagTranscript     string
+		flagProvider           string
+		flagModel              string
+		flagBaseURL            string
+		flagAPIKeyEnv          string
+		flagProbeBudget        int
+		flagStochasticRuns     int
+		flagConcurrency        int
+		flagPerAgent           bool
+		flagRequestsPerSec     float64
+		flagRequestTimeout     time.Duration
+		flagAdaptive           bool
+		flagMinConcurrency     int
+		flagMaxConcurrency     int
+		flagTranscript         string
+		flagTranscriptFields   string
+		flagTranscriptFailures bool
+		flagCalibrationData    string
+		flagProbesCSV          string
+		flagAdjacentProbes     bool
+		flagStream             bool
+		flagProgress           string
+		flagCACert             string
+		flagRegion             string
+		flagDryRun             bool
+		flagYes                bool
+		flagWorstCalibrated    int
+		flagResume             string
+		flagSeed               int64
+		flagOverlapProbes      bool
+		flagHardCalibration    bool
+		flagProbesFile         string
+		flagProfile            string
 	)
 
 	testCmd := &cobra.Command{
@@ -102,109 +283,883 @@ func main() {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			applyCIDefaults(cmd, &flagFormat, &flagNoPager, flagCI)
 			agentsPath := args[0]
+			timer := newPhaseTimer()
+			runInfo := eval.NewRunInfo()
+
+			if flagTUI && !tui.Interactive() {
+				return fmt.Errorf("--tui needs an interactive terminal")
+			}
 
 			cfg, err := config.Load(flagConfig, agentsPath)
 			if err != nil {
-				return fmt.Errorf("load config: %w", err)
+				return exitErrorf(exitConfig, "load config: %w", err)
+			}
+			if flagWarnUnusedDomains {
+				enableConfigOption(cfg, "checks", "warn_unused_domains")
+			}
+			if flagClaimsFromSkills {
+				enableConfigOption(cfg, "claims", "from_skills")
+			}
+			if flagWarningsAsErrors {
+				enableConfigOption(cfg, "thresholds", "warnings_as_errors")
+			}
+			if flagFailOnDuplicates {
+				enableConfigOption(cfg, "scan", "fail_on_duplicates")
+			}
+			if err := applyFailOn(cfg, flagFailOn); err != nil {
+				return exitErrorf(exitConfig, "%w", err)
+			}
+			if err := checkProgressFormat(flagProgress); err != nil {
+				return exitErrorf(exitConfig, "%w", err)
+			}
+			if flagProgress == "json" {
+				chatter = io.Discard
+			}
+			transcriptOpts := report.TranscriptOptions{FailuresOnly: flagTranscriptFailures}
+			if flagTranscriptFields != "" {
+				if transcriptOpts.Fields, err = report.ParseTranscriptFields(flagTranscriptFields); err != nil {
+					return exitErrorf(exitConfig, "--transcript-fields: %w", err)
+				}
 			}
 
-			agents, err := loadAgents(agentsPath, flagRecursive, flagNoDedup)
+			analyzer := analysis.NewStaticAnalyzer(cfg)
+			cache := openCache(analyzer, agentsPath, flagCacheDir, flagNoCache)
+			loadOpts := loadOptions(cfg, flagRecursive, flagNoDedup, flagInclude, flagExclude, flagRespectGitignore)
+			var loadStats loader.LoadStats
+			loadOpts.Stats = &loadStats
+			var agents []loader.AgentDefinition
+			if flagIncremental {
+				agents, err = streamAgents(agentsPath, loadOpts, analyzer, cfg)
+			} else {
+				agents, err = loadAgents(agentsPath, loadOpts)
+			}
 			if err != nil {
-				return fmt.Errorf("load agents: %w", err)
+				return exitErrorf(exitConfig, "load agents: %w", err)
+			}
+			agents, err = skipAgents(agents, cfg, flagSkipIDs)
+			if err != nil {
+				return exitErrorf(exitConfig, "%w", err)
 			}
 			if len(agents) == 0 {
-				return fmt.Errorf("no agent definitions found in %s", agentsPath)
+				return exitErrorf(exitConfig, "no agent definitions found in %s", agentsPath)
+			}
+			agents, subset, err := selectAgents(agents, cfg, flagAgents, flagDomains)
+			if err != nil {
+				return exitErrorf(exitConfig, "%w", err)
 			}
 
-			printLoadSummary(agents, agentsPath, flagRecursive)
+			printLoadSummary(agents, agentsPath, flagRecursive, loadStats.Files)
+			timer.lap("load")
 
 			// Static analysis
-			staticReport := analysis.RunStaticAnalysis(agents, cfg)
+			staticReport := eval.RunStaticWith(analyzer, agents, cfg)
+			staticReport.Subset = subset
+			staticReport.Run = runInfo
+			saveCache(cache)
+			timer.add(staticReport.Timings)
 
 			// Resolve provider config from flags and config file
-			providerCfg := resolveProviderConfig(cfg, flagProvider, flagModel, flagBaseURL, flagAPIKeyEnv)
+			providerCfg := resolveProviderConfig(cfg, flagProvider, flagModel, flagBaseURL, flagAPIKeyEnv, flagCACert, flagRegion)
+			if flagRequestTimeout > 0 {
+				// The per-call deadline replaces the client's own timeout.
+				providerCfg.Timeout = -1
+			}
 
 			client, err := provider.NewClient(providerCfg)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to initialize API client: %v\n", err)
-				fmt.Fprintln(os.Stderr, "Set the appropriate API key env var (e.g. ANTHROPIC_API_KEY, OPENAI_API_KEY).")
-				os.Exit(1)
+				fmt.Fprintln(os.Stderr, "Set the appropriate API key env var (e.g. ANTHROPIC_API_KEY, OPENAI_API_KEY, GEMINI_API_KEY).")
+				return exitErrorf(exitConfig, "initialize API client: %w", err)
 			}
 
 			// Generate probes
-			probeQuestions := probes.GenerateProbes(agents, flagProbeBudget)
-			stochastic := flagStochasticRuns
-			totalCalls := len(probeQuestions) * (1 + stochastic)
-			fmt.Fprintf(os.Stderr, "Generated %d probes (budget: %d)\n", len(probeQuestions), flagProbeBudget)
-			fmt.Fprintf(os.Stderr, "Running %d API calls...\n", totalCalls)
-
-			liveReport := probes.RunLiveProbes(
-				context.Background(),
-				agents,
-				probeQuestions,
-				client,
-				probes.RunConfig{
-					StochasticRuns: stochastic,
-					BatchDelay:     300 * time.Millisecond,
-					Concurrency:    flagConcurrency,
-				},
-				func(done, total int, agentID, probeID string) {
-					fmt.Fprintf(os.Stderr, "  [%d/%d] %s / %s\n", done, total, agentID, probeID)
+			if flagAdjacentProbes {
+				enableConfigOption(cfg, "probes", "adjacent_probes")
+			}
+			if flagOverlapProbes {
+				enableConfigOption(cfg, "probes", "overlap_probes")
+			}
+			if flagHardCalibration {
+				enableConfigOption(cfg, "probes", "hard_calibration")
+			}
+			profile, err := resolveProfile(cfg, flagProfile, flagProbeBudget, cmd.Flags().Changed("probe-budget"),
+				flagStochasticRuns, cmd.Flags().Changed("stochastic-runs"))
+			if err != nil {
+				return exitErrorf(exitConfig, "%w", err)
+			}
+			worstCalibrated := flagWorstCalibrated
+			if !cmd.Flags().Changed("worst-calibrated") {
+				worstCalibrated = int(getFloatFromConfig(getMapFromConfig(cfg, "probes"), "worst_calibrated", float64(flagWorstCalibrated)))
+			}
+			plan, err := eval.PlanProbes(staticReport, cfg, eval.RunConfig{
+				Budget:        profile.Budget,
+				ProbeTypes:    profile.ProbeTypes,
+				OnlyDomains:   flagDomains,
+				QuestionsFile: flagProbesFile,
+				Runner: probes.RunConfig{
+					StochasticRuns:      profile.StochasticRuns,
+					Provider:            providerCfg.Provider,
+					Model:               providerModel(providerCfg),
+					Concurrency:         flagConcurrency,
+					PerAgentConcurrency: flagPerAgent,
+					RequestsPerSecond:   flagRequestsPerSec,
+					RequestTimeout:      flagRequestTimeout,
+
+					AdaptiveConcurrency: flagAdaptive,
+					MinConcurrency:      flagMinConcurrency,
+					MaxConcurrency:      flagMaxConcurrency,
+
+					Stream:  flagStream,
+					OnChunk: streamHeartbeat(chatter, 5*time.Second),
+
+					WorstCalibrated: worstCalibrated,
+
+					CheckpointPath: flagResume,
+					Seed:           flagSeed,
 				},
-			)
+			})
+			if err != nil {
+				return exitErrorf(exitConfig, "%w", err)
+			}
+			timer.lap("probe generation")
+			for _, w := range plan.Warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+			}
+			fmt.Fprintf(chatter, "Generated %d probes (budget: %d)\n", len(plan.Questions), plan.Budget)
+
+			probeCfg := probeRunConfig(providerCfg, plan.Budget, plan.Runner.StochasticRuns, flagConcurrency)
+			probeCfg.Profile = profile.Name
+			probeCfg.Seed = flagSeed
+			estimate := plan.Estimate(probeCfg.Model)
 
-			output := formatReport(staticReport, liveReport, flagFormat)
-			if err := writeOutput(output, flagOutput, flagFormat, flagNoPager); err != nil {
+			if flagDryRun {
+				output := report.FormatDryRun(estimate, providerCfg.Provider, plan.Budget, flagFormat)
+				return writeOutput(output, flagOutput, flagFormat, true)
+			}
+			if err := checkCallBudget(estimate, cfg, flagYes); err != nil {
+				return err
+			}
+			if flagResume != "" {
+				checkpoint, err := probes.LoadCheckpoint(flagResume)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(chatter, "Checkpointing to %s (%d probe(s) already recorded)\n", flagResume, checkpoint.Len())
+			}
+			fmt.Fprintf(chatter, "Running %d API calls...\n", estimate.Calls)
+
+			liveReport := eval.RunPlan(cmd.Context(), plan, client, progressPrinter(os.Stderr, flagProgress))
+			timer.lap("api calls")
+			if liveReport.Resumed > 0 {
+				fmt.Fprintf(chatter, "Resumed %d probe(s) from %s; made %d API calls\n", liveReport.Resumed, flagResume, liveReport.TotalCalls)
+			}
+
+			runCfg := buildRunConfig(staticReport, cfg, config.Resolve(flagConfig, agentsPath), flagRecursive, flagNoDedup)
+			runCfg.Probes = probeCfg
+			if err := showReport(staticReport, liveReport, flagFormat, runCfg, flagWrapWidth, flagOutput, flagNoPager, flagTUI); err != nil {
 				return err
 			}
 
 			if flagTranscript != "" {
-				transcript := report.FormatTranscript(liveReport)
+				transcriptOpts.HedgeThresholds = plan.Runner.HedgeThresholds
+				transcript := report.FormatTranscriptWithOptions(liveReport, staticReport.Run, transcriptOpts)
 				if err := os.WriteFile(flagTranscript, []byte(transcript), 0644); err != nil {
 					return fmt.Errorf("write transcript: %w", err)
 				}
-				fmt.Fprintf(os.Stderr, "Transcript written to %s\n", flagTranscript)
+				fmt.Fprintf(chatter, "Transcript written to %s\n", flagTranscript)
 			}
+			if flagProbesCSV != "" {
+				if err := os.WriteFile(flagProbesCSV, []byte(report.FormatProbesCSV(liveReport)), 0644); err != nil {
+					return fmt.Errorf("write probes CSV: %w", err)
+				}
+				fmt.Fprintf(chatter, "Probe responses written to %s\n", flagProbesCSV)
+			}
+			if flagCalibrationData != "" {
+				bins := probes.ReliabilityDiagram(liveReport.AgentResults, probes.DefaultReliabilityBins)
+				data := report.FormatCalibrationData(bins, staticReport.Run, flagCalibrationData)
+				if err := os.WriteFile(flagCalibrationData, []byte(data), 0644); err != nil {
+					return fmt.Errorf("write calibration data: %w", err)
+				}
+				fmt.Fprintf(chatter, "Calibration data written to %s\n", flagCalibrationData)
+			}
+			timer.lap("report")
+			timer.print(flagTiming)
 
+			if liveReport.Canceled {
+				return exitErrorf(exitFailure, "run canceled; the report covers the completed probes only")
+			}
 			if flagCI {
 				return checkCIResult(staticReport, liveReport, cfg)
 			}
-			return nil
+			return checkDuplicates(staticReport.Agents, cfg)
 		},
 	}
-	testCmd.Flags().BoolVar(&flagCI, "ci", false, "CI mode: JSON output, no pager, exit 1 on failure")
-	testCmd.Flags().StringVar(&flagFormat, "format", "terminal", "Output format: terminal, json, markdown")
+	testCmd.Flags().BoolVar(&flagCI, "ci", false, "CI mode: JSON output, no pager, exit 2-4 on failure (see README)")
+	testCmd.Flags().StringVar(&flagFormat, "format", "terminal", "Output format: terminal, json, markdown, html, gitlab, junit, sarif")
 	testCmd.Flags().StringVar(&flagConfig, "config", "", "Path to agent-evals.yaml config")
 	testCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Write report to file")
 	testCmd.Flags().BoolVar(&flagNoPager, "no-pager", false, "Disable automatic paging")
-	testCmd.Flags().StringVar(&flagProvider, "provider", "anthropic", "LLM provider: anthropic, openai, openai-compatible")
+	testCmd.Flags().BoolVar(&flagTUI, "tui", false, "Browse the results and probe transcripts interactively instead of printing the report (the report is still written with -o)")
+	testCmd.Flags().StringVar(&flagProvider, "provider", "anthropic", "LLM provider: anthropic, openai, gemini, bedrock, openai-compatible, mock (offline canned answers)")
 	testCmd.Flags().StringVar(&flagModel, "model", "", "Model to use for probes")
-	testCmd.Flags().StringVar(&flagBaseURL, "base-url", "", "Base URL for openai-compatible provider")
+	testCmd.Flags().StringVar(&flagBaseURL, "base-url", "", "API base URL (regional or gateway endpoint; required for openai-compatible)")
+	testCmd.Flags().StringVar(&flagCACert, "ca-cert", "", "PEM file with extra CA certificates to trust for API calls")
+	testCmd.Flags().StringVar(&flagRegion, "region", "", "AWS region for the bedrock provider (default AWS_REGION)")
 	testCmd.Flags().StringVar(&flagAPIKeyEnv, "api-key-env", "", "Environment variable name for API key")
-	testCmd.Flags().IntVar(&flagProbeBudget, "probe-budget", 500, "Max API calls for live probes")
-	testCmd.Flags().IntVar(&flagStochasticRuns, "stochastic-runs", 5, "Stochastic runs per probe")
+	testCmd.Flags().IntVar(&flagProbeBudget, "probe-budget", probes.DefaultProbeBudget, "Max API calls for live probes")
+	testCmd.Flags().IntVar(&flagStochasticRuns, "stochastic-runs", probes.DefaultStochasticRuns, "Stochastic runs per probe")
+	testCmd.Flags().StringVar(&flagProfile, "profile", "", "Probe profile: smoke, full, or one defined under probe_profiles (sets budget, stochastic runs and probe types)")
 	testCmd.Flags().IntVar(&flagConcurrency, "concurrency", 3, "Max concurrent API calls")
+	testCmd.Flags().BoolVar(&flagPerAgent, "per-agent-concurrency", false, "Apply --concurrency to each agent separately, so every agent's probes run in parallel")
+	testCmd.Flags().Float64Var(&flagRequestsPerSec, "requests-per-second", 0, "Max API calls started per second across all concurrent probes (default: 300ms pause between runs of a probe)")
+	testCmd.Flags().DurationVar(&flagRequestTimeout, "request-timeout", 0, "Give up on an API call after this long, e.g. 30s, recording it as an error; replaces the HTTP client's 2m timeout, so it may be longer (default: 2m)")
+	testCmd.Flags().BoolVar(&flagAdaptive, "adaptive-concurrency", false, "Adjust concurrency automatically when the provider rate-limits")
+	testCmd.Flags().IntVar(&flagMinConcurrency, "min-concurrency", 1, "Lower bound for --adaptive-concurrency")
+	testCmd.Flags().IntVar(&flagMaxConcurrency, "max-concurrency", 0, "Upper bound for --adaptive-concurrency (default 2x --concurrency)")
+	testCmd.Flags().StringVar(&flagProgress, "progress", "text", "Probe progress on stderr: text, or json for one JSON object per line")
+	testCmd.Flags().BoolVar(&flagStream, "stream", false, "Stream responses (anthropic, openai) and print periodic progress while answers arrive")
+	testCmd.Flags().BoolVar(&flagAdjacentProbes, "adjacent-probes", false, "Add probes from domains neighboring each agent's claimed domains")
+	testCmd.Flags().BoolVar(&flagOverlapProbes, "overlap-probes", false, "Ask overlapping agents the same shared-domain questions and flag contradictory answers")
+	testCmd.Flags().BoolVar(&flagHardCalibration, "hard-calibration", false, "Add hard in-domain questions where hedging is appropriate, to check that confidence tracks difficulty")
+	testCmd.Flags().StringVar(&flagProbesFile, "probes-file", "", "YAML or JSON file of extra probe questions, keyed by domain (overrides probes.questions_file)")
+	testCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Generate probes and print the estimated API calls, tokens and cost without calling the provider")
+	testCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Run even when the planned API calls exceed probes.max_total_calls")
+	testCmd.Flags().BoolVar(&flagYes, "force", false, "Same as --yes")
+	testCmd.Flags().IntVar(&flagWorstCalibrated, "worst-calibrated", 5, "Number of most overconfident out-of-scope responses to report (0 to disable)")
+	testCmd.Flags().Int64Var(&flagSeed, "seed", 0, "Seed for probe selection and prompt reordering, for reproducible runs (0 = unseeded)")
+	testCmd.Flags().StringVar(&flagResume, "resume", "", "Checkpoint file: record each completed probe and skip probes already recorded there")
 	testCmd.Flags().StringVar(&flagTranscript, "transcript", "", "Write full probe Q&A transcript to file (markdown)")
+	testCmd.Flags().StringVar(&flagTranscriptFields, "transcript-fields", "", "Comma-separated response fields to write to the transcript: confidence, hedging, refusal, raw; prefix with - to drop one (default: all)")
+	testCmd.Flags().BoolVar(&flagTranscriptFailures, "transcript-failures-only", false, "Write only failing probes to the transcript")
+	testCmd.Flags().StringVar(&flagProbesCSV, "probes-csv", "", "Write one CSV row per probe response to file, for spreadsheet analysis")
+	testCmd.Flags().StringVar(&flagCalibrationData, "calibration-data", "", "Write reliability diagram bins of graded responses to file (.csv for CSV, otherwise JSON)")
 	testCmd.Flags().BoolVarP(&flagRecursive, "recursive", "r", false, "Recursively scan nested directories for agent definitions")
 	testCmd.Flags().BoolVar(&flagNoDedup, "no-dedup", false, "Disable content-hash deduplication (only with --recursive)")
+	testCmd.Flags().BoolVar(&flagIncremental, "incremental", false, "Analyze each agent's domains as its file is read, reporting each file, instead of after loading (only with --recursive)")
+	testCmd.Flags().BoolVar(&flagFailOnDuplicates, "fail-on-duplicates", false, "Exit 2 when deduplication finds agents with identical content (only with --recursive)")
+	testCmd.Flags().BoolVar(&flagNoCache, "no-cache", false, "Analyze every agent instead of reusing cached results for unchanged agents")
+	testCmd.Flags().StringVar(&flagCacheDir, "cache-dir", "", "Directory for "+analysis.CacheFileName+" (default: agent-evals/ under the user cache directory)")
+	testCmd.Flags().StringArrayVar(&flagSkipIDs, "skip-id", nil, "Skip agents whose ID matches this regex (repeatable)")
+	testCmd.Flags().StringArrayVar(&flagAgents, "agent", nil, "Evaluate only the agent with this ID, qualified or not (repeatable); overlaps and gaps then cover the subset only")
+	testCmd.Flags().StringArrayVar(&flagDomains, "domain", nil, "Evaluate only agents that claim this domain, and probe only this domain (repeatable)")
+	testCmd.Flags().StringArrayVar(&flagInclude, "include", nil, "Load only files whose path relative to <path> matches this glob, e.g. 'agents/**' (repeatable)")
+	testCmd.Flags().StringArrayVar(&flagExclude, "exclude", nil, "Skip files and directories whose path relative to <path> matches this glob, e.g. '**/vendor/**' (repeatable)")
+	testCmd.Flags().BoolVar(&flagRespectGitignore, "respect-gitignore", false, "Skip files and directories ignored by .gitignore files")
+	testCmd.Flags().BoolVar(&flagWarnUnusedDomains, "warn-unused-domains", false, "Report configured custom domains that no agent matched")
+	testCmd.Flags().BoolVar(&flagClaimsFromSkills, "claims-from-skills", false, "Treat domains named by skills/rules as claimed domains")
+	testCmd.Flags().BoolVar(&flagWarningsAsErrors, "warnings-as-errors", false, "Count warnings as errors in the overall score and CI result")
+	testCmd.Flags().StringVar(&flagFailOn, "fail-on", "", "Least severe issue that fails the CI result: error, warning or info (default: thresholds.fail_on, or error)")
+	testCmd.Flags().BoolVar(&flagTiming, "timing", false, "Print a wall-clock breakdown of each phase to stderr")
+	testCmd.Flags().IntVar(&flagWrapWidth, "wrap-width", 0, "Terminal report width in columns (default: terminal width, or 80; minimum 60)")
+
+	// ── schema command ───────────────────────────────────────────
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for the JSON report format",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Println(report.FormatJSONSchema())
+			return nil
+		},
+	}
+
+	// ── diff command ─────────────────────────────────────────────
+	var (
+		diffConfig        string
+		diffFormat        string
+		diffOutput        string
+		diffMaxRegression float64
+	)
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <old.json> <new.json>",
+		Short: "Compare two JSON reports and fail when scores regress",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			cfg, err := config.Load(diffConfig, ".")
+			if err != nil {
+				return exitErrorf(exitConfig, "load config: %w", err)
+			}
+			maxRegression := getFloatFromConfig(getMapFromConfig(cfg, "thresholds"), "max_regression", report.DefaultMaxRegression)
+			if cmd.Flags().Changed("max-regression") {
+				maxRegression = diffMaxRegression
+			}
+
+			var reports [2]*report.Report
+			for i, path := range args {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("read report: %w", err)
+				}
+				if reports[i], err = report.ParseJSON(data); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+
+			d := report.Diff(reports[0], reports[1], maxRegression)
+			var output string
+			switch diffFormat {
+			case "text":
+				output = report.FormatDiff(d)
+			case "json":
+				output = report.FormatDiffJSON(d)
+			default:
+				return fmt.Errorf("unknown diff format %q (want text or json)", diffFormat)
+			}
+			if err := writeOutput(output, diffOutput, diffFormat, true); err != nil {
+				return err
+			}
+			if d.HasRegressions() {
+				return fmt.Errorf("diff failed: %d metric(s) regressed beyond max_regression %.1f%%", len(d.Regressions), maxRegression*100)
+			}
+			return nil
+		},
+	}
+	diffCmd.Flags().StringVar(&diffConfig, "config", "", "Path to agent-evals.yaml config (for thresholds.max_regression)")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format: text, json")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "", "Write the diff to file")
+	diffCmd.Flags().Float64Var(&diffMaxRegression, "max-regression", report.DefaultMaxRegression, "Largest tolerated score drop, 0-1 (overrides thresholds.max_regression)")
+
+	// ── generate-questions command ───────────────────────────────
+	var (
+		genDomain    string
+		genCount     int
+		genConfig    string
+		genOutput    string
+		genProvider  string
+		genModel     string
+		genBaseURL   string
+		genAPIKeyEnv string
+		genCACert    string
+		genRegion    string
+	)
+
+	generateCmd := &cobra.Command{
+		Use:   "generate-questions",
+		Short: "Generate boundary and calibration questions for a domain with the LLM",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(genConfig, ".")
+			if err != nil {
+				return exitErrorf(exitConfig, "load config: %w", err)
+			}
+			providerCfg := resolveProviderConfig(cfg, genProvider, genModel, genBaseURL, genAPIKeyEnv, genCACert, genRegion)
+			client, err := provider.NewClient(providerCfg)
+			if err != nil {
+				return fmt.Errorf("initialize API client: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Generating %d questions for domain %q...\n", genCount, genDomain)
+			questions, err := probes.GenerateDomainQuestions(context.Background(), client, genDomain, genCount)
+			if err != nil {
+				return fmt.Errorf("generate questions: %w", err)
+			}
+			return writeQuestions(questions, genDomain, genOutput)
+		},
+	}
+	generateCmd.Flags().StringVar(&genDomain, "domain", "", "Domain to generate questions for (e.g. backend, payments)")
+	generateCmd.Flags().IntVar(&genCount, "count", 10, "Number of questions to generate")
+	generateCmd.Flags().StringVar(&genConfig, "config", "", "Path to agent-evals.yaml config (for probes.provider/model)")
+	generateCmd.Flags().StringVarP(&genOutput, "output", "o", "", "Write questions to file instead of stdout")
+	generateCmd.Flags().StringVar(&genProvider, "provider", "anthropic", "LLM provider: anthropic, openai, gemini, bedrock, openai-compatible")
+	generateCmd.Flags().StringVar(&genModel, "model", "", "Model to generate with")
+	generateCmd.Flags().StringVar(&genBaseURL, "base-url", "", "API base URL (regional or gateway endpoint; required for openai-compatible)")
+	generateCmd.Flags().StringVar(&genAPIKeyEnv, "api-key-env", "", "Environment variable name for API key")
+	generateCmd.Flags().StringVar(&genCACert, "ca-cert", "", "PEM file with extra CA certificates to trust for API calls")
+	generateCmd.Flags().StringVar(&genRegion, "region", "", "AWS region for the bedrock provider (default AWS_REGION)")
+	generateCmd.MarkFlagRequired("domain")
+
+	// ── init command ─────────────────────────────────────────────
+	var initForce bool
+
+	initCmd := &cobra.Command{
+		Use:   "init [path]",
+		Short: "Write a commented " + config.FileName + " template to a directory (default .)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			target := "."
+			if len(args) == 1 {
+				target = args[0]
+			}
+			path, err := initConfig(target, initForce)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+			return nil
+		},
+	}
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing config file")
+
+	// ── list command ─────────────────────────────────────────────
+	var (
+		listConfig    string
+		listFormat    string
+		listOutput    string
+		listRecursive bool
+		listNoDedup   bool
+		listNoPager   bool
+		listInclude   []string
+		listExclude   []string
+		listGitignore bool
+	)
+
+	listCmd := &cobra.Command{
+		Use:   "list <path>",
+		Short: "List the agents that would be analyzed, with their domains, without scoring them",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if listFormat != "terminal" && listFormat != "json" {
+				return fmt.Errorf("unknown list format %q (want terminal or json)", listFormat)
+			}
+			cfg, err := config.Load(listConfig, args[0])
+			if err != nil {
+				return exitErrorf(exitConfig, "load config: %w", err)
+			}
+			agents, err := loadAgents(args[0], loadOptions(cfg, listRecursive, listNoDedup, listInclude, listExclude, listGitignore))
+			if err != nil {
+				return exitErrorf(exitConfig, "load agents: %w", err)
+			}
+
+			keywords, extract := analysis.ResolveDomains(cfg), analysis.ResolveExtractOptions(cfg)
+			domains := make(map[string]map[string]float64, len(agents))
+			for i := range agents {
+				domains[agents[i].ID] = analysis.ExtractDomainsWith(&agents[i], keywords, extract)
+			}
+			return writeOutput(report.FormatAgentList(agents, domains, listFormat), listOutput, listFormat, listNoPager)
+		},
+	}
+	listCmd.Flags().StringVar(&listConfig, "config", "", "Path to agent-evals.yaml config (for domains)")
+	listCmd.Flags().StringVar(&listFormat, "format", "terminal", "Output format: terminal, json")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "", "Write the list to file")
+	listCmd.Flags().BoolVarP(&listRecursive, "recursive", "r", false, "Recursively scan nested directories for agent definitions")
+	listCmd.Flags().BoolVar(&listNoDedup, "no-dedup", false, "Disable content-hash deduplication (only with --recursive)")
+	listCmd.Flags().StringArrayVar(&listInclude, "include", nil, "Load only files whose path relative to <path> matches this glob, e.g. 'agents/**' (repeatable)")
+	listCmd.Flags().StringArrayVar(&listExclude, "exclude", nil, "Skip files and directories whose path relative to <path> matches this glob, e.g. '**/vendor/**' (repeatable)")
+	listCmd.Flags().BoolVar(&listGitignore, "respect-gitignore", false, "Skip files and directories ignored by .gitignore files")
+	listCmd.Flags().BoolVar(&listNoPager, "no-pager", false, "Disable automatic paging")
+
+	// ── diff-agent command ───────────────────────────────────────
+	var (
+		diffAgentBefore string
+		diffAgentAfter  string
+		diffAgentConfig string
+		diffAgentFormat string
+		diffAgentOutput string
+	)
+
+	diffAgentCmd := &cobra.Command{
+		Use:   "diff-agent --before <file> --after <file>",
+		Short: "Compare the detected domains of two versions of an agent definition",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if diffAgentFormat != "terminal" && diffAgentFormat != "json" {
+				return fmt.Errorf("unknown diff-agent format %q (want terminal or json)", diffAgentFormat)
+			}
+			cfg, err := config.Load(diffAgentConfig, filepath.Dir(diffAgentAfter))
+			if err != nil {
+				return exitErrorf(exitConfig, "load config: %w", err)
+			}
+			keywords, extract := analysis.ResolveDomains(cfg), analysis.ResolveExtractOptions(cfg)
+
+			var scores [2]map[string]float64
+			for i, path := range []string{diffAgentBefore, diffAgentAfter} {
+				agent, err := loadAgentVersion(path)
+				if err != nil {
+					return err
+				}
+				versions := []loader.AgentDefinition{agent}
+				mergeSkillClaims(versions, cfg)
+				scores[i] = analysis.ExtractDomainsWith(&versions[0], keywords, extract)
+			}
+
+			d := analysis.DiffDomains(scores[0], scores[1], analysis.ResolveDomainThresholds(cfg))
+			return writeOutput(report.FormatDomainDiff(d, diffAgentBefore, diffAgentAfter, diffAgentFormat), diffAgentOutput, diffAgentFormat, true)
+		},
+	}
+	diffAgentCmd.Flags().StringVar(&diffAgentBefore, "before", "", "Agent definition file before the edit")
+	diffAgentCmd.Flags().StringVar(&diffAgentAfter, "after", "", "Agent definition file after the edit")
+	diffAgentCmd.Flags().StringVar(&diffAgentConfig, "config", "", "Path to agent-evals.yaml config (for domains)")
+	diffAgentCmd.Flags().StringVar(&diffAgentFormat, "format", "terminal", "Output format: terminal, json")
+	diffAgentCmd.Flags().StringVarP(&diffAgentOutput, "output", "o", "", "Write the diff to file")
+	diffAgentCmd.MarkFlagRequired("before")
+	diffAgentCmd.MarkFlagRequired("after")
+
+	root.AddCommand(checkCmd, testCmd, schemaCmd, diffCmd, generateCmd, initCmd, listCmd, diffAgentCmd)
+	return root
+}
+
+// initConfig writes the config template to target, a directory or a .yaml
+// or .yml file path, and returns the path written. An existing file is only
+// replaced when force is set.
+func initConfig(target string, force bool) (string, error) {
+	path := target
+	if ext := filepath.Ext(target); ext != ".yaml" && ext != ".yml" {
+		path = filepath.Join(target, config.FileName)
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", fmt.Errorf("%s already exists (use --force to overwrite it)", path)
+	}
+
+	domains := make([]string, 0, len(analysis.BuiltinDomains))
+	for d := range analysis.BuiltinDomains {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	content := config.Template(domains, provider.DefaultModel("anthropic"))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("write config: %w", err)
+	}
+	return path, nil
+}
+
+// writeQuestions writes generated questions as a probes.questions config
+// snippet to path, or to stdout when path is empty.
+func writeQuestions(questions []probes.CustomQuestion, domain, path string) error {
+	out, err := probes.FormatCustomQuestions(domain, questions)
+	if err != nil {
+		return fmt.Errorf("format questions: %w", err)
+	}
+	if path == "" {
+		fmt.Print(out)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+		return fmt.Errorf("write questions: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d questions to %s\n", len(questions), path)
+	return nil
+}
 
-	root.AddCommand(checkCmd, testCmd)
+func loadAgents(path string, opts loader.LoadOptions) ([]loader.AgentDefinition, error) {
+	return loader.LoadAgentsFiltered(path, opts)
+}
+
+// loadOptions combines the --recursive, --no-dedup, --include, --exclude and
+// --respect-gitignore flags with the loader section of config: patterns from
+// both apply, and .gitignore files are respected when either enables it.
+func loadOptions(cfg map[string]any, recursive, noDedup bool, include, exclude []string, respectGitignore bool) loader.LoadOptions {
+	section := getMapFromConfig(cfg, "loader")
+	fromConfig, _ := section["respect_gitignore"].(bool)
+	return loader.LoadOptions{
+		Include:          append(append([]string{}, include...), toStrings(section["include"])...),
+		Exclude:          append(append([]string{}, exclude...), toStrings(section["exclude"])...),
+		Recursive:        recursive,
+		Dedup:            !noDedup,
+		RespectGitignore: respectGitignore || fromConfig,
+	}
+}
+
+// toStrings reads a config value holding a string or a list of strings.
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// openCache attaches the analysis cache in dir, or in the agents directory
+// when dir is empty, to analyzer. A cache that cannot be read is skipped
+// with a warning rather than failing the run.
+func openCache(analyzer *analysis.StaticAnalyzer, agentsPath, dir string, disabled bool) *analysis.Cache {
+	if disabled {
+		return nil
+	}
+	if dir == "" {
+		var err error
+		if dir, err = defaultCacheDir(agentsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; analyzing without cache\n", err)
+			return nil
+		}
+	}
+	cache, err := analysis.LoadCache(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; analyzing without cache\n", err)
+		return nil
+	}
+	analyzer.UseCache(cache)
+	return cache
+}
+
+// defaultCacheDir returns the cache directory for agentsPath under the
+// user's cache directory, one per agents path, creating it if needed.
+func defaultCacheDir(agentsPath string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate cache directory: %w", err)
+	}
+	abs, err := filepath.Abs(agentsPath)
+	if err != nil {
+		return "", fmt.Errorf("locate cache directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	dir := filepath.Join(base, "agent-evals", hex.EncodeToString(sum[:8]))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// saveCache writes the analysis cache after a report, warning if it cannot.
+func saveCache(cache *analysis.Cache) {
+	if cache == nil {
+		return
+	}
+	if hits, misses := cache.Stats(); hits > 0 {
+		fmt.Fprintf(chatter, "Reused cached analysis for %d of %d agent(s)\n", hits, hits+misses)
+	}
+	if err := cache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// streamAgents loads agents for --incremental: the tree is streamed and each
+// agent's domains are extracted by analyzer as soon as it is read and
+// reported to chatter, so only the fleet-wide analysis remains once the walk
+// ends.
+func streamAgents(path string, opts loader.LoadOptions, analyzer *analysis.StaticAnalyzer, cfg map[string]any) ([]loader.AgentDefinition, error) {
+	if !opts.Recursive {
+		return nil, fmt.Errorf("--incremental requires --recursive")
+	}
+	skillClaims, confidence := analysis.ResolveSkillClaims(cfg)
+	domains := analysis.ResolveDomains(cfg)
+
+	stream, errc := loader.StreamAgentsFiltered(path, opts)
+	var agents []loader.AgentDefinition
+	for agent := range stream {
+		agents = append(agents, agent)
+		added := agents[len(agents)-1:]
+		if skillClaims {
+			analysis.MergeSkillClaims(added, domains, confidence)
+		}
+		strong := analyzer.Add(&added[0])
+		switch {
+		case agent.ReferenceOnly:
+			fmt.Fprintf(chatter, "Read %s (reference only)\n", agent.SourcePath)
+		case len(strong) == 0:
+			fmt.Fprintf(chatter, "Analyzed %s: no strong domains\n", agent.SourcePath)
+		default:
+			fmt.Fprintf(chatter, "Analyzed %s: %s\n", agent.SourcePath, strings.Join(strong, ", "))
+		}
+	}
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	return loader.FinalizeRecursive(agents, opts.Dedup), nil
+}
+
+// loadAgentVersion loads the single agent defined by the file at path, for
+// diff-agent.
+func loadAgentVersion(path string) (loader.AgentDefinition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return loader.AgentDefinition{}, fmt.Errorf("agent file not found: %s", path)
+	}
+	if info.IsDir() {
+		return loader.AgentDefinition{}, fmt.Errorf("%s is a directory; diff-agent compares two agent files", path)
+	}
+	agents, err := loader.LoadAgents(path)
+	if err != nil {
+		return loader.AgentDefinition{}, fmt.Errorf("load %s: %w", path, err)
+	}
+	if len(agents) != 1 {
+		return loader.AgentDefinition{}, fmt.Errorf("%s defines %d agents; diff-agent compares one agent", path, len(agents))
+	}
+	return agents[0], nil
+}
+
+// skipAgents drops agents matching --skip-id patterns or scan.skip_ids in
+// config.
+func skipAgents(agents []loader.AgentDefinition, cfg map[string]any, flagPatterns []string) ([]loader.AgentDefinition, error) {
+	patterns := append([]string{}, flagPatterns...)
+	if list, ok := getMapFromConfig(cfg, "scan")["skip_ids"].([]any); ok {
+		for _, item := range list {
+			if p, ok := item.(string); ok {
+				patterns = append(patterns, p)
+			}
+		}
+	}
+	kept, err := loader.SkipAgents(agents, patterns)
+	if err != nil {
+		return nil, err
+	}
+	if skipped := len(agents) - len(kept); skipped > 0 {
+		fmt.Fprintf(chatter, "Skipped %d agent(s) matching skip patterns\n", skipped)
+	}
+	return kept, nil
+}
+
+// selectAgents keeps the agents named by --agent and, of those, the agents
+// probed on a --domain. It also returns the subset description the report
+// notes, or "" when no filter was given.
+func selectAgents(agents []loader.AgentDefinition, cfg map[string]any, ids, domains []string) ([]loader.AgentDefinition, string, error) {
+	if len(ids) == 0 && len(domains) == 0 {
+		return agents, "", nil
+	}
+	kept, err := loader.SelectAgents(agents, ids)
+	if err != nil {
+		return nil, "", fmt.Errorf("--agent: %w", err)
+	}
+	if len(domains) > 0 {
+		mergeSkillClaims(kept, cfg)
+		kept = probes.SelectDomainAgents(kept, domains, analysis.ResolveDomains(cfg), analysis.ResolveExtractOptions(cfg))
+		if len(kept) == 0 {
+			return nil, "", fmt.Errorf("--domain: no agent claims %s", strings.Join(domains, ", "))
+		}
+	}
+
+	var filters []string
+	for _, id := range ids {
+		filters = append(filters, "--agent "+id)
+	}
+	for _, d := range domains {
+		filters = append(filters, "--domain "+d)
+	}
+	subset := fmt.Sprintf("%d of %d agents (%s)", len(kept), len(agents), strings.Join(filters, ", "))
+	fmt.Fprintf(chatter, "Evaluating %s\n", subset)
+	return kept, subset, nil
+}
+
+// streamHeartbeat returns a RunConfig.OnChunk callback that prints at most
+// one progress line per interval, so logs show activity while long answers
+// stream in.
+func streamHeartbeat(w io.Writer, interval time.Duration) func(agentID, probeID, chunk string) {
+	var mu sync.Mutex
+	var last time.Time
+	received := 0
+	return func(agentID, probeID, chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		received += len(chunk)
+		if now := time.Now(); now.Sub(last) >= interval {
+			last = now
+			fmt.Fprintf(w, "  … streaming %s / %s (%d chars received)\n", agentID, probeID, received)
+		}
+	}
+}
+
+// progressEvent is a line of --progress json output. Agent and Probe are
+// empty on the final line of a canceled run.
+type progressEvent struct {
+	Done      int    `json:"done"`
+	Total     int    `json:"total"`
+	Agent     string `json:"agent"`
+	Probe     string `json:"probe"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+	Canceled  bool   `json:"canceled,omitempty"`
+}
+
+// checkProgressFormat reports whether format is a --progress value.
+func checkProgressFormat(format string) error {
+	switch format {
+	case "", "text", "json":
+		return nil
+	}
+	return fmt.Errorf("--progress must be text or json, got %q", format)
+}
+
+// progressPrinter returns the probe progress callback for --progress: a
+// progressEvent per line for json, timed from the call to progressPrinter,
+// or else a "[done/total] agent / probe" line per probe.
+func progressPrinter(w io.Writer, format string) probes.ProgressCallback {
+	if format == "json" {
+		start := time.Now()
+		return func(done, total int, agentID, probeID string) {
+			data, err := json.Marshal(progressEvent{
+				Done:      done,
+				Total:     total,
+				Agent:     agentID,
+				Probe:     probeID,
+				ElapsedMs: time.Since(start).Milliseconds(),
+				Canceled:  agentID == "",
+			})
+			if err == nil {
+				fmt.Fprintf(w, "%s\n", data)
+			}
+		}
+	}
+	return func(done, total int, agentID, probeID string) {
+		if agentID == "" {
+			fmt.Fprintf(w, "Canceled after %d/%d probes; reporting the completed ones\n", done, total)
+			return
+		}
+		fmt.Fprintf(w, "  [%d/%d] %s / %s\n", done, total, agentID, probeID)
+	}
+}
+
+// phaseTimer records the wall-clock duration of consecutive run phases for
+// --timing.
+type phaseTimer struct {
+	phases []analysis.PhaseTiming
+	start  time.Time
+}
 
-	if err := root.Execute(); err != nil {
-		os.Exit(1)
+func newPhaseTimer() *phaseTimer {
+	return &phaseTimer{start: time.Now()}
+}
+
+// lap records the time since the previous lap as phase name.
+func (t *phaseTimer) lap(name string) {
+	now := time.Now()
+	t.phases = append(t.phases, analysis.PhaseTiming{Name: name, Duration: now.Sub(t.start)})
+	t.start = now
+}
+
+// add records phases that were timed elsewhere and restarts the clock.
+func (t *phaseTimer) add(phases []analysis.PhaseTiming) {
+	t.phases = append(t.phases, phases...)
+	t.start = time.Now()
+}
+
+func (t *phaseTimer) print(enabled bool) {
+	if enabled {
+		fmt.Fprint(os.Stderr, "\n"+report.FormatTimings(t.phases))
+	}
+}
+
+// enableConfigOption sets cfg[section][name] to true so that a CLI flag can
+// switch on an option that is otherwise read from the config file.
+func enableConfigOption(cfg map[string]any, section, name string) {
+	setConfigOption(cfg, section, name, true)
+}
+
+// setConfigOption sets cfg[section][name] to value, creating the section
+// if needed.
+func setConfigOption(cfg map[string]any, section, name string, value any) {
+	m, ok := cfg[section].(map[string]any)
+	if !ok {
+		m = make(map[string]any)
+		cfg[section] = m
 	}
+	m[name] = value
 }
 
-func loadAgents(path string, recursive, noDedup bool) ([]loader.AgentDefinition, error) {
-	if recursive {
-		return loader.LoadAgentsRecursive(path, !noDedup)
+// mergeSkillClaims adds domains named by agents' skills and rules to their
+// effective claims when claims.from_skills is enabled.
+func mergeSkillClaims(agents []loader.AgentDefinition, cfg map[string]any) {
+	if enabled, confidence := analysis.ResolveSkillClaims(cfg); enabled {
+		analysis.MergeSkillClaims(agents, analysis.ResolveDomains(cfg), confidence)
 	}
-	return loader.LoadAgents(path)
 }
 
-func printLoadSummary(agents []loader.AgentDefinition, path string, recursive bool) {
+// printLoadSummary reports how many agents were loaded. After a recursive
+// scan that collapsed duplicates it also gives the number of files the
+// loader read agents from, files, and the duplicates collapsed.
+func printLoadSummary(agents []loader.AgentDefinition, path string, recursive bool, files int) {
 	if !recursive {
-		fmt.Fprintf(os.Stderr, "Loaded %d agent(s) from %s\n", len(agents), path)
+		fmt.Fprintf(chatter, "Loaded %d agent(s) from %s\n", len(agents), path)
 		return
 	}
 	dupes := 0
@@ -212,21 +1167,66 @@ func printLoadSummary(agents []loader.AgentDefinition, path string, recursive bo
 		dupes += len(a.AlsoFoundIn)
 	}
 	if dupes > 0 {
-		fmt.Fprintf(os.Stderr, "Loaded %d unique agent(s) from %s (%d duplicates collapsed)\n", len(agents), path, dupes)
+		fmt.Fprintf(chatter, "Loaded %d unique agent(s) from %s (%d files scanned, %d duplicates collapsed)\n", len(agents), path, files, dupes)
 	} else {
-		fmt.Fprintf(os.Stderr, "Loaded %d agent(s) from %s (recursive)\n", len(agents), path)
+		fmt.Fprintf(chatter, "Loaded %d agent(s) from %s (recursive)\n", len(agents), path)
 	}
 }
 
-func formatReport(static *analysis.StaticReport, live *probes.LiveProbeReport, format string) string {
+func formatReport(static *analysis.StaticReport, live *probes.LiveProbeReport, format string, runCfg *report.RunConfig, width int) string {
 	switch format {
 	case "json":
-		return report.FormatJSON(static, live)
+		return report.FormatJSONWithRunConfig(static, live, runCfg)
 	case "markdown":
 		return report.FormatMarkdown(static, live)
+	case "html":
+		return report.FormatHTML(static, live)
+	case "gitlab":
+		return report.FormatGitLabCodeQuality(static, live)
+	case "junit":
+		return report.FormatJUnitWithRunConfig(static, live, runCfg)
+	case "sarif":
+		return report.FormatSARIF(static, live)
 	default:
-		return report.FormatTerminal(static, live)
+		return report.FormatTerminalWidth(static, live, width)
+	}
+}
+
+// showReport writes the report in format to outputPath, or to stdout unless
+// browse is set; with browse, the results are then opened in the
+// interactive browser.
+func showReport(static *analysis.StaticReport, live *probes.LiveProbeReport, format string, runCfg *report.RunConfig, wrapWidth int, outputPath string, noPager, browse bool) error {
+	if !browse || outputPath != "" {
+		output := formatReport(static, live, format, runCfg, reportWidth(wrapWidth, outputPath))
+		if err := writeOutput(output, outputPath, format, noPager); err != nil {
+			return err
+		}
+	}
+	if browse {
+		return tui.Run(static, live)
+	}
+	return nil
+}
+
+// maxDetectedWidth caps the detected terminal width so lines stay readable
+// on very wide terminals; --wrap-width is not capped.
+const maxDetectedWidth = 120
+
+// reportWidth returns the terminal report width: --wrap-width if set,
+// otherwise the width of stdout when it is a terminal and the report is not
+// written to a file, otherwise report.DefaultWidth.
+func reportWidth(flagWidth int, outputPath string) int {
+	if flagWidth > 0 {
+		return flagWidth
 	}
+	if outputPath != "" || !isTerminal() {
+		return report.DefaultWidth
+	}
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return report.DefaultWidth
+	}
+	return min(width, maxDetectedWidth)
 }
 
 func writeOutput(output, path, format string, noPager bool) error {
@@ -235,12 +1235,13 @@ func writeOutput(output, path, format string, noPager bool) error {
 		if err := os.WriteFile(path, []byte(output), 0644); err != nil {
 			return fmt.Errorf("write output: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "Report written to %s\n", path)
+		fmt.Fprintf(chatter, "Report written to %s\n", path)
 		return nil
 	}
 
-	// Use pager for terminal format when stdout is a TTY
-	if format == "terminal" && !noPager && isTerminal() {
+	// Use pager for terminal format when stdout is a TTY that can run one;
+	// other formats, such as html, are written as-is for redirecting to a file
+	if format == "terminal" && !noPager && !pagerUnsuitable(os.Getenv) && isTerminal() {
 		return outputWithPager(output)
 	}
 
@@ -253,6 +1254,21 @@ func isTerminal() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
 }
 
+// pagerUnsuitable reports environments where paging gets in the way even
+// when stdout is a TTY: TERM unset or "dumb", which a pager cannot drive,
+// and CI runners, which often allocate a pseudo-TTY with nobody to quit the
+// pager. getenv is os.Getenv outside tests.
+func pagerUnsuitable(getenv func(string) string) bool {
+	if t := getenv("TERM"); t == "" || t == "dumb" {
+		return true
+	}
+	switch strings.ToLower(getenv("CI")) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
 // outputWithPager pipes output through a pager (less -R by default).
 func outputWithPager(output string) error {
 	pager := os.Getenv("PAGER")
@@ -292,27 +1308,192 @@ func outputWithPager(output string) error {
 	return nil
 }
 
+// checkCIResult returns the first failure report.Judge finds, with its
+// exit code, so --ci agrees with the JSON report's pass field.
 func checkCIResult(static *analysis.StaticReport, live *probes.LiveProbeReport, cfg map[string]any) error {
-	thresholds := getMapFromConfig(cfg, "thresholds")
-	minOverall := getFloatFromConfig(thresholds, "min_overall_score", 0.7)
+	f := report.Judge(static, live, ciGate(cfg))
+	if f == nil {
+		return nil
+	}
+	code := exitStatic
+	switch f.Category {
+	case report.FailOverall:
+		code = exitOverall
+	case report.FailLive:
+		code = exitLive
+	}
+	return exitErrorf(code, "check failed: %s", f.Message)
+}
 
-	if static.HasFailures() || static.Overall < minOverall {
-		return fmt.Errorf("check failed: overall score %.0f%% below threshold %.0f%%", static.Overall*100, minOverall*100)
+// ciGate reads the pass criteria that checkCIResult takes from config
+// rather than from the static report.
+func ciGate(cfg map[string]any) report.Gate {
+	enabled, _ := getMapFromConfig(cfg, "scan")["fail_on_duplicates"].(bool)
+	return report.Gate{
+		MinBoundaryScore: getFloatFromConfig(getMapFromConfig(cfg, "thresholds"), "min_boundary_score", report.DefaultGate.MinBoundaryScore),
+		FailOnDuplicates: enabled,
 	}
+}
 
-	if live != nil {
-		minBoundary := getFloatFromConfig(thresholds, "min_boundary_score", 0.5)
-		for agentID, results := range live.AgentResults {
-			if results.ProbesRun > 0 && results.BoundaryScore < minBoundary {
-				return fmt.Errorf("check failed: agent '%s' boundary score %.0f%% below threshold %.0f%%",
-					agentID, results.BoundaryScore*100, minBoundary*100)
-			}
-		}
+// Exit codes of the check and test commands, so CI can tell failures
+// apart. Any other error, such as a usage error or a regression found by
+// diff, exits 1.
+const (
+	exitFailure = 1
+	exitStatic  = 2 // a static issue at --fail-on severity, such as a conflict, or duplicate agents
+	exitOverall = 3 // overall score below thresholds.min_overall_score
+	exitLive    = 4 // a live boundary score below thresholds.min_boundary_score, or a live issue at --fail-on severity
+	exitConfig  = 5 // the config, agents or provider could not be loaded
+)
+
+// exitError is an error that exits with a code other than exitFailure.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// exitErrorf formats an error, as fmt.Errorf does, that exits with code.
+func exitErrorf(code int, format string, a ...any) error {
+	return &exitError{code: code, err: fmt.Errorf(format, a...)}
+}
+
+// exitCode returns the exit code for the error a command returned: 0 for
+// none, the code of an exitError, or exitFailure.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
 	}
+	return exitFailure
+}
+
+// failOnLevel names the severity static fails on, for messages.
+func failOnLevel(static *analysis.StaticReport) string {
+	if static.FailOn == "" {
+		return analysis.DefaultFailOn
+	}
+	return static.FailOn
+}
 
+// applyFailOn sets thresholds.fail_on from --fail-on, when given, and
+// checks the resulting level.
+func applyFailOn(cfg map[string]any, level string) error {
+	if level == "" {
+		_, err := analysis.ResolveFailOn(cfg)
+		return err
+	}
+	setConfigOption(cfg, "thresholds", "fail_on", level)
+	if _, err := analysis.ResolveFailOn(cfg); err != nil {
+		return fmt.Errorf("--fail-on must be error, warning or info, got %q", level)
+	}
+	return nil
+}
+
+// checkDuplicates fails when scan.fail_on_duplicates is enabled and
+// deduplication collapsed any agents, listing every duplicate location.
+func checkDuplicates(agents []loader.AgentDefinition, cfg map[string]any) error {
+	if !ciGate(cfg).FailOnDuplicates {
+		return nil
+	}
+	if f := report.DuplicatesFailure(agents); f != nil {
+		return exitErrorf(exitStatic, "check failed: %s", f.Message)
+	}
 	return nil
 }
 
+// buildRunConfig records the resolved configuration of a run for the JSON
+// report's run_config field.
+func buildRunConfig(static *analysis.StaticReport, cfg map[string]any, configPath string, recursive, noDedup bool) *report.RunConfig {
+	thresholds := getMapFromConfig(cfg, "thresholds")
+	gate := ciGate(cfg)
+	return &report.RunConfig{
+		ConfigPath:       configPath,
+		Recursive:        recursive,
+		Dedup:            recursive && !noDedup,
+		FailOnDuplicates: gate.FailOnDuplicates,
+		Thresholds: report.RunThresholds{
+			MinOverallScore:  static.Bands.Pass,
+			WarnOverallScore: static.Bands.Warn,
+			MinBoundaryScore: gate.MinBoundaryScore,
+			MaxOverlapScore:  getFloatFromConfig(thresholds, "max_overlap_score", analysis.DefaultMaxOverlapScore),
+			WarningsAsErrors: static.WarningsAsErrors,
+			FailOn:           failOnLevel(static),
+
+			StrongDomainScore:  static.Thresholds.Strong,
+			OverlapDomainScore: static.Thresholds.Overlap,
+		},
+	}
+}
+
+// probeRunConfig records the provider and probe settings of a live run. Only
+// the name of the API key variable is kept, never its value.
+func probeRunConfig(p provider.Config, budget, stochasticRuns, concurrency int) *report.ProbeRunConfig {
+	return &report.ProbeRunConfig{
+		Provider:       p.Provider,
+		Model:          providerModel(p),
+		BaseURL:        p.BaseURL,
+		APIKeyEnv:      p.APIKeyEnv,
+		CACertFile:     p.CACertFile,
+		Region:         p.Region,
+		ProbeBudget:    budget,
+		StochasticRuns: stochasticRuns,
+		Concurrency:    concurrency,
+	}
+}
+
+// providerModel returns the model p calls: its Model, or the provider's
+// default.
+func providerModel(p provider.Config) string {
+	if p.Model != "" {
+		return p.Model
+	}
+	return provider.DefaultModel(p.Provider)
+}
+
+// defaultMaxTotalCalls is the safety cap on planned API calls when
+// probes.max_total_calls is not set.
+const defaultMaxTotalCalls = 10000
+
+// resolveProfile returns the probe settings of a test run: those of the
+// --profile named name, or the flag values when no profile is given.
+// --probe-budget and --stochastic-runs override the profile when set.
+func resolveProfile(cfg map[string]any, name string, budget int, budgetSet bool, stochasticRuns int, runsSet bool) (probes.Profile, error) {
+	if name == "" {
+		return probes.Profile{Budget: budget, StochasticRuns: stochasticRuns}, nil
+	}
+	profile, err := probes.ResolveProfile(cfg, name)
+	if err != nil {
+		return probes.Profile{}, fmt.Errorf("--profile: %w", err)
+	}
+	if budgetSet {
+		profile.Budget = budget
+	}
+	if runsSet {
+		profile.StochasticRuns = stochasticRuns
+	}
+	return profile, nil
+}
+
+// checkCallBudget refuses a run that plans more API calls than
+// probes.max_total_calls (or defaultMaxTotalCalls), unless confirmed is set
+// by --yes/--force. Many agents and domains with a high --stochastic-runs
+// can otherwise plan tens of thousands of calls without notice.
+func checkCallBudget(estimate probes.RunEstimate, cfg map[string]any, confirmed bool) error {
+	limit := int(getFloatFromConfig(getMapFromConfig(cfg, "probes"), "max_total_calls", defaultMaxTotalCalls))
+	if limit <= 0 || estimate.Calls <= limit || confirmed {
+		return nil
+	}
+	return fmt.Errorf("run plans %d API calls, above the limit of %d (probes.max_total_calls); "+
+		"lower --probe-budget or --stochastic-runs, check the estimate with --dry-run, or pass --yes to run anyway",
+		estimate.Calls, limit)
+}
+
 // applyCIDefaults sets machine-friendly defaults when --ci is used:
 // JSON format and no pager, unless the user explicitly overrode them.
 func applyCIDefaults(cmd *cobra.Command, format *string, noPager *bool, ci bool) {
@@ -325,13 +1506,15 @@ func applyCIDefaults(cmd *cobra.Command, format *string, noPager *bool, ci bool)
 	*noPager = true
 }
 
-func resolveProviderConfig(cfg map[string]any, flagProvider, flagModel, flagBaseURL, flagAPIKeyEnv string) provider.Config {
+func resolveProviderConfig(cfg map[string]any, flagProvider, flagModel, flagBaseURL, flagAPIKeyEnv, flagCACert, flagRegion string) provider.Config {
 	probesCfg := getMapFromConfig(cfg, "probes")
 
 	p := provider.Config{
-		Provider: flagProvider,
-		Model:    flagModel,
-		BaseURL:  flagBaseURL,
+		Provider:   flagProvider,
+		Model:      flagModel,
+		BaseURL:    flagBaseURL,
+		CACertFile: flagCACert,
+		Region:     flagRegion,
 	}
 
 	// Fill from config file if flags not set
@@ -350,6 +1533,26 @@ func resolveProviderConfig(cfg map[string]any, flagProvider, flagModel, flagBase
 			p.BaseURL = u
 		}
 	}
+	if p.CACertFile == "" {
+		if f, ok := probesCfg["ca_cert"].(string); ok {
+			p.CACertFile = f
+		}
+	}
+	if p.Region == "" {
+		if r, ok := probesCfg["region"].(string); ok {
+			p.Region = r
+		}
+	}
+	if _, set := probesCfg["max_retries"]; set {
+		p.MaxRetries = int(getFloatFromConfig(probesCfg, "max_retries", 0))
+		if p.MaxRetries == 0 {
+			p.MaxRetries = -1 // max_retries: 0 disables retries
+		}
+	}
+	if p.Provider == "mock" {
+		p.MockConfidence = int(getFloatFromConfig(probesCfg, "mock_confidence", 0))
+		p.MockInScope = probes.MockScope
+	}
 	if flagAPIKeyEnv != "" {
 		p.APIKeyEnv = flagAPIKeyEnv
 	} else if env, ok := probesCfg["api_key_env"].(string); ok {
@@ -0,0 +1,679 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thinkwright/agent-evals/eval"
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/config"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+	"github.com/thinkwright/agent-evals/internal/provider"
+	"github.com/thinkwright/agent-evals/internal/report"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// TestMockProviderGoldenReports runs the test pipeline over the fixture
+// agents against the mock provider and compares the reports with golden
+// files. Run with -update after an intended report change.
+func TestMockProviderGoldenReports(t *testing.T) {
+	dir := filepath.Join("..", "..", "testdata", "fixtures")
+	cfg, err := config.Load("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents, err := loader.LoadAgents(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := provider.NewClient(provider.Config{Provider: "mock", MockInScope: probes.MockScope})
+	if err != nil {
+		t.Fatal(err)
+	}
+	static, live, err := eval.RunFullWithClient(context.Background(), agents, cfg, client, eval.RunConfig{
+		Budget: 200,
+		Runner: probes.RunConfig{StochasticRuns: 2, Concurrency: 1, RequestsPerSecond: 1e6, Seed: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := analysis.RunInfo{ID: "00000000-0000-4000-8000-000000000000", Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	static.Run = run
+	live.Timestamp = run.TimestampString()
+
+	for _, format := range []string{"markdown", "junit"} {
+		got := formatReport(static, live, format, nil, 0)
+		path := filepath.Join("testdata", "mock_report."+format+".golden")
+		if *update {
+			if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%v (run with -update to create it)", err)
+		}
+		if got != string(want) {
+			t.Errorf("%s report differs from %s (run with -update if intended):\n%s", format, path, got)
+		}
+	}
+}
+
+func TestSelectAgentsSubset(t *testing.T) {
+	dir := filepath.Join("..", "..", "testdata", "fixtures")
+	cfg, err := config.Load("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents, err := loader.LoadAgents(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept, subset, err := selectAgents(agents, cfg, []string{"backend_api"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0].ID != "backend_api" {
+		t.Fatalf("expected only backend_api, got %v", kept)
+	}
+	if want := fmt.Sprintf("1 of %d agents (--agent backend_api)", len(agents)); subset != want {
+		t.Errorf("subset = %q, want %q", subset, want)
+	}
+
+	static := eval.RunStatic(kept, cfg)
+	static.Subset = subset
+	if len(static.Overlaps) != 0 {
+		t.Errorf("expected no overlaps for a single agent, got %v", static.Overlaps)
+	}
+	plan, err := eval.PlanProbes(static, cfg, eval.RunConfig{Budget: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Questions) == 0 {
+		t.Fatal("expected probes for backend_api")
+	}
+	for _, q := range plan.Questions {
+		if q.TargetAgent != "backend_api" {
+			t.Errorf("probe %s targets %s, want backend_api only", q.ID, q.TargetAgent)
+		}
+	}
+	if md := report.FormatMarkdown(static, nil); !strings.Contains(md, "overlaps and gaps cover these agents only") {
+		t.Errorf("expected the markdown report to note the subset:\n%s", md)
+	}
+
+	if _, _, err := selectAgents(agents, cfg, []string{"nobody"}, nil); err == nil {
+		t.Error("expected an error for an --agent that matches no agent")
+	}
+	if _, _, err := selectAgents(agents, cfg, nil, []string{"payments"}); err == nil {
+		t.Error("expected an error for a --domain no agent claims")
+	}
+	if kept, subset, _ := selectAgents(agents, cfg, nil, nil); len(kept) != len(agents) || subset != "" {
+		t.Errorf("without filters all agents should be kept with no subset, got %d, %q", len(kept), subset)
+	}
+}
+
+func TestCheckCIResultFailOn(t *testing.T) {
+	issues := []analysis.Issue{
+		{Severity: "warning", Category: "overlap", Message: "High scope overlap"},
+		{Severity: "info", Category: "unused_domain", Message: "Unused domain"},
+	}
+	live := &probes.LiveProbeReport{
+		Issues: []analysis.Issue{{Severity: "info", Category: "robustness", Message: "Order-sensitive answers"}},
+	}
+	tests := []struct {
+		failOn     string
+		live       *probes.LiveProbeReport
+		wantErr    string // "" for a pass
+		jsonPasses bool
+	}{
+		{"", nil, "", true},
+		{"error", live, "", true},
+		{"warning", nil, "1 issue(s) at --fail-on warning: High scope overlap", false},
+		{"info", nil, "2 issue(s) at --fail-on info", false},
+	}
+	for _, tt := range tests {
+		cfg := map[string]any{}
+		if err := applyFailOn(cfg, tt.failOn); err != nil {
+			t.Fatal(err)
+		}
+		static := analysis.RunStaticAnalysis(nil, cfg)
+		static.Issues = issues
+		static.Overall = 0.9
+
+		err := checkCIResult(static, tt.live, cfg)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("--fail-on %q: expected a pass, got %v", tt.failOn, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("--fail-on %q: expected an error containing %q, got %v", tt.failOn, tt.wantErr, err)
+		}
+
+		var decoded report.Report
+		if err := json.Unmarshal([]byte(report.FormatJSON(static, tt.live)), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Pass != tt.jsonPasses {
+			t.Errorf("--fail-on %q: JSON pass = %v, want %v", tt.failOn, decoded.Pass, tt.jsonPasses)
+		}
+	}
+
+	// A live info issue fails at --fail-on info even when static issues pass
+	cfg := map[string]any{}
+	if err := applyFailOn(cfg, "info"); err != nil {
+		t.Fatal(err)
+	}
+	static := analysis.RunStaticAnalysis(nil, cfg)
+	static.Overall = 0.9
+	if err := checkCIResult(static, live, cfg); err == nil || !strings.Contains(err.Error(), "Order-sensitive") {
+		t.Errorf("expected the live info issue to fail the check, got %v", err)
+	}
+
+	if err := applyFailOn(map[string]any{}, "critical"); err == nil || !strings.Contains(err.Error(), "--fail-on") {
+		t.Errorf("expected an error naming --fail-on, got %v", err)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	fixtures := filepath.Join("..", "..", "testdata", "fixtures")
+	dir := t.TempDir()
+	config := func(name, thresholds string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("thresholds:\n"+thresholds), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	strictOverall := config("overall.yaml", "  min_overall_score: 0.99\n")
+	strictBoundary := config("boundary.yaml", "  min_boundary_score: 0.99\n")
+	out := filepath.Join(dir, "report.json")
+	check := []string{"check", fixtures, "--ci", "--no-cache", "-o", out}
+	mock := []string{"test", fixtures, "--ci", "--no-cache", "-o", out, "--provider", "mock",
+		"--probe-budget", "300", "--stochastic-runs", "1", "--requests-per-second", "1000000"}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"pass", check, 0},
+		{"unknown flag", append(check, "--no-such-flag"), exitFailure},
+		{"static issue", append(check, "--fail-on", "warning"), exitStatic},
+		{"overall below threshold", append(check, "--config", strictOverall), exitOverall},
+		{"live boundary below threshold", append(mock, "--config", strictBoundary), exitLive},
+		{"missing agents", []string{"check", filepath.Join(dir, "missing"), "--ci"}, exitConfig},
+		{"invalid --fail-on", append(check, "--fail-on", "critical"), exitConfig},
+		{"unknown provider", []string{"test", fixtures, "--ci", "--no-cache", "--provider", "nope"}, exitConfig},
+		{"invalid --progress", append(mock, "--progress", "xml"), exitConfig},
+		{"invalid --transcript-fields", append(mock, "--transcript-fields", "nope"), exitConfig},
+		{"unknown profile", append(mock, "--profile", "nope"), exitConfig},
+		{"missing probes file", append(mock, "--probes-file", filepath.Join(dir, "missing.yaml")), exitConfig},
+		{"unknown agent", append(mock, "--agent", "nope"), exitConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(tt.args); got != tt.want {
+				t.Errorf("agent-evals %s: exit code %d, want %d", strings.Join(tt.args, " "), got, tt.want)
+			}
+		})
+	}
+}
+
+// runCapturingStderr runs the command line args, returning the exit code
+// and everything written to stderr, chatter included.
+func runCapturingStderr(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(f *os.File, c io.Writer) { os.Stderr, chatter = f, c }(os.Stderr, chatter)
+	os.Stderr = w
+	captured := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		captured <- data
+	}()
+
+	code := run(args)
+	w.Close()
+	return code, string(<-captured)
+}
+
+func TestPreCommitReportsLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	code, stderr := runCapturingStderr(t, "check", filepath.Join(dir, "missing"), "--pre-commit")
+	if code != exitConfig || !strings.Contains(stderr, "load agents") {
+		t.Errorf("missing agents dir: exit code %d, stderr %q; want %d and the load error", code, stderr, exitConfig)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("thresholds: [not, a, map"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, stderr = runCapturingStderr(t, "check", filepath.Join("..", "..", "testdata", "fixtures"), "--pre-commit", "--no-cache", "--config", bad)
+	if code != exitConfig || !strings.Contains(stderr, "load config") {
+		t.Errorf("broken config: exit code %d, stderr %q; want %d and the config error", code, stderr, exitConfig)
+	}
+}
+
+func TestIncrementalReportsEachFile(t *testing.T) {
+	path := filepath.Join("..", "..", "internal", "loader", "testdata", "recursive")
+	code, stderr := runCapturingStderr(t, "check", path, "-r", "--incremental", "--no-cache", "-o", filepath.Join(t.TempDir(), "report.txt"))
+	if code == exitConfig || code == exitFailure {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+	for _, file := range []string{"plugin-a/agents/data-engineer.md", "plugin-b/agents/frontend-dev.md", "plugin-c/agents/backend-architect.md"} {
+		if !strings.Contains(stderr, "Analyzed "+filepath.FromSlash(file)+": ") {
+			t.Errorf("expected a progress line for %s, got:\n%s", file, stderr)
+		}
+	}
+	if got := strings.Count(stderr, "Analyzed "); got != 5 {
+		t.Errorf("expected one progress line per file, got %d:\n%s", got, stderr)
+	}
+	if strings.Index(stderr, "Analyzed ") > strings.Index(stderr, "Loaded ") {
+		t.Errorf("files should be reported as they are read, before the load summary:\n%s", stderr)
+	}
+}
+
+func TestSkipIDFlag(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "report.json")
+	code, stderr := runCapturingStderr(t, "check", filepath.Join("..", "..", "testdata", "fixtures"), "--no-cache",
+		"--format", "json", "-o", out, "--skip-id", "^backend", "--skip-id", "guru$")
+	if code == exitConfig || code == exitFailure {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded report.Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, a := range decoded.Agents {
+		ids = append(ids, a.ID)
+	}
+	if want := []string{"devops_platform", "frontend_react", "transcript"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("agents = %v, want %v", ids, want)
+	}
+
+	code, stderr = runCapturingStderr(t, "check", filepath.Join("..", "..", "testdata", "fixtures"), "--no-cache", "--skip-id", "(")
+	if code != exitConfig {
+		t.Errorf("invalid --skip-id regex: exit code %d, stderr %q; want %d", code, stderr, exitConfig)
+	}
+}
+
+func TestDryRunStaysWithinBudget(t *testing.T) {
+	for _, runs := range []string{"1", "10"} {
+		out := filepath.Join(t.TempDir(), "dryrun.json")
+		code, stderr := runCapturingStderr(t, "test", filepath.Join("..", "..", "testdata", "fixtures"), "--no-cache",
+			"--provider", "mock", "--dry-run", "--format", "json", "-o", out, "--probe-budget", "200", "--stochastic-runs", runs)
+		if code != 0 {
+			t.Fatalf("--stochastic-runs %s: exit code %d, stderr:\n%s", runs, code, stderr)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded report.DryRunReport
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.OverBudget || decoded.TotalCalls > decoded.Budget || decoded.Probes == 0 {
+			t.Errorf("--stochastic-runs %s: planned %d probes, %d calls for a budget of %d", runs, decoded.Probes, decoded.TotalCalls, decoded.Budget)
+		}
+	}
+}
+
+func TestDebugLogging(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"m","choices":[{"message":{"content":"","reasoning_content":"Not sure.\nCONFIDENCE: 40"}}]}`))
+	}))
+	defer server.Close()
+
+	args := []string{"test", filepath.Join("..", "..", "testdata", "fixtures"), "--no-cache", "-o", filepath.Join(t.TempDir(), "report.json"),
+		"--provider", "openai-compatible", "--base-url", server.URL, "--model", "m",
+		"--agent", "backend_api", "--probe-budget", "12", "--stochastic-runs", "1", "--requests-per-second", "1000000"}
+	code, stderr := runCapturingStderr(t, args...)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+	if strings.Contains(stderr, "non-standard field") {
+		t.Errorf("debug message logged without --debug:\n%s", stderr)
+	}
+
+	code, stderr = runCapturingStderr(t, append(args, "--debug")...)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+	if !strings.Contains(stderr, "level=DEBUG") || !strings.Contains(stderr, "field=message.reasoning_content") {
+		t.Errorf("expected the answer field in a debug message with --debug, got:\n%s", stderr)
+	}
+}
+
+func TestProgressJSON(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "report.json")
+	code, stderr := runCapturingStderr(t, "test", filepath.Join("..", "..", "testdata", "fixtures"), "--no-cache", "-o", out,
+		"--provider", "mock", "--probe-budget", "60", "--stochastic-runs", "1", "--requests-per-second", "1000000",
+		"--progress", "json", "--transcript", filepath.Join(t.TempDir(), "transcript.md"))
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+
+	// Every stderr line is a JSON progress event; other messages are dropped.
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("stderr line %q is not JSON: %v", line, err)
+		}
+		for _, key := range []string{"done", "total", "agent", "probe", "elapsed_ms"} {
+			if _, ok := event[key]; !ok {
+				t.Errorf("progress line %q has no %q", line, key)
+			}
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		t.Fatalf("no JSON progress lines in stderr:\n%s", stderr)
+	}
+	last := events[len(events)-1]
+	if total := last["total"].(float64); last["done"] != total || float64(len(events)) != total {
+		t.Errorf("%d progress lines ending at %v/%v, want one per probe", len(events), last["done"], total)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := exitCode(nil); got != 0 {
+		t.Errorf("no error: exit code %d, want 0", got)
+	}
+	if got := exitCode(fmt.Errorf("plain")); got != exitFailure {
+		t.Errorf("plain error: exit code %d, want %d", got, exitFailure)
+	}
+	wrapped := fmt.Errorf("context: %w", exitErrorf(exitLive, "boundary"))
+	if got := exitCode(wrapped); got != exitLive {
+		t.Errorf("wrapped exit error: exit code %d, want %d", got, exitLive)
+	}
+}
+
+func TestCacheDefaultsToUserCacheDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	base, err := os.UserCacheDir()
+	if err != nil {
+		t.Skipf("no user cache directory: %v", err)
+	}
+
+	agents := t.TempDir()
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "fixtures", "backend_api.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(agents, "backend_api.yaml"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, stderr := runCapturingStderr(t, "check", agents, "-o", filepath.Join(home, "report.json")); code != 0 {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(agents, analysis.CacheFileName)); err == nil {
+		t.Error("cache file written into the agents directory")
+	}
+	if found, _ := filepath.Glob(filepath.Join(base, "agent-evals", "*", analysis.CacheFileName)); len(found) != 1 {
+		t.Errorf("expected one cache file under %s, found %v", base, found)
+	}
+}
+
+func TestFailOnDuplicates(t *testing.T) {
+	// plugin-a and plugin-b hold identical backend-architect definitions.
+	path := filepath.Join("..", "..", "internal", "loader", "testdata", "recursive")
+	agents, err := loadAgents(path, loader.LoadOptions{Recursive: true, Dedup: true})
+	if err != nil {
+		t.Fatalf("load agents: %v", err)
+	}
+
+	cfg := map[string]any{}
+	if err := checkDuplicates(agents, cfg); err != nil {
+		t.Errorf("duplicates should not fail without the option: %v", err)
+	}
+
+	enableConfigOption(cfg, "scan", "fail_on_duplicates")
+	static := analysis.RunStaticAnalysis(agents, cfg)
+	err = checkCIResult(static, nil, cfg)
+	if err == nil {
+		t.Fatal("expected --fail-on-duplicates to fail the check")
+	}
+	for _, want := range []string{"1 duplicate", "plugin-a", "plugin-b", "backend-architect.md"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+
+	var decoded report.Report
+	if err := json.Unmarshal([]byte(report.FormatJSONWithRunConfig(static, nil, buildRunConfig(static, cfg, "", true, false))), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Pass {
+		t.Error("JSON pass should be false when --fail-on-duplicates fails the check")
+	}
+}
+
+func TestFailOnDuplicatesNoDedup(t *testing.T) {
+	path := filepath.Join("..", "..", "internal", "loader", "testdata", "recursive")
+	agents, err := loadAgents(path, loader.LoadOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("load agents: %v", err)
+	}
+	cfg := map[string]any{"scan": map[string]any{"fail_on_duplicates": true}}
+	if err := checkDuplicates(agents, cfg); err != nil {
+		t.Errorf("without dedup no duplicates are recorded, got %v", err)
+	}
+}
+
+func TestRecursiveLoadSummary(t *testing.T) {
+	path := filepath.Join("..", "..", "internal", "loader", "testdata", "recursive")
+	defer func(w io.Writer) { chatter = w }(chatter)
+
+	cases := []struct {
+		name      string
+		recursive bool
+		noDedup   bool
+		agents    int
+		want      string
+	}{
+		// Every agent is nested, so only a recursive scan finds them.
+		{"flat", false, false, 0, "Loaded 0 agent(s)"},
+		{"dedup", true, false, 4, "Loaded 4 unique agent(s) from " + path + " (5 files scanned, 1 duplicates collapsed)"},
+		{"no dedup", true, true, 5, "Loaded 5 agent(s) from " + path + " (recursive)"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stats loader.LoadStats
+			agents, err := loadAgents(path, loader.LoadOptions{Recursive: tc.recursive, Dedup: !tc.noDedup, Stats: &stats})
+			if err != nil {
+				t.Fatalf("load agents: %v", err)
+			}
+			if len(agents) != tc.agents {
+				t.Errorf("expected %d agents, got %d", tc.agents, len(agents))
+			}
+
+			var buf bytes.Buffer
+			chatter = &buf
+			printLoadSummary(agents, path, tc.recursive, stats.Files)
+			if !strings.Contains(buf.String(), tc.want) {
+				t.Errorf("summary %q should contain %q", buf.String(), tc.want)
+			}
+
+			if tc.recursive && !tc.noDedup {
+				meta := report.BuildReport(analysis.RunStaticAnalysis(agents, map[string]any{}), nil).ScanMetadata
+				if meta == nil || meta.TotalFilesScanned != 5 || meta.DuplicatesCollapsed != 1 {
+					t.Errorf("scan_metadata should match the summary, got %+v", meta)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadOptionsExclude(t *testing.T) {
+	path := filepath.Join("..", "..", "internal", "loader", "testdata", "filtered")
+	cfg := map[string]any{"loader": map[string]any{"exclude": []any{"node_modules/**"}}}
+	opts := loadOptions(cfg, true, false, nil, []string{"**/vendor/**"}, false)
+	if len(opts.Exclude) != 2 {
+		t.Fatalf("flag and config patterns should both apply, got %v", opts.Exclude)
+	}
+
+	agents, err := loadAgents(path, opts)
+	if err != nil {
+		t.Fatalf("load agents: %v", err)
+	}
+	for _, a := range agents {
+		if strings.Contains(a.SourcePath, "vendor") || strings.Contains(a.SourcePath, "node_modules") {
+			t.Errorf("excluded agent %s (%s) was loaded", a.ID, a.SourcePath)
+		}
+	}
+	if len(agents) != 2 {
+		t.Errorf("expected the 2 agents outside vendored directories, got %d", len(agents))
+	}
+
+	if opts.RespectGitignore {
+		t.Error(".gitignore files should be ignored by default")
+	}
+	cfg["loader"].(map[string]any)["respect_gitignore"] = true
+	if !loadOptions(cfg, true, false, nil, nil, false).RespectGitignore {
+		t.Error("loader.respect_gitignore should enable .gitignore support")
+	}
+}
+
+func TestCheckCallBudget(t *testing.T) {
+	cfg := map[string]any{"probes": map[string]any{"max_total_calls": 100}}
+
+	if err := checkCallBudget(probes.RunEstimate{Calls: 100}, cfg, false); err != nil {
+		t.Errorf("a run at the limit should proceed: %v", err)
+	}
+	err := checkCallBudget(probes.RunEstimate{Calls: 600}, cfg, false)
+	if err == nil {
+		t.Fatal("expected a run above probes.max_total_calls to abort without --yes")
+	}
+	for _, want := range []string{"600", "100", "--yes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+	if err := checkCallBudget(probes.RunEstimate{Calls: 600}, cfg, true); err != nil {
+		t.Errorf("--force should let the run proceed: %v", err)
+	}
+
+	if err := checkCallBudget(probes.RunEstimate{Calls: defaultMaxTotalCalls + 1}, map[string]any{}, false); err == nil {
+		t.Error("expected the default safety cap to apply without probes.max_total_calls")
+	}
+}
+
+func TestInitConfig(t *testing.T) {
+	dir := t.TempDir()
+	path, err := initConfig(dir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if path != filepath.Join(dir, config.FileName) {
+		t.Errorf("wrote %s, want %s in %s", path, config.FileName, dir)
+	}
+
+	// The template is read by the same code as a hand-written config, and
+	// keeps every built-in domain.
+	cfg, err := config.Load("", dir)
+	if err != nil {
+		t.Fatalf("load template: %v", err)
+	}
+	domains := analysis.ResolveDomains(cfg)
+	for d := range analysis.BuiltinDomains {
+		if _, ok := domains[d]; !ok {
+			t.Errorf("template drops built-in domain %q", d)
+		}
+	}
+	if got := len(domains["backend"]); got != len(analysis.BuiltinDomains["backend"])+3 {
+		t.Errorf("extended backend domain has %d keywords, want the built-in ones plus 3", got)
+	}
+	if bands := analysis.ResolveScoreBands(cfg); bands != analysis.DefaultScoreBands {
+		t.Errorf("template thresholds %+v should match the defaults %+v", bands, analysis.DefaultScoreBands)
+	}
+	pc := resolveProviderConfig(cfg, "anthropic", "", "", "", "", "")
+	if pc.Provider != "anthropic" || pc.Model == "" || pc.APIKeyEnv != "ANTHROPIC_API_KEY" {
+		t.Errorf("template provider settings not picked up: %+v", pc)
+	}
+
+	// An existing file is kept unless forced.
+	if err := os.WriteFile(path, []byte("thresholds: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := initConfig(dir, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected init to refuse to overwrite, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "thresholds: {}\n" {
+		t.Error("existing config was overwritten without --force")
+	}
+	if _, err := initConfig(path, true); err != nil {
+		t.Errorf("init --force: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "extends: builtin") {
+		t.Error("--force should replace the existing config")
+	}
+}
+
+func TestResolveProviderConfigMaxRetries(t *testing.T) {
+	tests := []struct {
+		probes map[string]any
+		want   int
+	}{
+		{nil, 0},
+		{map[string]any{"max_retries": 5}, 5},
+		{map[string]any{"max_retries": 0}, -1},
+	}
+	for _, tt := range tests {
+		pc := resolveProviderConfig(map[string]any{"probes": tt.probes}, "anthropic", "", "", "", "", "")
+		if pc.MaxRetries != tt.want {
+			t.Errorf("probes %v: MaxRetries = %d, want %d", tt.probes, pc.MaxRetries, tt.want)
+		}
+	}
+}
+
+func TestPagerUnsuitable(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"interactive terminal", map[string]string{"TERM": "xterm-256color"}, false},
+		{"dumb terminal", map[string]string{"TERM": "dumb"}, true},
+		{"TERM unset", map[string]string{}, true},
+		{"CI runner with a pseudo-TTY", map[string]string{"TERM": "xterm", "CI": "true"}, true},
+		{"CI disabled", map[string]string{"TERM": "xterm", "CI": "false"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := pagerUnsuitable(getenv); got != tt.want {
+				t.Errorf("pagerUnsuitable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Package eval runs agent-evals in process: the static analysis of the check
+// command, and the static analysis plus live probes of the test command,
+// without the CLI's flags, pager, report formatting or exit codes.
+//
+// A deploy-time check might look like:
+//
+//	cfg, err := eval.LoadConfig("", "agents")
+//	agents, err := eval.LoadAgents("agents")
+//	static, live, err := eval.RunFull(ctx, agents, cfg,
+//		eval.ProviderConfig{Provider: "anthropic"}, eval.RunConfig{Budget: 50})
+//	if static.HasFailures() || live.HasFailuresAt(static.FailOn) { ... }
+//
+// The types below alias those of the internal packages, so that callers
+// outside this module can name them.
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/config"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+type (
+	AgentDefinition  = loader.AgentDefinition
+	StaticReport     = analysis.StaticReport
+	StaticAnalyzer   = analysis.StaticAnalyzer
+	RunInfo          = analysis.RunInfo
+	LiveProbeReport  = probes.LiveProbeReport
+	ProbeQuestion    = probes.ProbeQuestion
+	ProbeRunConfig   = probes.RunConfig
+	ProgressCallback = probes.ProgressCallback
+	RunEstimate      = probes.RunEstimate
+	ProviderConfig   = provider.Config
+	LLMClient        = provider.LLMClient
+)
+
+// LoadConfig loads configPath, or the agent-evals.yaml alongside agentsPath
+// when configPath is empty. With neither, the config is empty.
+func LoadConfig(configPath, agentsPath string) (map[string]any, error) {
+	return config.Load(configPath, agentsPath)
+}
+
+// LoadAgents loads the agent definitions in path, a file or a directory.
+func LoadAgents(path string) ([]AgentDefinition, error) {
+	return loader.LoadAgents(path)
+}
+
+// RunStatic runs the static analysis of the check command on agents.
+func RunStatic(agents []AgentDefinition, cfg map[string]any) *StaticReport {
+	return RunStaticWith(analysis.NewStaticAnalyzer(cfg), agents, cfg)
+}
+
+// NewRunInfo returns a fresh run ID timestamped now. The analysis leaves
+// StaticReport.Run zero so its reports are reproducible; set it to label
+// the reports of one run.
+func NewRunInfo() RunInfo {
+	return analysis.NewRunInfo()
+}
+
+// RunStaticWith is RunStatic with an analyzer built by the caller from the
+// same cfg, for example one with a cache attached or agents already added.
+// When claims.from_skills is set, it fills in the agents' skill domains
+// first.
+func RunStaticWith(analyzer *StaticAnalyzer, agents []AgentDefinition, cfg map[string]any) *StaticReport {
+	if enabled, confidence := analysis.ResolveSkillClaims(cfg); enabled {
+		analysis.MergeSkillClaims(agents, analysis.ResolveDomains(cfg), confidence)
+	}
+	return analyzer.Report(agents)
+}
+
+// RunConfig holds the settings of a live run that the test command takes
+// from flags rather than config.
+type RunConfig struct {
+	// Budget is the maximum number of API calls, as with --probe-budget:
+	// probes are generated until each one's first call plus its stochastic
+	// runs would exceed it. Zero uses probes.DefaultProbeBudget.
+	Budget int
+
+	// ProbeTypes, when non-empty, keeps only probes of these types.
+	ProbeTypes []string
+
+	// OnlyDomains, when non-empty, keeps only the probes of these domains
+	// (see probes.GenerateOptions.OnlyDomains).
+	OnlyDomains []string
+
+	// QuestionsFile is a YAML or JSON file of extra probe questions keyed by
+	// domain. Empty uses probes.questions_file from config, if any.
+	QuestionsFile string
+
+	// Runner configures the probe runner. MinProbesForScore,
+	// HedgeThresholds, RobustnessCheck, RobustnessSeed, ConversationPrefixes
+	// and Language are read from config; the rest are used as given, except
+	// that a zero StochasticRuns uses probes.DefaultStochasticRuns. Seed also
+	// seeds probe selection.
+	Runner ProbeRunConfig
+
+	// Progress, if set, is called by RunFull after each probe completes.
+	Progress ProgressCallback
+}
+
+// Plan is a live run ready to start: the agents to probe, their probes and
+// the runner settings, resolved from config and a RunConfig.
+type Plan struct {
+	Agents    []AgentDefinition
+	Questions []ProbeQuestion
+	Runner    ProbeRunConfig
+	Budget    int
+
+	// Warnings are problems with the probe settings that do not stop the
+	// run, such as questions file domains no agent claims.
+	Warnings []string
+}
+
+// PlanProbes generates the probes of a live run over the agents of static,
+// leaving out reference-only agents, which are not probed. It reads the
+// probe and scoring settings of cfg, so a caller can estimate or confirm a
+// run before RunPlan calls the provider.
+func PlanProbes(static *StaticReport, cfg map[string]any, runCfg RunConfig) (*Plan, error) {
+	agents := static.Agents
+	customQuestions, replaceBuiltin, warnings, err := resolveCustomQuestions(cfg, runCfg.QuestionsFile, agents)
+	if err != nil {
+		return nil, err
+	}
+	prefixes, err := probes.ResolveConversationPrefixes(cfg)
+	if err != nil {
+		return nil, err
+	}
+	language, err := probes.ResolveLanguage(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := runCfg.Budget
+	if budget == 0 {
+		budget = probes.DefaultProbeBudget
+	}
+	runner := runCfg.Runner
+	if runner.StochasticRuns == 0 {
+		runner.StochasticRuns = probes.DefaultStochasticRuns
+	}
+	questions := probes.GenerateProbesWithOptions(agents, budget, probes.GenerateOptions{
+		Adjacency:        probes.ResolveAdjacency(cfg),
+		InScopeQuestions: probes.ResolveInScopeQuestions(cfg),
+		HardCalibration:  probes.ResolveHardCalibration(cfg),
+		CustomQuestions:  customQuestions,
+		ReplaceBuiltin:   replaceBuiltin,
+		Domains:          analysis.ResolveDomains(cfg),
+		Extract:          analysis.ResolveExtractOptions(cfg),
+		Overlaps:         probes.ResolveOverlapProbes(cfg, static.Overlaps),
+		ProbeTypes:       runCfg.ProbeTypes,
+		OnlyDomains:      runCfg.OnlyDomains,
+		StochasticRuns:   runner.StochasticRuns,
+		Seed:             runner.Seed,
+	})
+
+	probesCfg := analysis.GetMap(cfg, "probes")
+	runner.RobustnessCheck = analysis.GetBool(probesCfg, "robustness_check")
+	runner.RobustnessSeed = int64(analysis.GetFloat(probesCfg, "robustness_seed", 1))
+	if _, set := probesCfg["robustness_seed"]; !set && runner.Seed != 0 {
+		runner.RobustnessSeed = runner.Seed
+	}
+	runner.MinProbesForScore = minProbesForScore(cfg)
+	hedge := probes.ResolveHedgeThresholds(cfg)
+	runner.HedgeThresholds = &hedge
+	runner.ConversationPrefixes = prefixes
+	runner.Language = language
+
+	return &Plan{Agents: agents, Questions: questions, Runner: runner, Budget: budget, Warnings: warnings}, nil
+}
+
+// Estimate returns the API calls, tokens and cost plan is expected to take
+// with model, as priced by the test command's --dry-run.
+func (p *Plan) Estimate(model string) RunEstimate {
+	return probes.EstimateRun(p.Agents, p.Questions, p.Runner, model)
+}
+
+// RunPlan runs the probes of plan against client. progress may be nil. If
+// ctx is canceled, it returns a report of the probes completed so far, with
+// Canceled set.
+func RunPlan(ctx context.Context, plan *Plan, client LLMClient, progress ProgressCallback) *LiveProbeReport {
+	return probes.RunLiveProbes(ctx, plan.Agents, plan.Questions, client, plan.Runner, progress)
+}
+
+// RunFull runs the test command on agents: static analysis, then live
+// probes against the provider described by providerCfg. The mock provider
+// judges scope by domain keywords unless providerCfg.MockInScope is set.
+// A runCfg.Runner.RequestTimeout replaces the HTTP client's timeout unless
+// providerCfg.Timeout is set. It does not apply the probes.max_total_calls
+// limit the CLI asks confirmation for; use PlanProbes and Plan.Estimate to
+// check the size of a run first.
+func RunFull(ctx context.Context, agents []AgentDefinition, cfg map[string]any, providerCfg ProviderConfig, runCfg RunConfig) (*StaticReport, *LiveProbeReport, error) {
+	if providerCfg.Provider == "mock" && providerCfg.MockInScope == nil {
+		providerCfg.MockInScope = probes.MockScope
+	}
+	if runCfg.Runner.Provider == "" {
+		runCfg.Runner.Provider = providerCfg.Provider
+		runCfg.Runner.Model = providerCfg.Model
+		if runCfg.Runner.Model == "" {
+			runCfg.Runner.Model = provider.DefaultModel(providerCfg.Provider)
+		}
+	}
+	if runCfg.Runner.RequestTimeout > 0 && providerCfg.Timeout == 0 {
+		providerCfg.Timeout = -1
+	}
+	client, err := provider.NewClient(providerCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("initialize API client: %w", err)
+	}
+	return RunFullWithClient(ctx, agents, cfg, client, runCfg)
+}
+
+// RunFullWithClient is RunFull with a client built by the caller, such as a
+// mock or a client for a provider NewClient does not know.
+func RunFullWithClient(ctx context.Context, agents []AgentDefinition, cfg map[string]any, client LLMClient, runCfg RunConfig) (*StaticReport, *LiveProbeReport, error) {
+	static := RunStatic(agents, cfg)
+	plan, err := PlanProbes(static, cfg, runCfg)
+	if err != nil {
+		return static, nil, err
+	}
+	return static, RunPlan(ctx, plan, client, runCfg.Progress), nil
+}
+
+// resolveCustomQuestions returns the custom probe questions of config merged
+// with those of the questions file at path, or at probes.questions_file when
+// path is empty, the domains whose built-in questions the file replaces, and
+// a warning for each file domain no agent claims.
+func resolveCustomQuestions(cfg map[string]any, path string, agents []AgentDefinition) (map[string][]probes.CustomQuestion, map[string]bool, []string, error) {
+	custom := probes.ResolveCustomQuestions(cfg)
+	if path == "" {
+		path, _ = analysis.GetMap(cfg, "probes")["questions_file"].(string)
+	}
+	if path == "" {
+		return custom, nil, nil, nil
+	}
+
+	file, err := probes.LoadQuestionsFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var warnings []string
+	for _, domain := range file.UnclaimedDomains(agents) {
+		warnings = append(warnings, fmt.Sprintf("no agent claims domain %q from %s; its questions will not be asked", domain, path))
+	}
+	return file.Merge(custom), file.ReplacedDomains(), warnings, nil
+}
+
+// minProbesForScore reads scoring.min_probes_for_score, the number of probes
+// an agent needs before its live scores are reported. It defaults to 3.
+func minProbesForScore(cfg map[string]any) int {
+	return int(analysis.GetFloat(analysis.GetMap(cfg, "scoring"), "min_probes_for_score", 3))
+}
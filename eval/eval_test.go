@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+// mockClient is an LLMClient that hedges on questions outside the agent's
+// system prompt and answers confidently otherwise, counting its calls.
+type mockClient struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *mockClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	text := "This is outside my expertise; please consult a specialist. Confidence: 20"
+	if strings.Contains(strings.ToLower(req.SystemPrompt), "backend") {
+		text = "Use a connection pool sized to the database's limits. Confidence: 85"
+	}
+	return provider.CompletionResponse{Text: text, Model: "mock-model", TotalTokens: 10}, nil
+}
+
+func TestRunFullWithMockClient(t *testing.T) {
+	dir := filepath.Join("..", "testdata", "fixtures")
+	cfg, err := LoadConfig("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents, err := LoadAgents(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &mockClient{}
+	var mu sync.Mutex
+	progressed := 0
+	static, live, err := RunFullWithClient(context.Background(), agents, cfg, client, RunConfig{
+		Budget: 12,
+		Runner: ProbeRunConfig{StochasticRuns: 1, Concurrency: 2, RequestsPerSecond: 1000},
+		Progress: func(done, total int, agentID, probeID string) {
+			mu.Lock()
+			progressed++
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(static.Agents) != len(agents) {
+		t.Errorf("static report has %d agents, want %d", len(static.Agents), len(agents))
+	}
+	if len(static.Overlaps) == 0 {
+		t.Error("expected the static report to compare agents")
+	}
+	if live.TotalCalls == 0 || live.TotalCalls != client.calls {
+		t.Errorf("live report counts %d calls, the client served %d", live.TotalCalls, client.calls)
+	}
+	if live.Model != "mock-model" {
+		t.Errorf("live report model = %q, want mock-model", live.Model)
+	}
+	probed := 0
+	for _, r := range live.AgentResults {
+		probed += r.ProbesRun
+	}
+	if probed == 0 || probed > 12 {
+		t.Errorf("ran %d probes, want between 1 and the budget of 12", probed)
+	}
+	if progressed != probed {
+		t.Errorf("progress called %d times for %d probes", progressed, probed)
+	}
+}
+
+func TestRunStaticMatchesPlanAgents(t *testing.T) {
+	agents := []AgentDefinition{
+		{ID: "backend", SystemPrompt: "You are a backend engineer: APIs, databases and Go services."},
+		{ID: "glossary", SystemPrompt: "Reference material.", ReferenceOnly: true},
+	}
+	static := RunStatic(agents, nil)
+	plan, err := PlanProbes(static, nil, RunConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Agents) != 1 || plan.Agents[0].ID != "backend" {
+		t.Errorf("plan agents = %v, want only backend (reference-only agents are not probed)", plan.Agents)
+	}
+	if plan.Budget != 500 || plan.Runner.StochasticRuns != 5 {
+		t.Errorf("plan budget %d, stochastic runs %d; want the defaults", plan.Budget, plan.Runner.StochasticRuns)
+	}
+	if plan.Runner.MinProbesForScore != 3 {
+		t.Errorf("MinProbesForScore = %d, want 3 from the scoring default", plan.Runner.MinProbesForScore)
+	}
+	if est := plan.Estimate("mock-model"); est.Calls != len(plan.Questions)*(1+plan.Runner.StochasticRuns) {
+		t.Errorf("estimate plans %d calls for %d questions", est.Calls, len(plan.Questions))
+	}
+}
+
+func TestPlanProbesWarnsUnclaimedDomains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "questions.yaml")
+	content := "questions:\n  backend:\n    - question: How do you size a connection pool?\n  game_netcode:\n    - question: How is packet loss reconciled?\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	agents := []AgentDefinition{
+		{ID: "backend", SystemPrompt: "You are a backend engineer.", ClaimedDomains: []string{"backend"}},
+	}
+	plan, err := PlanProbes(RunStatic(agents, nil), nil, RunConfig{QuestionsFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], `"game_netcode"`) {
+		t.Errorf("Warnings = %q, want one for game_netcode", plan.Warnings)
+	}
+}
+
+func TestRunFullUnknownProvider(t *testing.T) {
+	_, _, err := RunFull(context.Background(), nil, nil, ProviderConfig{Provider: "nope"}, RunConfig{})
+	if err == nil || !strings.Contains(err.Error(), "initialize API client") {
+		t.Errorf("expected a client error, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// DomainDelta is the change in one domain's relevance between two versions
+// of an agent. A domain missing from a version scores 0 in it.
+type DomainDelta struct {
+	Domain       string  `json:"domain"`
+	Before       float64 `json:"before"`
+	After        float64 `json:"after"`
+	Delta        float64 `json:"delta"`
+	StrongBefore bool    `json:"strong_before"`
+	StrongAfter  bool    `json:"strong_after"`
+}
+
+// DomainDiff compares the detected domains of two versions of an agent.
+type DomainDiff struct {
+	Changes      []DomainDelta `json:"changes"`       // domains whose relevance changed, largest change first
+	GainedStrong []string      `json:"gained_strong"` // domains that became strong
+	LostStrong   []string      `json:"lost_strong"`   // domains that are no longer strong
+}
+
+// DiffDomains compares the domain relevance scores of an agent before and
+// after an edit, as returned by ExtractDomains. A domain is strong in a
+// version when it scores above thresholds.Overlap, so that it takes part in
+// overlap detection.
+func DiffDomains(before, after map[string]float64, thresholds DomainThresholds) DomainDiff {
+	names := make(map[string]bool, len(before)+len(after))
+	for d := range before {
+		names[d] = true
+	}
+	for d := range after {
+		names[d] = true
+	}
+
+	d := DomainDiff{Changes: []DomainDelta{}, GainedStrong: []string{}, LostStrong: []string{}}
+	for name := range names {
+		b, a := before[name], after[name]
+		if math.Abs(a-b) < 1e-9 {
+			continue
+		}
+		change := DomainDelta{
+			Domain:       name,
+			Before:       b,
+			After:        a,
+			Delta:        a - b,
+			StrongBefore: thresholds.OverlapDomain(b),
+			StrongAfter:  thresholds.OverlapDomain(a),
+		}
+		d.Changes = append(d.Changes, change)
+		switch {
+		case change.StrongAfter && !change.StrongBefore:
+			d.GainedStrong = append(d.GainedStrong, name)
+		case change.StrongBefore && !change.StrongAfter:
+			d.LostStrong = append(d.LostStrong, name)
+		}
+	}
+
+	sort.Slice(d.Changes, func(i, j int) bool {
+		ci, cj := math.Abs(d.Changes[i].Delta), math.Abs(d.Changes[j].Delta)
+		if ci != cj {
+			return ci > cj
+		}
+		return d.Changes[i].Domain < d.Changes[j].Domain
+	})
+	sort.Strings(d.GainedStrong)
+	sort.Strings(d.LostStrong)
+	return d
+}
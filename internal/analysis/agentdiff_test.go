@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestDiffDomains(t *testing.T) {
+	keywords := map[string][]Keyword{
+		"security": Keywords("auth", "xss", "csrf", "injection"),
+		"backend":  Keywords("api", "database", "sql", "cache"),
+		"frontend": Keywords("react", "css", "html", "browser", "component", "dom", "jsx", "vue", "svelte", "webpack"),
+		"docs":     Keywords("readme", "changelog", "guide", "tutorial", "wiki"),
+	}
+	before := &loader.AgentDefinition{
+		ID:           "reviewer",
+		SystemPrompt: "You review auth flows for XSS and CSRF in our API. Keep the README current.",
+	}
+	after := &loader.AgentDefinition{
+		ID:           "reviewer",
+		SystemPrompt: "You review API and database changes, and React and CSS in the browser. Keep the README current.",
+	}
+
+	d := DiffDomains(ExtractDomains(before, keywords), ExtractDomains(after, keywords), DefaultDomainThresholds)
+
+	want := []struct {
+		domain string
+		delta  float64
+	}{
+		{"security", -1.0},
+		{"backend", 0.5},
+		{"frontend", 0.6},
+	}
+	if len(d.Changes) != len(want) {
+		t.Fatalf("expected %d changed domains (docs unchanged), got %+v", len(want), d.Changes)
+	}
+	deltas := make(map[string]float64)
+	for _, c := range d.Changes {
+		deltas[c.Domain] = c.Delta
+	}
+	for _, w := range want {
+		if math.Abs(deltas[w.domain]-w.delta) > 1e-9 {
+			t.Errorf("%s: expected delta %+.2f, got %+.2f", w.domain, w.delta, deltas[w.domain])
+		}
+	}
+	if d.Changes[0].Domain != "security" {
+		t.Errorf("largest change should come first, got %s", d.Changes[0].Domain)
+	}
+
+	if len(d.LostStrong) != 1 || d.LostStrong[0] != "security" {
+		t.Errorf("expected security to lose strength, got %v", d.LostStrong)
+	}
+	if len(d.GainedStrong) != 1 || d.GainedStrong[0] != "frontend" {
+		t.Errorf("expected frontend to become strong, got %v", d.GainedStrong)
+	}
+}
+
+func TestDiffDomainsUnchanged(t *testing.T) {
+	scores := map[string]float64{"backend": 0.8, "security": 0.2}
+	d := DiffDomains(scores, scores, DefaultDomainThresholds)
+	if len(d.Changes) != 0 || len(d.GainedStrong) != 0 || len(d.LostStrong) != 0 {
+		t.Errorf("identical versions should have no changes, got %+v", d)
+	}
+}
@@ -0,0 +1,226 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// CacheFileName is the name of the analysis cache file in the cache
+// directory.
+const CacheFileName = ".agent-evals-cache.json"
+
+// cacheVersion changes whenever extraction or scoring changes in a way that
+// invalidates cached results.
+const cacheVersion = 3
+
+// cacheEntry is the per-agent static analysis result of an earlier run.
+// Score excludes MaxOverlapWithOther, which depends on the other agents and
+// is recomputed every run.
+type cacheEntry struct {
+	Inputs  string             `json:"inputs"`
+	Domains map[string]float64 `json:"domains"`
+	Score   *AgentScore        `json:"score,omitempty"`
+}
+
+// cacheFile is the on-disk layout of the cache.
+type cacheFile struct {
+	Version  int                   `json:"version"`
+	Keywords string                `json:"keywords"`
+	Entries  map[string]cacheEntry `json:"entries"`
+}
+
+// Cache persists each agent's extracted domains and agent score between
+// runs, keyed by the agent's content hash, so that unchanged agents in a
+// large fleet are not re-analyzed. Pairwise overlap, gap and issue analysis
+// always run. An entry is only reused when the rest of the agent's inputs
+// (skills, rules and claimed domains) and the domain keywords it was
+// computed with are unchanged.
+type Cache struct {
+	path     string
+	keywords string
+	entries  map[string]cacheEntry
+	used     map[string]bool // entries looked up or stored by this run
+	hits     int
+	misses   int
+}
+
+// LoadCache reads the cache in dir. A missing file yields an empty cache, as
+// does one that does not parse or was written by another cache version, so
+// a damaged cache only costs a full run.
+func LoadCache(dir string) (*Cache, error) {
+	c := &Cache{
+		path:    filepath.Join(dir, CacheFileName),
+		entries: make(map[string]cacheEntry),
+		used:    make(map[string]bool),
+	}
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+	var f cacheFile
+	if err := json.Unmarshal(data, &f); err != nil || f.Version != cacheVersion {
+		return c, nil
+	}
+	c.keywords = f.Keywords
+	for k, e := range f.Entries {
+		c.entries[k] = e
+	}
+	return c, nil
+}
+
+// Path returns the cache file path.
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// Stats returns how many agents were served from the cache and how many
+// had to be analyzed.
+func (c *Cache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	return c.hits, c.misses
+}
+
+// Save writes the cache, keeping only the entries used by this run so that
+// removed agents do not accumulate.
+func (c *Cache) Save() error {
+	f := cacheFile{Version: cacheVersion, Keywords: c.keywords, Entries: make(map[string]cacheEntry)}
+	for k := range c.used {
+		if e, ok := c.entries[k]; ok {
+			f.Entries[k] = e
+		}
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	return nil
+}
+
+// useSettings drops every entry when the domain keywords, extraction
+// options or domain thresholds differ from those the cache was written
+// with, since all extracted domains and agent scores depend on them.
+func (c *Cache) useSettings(domains map[string][]Keyword, opts ExtractOptions, thresholds DomainThresholds) {
+	fp := settingsFingerprint(domains, opts, thresholds)
+	if c.keywords != fp {
+		c.keywords = fp
+		c.entries = make(map[string]cacheEntry)
+	}
+}
+
+// domains returns the cached domains of agent, if its inputs are unchanged.
+func (c *Cache) domains(agent *loader.AgentDefinition) (map[string]float64, bool) {
+	key, inputs := cacheKey(agent)
+	c.used[key] = true
+	e, ok := c.entries[key]
+	if !ok || e.Inputs != inputs {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return e.Domains, true
+}
+
+// storeDomains records freshly extracted domains, discarding any score
+// cached for the agent's old inputs.
+func (c *Cache) storeDomains(agent *loader.AgentDefinition, domains map[string]float64) {
+	key, inputs := cacheKey(agent)
+	c.used[key] = true
+	c.entries[key] = cacheEntry{Inputs: inputs, Domains: domains}
+}
+
+// score returns the cached agent score of agent, if its inputs are
+// unchanged.
+func (c *Cache) score(agent *loader.AgentDefinition) (AgentScore, bool) {
+	key, inputs := cacheKey(agent)
+	e, ok := c.entries[key]
+	if !ok || e.Inputs != inputs || e.Score == nil {
+		return AgentScore{}, false
+	}
+	return *e.Score, true
+}
+
+// storeScore records an agent score alongside the agent's cached domains.
+func (c *Cache) storeScore(agent *loader.AgentDefinition, score AgentScore) {
+	key, inputs := cacheKey(agent)
+	e, ok := c.entries[key]
+	if !ok || e.Inputs != inputs {
+		return
+	}
+	score.MaxOverlapWithOther = 0
+	e.Score = &score
+	c.entries[key] = e
+}
+
+// cacheKey returns the agent's content hash, computing it for agents loaded
+// without --recursive, and a hash of the other inputs to extraction.
+func cacheKey(agent *loader.AgentDefinition) (key, inputs string) {
+	key = agent.ContentHash
+	if key == "" {
+		sum := sha256.Sum256([]byte(agent.SystemPrompt))
+		key = hex.EncodeToString(sum[:])
+	}
+
+	h := sha256.New()
+	h.Write([]byte(agent.FullContext()))
+	for _, d := range agent.ClaimedDomains {
+		h.Write([]byte{0})
+		h.Write([]byte(d))
+	}
+	skills := make([]string, 0, len(agent.SkillDomains))
+	for d := range agent.SkillDomains {
+		skills = append(skills, d)
+	}
+	sort.Strings(skills)
+	for _, d := range skills {
+		fmt.Fprintf(h, "\x01%s=%g", d, agent.SkillDomains[d])
+	}
+	return key, hex.EncodeToString(h.Sum(nil))
+}
+
+func settingsFingerprint(domains map[string][]Keyword, opts ExtractOptions, thresholds DomainThresholds) string {
+	names := make([]string, 0, len(domains))
+	for d := range domains {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, d := range names {
+		h.Write([]byte(d))
+		for _, kw := range domains[d] {
+			h.Write([]byte{0})
+			h.Write([]byte(kw.Term))
+			if kw.Weight != 1 {
+				fmt.Fprintf(h, "\x02%g", kw.Weight)
+			}
+			if kw.Regex {
+				h.Write([]byte{3})
+			}
+		}
+		h.Write([]byte{1})
+	}
+	if opts.CountNegated {
+		h.Write([]byte("\x04count_negated"))
+	}
+	// Only the strong cutoff shapes cached scores; overlap is recomputed
+	if t := thresholds.withDefaults(); t.Strong != DefaultDomainThresholds.Strong {
+		fmt.Fprintf(h, "\x05strong=%g", t.Strong)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// countAnalysis counts calls to ExtractDomains and ScoreAgent until the test
+// ends.
+func countAnalysis(t *testing.T) (extracted, scored *int) {
+	t.Helper()
+	extracted, scored = new(int), new(int)
+	origExtract, origScore := extractDomains, scoreAgent
+	extractDomains = func(agent *loader.AgentDefinition, keywords map[string][]Keyword, opts ExtractOptions) map[string]float64 {
+		*extracted++
+		return origExtract(agent, keywords, opts)
+	}
+	scoreAgent = func(agent *loader.AgentDefinition, domainMap map[string]map[string]float64, overlaps []OverlapResult, thresholds DomainThresholds) AgentScore {
+		*scored++
+		return origScore(agent, domainMap, overlaps, thresholds)
+	}
+	t.Cleanup(func() { extractDomains, scoreAgent = origExtract, origScore })
+	return extracted, scored
+}
+
+func cachedReport(t *testing.T, dir string, agents []loader.AgentDefinition, cfg map[string]any) *StaticReport {
+	t.Helper()
+	cache, err := LoadCache(dir)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	analyzer := NewStaticAnalyzer(cfg)
+	analyzer.UseCache(cache)
+	report := analyzer.Report(agents)
+	if err := cache.Save(); err != nil {
+		t.Fatalf("save cache: %v", err)
+	}
+	return report
+}
+
+func TestCacheSkipsUnchangedAgents(t *testing.T) {
+	agents, err := loader.LoadAgentsRecursive(filepath.Join("..", "loader", "testdata", "recursive"), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := map[string]any{}
+	dir := t.TempDir()
+	extracted, scored := countAnalysis(t)
+
+	first := cachedReport(t, dir, agents, cfg)
+	if *extracted != len(agents) || *scored != len(agents) {
+		t.Fatalf("first run: extracted %d, scored %d, want %d each", *extracted, *scored, len(agents))
+	}
+	if _, err := os.Stat(filepath.Join(dir, CacheFileName)); err != nil {
+		t.Fatalf("cache file not written: %v", err)
+	}
+
+	*extracted, *scored = 0, 0
+	second := cachedReport(t, dir, agents, cfg)
+	if *extracted != 0 || *scored != 0 {
+		t.Errorf("unchanged run: extracted %d, scored %d, want 0", *extracted, *scored)
+	}
+	if !reflect.DeepEqual(second.DomainMap, first.DomainMap) {
+		t.Errorf("DomainMap differs:\n got %v\nwant %v", second.DomainMap, first.DomainMap)
+	}
+	if !reflect.DeepEqual(second.AgentScores, first.AgentScores) {
+		t.Errorf("AgentScores differ:\n got %+v\nwant %+v", second.AgentScores, first.AgentScores)
+	}
+	if !reflect.DeepEqual(second.Issues, first.Issues) {
+		t.Errorf("Issues differ:\n got %+v\nwant %+v", second.Issues, first.Issues)
+	}
+
+	// Editing one agent re-analyzes only that agent.
+	agents[0].SystemPrompt += "\nAlways write tests for new endpoints."
+	agents[0].ContentHash = ""
+	*extracted, *scored = 0, 0
+	cachedReport(t, dir, agents, cfg)
+	if *extracted != 1 || *scored != 1 {
+		t.Errorf("one edited agent: extracted %d, scored %d, want 1 each", *extracted, *scored)
+	}
+}
+
+func TestCacheInvalidatedByKeywords(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "api", SystemPrompt: "You design REST API endpoints and database schemas."},
+	}
+	dir := t.TempDir()
+	extracted, _ := countAnalysis(t)
+
+	cachedReport(t, dir, agents, map[string]any{})
+	cfg := map[string]any{"domains": []any{
+		"api_design",
+		map[string]any{"name": "billing", "keywords": []any{"invoice", "refund"}},
+	}}
+	*extracted = 0
+	cachedReport(t, dir, agents, cfg)
+	if *extracted != 1 {
+		t.Errorf("changed domain keywords: extracted %d, want 1", *extracted)
+	}
+}
+
+func TestLoadCacheIgnoresDamagedFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, CacheFileName), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := LoadCache(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("expected empty cache, got %d entries", len(cache.entries))
+	}
+}
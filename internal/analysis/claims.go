@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// DefaultSkillClaimConfidence is the relevance given to a domain inferred
+// from an agent's skills or rules when none is configured.
+const DefaultSkillClaimConfidence = 0.8
+
+// ResolveSkillClaims reads claims.from_skills and claims.skill_confidence
+// from config. It reports whether skill/rule merging is enabled and the
+// confidence to assign to inferred domains.
+func ResolveSkillClaims(config map[string]any) (bool, float64) {
+	claims := GetMap(config, "claims")
+	confidence := GetFloat(claims, "skill_confidence", DefaultSkillClaimConfidence)
+	if confidence <= 0 || confidence > 1 {
+		confidence = DefaultSkillClaimConfidence
+	}
+	return GetBool(claims, "from_skills"), confidence
+}
+
+// SkillDomains returns the domains whose keywords appear in an agent's
+// skills or rules, ignoring its system prompt.
+func SkillDomains(agent *loader.AgentDefinition, domainKeywords map[string][]Keyword) []string {
+	if len(agent.Skills) == 0 && len(agent.Rules) == 0 {
+		return nil
+	}
+	text := strings.ToLower(strings.Join(agent.Skills, "\n") + "\n" + strings.Join(agent.Rules, "\n"))
+
+	var domains []string
+	for domain, keywords := range domainKeywords {
+		for _, kw := range keywords {
+			if countKeyword(text, kw) > 0 {
+				domains = append(domains, domain)
+				break
+			}
+		}
+	}
+	return domains
+}
+
+// MergeSkillClaims populates SkillDomains on each agent so that domains named
+// by its skills and rules count as claimed, at the given confidence, for both
+// domain extraction and probe generation.
+func MergeSkillClaims(agents []loader.AgentDefinition, domainKeywords map[string][]Keyword, confidence float64) {
+	for i := range agents {
+		domains := SkillDomains(&agents[i], domainKeywords)
+		if len(domains) == 0 {
+			continue
+		}
+		agents[i].SkillDomains = make(map[string]float64, len(domains))
+		for _, d := range domains {
+			agents[i].SkillDomains[d] = confidence
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestMergeSkillClaims(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{
+			ID:           "infra_helper",
+			SystemPrompt: "You help the team.",
+			Skills:       []string{"Kubernetes management", "Terraform"},
+		},
+		{ID: "prose_only", SystemPrompt: "You help with kubernetes."},
+	}
+
+	MergeSkillClaims(agents, ResolveDomains(nil), 0.6)
+
+	if got := agents[0].SkillDomains["devops"]; got != 0.6 {
+		t.Errorf("expected devops inferred from skills at 0.6, got %.2f (%v)", got, agents[0].SkillDomains)
+	}
+	if agents[1].SkillDomains != nil {
+		t.Errorf("expected no skill domains for an agent without skills, got %v", agents[1].SkillDomains)
+	}
+
+	domains := ExtractDomains(&agents[0], ResolveDomains(nil))
+	if domains["devops"] < 0.6 {
+		t.Errorf("expected devops relevance of at least 0.6, got %.2f", domains["devops"])
+	}
+}
+
+func TestResolveSkillClaims(t *testing.T) {
+	enabled, conf := ResolveSkillClaims(nil)
+	if enabled || conf != DefaultSkillClaimConfidence {
+		t.Errorf("expected disabled at default confidence, got %v %.2f", enabled, conf)
+	}
+
+	enabled, conf = ResolveSkillClaims(map[string]any{
+		"claims": map[string]any{"from_skills": true, "skill_confidence": 0.5},
+	})
+	if !enabled || conf != 0.5 {
+		t.Errorf("expected enabled at 0.5, got %v %.2f", enabled, conf)
+	}
+}
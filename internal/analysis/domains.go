@@ -2,8 +2,14 @@ package analysis
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/thinkwright/agent-evals/internal/loader"
 )
@@ -79,10 +85,66 @@ var BuiltinDomains = map[string][]string{
 		"documentation", "style guide", "tone of voice"},
 }
 
+// warningOutput receives config warnings from ResolveDomains. Each warning
+// is written once per process, since a run resolves domains more than once.
+var (
+	warningOutput io.Writer = os.Stderr
+	warningsMu    sync.Mutex
+	warned        = make(map[string]bool)
+)
+
+func warnOnce(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	if !warned[msg] {
+		warned[msg] = true
+		fmt.Fprint(warningOutput, msg)
+	}
+}
+
+// Keyword is a domain keyword and how strongly a match signals the domain.
+// ExtractDomains counts each match Weight times, so a distinctive term can
+// outweigh several generic ones. Plain-string keywords have weight 1.
+// Term matches whole words only, unless Regex makes it a regular
+// expression matched case-insensitively.
+type Keyword struct {
+	Term   string
+	Weight float64
+	Regex  bool
+}
+
+// Keywords returns terms as keywords of weight 1.
+func Keywords(terms ...string) []Keyword {
+	keywords := make([]Keyword, len(terms))
+	for i, t := range terms {
+		keywords[i] = Keyword{Term: t, Weight: 1}
+	}
+	return keywords
+}
+
+// terms returns the terms of keywords.
+func terms(keywords []Keyword) []string {
+	out := make([]string, len(keywords))
+	for i, kw := range keywords {
+		out[i] = kw.Term
+	}
+	return out
+}
+
+// collisionThreshold is the fraction of a custom domain's keywords that must
+// also belong to a built-in domain for ResolveDomains to warn about it.
+const collisionThreshold = 0.5
+
 // ResolveDomains builds a domain keyword map from configuration. If config is
 // nil or has no "domains" key, all built-in domains are returned. Entries can
 // be strings (built-in refs) or maps with name, optional extends, and keywords.
-func ResolveDomains(config map[string]any) map[string][]string {
+// A keyword is a string or a map with a term and a weight; "regex: true" on
+// a domain makes its keywords regular expressions, and "parent" is read by
+// ResolveDomainParents. It warns about
+// custom domains whose keywords mostly duplicate a built-in domain in use,
+// since agents matching them are credited for both.
+func ResolveDomains(config map[string]any) map[string][]Keyword {
 	if config == nil {
 		return copyDomains(BuiltinDomains)
 	}
@@ -95,76 +157,204 @@ func ResolveDomains(config map[string]any) map[string][]string {
 		return copyDomains(BuiltinDomains)
 	}
 
-	result := make(map[string][]string)
+	result := make(map[string][]Keyword)
+	var custom []string
 	for _, entry := range entries {
 		switch v := entry.(type) {
 		case string:
 			if kw, ok := BuiltinDomains[v]; ok {
-				result[v] = copySlice(kw)
+				result[v] = Keywords(kw...)
 			} else {
-				fmt.Fprintf(os.Stderr, "Warning: unknown built-in domain %q, skipping\n", v)
+				warnOnce("Warning: unknown built-in domain %q, skipping\n", v)
 			}
 		case map[string]any:
 			name, _ := v["name"].(string)
 			if name == "" {
 				continue
 			}
-			keywords := toStringSlice(v["keywords"])
+			keywords := toKeywords(name, v["keywords"], GetBool(v, "regex"))
 			extends, _ := v["extends"].(string)
 			if extends == "builtin" {
 				if builtin, ok := BuiltinDomains[name]; ok {
-					merged := copySlice(builtin)
-					merged = append(merged, keywords...)
-					result[name] = merged
+					result[name] = append(Keywords(builtin...), keywords...)
 				} else {
 					// extends unknown built-in — treat as custom-only
 					if len(keywords) > 0 {
 						result[name] = keywords
+						custom = append(custom, name)
 					}
 				}
 			} else {
 				if len(keywords) > 0 {
 					result[name] = keywords
+					custom = append(custom, name)
 				}
 			}
 		}
 	}
 
+	for _, c := range domainCollisions(result, custom) {
+		warnOnce("Warning: custom domain %q shares %d of %d keywords with built-in %q (%s); agents matching them are credited for both. Consider extending %q (extends: builtin) instead\n",
+			c.Custom, len(c.Shared), c.Keywords, c.Builtin, strings.Join(c.Shared, ", "), c.Builtin)
+	}
 	return result
 }
 
-func copyDomains(src map[string][]string) map[string][]string {
-	dst := make(map[string][]string, len(src))
-	for k, v := range src {
-		dst[k] = copySlice(v)
+// ResolveDomainParents returns the parent of each domain entry in config
+// that names one with "parent", such as a web umbrella over frontend and
+// api_design. A parent need not be a domain with keywords of its own. A
+// parent that would make a domain its own ancestor is ignored with a
+// warning.
+func ResolveDomainParents(config map[string]any) map[string]string {
+	entries, _ := config["domains"].([]any)
+	parents := make(map[string]string)
+	for _, entry := range entries {
+		m, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		parent, _ := m["parent"].(string)
+		if name == "" || parent == "" {
+			continue
+		}
+		if parent == name || isAncestor(name, parent, parents) {
+			warnOnce("Warning: domain %q cannot have parent %q, which would make it its own ancestor; ignoring it\n", name, parent)
+			continue
+		}
+		parents[name] = parent
 	}
-	return dst
+	return parents
 }
 
-func copySlice(s []string) []string {
-	c := make([]string, len(s))
-	copy(c, s)
-	return c
+// isAncestor reports whether ancestor is reached by following parents up
+// from domain.
+func isAncestor(ancestor, domain string, parents map[string]string) bool {
+	for d, ok := parents[domain]; ok; d, ok = parents[d] {
+		if d == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// domainCollision is a custom domain whose keywords mostly duplicate a
+// built-in domain's.
+type domainCollision struct {
+	Custom   string
+	Builtin  string
+	Shared   []string
+	Keywords int // keywords in the custom domain
 }
 
-func toStringSlice(v any) []string {
+// domainCollisions returns, for each custom domain, the built-in domains in
+// resolved that hold at least collisionThreshold of its keywords. A custom
+// domain that reuses a built-in's name replaces it and is not compared.
+func domainCollisions(resolved map[string][]Keyword, custom []string) []domainCollision {
+	var builtins []string
+	for name := range resolved {
+		if _, ok := BuiltinDomains[name]; ok {
+			builtins = append(builtins, name)
+		}
+	}
+	sort.Strings(builtins)
+
+	var collisions []domainCollision
+	for _, name := range custom {
+		if _, ok := BuiltinDomains[name]; ok {
+			continue
+		}
+		keywords := uniqueLower(terms(resolved[name]))
+		for _, b := range builtins {
+			known := make(map[string]bool)
+			for _, kw := range resolved[b] {
+				known[strings.ToLower(kw.Term)] = true
+			}
+			var shared []string
+			for _, kw := range keywords {
+				if known[kw] {
+					shared = append(shared, kw)
+				}
+			}
+			if len(shared) > 0 && float64(len(shared)) >= collisionThreshold*float64(len(keywords)) {
+				collisions = append(collisions, domainCollision{Custom: name, Builtin: b, Shared: shared, Keywords: len(keywords)})
+			}
+		}
+	}
+	return collisions
+}
+
+// uniqueLower returns keywords lowercased with duplicates removed, in order.
+func uniqueLower(keywords []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, kw := range keywords {
+		kw = strings.ToLower(strings.TrimSpace(kw))
+		if kw != "" && !seen[kw] {
+			seen[kw] = true
+			out = append(out, kw)
+		}
+	}
+	return out
+}
+
+func copyDomains(src map[string][]string) map[string][]Keyword {
+	dst := make(map[string][]Keyword, len(src))
+	for k, v := range src {
+		dst[k] = Keywords(v...)
+	}
+	return dst
+}
+
+// toKeywords reads a domain's keywords list from config: strings, or maps
+// with a term and a positive weight (default 1).
+func toKeywords(domain string, v any, regex bool) []Keyword {
 	items, ok := v.([]any)
 	if !ok {
 		return nil
 	}
-	var result []string
+	var result []Keyword
 	for _, item := range items {
-		if s, ok := item.(string); ok {
-			result = append(result, s)
+		keyword := Keyword{Weight: 1, Regex: regex}
+		switch kw := item.(type) {
+		case string:
+			keyword.Term = kw
+		case map[string]any:
+			keyword.Term, _ = kw["term"].(string)
+			if keyword.Term == "" {
+				warnOnce("Warning: domain %q has a keyword without a term, skipping\n", domain)
+				continue
+			}
+			keyword.Weight = GetFloat(kw, "weight", 1)
+			if keyword.Weight <= 0 {
+				warnOnce("Warning: domain %q keyword %q has weight %v; weights must be positive, using 1\n", domain, keyword.Term, kw["weight"])
+				keyword.Weight = 1
+			}
+		default:
+			continue
+		}
+		if regex {
+			if _, err := keywordPattern(keyword.Term); err != nil {
+				warnOnce("Warning: domain %q keyword %q is not a valid regular expression, skipping: %v\n", domain, keyword.Term, err)
+				continue
+			}
 		}
+		result = append(result, keyword)
 	}
 	return result
 }
 
 // ExtractDomains extracts domains from an agent's definition with relevance scores.
 // Returns a map of domain -> relevance_score (0-1).
-func ExtractDomains(agent *loader.AgentDefinition, domainKeywords map[string][]string) map[string]float64 {
-	text := strings.ToLower(agent.FullContext())
+func ExtractDomains(agent *loader.AgentDefinition, domainKeywords map[string][]Keyword) map[string]float64 {
+	return ExtractDomainsWith(agent, domainKeywords, ExtractOptions{})
+}
+
+// ExtractDomainsWith is ExtractDomains with options. Unless
+// opts.CountNegated is set, keywords shortly after a negation, as in "you do
+// not handle security", are not counted.
+func ExtractDomainsWith(agent *loader.AgentDefinition, domainKeywords map[string][]Keyword, opts ExtractOptions) map[string]float64 {
+	text := keywordText(agent, opts)
 	scores := make(map[string]float64)
 
 	// Start with explicitly claimed domains
@@ -173,17 +363,26 @@ func ExtractDomains(agent *loader.AgentDefinition, domainKeywords map[string][]s
 		scores[normalized] = 1.0
 	}
 
+	// Domains inferred from skills/rules count as claims at their confidence
+	for domain, confidence := range agent.SkillDomains {
+		if confidence > scores[domain] {
+			scores[domain] = confidence
+		}
+	}
+
 	// Keyword-based extraction.
-	// Score = hits / (len(keywords) * 0.5). The 0.5 factor means an agent
-	// matching half its domain's keywords reaches 1.0, reflecting that no
-	// single prompt will use every keyword in a domain.
+	// Score = sum(hits * weight) / (sum(weight) * 0.5). The 0.5 factor means
+	// an agent matching half its domain's keyword weight reaches 1.0,
+	// reflecting that no single prompt will use every keyword in a domain.
+	// With every weight 1 this is hits / (len(keywords) * 0.5).
 	for domain, keywords := range domainKeywords {
-		hits := 0
+		var hits, total float64
 		for _, kw := range keywords {
-			hits += strings.Count(text, kw)
+			hits += float64(countKeyword(text, kw)) * kw.Weight
+			total += kw.Weight
 		}
 		if hits > 0 {
-			score := float64(hits) / (float64(len(keywords)) * 0.5)
+			score := hits / (total * 0.5)
 			if score > 1.0 {
 				score = 1.0
 			}
@@ -195,3 +394,98 @@ func ExtractDomains(agent *loader.AgentDefinition, domainKeywords map[string][]s
 
 	return scores
 }
+
+// keywordText returns the agent's full context as keywords are matched
+// against it: lowercased and, unless opts.CountNegated, with negated phrases
+// blanked.
+func keywordText(agent *loader.AgentDefinition, opts ExtractOptions) string {
+	text := strings.ToLower(agent.FullContext())
+	if !opts.CountNegated {
+		text = maskNegated(text)
+	}
+	return text
+}
+
+// countKeyword returns how many times kw occurs in text, which must already
+// be lowercase. A plain keyword only counts where it is not part of a longer
+// word: "sql" does not match inside "mysql" or "postgresql", though a plural
+// "s" or "es" is allowed, so "api" matches "apis". Word edges are
+// only required at the keyword's own letters and digits, so "ci/cd",
+// "next.js" and "query optimization" match as written and ".net" matches
+// after a space. A regex keyword counts the non-overlapping matches of its
+// pattern.
+func countKeyword(text string, kw Keyword) int {
+	if kw.Regex {
+		re, err := keywordPattern(kw.Term)
+		if err != nil {
+			return 0
+		}
+		return len(re.FindAllStringIndex(text, -1))
+	}
+	term := kw.Term
+	if term == "" {
+		return 0
+	}
+	first, _ := utf8.DecodeRuneInString(term)
+	last, _ := utf8.DecodeLastRuneInString(term)
+	n := 0
+	for i := 0; i < len(text); {
+		j := strings.Index(text[i:], term)
+		if j < 0 {
+			break
+		}
+		start, end := i+j, i+j+len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(last) {
+			end += pluralSuffix(text[end:])
+		}
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (!isWordRune(first) || start == 0 || !isWordRune(before)) &&
+			(!isWordRune(last) || end == len(text) || !isWordRune(after)) {
+			n++
+			i = end
+		} else {
+			_, size := utf8.DecodeRuneInString(text[start:])
+			i = start + size
+		}
+	}
+	return n
+}
+
+// pluralSuffix returns the length of the "s" or "es" that rest starts
+// with, when it ends the word there, so that "api" matches "apis".
+func pluralSuffix(rest string) int {
+	for _, suffix := range []string{"s", "es"} {
+		if strings.HasPrefix(rest, suffix) {
+			r, _ := utf8.DecodeRuneInString(rest[len(suffix):])
+			if len(rest) == len(suffix) || !isWordRune(r) {
+				return len(suffix)
+			}
+		}
+	}
+	return 0
+}
+
+// isWordRune reports whether r can be part of a word. Unlike \w in regular
+// expressions it excludes '_', so "backend" matches in "backend_service".
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// keywordPatterns caches compiled regex keywords, which are matched against
+// every agent.
+var keywordPatterns sync.Map // term -> *regexp.Regexp
+
+// keywordPattern compiles a regex keyword, case-insensitively since it is
+// matched against lowercased text.
+func keywordPattern(term string) (*regexp.Regexp, error) {
+	if re, ok := keywordPatterns.Load(term); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("(?i)" + term)
+	if err != nil {
+		return nil, err
+	}
+	keywordPatterns.Store(term, re)
+	return re, nil
+}
@@ -1,6 +1,8 @@
 package analysis
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/thinkwright/agent-evals/internal/loader"
@@ -14,7 +16,7 @@ func TestExtractDomainsKeywordMatching(t *testing.T) {
 			and design microservice architectures.`,
 	}
 
-	domains := ExtractDomains(agent, BuiltinDomains)
+	domains := ExtractDomains(agent, ResolveDomains(nil))
 
 	// Should detect backend (multiple keyword hits: backend, api, rest, microservice)
 	if domains["backend"] == 0 {
@@ -45,7 +47,7 @@ func TestExtractDomainsClaimedDomainsOverride(t *testing.T) {
 		ClaimedDomains: []string{"Security", "dev-ops"},
 	}
 
-	domains := ExtractDomains(agent, BuiltinDomains)
+	domains := ExtractDomains(agent, ResolveDomains(nil))
 
 	// Claimed domains should be normalized and set to 1.0
 	if domains["security"] != 1.0 {
@@ -64,7 +66,7 @@ func TestExtractDomainsClaimedAndKeywordBothPresent(t *testing.T) {
 		ClaimedDomains: []string{"security"},
 	}
 
-	domains := ExtractDomains(agent, BuiltinDomains)
+	domains := ExtractDomains(agent, ResolveDomains(nil))
 
 	// Claimed = 1.0, keyword hits would produce some score. Result should be 1.0.
 	if domains["security"] != 1.0 {
@@ -74,7 +76,7 @@ func TestExtractDomainsClaimedAndKeywordBothPresent(t *testing.T) {
 
 func TestExtractDomainsEmptyPrompt(t *testing.T) {
 	agent := &loader.AgentDefinition{ID: "empty", SystemPrompt: ""}
-	domains := ExtractDomains(agent, BuiltinDomains)
+	domains := ExtractDomains(agent, ResolveDomains(nil))
 
 	if len(domains) != 0 {
 		t.Errorf("expected no domains for empty prompt, got %d: %v", len(domains), domains)
@@ -89,7 +91,7 @@ func TestExtractDomainsScoreCapping(t *testing.T) {
 			tdd bdd cypress playwright jest testing test test test`,
 	}
 
-	domains := ExtractDomains(agent, BuiltinDomains)
+	domains := ExtractDomains(agent, ResolveDomains(nil))
 
 	if domains["testing"] > 1.0 {
 		t.Errorf("domain score should be capped at 1.0, got %.2f", domains["testing"])
@@ -104,7 +106,7 @@ func TestExtractDomainsSkillsAndRulesContribute(t *testing.T) {
 		Rules:        []string{"Always follow CI/CD best practices", "Use Helm for deployments"},
 	}
 
-	domains := ExtractDomains(agent, BuiltinDomains)
+	domains := ExtractDomains(agent, ResolveDomains(nil))
 
 	// DevOps keywords are in skills and rules
 	if domains["devops"] == 0 {
@@ -180,7 +182,7 @@ func TestResolveDomainsExtends(t *testing.T) {
 	// Check that custom keywords are present
 	found := false
 	for _, k := range kw {
-		if k == "axum" {
+		if k.Term == "axum" {
 			found = true
 			break
 		}
@@ -232,8 +234,8 @@ func TestResolveDomainsUnknownBuiltin(t *testing.T) {
 }
 
 func TestExtractDomainsCustomKeywords(t *testing.T) {
-	custom := map[string][]string{
-		"payments": {"stripe", "plaid", "payment gateway"},
+	custom := map[string][]Keyword{
+		"payments": Keywords("stripe", "plaid", "payment gateway"),
 	}
 	agent := &loader.AgentDefinition{
 		ID:           "pay_agent",
@@ -248,3 +250,216 @@ func TestExtractDomainsCustomKeywords(t *testing.T) {
 		t.Error("did not expect backend domain with custom-only keywords")
 	}
 }
+
+func TestResolveDomainsWeightedKeywords(t *testing.T) {
+	warnings := captureWarnings(t)
+	result := ResolveDomains(map[string]any{
+		"domains": []any{
+			map[string]any{
+				"name": "payments",
+				"keywords": []any{
+					"stripe",
+					map[string]any{"term": "pci dss", "weight": 3},
+					map[string]any{"term": "checkout", "weight": 0.5},
+					map[string]any{"term": "ledger", "weight": -2},
+					map[string]any{"weight": 2},
+				},
+			},
+		},
+	})
+	want := []Keyword{{Term: "stripe", Weight: 1}, {Term: "pci dss", Weight: 3}, {Term: "checkout", Weight: 0.5}, {Term: "ledger", Weight: 1}}
+	got := result["payments"]
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("keyword %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	out := warnings.String()
+	if !strings.Contains(out, `keyword "ledger" has weight -2`) {
+		t.Errorf("expected a warning for the negative weight, got %q", out)
+	}
+	if !strings.Contains(out, "keyword without a term") {
+		t.Errorf("expected a warning for the missing term, got %q", out)
+	}
+}
+
+func TestExtractDomainsHighWeightKeywordDominates(t *testing.T) {
+	keywords := map[string][]Keyword{
+		"ml": {{Term: "transformer", Weight: 5}, {Term: "training", Weight: 1}, {Term: "dataset", Weight: 1}, {Term: "inference", Weight: 1}},
+	}
+	heavy := &loader.AgentDefinition{ID: "heavy", SystemPrompt: "You explain transformer architectures."}
+	light := &loader.AgentDefinition{ID: "light", SystemPrompt: "You plan training schedules and curate each dataset."}
+
+	heavyScore := ExtractDomains(heavy, keywords)["ml"]
+	lightScore := ExtractDomains(light, keywords)["ml"]
+	if heavyScore <= lightScore {
+		t.Errorf("one weight-5 hit (%.2f) should outscore two weight-1 hits (%.2f)", heavyScore, lightScore)
+	}
+	// 5 / (8 * 0.5) caps at 1; 2 / (8 * 0.5) = 0.5
+	if heavyScore != 1.0 || lightScore != 0.5 {
+		t.Errorf("expected scores 1.0 and 0.5, got %.2f and %.2f", heavyScore, lightScore)
+	}
+
+	// Unweighted, the same prompts rank the other way round
+	plain := map[string][]Keyword{"ml": Keywords("transformer", "training", "dataset", "inference")}
+	if ExtractDomains(heavy, plain)["ml"] >= ExtractDomains(light, plain)["ml"] {
+		t.Error("without weights, two keyword hits should outscore one")
+	}
+}
+
+// captureWarnings redirects ResolveDomains warnings for the rest of the test.
+func captureWarnings(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := warningOutput
+	warningOutput = &buf
+	warned = make(map[string]bool)
+	t.Cleanup(func() { warningOutput = prev })
+	return &buf
+}
+
+func TestResolveDomainsWarnsOnBuiltinCollision(t *testing.T) {
+	warnings := captureWarnings(t)
+	result := ResolveDomains(map[string]any{
+		"domains": []any{
+			"frontend",
+			"backend",
+			map[string]any{"name": "web", "keywords": []any{"React", "css", "tailwind", "storybook"}},
+			map[string]any{"name": "payments", "keywords": []any{"stripe", "ledger", "api"}},
+		},
+	})
+	if _, ok := result["web"]; !ok {
+		t.Fatal("colliding custom domain should still be resolved")
+	}
+
+	out := warnings.String()
+	if !strings.Contains(out, `custom domain "web" shares 3 of 4 keywords with built-in "frontend"`) {
+		t.Errorf("expected a collision warning for web, got %q", out)
+	}
+	if !strings.Contains(out, "extends: builtin") {
+		t.Errorf("expected the warning to suggest extends, got %q", out)
+	}
+	if strings.Contains(out, "payments") {
+		t.Errorf("payments shares only 1 of 3 keywords and should not warn, got %q", out)
+	}
+
+	// Resolving again, as a run does for probe generation, does not repeat it
+	ResolveDomains(map[string]any{"domains": []any{"frontend",
+		map[string]any{"name": "web", "keywords": []any{"React", "css", "tailwind", "storybook"}}}})
+	if n := strings.Count(warnings.String(), `"web"`); n != 1 {
+		t.Errorf("expected the warning once, got it %d times", n)
+	}
+}
+
+func TestResolveDomainsNoCollisionWithUnusedBuiltin(t *testing.T) {
+	warnings := captureWarnings(t)
+	ResolveDomains(map[string]any{
+		"domains": []any{
+			"backend",
+			map[string]any{"name": "web", "keywords": []any{"react", "css"}},
+		},
+	})
+	if warnings.Len() != 0 {
+		t.Errorf("frontend is not configured, so web cannot be double-credited; got %q", warnings.String())
+	}
+}
+
+func TestCountKeywordWholeWords(t *testing.T) {
+	tests := []struct {
+		text, keyword string
+		want          int
+	}{
+		{"we run mysql, nosql and postgresql", "sql", 0},
+		{"we run mysql, nosql and postgresql", "mysql", 1},
+		{"write sql; sql-first design", "sql", 2},
+		{"own the ci/cd pipeline and ci/cd.", "ci/cd", 2},
+		{"build with next.js", "next.js", 1},
+		{"a next.jsx file", "next.js", 0},
+		{"tune query optimization hints", "query optimization", 1},
+		{"port it to .net", ".net", 1},
+		{"design rest apis", "api", 1},
+		{"manage databases", "database", 1},
+		{"a testing harness", "test", 0},
+		{"the backend_service agent", "backend", 1},
+		{"the interest rate", "rest", 0},
+	}
+	for _, tt := range tests {
+		if got := countKeyword(tt.text, Keyword{Term: tt.keyword, Weight: 1}); got != tt.want {
+			t.Errorf("countKeyword(%q, %q) = %d, want %d", tt.text, tt.keyword, got, tt.want)
+		}
+	}
+}
+
+func TestExtractDomainsMySQLCountsOnce(t *testing.T) {
+	agent := &loader.AgentDefinition{ID: "dba", SystemPrompt: "You tune MySQL."}
+	hits := ExplainDomains(agent, ResolveDomains(nil), ExtractOptions{})["databases"]
+	if len(hits) != 1 || hits["mysql"] != 1 {
+		t.Errorf("expected only mysql to match once, got %v", hits)
+	}
+
+	devops := &loader.AgentDefinition{ID: "ops", SystemPrompt: "You maintain CI/CD."}
+	if hits := ExplainDomains(devops, ResolveDomains(nil), ExtractOptions{})["devops"]; hits["ci/cd"] != 1 {
+		t.Errorf("expected ci/cd to match, got %v", hits)
+	}
+}
+
+func TestResolveDomainsRegexKeywords(t *testing.T) {
+	warnings := captureWarnings(t)
+	result := ResolveDomains(map[string]any{
+		"domains": []any{
+			map[string]any{
+				"name":     "payments",
+				"regex":    true,
+				"keywords": []any{`pci[- ]dss`, map[string]any{"term": `iso ?8583`, "weight": 2}, `stripe(`},
+			},
+		},
+	})
+	keywords := result["payments"]
+	if len(keywords) != 2 || !keywords[0].Regex || !keywords[1].Regex {
+		t.Fatalf("expected two regex keywords, got %v", keywords)
+	}
+	if !strings.Contains(warnings.String(), `keyword "stripe(" is not a valid regular expression`) {
+		t.Errorf("expected a warning for the invalid pattern, got %q", warnings.String())
+	}
+
+	agent := &loader.AgentDefinition{ID: "pay", SystemPrompt: "You keep card data PCI-DSS compliant and parse ISO 8583 messages."}
+	hits := ExplainDomains(agent, result, ExtractOptions{})["payments"]
+	if hits[`pci[- ]dss`] != 1 || hits[`iso ?8583`] != 1 {
+		t.Errorf("expected both patterns to match once, got %v", hits)
+	}
+}
+
+func TestResolveDomainParents(t *testing.T) {
+	warnings := captureWarnings(t)
+	parents := ResolveDomainParents(map[string]any{
+		"domains": []any{
+			"security",
+			map[string]any{"name": "frontend", "extends": "builtin", "parent": "web"},
+			map[string]any{"name": "api_design", "extends": "builtin", "parent": "web"},
+			map[string]any{"name": "web", "keywords": []any{"web"}, "parent": "css"},
+			map[string]any{"name": "css", "keywords": []any{"css"}, "parent": "frontend"},
+			map[string]any{"name": "loop", "keywords": []any{"loop"}, "parent": "loop"},
+		},
+	})
+	want := map[string]string{"frontend": "web", "api_design": "web", "web": "css"}
+	if len(parents) != len(want) {
+		t.Errorf("expected %v, got %v", want, parents)
+	}
+	for child, parent := range want {
+		if parents[child] != parent {
+			t.Errorf("expected %s's parent to be %s, got %q", child, parent, parents[child])
+		}
+	}
+	out := warnings.String()
+	if !strings.Contains(out, `domain "css" cannot have parent "frontend"`) || !strings.Contains(out, `domain "loop" cannot have parent "loop"`) {
+		t.Errorf("expected warnings for both cycles, got %q", out)
+	}
+
+	if parents := ResolveDomainParents(nil); len(parents) != 0 {
+		t.Errorf("expected no parents without config, got %v", parents)
+	}
+}
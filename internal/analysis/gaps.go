@@ -5,42 +5,78 @@ import "sort"
 // GapResult represents a domain with insufficient agent coverage.
 type GapResult struct {
 	Domain       string
+	Children     []string // child domains rolled up into Domain, sorted
 	ClosestAgent string
 	ClosestScore float64
 	Verdict      string // "uncovered" | "weakly_covered"
 }
 
-// FindGaps finds domains with no strong agent coverage.
-func FindGaps(allDomains map[string]bool, domainMap map[string]map[string]float64) []GapResult {
-	sorted := make([]string, 0, len(allDomains))
+// FindGaps finds domains with no strong agent coverage: a domain is covered
+// when some agent scores at least thresholds.Strong in it, and uncovered
+// below 0.2 (or thresholds.Strong, if lower). With parents (child
+// domain -> parent, see ResolveDomainParents), child domains are rolled up
+// and reported at their top-level parent: an agent's coverage of the parent
+// is its best score across the parent and all its descendants, so the
+// parent is covered when any of them is strongly covered. Nil parents
+// reports every domain on its own.
+func FindGaps(allDomains map[string]bool, domainMap map[string]map[string]float64, parents map[string]string, thresholds DomainThresholds) []GapResult {
+	strong := thresholds.withDefaults().Strong
+	uncovered := min(0.2, strong)
+
+	groups := make(map[string][]string) // root -> member domains
 	for d := range allDomains {
+		root := rootDomain(d, parents)
+		groups[root] = append(groups[root], d)
+	}
+	sorted := make([]string, 0, len(groups))
+	for d := range groups {
 		sorted = append(sorted, d)
 	}
 	sort.Strings(sorted)
 
+	// Visit agents in ID order so that, on a tie, the lexicographically
+	// first agent is reported as closest.
+	agentIDs := make([]string, 0, len(domainMap))
+	for id := range domainMap {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+
 	var gaps []GapResult
 	for _, domain := range sorted {
 		var bestAgent string
 		var bestScore float64
 
-		for agentID, scores := range domainMap {
-			score := scores[domain]
-			if score > bestScore {
-				bestScore = score
-				bestAgent = agentID
+		for _, agentID := range agentIDs {
+			for _, member := range groups[domain] {
+				score := domainMap[agentID][member]
+				if score > bestScore {
+					bestScore = score
+					bestAgent = agentID
+				}
 			}
 		}
 
-		if bestScore < 0.2 {
+		var children []string
+		for _, member := range groups[domain] {
+			if member != domain {
+				children = append(children, member)
+			}
+		}
+		sort.Strings(children)
+
+		if bestScore < uncovered {
 			gaps = append(gaps, GapResult{
 				Domain:       domain,
+				Children:     children,
 				ClosestAgent: bestAgent,
 				ClosestScore: bestScore,
 				Verdict:      "uncovered",
 			})
-		} else if bestScore < 0.5 {
+		} else if bestScore < strong {
 			gaps = append(gaps, GapResult{
 				Domain:       domain,
+				Children:     children,
 				ClosestAgent: bestAgent,
 				ClosestScore: bestScore,
 				Verdict:      "weakly_covered",
@@ -50,3 +86,17 @@ func FindGaps(allDomains map[string]bool, domainMap map[string]map[string]float6
 
 	return gaps
 }
+
+// rootDomain follows domain's parents up to a domain with none. A cycle
+// stops at the domain where it closes.
+func rootDomain(domain string, parents map[string]string) string {
+	seen := map[string]bool{domain: true}
+	for {
+		parent, ok := parents[domain]
+		if !ok || seen[parent] {
+			return domain
+		}
+		seen[parent] = true
+		domain = parent
+	}
+}
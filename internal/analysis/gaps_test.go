@@ -14,7 +14,7 @@ func TestFindGapsUncovered(t *testing.T) {
 		"agent_a": {"backend": 0.9, "security": 0.1, "testing": 0.0},
 	}
 
-	gaps := FindGaps(allDomains, domainMap)
+	gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
 
 	// security (0.1 < 0.2) → uncovered, testing (0.0 < 0.2) → uncovered
 	if len(gaps) != 2 {
@@ -40,7 +40,7 @@ func TestFindGapsWeaklyCovered(t *testing.T) {
 		"agent_a": {"security": 0.35},
 	}
 
-	gaps := FindGaps(allDomains, domainMap)
+	gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
 
 	if len(gaps) != 1 {
 		t.Fatalf("expected 1 gap, got %d", len(gaps))
@@ -59,7 +59,7 @@ func TestFindGapsWellCovered(t *testing.T) {
 		"agent_a": {"backend": 0.8},
 	}
 
-	gaps := FindGaps(allDomains, domainMap)
+	gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
 
 	if len(gaps) != 0 {
 		t.Errorf("expected no gaps for well-covered domain (score 0.8), got %+v", gaps)
@@ -74,7 +74,7 @@ func TestFindGapsMultipleAgentsBestWins(t *testing.T) {
 		"agent_c": {"security": 0.15},
 	}
 
-	gaps := FindGaps(allDomains, domainMap)
+	gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
 
 	// Best score is 0.4 (agent_b), which is weakly_covered (0.2 <= 0.4 < 0.5)
 	if len(gaps) != 1 {
@@ -92,7 +92,7 @@ func TestFindGapsNoAgents(t *testing.T) {
 	allDomains := map[string]bool{"backend": true, "security": true}
 	domainMap := map[string]map[string]float64{}
 
-	gaps := FindGaps(allDomains, domainMap)
+	gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
 
 	// All domains should be uncovered
 	if len(gaps) != 2 {
@@ -127,7 +127,7 @@ func TestFindGapsThresholdBoundaries(t *testing.T) {
 				"agent": {"testing": tt.score},
 			}
 
-			gaps := FindGaps(allDomains, domainMap)
+			gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
 
 			if tt.isGap {
 				if len(gaps) != 1 {
@@ -155,7 +155,7 @@ func TestFindGapsSortedOutput(t *testing.T) {
 		"agent": {"testing": 0.0, "backend": 0.0, "security": 0.0},
 	}
 
-	gaps := FindGaps(allDomains, domainMap)
+	gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
 
 	if len(gaps) < 2 {
 		t.Fatal("expected multiple gaps")
@@ -168,3 +168,81 @@ func TestFindGapsSortedOutput(t *testing.T) {
 		}
 	}
 }
+
+func TestFindGapsClosestAgentTieBreak(t *testing.T) {
+	allDomains := map[string]bool{"security": true}
+	domainMap := map[string]map[string]float64{
+		"zeta":  {"security": 0.3},
+		"alpha": {"security": 0.3},
+		"mid":   {"security": 0.3},
+	}
+
+	for i := 0; i < 20; i++ {
+		gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds)
+		if len(gaps) != 1 {
+			t.Fatalf("expected 1 gap, got %d", len(gaps))
+		}
+		if gaps[0].ClosestAgent != "alpha" {
+			t.Fatalf("run %d: expected tie to resolve to 'alpha', got %q", i, gaps[0].ClosestAgent)
+		}
+	}
+}
+
+func TestFindGapsRollsUpChildrenIntoParent(t *testing.T) {
+	allDomains := map[string]bool{"frontend": true, "api_design": true, "security": true}
+	// Each agent strongly covers one child of web and is weak on the other
+	domainMap := map[string]map[string]float64{
+		"ui":  {"frontend": 0.8, "api_design": 0.1},
+		"api": {"frontend": 0.1, "api_design": 0.7},
+	}
+	parents := map[string]string{"frontend": "web", "api_design": "web"}
+
+	gaps := FindGaps(allDomains, domainMap, parents, DefaultDomainThresholds)
+	if len(gaps) != 1 || gaps[0].Domain != "security" {
+		t.Fatalf("expected only security as a gap, web being covered through its children; got %+v", gaps)
+	}
+
+	// With neither child strongly covered, the gap is reported once, at web
+	weak := map[string]map[string]float64{
+		"ui":  {"frontend": 0.3},
+		"api": {"api_design": 0.1},
+	}
+	gaps = FindGaps(map[string]bool{"frontend": true, "api_design": true}, weak, parents, DefaultDomainThresholds)
+	if len(gaps) != 1 {
+		t.Fatalf("expected a single rolled-up gap, got %+v", gaps)
+	}
+	g := gaps[0]
+	if g.Domain != "web" || g.Verdict != "weakly_covered" || g.ClosestAgent != "ui" || g.ClosestScore != 0.3 {
+		t.Errorf("expected web weakly covered by ui at 0.3, got %+v", g)
+	}
+	if len(g.Children) != 2 || g.Children[0] != "api_design" || g.Children[1] != "frontend" {
+		t.Errorf("expected sorted children [api_design frontend], got %v", g.Children)
+	}
+}
+
+func TestFindGapsRollupOneChildCoversParent(t *testing.T) {
+	allDomains := map[string]bool{"frontend": true, "api_design": true}
+	domainMap := map[string]map[string]float64{
+		"ui": {"frontend": 0.9},
+	}
+
+	// Without rollup the uncovered child is a gap of its own
+	if gaps := FindGaps(allDomains, domainMap, nil, DefaultDomainThresholds); len(gaps) != 1 || gaps[0].Domain != "api_design" {
+		t.Errorf("expected api_design as a gap without rollup, got %+v", gaps)
+	}
+	parents := map[string]string{"frontend": "web", "api_design": "web"}
+	if gaps := FindGaps(allDomains, domainMap, parents, DefaultDomainThresholds); len(gaps) != 0 {
+		t.Errorf("a parent is covered when any child is strongly covered, got %+v", gaps)
+	}
+}
+
+func TestFindGapsRollupNested(t *testing.T) {
+	allDomains := map[string]bool{"web": true, "frontend": true, "css": true}
+	domainMap := map[string]map[string]float64{
+		"stylist": {"css": 0.6},
+	}
+	parents := map[string]string{"css": "frontend", "frontend": "web"}
+	if gaps := FindGaps(allDomains, domainMap, parents, DefaultDomainThresholds); len(gaps) != 0 {
+		t.Errorf("a grandchild's coverage should roll up to web, got %+v", gaps)
+	}
+}
@@ -0,0 +1,215 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// minGroupConflictAgents is the number of agents a disagreement must involve
+// to be reported as a group conflict; two-agent disagreements are already
+// reported as pairwise conflicts.
+const minGroupConflictAgents = 3
+
+// DefaultAlternativeTerms are the sets of alternatives for one choice used
+// when analysis.alternative_terms is not configured.
+var DefaultAlternativeTerms = [][]string{
+	{"rest", "graphql"},
+	{"postgresql", "mysql"},
+	{"npm", "yarn", "pnpm"},
+	{"tabs", "spaces"},
+}
+
+// ResolveAlternativeTerms reads analysis.alternative_terms from config: a
+// list of sets of single-word terms that are alternatives for the same
+// choice, such as [npm, yarn, pnpm]. Configured sets replace
+// DefaultAlternativeTerms, and an empty list disables them. Terms are
+// lowercased; sets with fewer than two terms are skipped with a warning.
+func ResolveAlternativeTerms(config map[string]any) [][]string {
+	raw, set := GetMap(config, "analysis")["alternative_terms"]
+	if !set {
+		return DefaultAlternativeTerms
+	}
+	items, _ := raw.([]any)
+	alternatives := [][]string{}
+	for i, item := range items {
+		list, _ := item.([]any)
+		var terms []string
+		for _, v := range list {
+			if term, ok := v.(string); ok && strings.TrimSpace(term) != "" {
+				terms = append(terms, strings.ToLower(strings.TrimSpace(term)))
+			}
+		}
+		if len(terms) < 2 {
+			warnOnce("Warning: analysis.alternative_terms[%d] must list at least two terms, skipping\n", i)
+			continue
+		}
+		alternatives = append(alternatives, terms)
+	}
+	return alternatives
+}
+
+// DetectGroupConflicts is DetectGroupConflictsWith over
+// DefaultAlternativeTerms.
+func DetectGroupConflicts(agents []loader.AgentDefinition) []Issue {
+	return DetectGroupConflictsWith(agents, DefaultAlternativeTerms)
+}
+
+// DetectGroupConflictsWith clusters opposing instructions about the same
+// term across all agents, using the same heuristics as pairwise conflict
+// detection. Each term that at least three agents take sides on, with at
+// least one agent on each side, yields one warning naming every agent that
+// says to use it and every agent that says to avoid it. Terms listed
+// together in one set of alternatives, such as REST and GraphQL, are one
+// choice: when agents say to use different ones, a single warning names who
+// says to use and to avoid each of them. Other either/or choices are only
+// caught when an agent says to avoid a term. The pairs involved are still
+// reported as conflicts, so the group issue is a warning.
+func DetectGroupConflictsWith(agents []loader.AgentDefinition, alternatives [][]string) []Issue {
+	texts := make([]string, len(agents))
+	for i := range agents {
+		texts[i] = strings.ToLower(agents[i].FullContext())
+	}
+
+	// Terms any agent endorses, and who endorses them
+	endorse := make(map[string]map[string]bool)
+	for _, pair := range oppositionPairs {
+		re := regexp.MustCompile(pair.positive)
+		for i, text := range texts {
+			for _, m := range re.FindAllStringSubmatch(text, -1) {
+				if endorse[m[1]] == nil {
+					endorse[m[1]] = make(map[string]bool)
+				}
+				endorse[m[1]][agents[i].ID] = true
+			}
+		}
+	}
+
+	terms := make([]string, 0, len(endorse))
+	for term := range endorse {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	// Agents that reject term
+	rejectors := func(term string) map[string]bool {
+		reject := make(map[string]bool)
+		for _, pair := range oppositionPairs {
+			negRe, err := regexp.Compile(fmt.Sprintf(pair.negative, regexp.QuoteMeta(term)))
+			if err != nil {
+				continue
+			}
+			for i, text := range texts {
+				if negRe.MatchString(text) {
+					reject[agents[i].ID] = true
+				}
+			}
+		}
+		return reject
+	}
+
+	choiceIssues, covered := alternativeConflicts(alternatives, endorse, rejectors)
+
+	var issues []Issue
+	for _, term := range terms {
+		if covered[term] {
+			continue
+		}
+		reject := rejectors(term)
+		participants := union(endorse[term], reject)
+		if len(reject) == 0 || len(participants) < minGroupConflictAgents {
+			continue
+		}
+
+		users, avoiders := sortedKeys(endorse[term]), sortedKeys(reject)
+		issues = append(issues, Issue{
+			Severity: "warning",
+			Category: "group-conflict",
+			Message: fmt.Sprintf("%d agents disagree about '%s': %s %s use it; %s %s avoid it",
+				len(participants), term, quoteIDs(users), says(len(users)), quoteIDs(avoiders), says(len(avoiders))),
+			Agents: sortedKeys(participants),
+		})
+	}
+	return append(issues, choiceIssues...)
+}
+
+// alternativeConflicts returns a warning for each set of alternatives that
+// agents say to use more than one of, and the terms those warnings cover,
+// so they are not reported again on their own.
+func alternativeConflicts(alternatives [][]string, endorse map[string]map[string]bool, rejectors func(string) map[string]bool) ([]Issue, map[string]bool) {
+	var issues []Issue
+	covered := make(map[string]bool)
+	for _, alts := range alternatives {
+		used := 0
+		for _, term := range alts {
+			if len(endorse[term]) > 0 {
+				used++
+			}
+		}
+		if used < 2 {
+			continue
+		}
+
+		participants := make(map[string]bool)
+		var sides []string
+		for _, term := range alts {
+			if users := sortedKeys(endorse[term]); len(users) > 0 {
+				participants = union(participants, endorse[term])
+				sides = append(sides, fmt.Sprintf("%s %s use '%s'", quoteIDs(users), says(len(users)), term))
+			}
+		}
+		for _, term := range alts {
+			reject := rejectors(term)
+			if avoiders := sortedKeys(reject); len(avoiders) > 0 {
+				participants = union(participants, reject)
+				sides = append(sides, fmt.Sprintf("%s %s avoid '%s'", quoteIDs(avoiders), says(len(avoiders)), term))
+			}
+		}
+		if len(participants) < minGroupConflictAgents {
+			continue
+		}
+
+		for _, term := range alts {
+			covered[term] = true
+		}
+		issues = append(issues, Issue{
+			Severity: "warning",
+			Category: "group-conflict",
+			Message:  fmt.Sprintf("%d agents disagree between %s: %s", len(participants), joinTerms(alts), strings.Join(sides, "; ")),
+			Agents:   sortedKeys(participants),
+		})
+	}
+	return issues, covered
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func quoteIDs(ids []string) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = "'" + id + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// joinTerms quotes terms as a list: "'a' and 'b'" or "'a', 'b' and 'c'".
+func joinTerms(terms []string) string {
+	return quoteIDs(terms[:len(terms)-1]) + " and '" + terms[len(terms)-1] + "'"
+}
+
+func says(n int) string {
+	if n == 1 {
+		return "says"
+	}
+	return "say"
+}
@@ -0,0 +1,144 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestDetectGroupConflicts(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "orders", SystemPrompt: "You build order services. Always use PostgreSQL for persistence."},
+		{ID: "reports", SystemPrompt: "You build reporting jobs. Prefer PostgreSQL over other stores."},
+		{ID: "cache", SystemPrompt: "You build caching layers. Never use PostgreSQL; use Redis instead."},
+		{ID: "docs", SystemPrompt: "You write documentation for the platform."},
+	}
+
+	issues := DetectGroupConflicts(agents)
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 group conflict, got %d: %+v", len(issues), issues)
+	}
+	issue := issues[0]
+	if issue.Category != "group-conflict" || issue.Severity != "warning" {
+		t.Errorf("unexpected category/severity: %s/%s", issue.Category, issue.Severity)
+	}
+	if want := []string{"cache", "orders", "reports"}; !reflect.DeepEqual(issue.Agents, want) {
+		t.Errorf("Agents = %v, want %v", issue.Agents, want)
+	}
+	want := "3 agents disagree about 'postgresql': 'orders', 'reports' say use it; 'cache' says avoid it"
+	if issue.Message != want {
+		t.Errorf("Message = %q, want %q", issue.Message, want)
+	}
+}
+
+func TestDetectGroupConflictsAlternatives(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "public", SystemPrompt: "Always use REST for the public API."},
+		{ID: "mobile", SystemPrompt: "Always use GraphQL for the public API."},
+		{ID: "gateway", SystemPrompt: "Never use REST; clients batch their queries."},
+	}
+
+	issues := DetectGroupConflicts(agents)
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 group conflict, got %d: %+v", len(issues), issues)
+	}
+	if want := []string{"gateway", "mobile", "public"}; !reflect.DeepEqual(issues[0].Agents, want) {
+		t.Errorf("Agents = %v, want %v", issues[0].Agents, want)
+	}
+	want := "3 agents disagree between 'rest' and 'graphql': 'public' says use 'rest'; 'mobile' says use 'graphql'; 'gateway' says avoid 'rest'"
+	if issues[0].Message != want {
+		t.Errorf("Message = %q, want %q", issues[0].Message, want)
+	}
+}
+
+func TestDetectGroupConflictsPackageManagers(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "web", SystemPrompt: "Always use npm for installing dependencies."},
+		{ID: "docs", SystemPrompt: "Always use yarn for installing dependencies."},
+		{ID: "tools", SystemPrompt: "Prefer pnpm in the monorepo."},
+	}
+	issues := DetectGroupConflicts(agents)
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 group conflict, got %d: %+v", len(issues), issues)
+	}
+	want := "3 agents disagree between 'npm', 'yarn' and 'pnpm': 'web' says use 'npm'; 'docs' says use 'yarn'; 'tools' says use 'pnpm'"
+	if issues[0].Message != want {
+		t.Errorf("Message = %q, want %q", issues[0].Message, want)
+	}
+}
+
+func TestResolveAlternativeTerms(t *testing.T) {
+	if got := ResolveAlternativeTerms(nil); !reflect.DeepEqual(got, DefaultAlternativeTerms) {
+		t.Errorf("unconfigured: got %v, want the defaults", got)
+	}
+
+	cfg := map[string]any{"analysis": map[string]any{"alternative_terms": []any{
+		[]any{"Kafka", "RabbitMQ"},
+		[]any{"lonely"},
+	}}}
+	if got, want := ResolveAlternativeTerms(cfg), [][]string{{"kafka", "rabbitmq"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("configured: got %v, want %v", got, want)
+	}
+
+	agents := []loader.AgentDefinition{
+		{ID: "orders", SystemPrompt: "Always use Kafka for events."},
+		{ID: "billing", SystemPrompt: "Always use RabbitMQ for events."},
+		{ID: "audit", SystemPrompt: "Always use Kafka for the audit log."},
+	}
+	var group int
+	for _, issue := range RunStaticAnalysis(agents, cfg).Issues {
+		if issue.Category == "group-conflict" {
+			group++
+		}
+	}
+	if group != 1 {
+		t.Errorf("expected a configured set to yield 1 group conflict, got %d", group)
+	}
+
+	disabled := map[string]any{"analysis": map[string]any{"alternative_terms": []any{}}}
+	if got := ResolveAlternativeTerms(disabled); len(got) != 0 {
+		t.Errorf("an empty list should disable alternatives, got %v", got)
+	}
+}
+
+func TestDetectGroupConflictsIgnoresPairs(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "Always use REST for public APIs."},
+		{ID: "b", SystemPrompt: "Never use REST; expose GraphQL."},
+		{ID: "c", SystemPrompt: "Always use PostgreSQL."},
+		{ID: "d", SystemPrompt: "Prefer PostgreSQL for analytics."},
+	}
+	if issues := DetectGroupConflicts(agents); len(issues) != 0 {
+		t.Errorf("two-agent disagreements and agreements should not be group conflicts, got %+v", issues)
+	}
+}
+
+func TestRunStaticAnalysisGroupConflict(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "orders", SystemPrompt: "Always use PostgreSQL for persistence."},
+		{ID: "reports", SystemPrompt: "Always use PostgreSQL for reporting tables."},
+		{ID: "cache", SystemPrompt: "Avoid PostgreSQL for hot paths. Don't use PostgreSQL for sessions."},
+	}
+	report := RunStaticAnalysis(agents, nil)
+
+	var group, pairwise int
+	for _, issue := range report.Issues {
+		switch issue.Category {
+		case "group-conflict":
+			group++
+			if !strings.Contains(issue.Message, "'cache' says avoid it") {
+				t.Errorf("unexpected message: %s", issue.Message)
+			}
+		case "conflict":
+			pairwise++
+		}
+	}
+	if group != 1 {
+		t.Errorf("expected 1 group-conflict issue, got %d", group)
+	}
+	if pairwise != 2 {
+		t.Errorf("expected the 2 pairwise conflicts to remain, got %d", pairwise)
+	}
+}
@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// ExplainDomains returns, for each domain, the hit count of every keyword
+// that matched the agent's full context. Keywords with zero hits and domains
+// with no hits are omitted. The counts are the ones ExtractDomainsWith
+// weights and sums for opts.
+func ExplainDomains(agent *loader.AgentDefinition, domainKeywords map[string][]Keyword, opts ExtractOptions) map[string]map[string]int {
+	text := keywordText(agent, opts)
+	result := make(map[string]map[string]int)
+	for domain, keywords := range domainKeywords {
+		for _, kw := range keywords {
+			n := countKeyword(text, kw)
+			if n == 0 {
+				continue
+			}
+			if result[domain] == nil {
+				result[domain] = make(map[string]int)
+			}
+			result[domain][kw.Term] += n
+		}
+	}
+	return result
+}
+
+// KeywordStat summarizes how a single domain keyword matched across a fleet.
+type KeywordStat struct {
+	Domain     string
+	Keyword    string
+	Weight     float64
+	TotalHits  int
+	AgentCount int  // distinct agents the keyword matched
+	ZeroHit    bool // matched no agent at all
+	Broad      bool // matched more than half of the agents (fleets of 3+)
+}
+
+// KeywordStats reports per-keyword hit counts across all agents, sorted by
+// domain then keyword. It is intended for tuning custom keyword lists:
+// zero-hit keywords are dead weight and broad ones blur domain boundaries.
+func KeywordStats(agents []loader.AgentDefinition, domainKeywords map[string][]Keyword, opts ExtractOptions) []KeywordStat {
+	type key struct{ domain, keyword string }
+	totals := make(map[key]int)
+	counts := make(map[key]int)
+
+	for i := range agents {
+		for domain, kws := range ExplainDomains(&agents[i], domainKeywords, opts) {
+			for kw, n := range kws {
+				k := key{domain, kw}
+				totals[k] += n
+				counts[k]++
+			}
+		}
+	}
+
+	domains := make([]string, 0, len(domainKeywords))
+	for d := range domainKeywords {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+
+	var stats []KeywordStat
+	for _, d := range domains {
+		weights := make(map[string]float64)
+		keywords := make([]string, 0, len(domainKeywords[d]))
+		for _, kw := range domainKeywords[d] {
+			if _, seen := weights[kw.Term]; !seen {
+				keywords = append(keywords, kw.Term)
+			}
+			weights[kw.Term] += kw.Weight
+		}
+		sort.Strings(keywords)
+		for _, kw := range keywords {
+			k := key{d, kw}
+			stats = append(stats, KeywordStat{
+				Domain:     d,
+				Keyword:    kw,
+				Weight:     weights[kw],
+				TotalHits:  totals[k],
+				AgentCount: counts[k],
+				ZeroHit:    totals[k] == 0,
+				Broad:      len(agents) >= 3 && counts[k]*2 > len(agents),
+			})
+		}
+	}
+	return stats
+}
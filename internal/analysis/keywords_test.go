@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestExplainDomains(t *testing.T) {
+	agent := &loader.AgentDefinition{
+		ID:           "backend_api",
+		SystemPrompt: "You build REST APIs. Every API is versioned.",
+	}
+	explained := ExplainDomains(agent, map[string][]Keyword{
+		"backend": Keywords("api", "rest", "grpc"),
+		"legal":   Keywords("contract"),
+	}, ExtractOptions{})
+
+	if explained["backend"]["api"] != 2 {
+		t.Errorf("expected 2 hits for 'api', got %d", explained["backend"]["api"])
+	}
+	if explained["backend"]["rest"] != 1 {
+		t.Errorf("expected 1 hit for 'rest', got %d", explained["backend"]["rest"])
+	}
+	if _, ok := explained["backend"]["grpc"]; ok {
+		t.Error("zero-hit keyword should be omitted")
+	}
+	if _, ok := explained["legal"]; ok {
+		t.Error("domain with no hits should be omitted")
+	}
+}
+
+func TestKeywordStatsZeroHit(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "We process payments through Stripe."},
+		{ID: "b", SystemPrompt: "Stripe webhooks and Stripe refunds."},
+	}
+	stats := KeywordStats(agents, map[string][]Keyword{
+		"payments": Keywords("stripe", "plaid"),
+	}, ExtractOptions{})
+
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 keyword stats, got %d", len(stats))
+	}
+	byKeyword := make(map[string]KeywordStat)
+	for _, s := range stats {
+		byKeyword[s.Keyword] = s
+	}
+
+	plaid := byKeyword["plaid"]
+	if !plaid.ZeroHit || plaid.TotalHits != 0 || plaid.AgentCount != 0 {
+		t.Errorf("expected plaid flagged as zero-hit, got %+v", plaid)
+	}
+	stripe := byKeyword["stripe"]
+	if stripe.ZeroHit || stripe.TotalHits != 3 || stripe.AgentCount != 2 {
+		t.Errorf("expected stripe with 3 hits across 2 agents, got %+v", stripe)
+	}
+}
+
+func TestKeywordStatsBroad(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You write content for the blog."},
+		{ID: "b", SystemPrompt: "You review content for accuracy."},
+		{ID: "c", SystemPrompt: "You manage the release pipeline."},
+	}
+	stats := KeywordStats(agents, map[string][]Keyword{
+		"writing": Keywords("content", "blog"),
+	}, ExtractOptions{})
+
+	for _, s := range stats {
+		switch s.Keyword {
+		case "content":
+			if !s.Broad {
+				t.Errorf("expected 'content' (2 of 3 agents) to be broad, got %+v", s)
+			}
+		case "blog":
+			if s.Broad {
+				t.Errorf("expected 'blog' (1 of 3 agents) not to be broad, got %+v", s)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// negationWindow is how many words after a negation cue are treated as
+// negated: enough for "do not handle security, authentication or
+// encryption", short enough that the next clause counts again.
+const negationWindow = 5
+
+// ExtractOptions adjusts keyword extraction. The zero value is the default.
+type ExtractOptions struct {
+	// CountNegated counts keywords in negated phrases, so that "you do not
+	// handle security" still credits security. It is set by
+	// analysis.negation_aware: false.
+	CountNegated bool
+}
+
+// ResolveExtractOptions reads the extraction options from config:
+// analysis.negation_aware defaults to true.
+func ResolveExtractOptions(config map[string]any) ExtractOptions {
+	aware, ok := GetMap(config, "analysis")["negation_aware"].(bool)
+	return ExtractOptions{CountNegated: ok && !aware}
+}
+
+// maskNegated blanks the words of text, which must already be lowercase,
+// that fall within negationWindow words after a negation cue such as "not",
+// "never" or "don't", so that keywords there do not match. A window ends
+// early at the end of a sentence or clause. Blanking keeps byte offsets, and
+// text without cues is returned unchanged.
+func maskNegated(text string) string {
+	var masked []byte
+	remaining := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isWordRune(r) {
+			if clauseBreak(text, i, r, size) {
+				remaining = 0
+			}
+			i += size
+			continue
+		}
+		end := wordEnd(text, i)
+		if remaining > 0 {
+			if masked == nil {
+				masked = []byte(text)
+			}
+			for j := i; j < end; j++ {
+				masked[j] = ' '
+			}
+			remaining--
+		}
+		if negationCue(text[i:end]) {
+			remaining = negationWindow
+		}
+		i = end
+	}
+	if masked == nil {
+		return text
+	}
+	return string(masked)
+}
+
+// wordEnd returns the end of the word starting at i, which runs over
+// letters, digits and apostrophes between them, as in "don't".
+func wordEnd(text string, i int) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if isWordRune(r) {
+			i += size
+			continue
+		}
+		if r == '\'' || r == '’' {
+			if next, _ := utf8.DecodeRuneInString(text[i+size:]); isWordRune(next) {
+				i += size
+				continue
+			}
+		}
+		break
+	}
+	return i
+}
+
+// clauseBreak reports whether r at i ends a clause: a newline, or sentence
+// punctuation followed by a space or the end of text. A period inside a
+// word, as in "next.js", does not.
+func clauseBreak(text string, i int, r rune, size int) bool {
+	switch r {
+	case '\n':
+		return true
+	case '.', ';', ':', '!', '?':
+		next, _ := utf8.DecodeRuneInString(text[i+size:])
+		return i+size == len(text) || next == ' ' || next == '\t' || next == '\n' || next == '\r'
+	}
+	return false
+}
+
+// negationCue reports whether word negates the words after it.
+func negationCue(word string) bool {
+	switch word {
+	case "not", "no", "never", "avoid", "cannot":
+		return true
+	}
+	return strings.HasSuffix(word, "n't") || strings.HasSuffix(word, "n’t")
+}
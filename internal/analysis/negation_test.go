@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestMaskNegated(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string // words left unmasked
+	}{
+		{"you handle security", []string{"you", "handle", "security"}},
+		{"you do not handle security", []string{"you", "do", "not"}},
+		{"never touch jwt. security matters", []string{"never", ".", "security", "matters"}},
+		{"don't use next.js for this, ever", []string{"don't", ".", ",", "ever"}},
+		{"avoid a b c d e f", []string{"avoid", "f"}},
+		{"no\nsecurity", []string{"no", "security"}},
+		{"it isn’t about tls", []string{"it", "isn’t"}},
+	}
+	for _, tt := range tests {
+		got := maskNegated(tt.text)
+		if len(got) != len(tt.text) {
+			t.Errorf("maskNegated(%q) changed the length to %d", tt.text, len(got))
+		}
+		if fields := strings.Fields(got); strings.Join(fields, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("maskNegated(%q) left %q, want %q", tt.text, fields, tt.want)
+		}
+	}
+}
+
+func TestExtractDomainsIgnoresNegatedKeywords(t *testing.T) {
+	agent := &loader.AgentDefinition{
+		ID:           "frontend",
+		SystemPrompt: "You are a frontend developer. You never handle security, authentication, encryption, jwt or oauth.",
+	}
+	keywords := ResolveDomains(nil)
+
+	aware := ExtractDomains(agent, keywords)["security"]
+	if DefaultDomainThresholds.OverlapDomain(aware) {
+		t.Errorf("a disclaimed domain should not be strong, got security %.2f", aware)
+	}
+	naive := ExtractDomainsWith(agent, keywords, ExtractOptions{CountNegated: true})["security"]
+	if !DefaultDomainThresholds.OverlapDomain(naive) {
+		t.Errorf("counting negated keywords should make security strong, got %.2f", naive)
+	}
+	// oauth is past the negation window and still counts
+	if aware == 0 {
+		t.Error("expected keywords after the negation window to count")
+	}
+	if ExtractDomains(agent, keywords)["frontend"] == 0 {
+		t.Error("expected the agent's own domain before the negation to count")
+	}
+}
+
+func TestResolveExtractOptions(t *testing.T) {
+	tests := []struct {
+		config map[string]any
+		want   ExtractOptions
+	}{
+		{nil, ExtractOptions{}},
+		{map[string]any{"analysis": map[string]any{"negation_aware": true}}, ExtractOptions{}},
+		{map[string]any{"analysis": map[string]any{"negation_aware": false}}, ExtractOptions{CountNegated: true}},
+		{map[string]any{"analysis": map[string]any{"similarity_method": "cosine"}}, ExtractOptions{}},
+	}
+	for _, tt := range tests {
+		if got := ResolveExtractOptions(tt.config); got != tt.want {
+			t.Errorf("ResolveExtractOptions(%v) = %+v, want %+v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestCacheRebuiltWhenNegationToggles(t *testing.T) {
+	keywords := ResolveDomains(nil)
+	if settingsFingerprint(keywords, ExtractOptions{}, DomainThresholds{}) == settingsFingerprint(keywords, ExtractOptions{CountNegated: true}, DomainThresholds{}) {
+		t.Error("expected the negation option to change the keyword fingerprint")
+	}
+}
@@ -2,9 +2,11 @@ package analysis
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/thinkwright/agent-evals/internal/loader"
 )
@@ -18,22 +20,156 @@ type OverlapResult struct {
 	PromptSimilarity        float64 // 0-1 textual similarity
 	ConflictingInstructions []string
 	Verdict                 string // "clean" | "warning" | "conflict"
+
+	// Accepted is set when the pair is listed in accepted_overlaps, with
+	// AcceptedReason holding its justification, if any.
+	Accepted       bool
+	AcceptedReason string
+}
+
+// AcceptedOverlap is an accepted_overlaps entry: a pair of agents whose
+// overlap is intentional, such as regional variants of the same agent.
+// Their overlap warning is downgraded to info, or dropped when Suppress is
+// set. Conflicting instructions are still reported as errors.
+type AcceptedOverlap struct {
+	Agents   [2]string
+	Reason   string
+	Suppress bool
+}
+
+// ResolveAcceptedOverlaps reads accepted_overlaps from config. Each entry is
+// a map with an agents list of exactly two agent IDs, an optional reason and
+// an optional suppress flag; malformed entries are skipped with a warning.
+func ResolveAcceptedOverlaps(config map[string]any) []AcceptedOverlap {
+	items, _ := config["accepted_overlaps"].([]any)
+	var accepted []AcceptedOverlap
+	for i, item := range items {
+		entry, _ := item.(map[string]any)
+		var ids []string
+		if list, ok := entry["agents"].([]any); ok {
+			for _, v := range list {
+				if id, ok := v.(string); ok && id != "" {
+					ids = append(ids, id)
+				}
+			}
+		}
+		if len(ids) != 2 {
+			warnOnce("Warning: accepted_overlaps[%d] must list exactly two agent IDs, skipping\n", i)
+			continue
+		}
+		reason, _ := entry["reason"].(string)
+		accepted = append(accepted, AcceptedOverlap{
+			Agents:   [2]string{ids[0], ids[1]},
+			Reason:   strings.TrimSpace(reason),
+			Suppress: GetBool(entry, "suppress"),
+		})
+	}
+	return accepted
+}
+
+// findAcceptedOverlap returns the accepted_overlaps entry for the pair a, b
+// in either order.
+func findAcceptedOverlap(accepted []AcceptedOverlap, a, b string) (AcceptedOverlap, bool) {
+	for _, ao := range accepted {
+		if (ao.Agents[0] == a && ao.Agents[1] == b) || (ao.Agents[0] == b && ao.Agents[1] == a) {
+			return ao, true
+		}
+	}
+	return AcceptedOverlap{}, false
 }
 
-// ComputeOverlaps computes pairwise overlap between all agents.
+// markAcceptedOverlaps sets Accepted and AcceptedReason on overlaps whose
+// pair is listed in accepted.
+func markAcceptedOverlaps(overlaps []OverlapResult, accepted []AcceptedOverlap) {
+	for i := range overlaps {
+		if ao, ok := findAcceptedOverlap(accepted, overlaps[i].AgentA, overlaps[i].AgentB); ok {
+			overlaps[i].Accepted = true
+			overlaps[i].AcceptedReason = ao.Reason
+		}
+	}
+}
+
+// OverlapExposure is an agent's share in the fleet's scope overlap.
+type OverlapExposure struct {
+	AgentID    string
+	MaxOverlap float64 // highest overlap with any other agent (AgentScore.MaxOverlapWithOther)
+	Overlaps   int     // other agents it overlaps above thresholds.max_overlap_score
+}
+
+// RankOverlapExposure ranks the agents that overlap any other agent, most
+// exposed first: by highest overlap, then by the number of agents they
+// overlap above maxOverlap, then by ID. The agents at the top are the ones
+// whose scope most needs tightening.
+func RankOverlapExposure(agentScores map[string]AgentScore, overlaps []OverlapResult, maxOverlap float64) []OverlapExposure {
+	counts := make(map[string]int)
+	for _, o := range overlaps {
+		if o.OverlapScore > maxOverlap {
+			counts[o.AgentA]++
+			counts[o.AgentB]++
+		}
+	}
+	var ranked []OverlapExposure
+	for id, score := range agentScores {
+		if score.MaxOverlapWithOther > 0 {
+			ranked = append(ranked, OverlapExposure{AgentID: id, MaxOverlap: score.MaxOverlapWithOther, Overlaps: counts[id]})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.MaxOverlap != b.MaxOverlap {
+			return a.MaxOverlap > b.MaxOverlap
+		}
+		if a.Overlaps != b.Overlaps {
+			return a.Overlaps > b.Overlaps
+		}
+		return a.AgentID < b.AgentID
+	})
+	return ranked
+}
+
+// Prompt similarity methods, selected by analysis.similarity_method.
+const (
+	SimilarityLCS    = "lcs"    // character longest common subsequence
+	SimilarityCosine = "cosine" // cosine of word frequencies, stopwords dropped
+)
+
+// ResolveSimilarityMethod reads analysis.similarity_method from config,
+// defaulting to SimilarityLCS. Unknown methods also fall back to it.
+func ResolveSimilarityMethod(config map[string]any) string {
+	method, _ := GetMap(config, "analysis")["similarity_method"].(string)
+	if strings.ToLower(strings.TrimSpace(method)) == SimilarityCosine {
+		return SimilarityCosine
+	}
+	return SimilarityLCS
+}
+
+// ComputeOverlaps computes pairwise overlap between all agents, comparing
+// prompts with SimilarityLCS and domains at DefaultDomainThresholds.
 func ComputeOverlaps(agents []loader.AgentDefinition, domainMap map[string]map[string]float64) []OverlapResult {
+	return ComputeOverlapsWithMethod(agents, domainMap, SimilarityLCS, DefaultDomainThresholds)
+}
+
+// ComputeOverlapsWithMethod computes pairwise overlap between all agents,
+// comparing prompts with the given similarity method and the domains each
+// agent scores above thresholds.Overlap.
+func ComputeOverlapsWithMethod(agents []loader.AgentDefinition, domainMap map[string]map[string]float64, method string, thresholds DomainThresholds) []OverlapResult {
+	sim := similarity
+	if method == SimilarityCosine {
+		sim = similarityCosine
+	}
 	var results []OverlapResult
 	for i := 0; i < len(agents); i++ {
 		for j := i + 1; j < len(agents); j++ {
-			results = append(results, computeOverlap(&agents[i], &agents[j], domainMap))
+			results = append(results, computeOverlap(&agents[i], &agents[j], domainMap, sim, thresholds))
 		}
 	}
 	return results
 }
 
-func computeOverlap(a, b *loader.AgentDefinition, domainMap map[string]map[string]float64) OverlapResult {
-	domainsA := strongDomains(domainMap[a.ID], 0.3)
-	domainsB := strongDomains(domainMap[b.ID], 0.3)
+func computeOverlap(a, b *loader.AgentDefinition, domainMap map[string]map[string]float64, sim func(a, b string) float64, thresholds DomainThresholds) OverlapResult {
+	cutoff := thresholds.withDefaults().Overlap
+	domainsA := strongDomains(domainMap[a.ID], cutoff)
+	domainsB := strongDomains(domainMap[b.ID], cutoff)
 
 	shared := intersection(domainsA, domainsB)
 	all := union(domainsA, domainsB)
@@ -43,7 +179,7 @@ func computeOverlap(a, b *loader.AgentDefinition, domainMap map[string]map[strin
 		overlapScore = float64(len(shared)) / float64(len(all))
 	}
 
-	promptSim := similarity(truncate(strings.ToLower(a.SystemPrompt), 2000),
+	promptSim := sim(truncate(strings.ToLower(a.SystemPrompt), 2000),
 		truncate(strings.ToLower(b.SystemPrompt), 2000))
 
 	conflicts := detectConflicts(a, b)
@@ -84,8 +220,15 @@ var oppositionPairs = []struct {
 }
 
 func detectConflicts(a, b *loader.AgentDefinition) []string {
-	textA := strings.ToLower(a.FullContext())
-	textB := strings.ToLower(b.FullContext())
+	return TextConflicts(a.ID, a.FullContext(), b.ID, b.FullContext())
+}
+
+// TextConflicts applies the opposition heuristics to two texts attributed
+// to idA and idB, returning messages such as "'a' says use 'redis' but 'b'
+// says avoid it" for each pattern one text endorses and the other rejects.
+func TextConflicts(idA, textA, idB, textB string) []string {
+	textA = strings.ToLower(textA)
+	textB = strings.ToLower(textB)
 
 	seen := make(map[string]bool)
 	var conflicts []string
@@ -115,8 +258,8 @@ func detectConflicts(a, b *loader.AgentDefinition) []string {
 		}
 	}
 
-	check(a.ID, b.ID, textA, textB)
-	check(b.ID, a.ID, textB, textA)
+	check(idA, idB, textA, textB)
+	check(idB, idA, textB, textA)
 
 	return conflicts
 }
@@ -198,3 +341,49 @@ func similarity(a, b string) float64 {
 	lcs := prev[n]
 	return 2.0 * float64(lcs) / float64(m+n)
 }
+
+// stopwords are common English words dropped before cosine similarity, so
+// boilerplate like "you are a" does not make unrelated prompts look alike.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "do": true, "for": true, "from": true,
+	"i": true, "if": true, "in": true, "is": true, "it": true, "its": true,
+	"not": true, "of": true, "on": true, "or": true, "so": true, "that": true,
+	"the": true, "their": true, "them": true, "they": true, "this": true,
+	"to": true, "was": true, "we": true, "when": true, "which": true,
+	"will": true, "with": true, "you": true, "your": true,
+}
+
+// similarityCosine computes the cosine similarity of the word frequency
+// vectors of two strings, ignoring case, punctuation and stopwords.
+func similarityCosine(a, b string) float64 {
+	ta, tb := termFrequencies(a), termFrequencies(b)
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1.0
+	}
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0.0
+	}
+
+	var dot, normA, normB float64
+	for term, fa := range ta {
+		dot += fa * tb[term]
+		normA += fa * fa
+	}
+	for _, fb := range tb {
+		normB += fb * fb
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func termFrequencies(text string) map[string]float64 {
+	tf := make(map[string]float64)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopwords[word] {
+			tf[word]++
+		}
+	}
+	return tf
+}
@@ -42,6 +42,79 @@ func TestSimilarity(t *testing.T) {
 	}
 }
 
+func TestSimilarityCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+		tol  float64
+	}{
+		{"identical strings", "hello world", "hello world", 1.0, 0.01},
+		{"both empty", "", "", 1.0, 0.01},
+		{"one empty", "hello", "", 0.0, 0.01},
+		{"word order ignored", "testing framework", "framework testing", 1.0, 0.01},
+		{"case and punctuation ignored", "REST APIs, databases.", "rest apis databases", 1.0, 0.01},
+		{"only stopwords differ", "you are the reviewer", "we are a reviewer", 1.0, 0.01},
+		{"realistic prompts similar",
+			"you are a backend api developer focusing on rest apis and databases",
+			"you are a backend service developer focusing on rest apis and data stores",
+			0.6, 0.15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := similarityCosine(tt.a, tt.b)
+			if got < tt.want-tt.tol || got > tt.want+tt.tol {
+				t.Errorf("similarityCosine(%q, %q) = %.3f, want %.3f ± %.2f", tt.a, tt.b, got, tt.want, tt.tol)
+			}
+		})
+	}
+}
+
+func TestSimilarityCosineIgnoresBoilerplate(t *testing.T) {
+	backend := "you are a backend api developer focusing on rest apis and databases"
+	legal := "you are a legal advisor specializing in contract law and compliance"
+
+	if lcs := similarity(backend, legal); lcs < 0.4 {
+		t.Fatalf("expected LCS to rate the pair high on shared boilerplate, got %.3f", lcs)
+	}
+	if cos := similarityCosine(backend, legal); cos >= 0.1 {
+		t.Errorf("expected cosine similarity well below 0.3 for unrelated prompts, got %.3f", cos)
+	}
+}
+
+func TestResolveSimilarityMethod(t *testing.T) {
+	tests := []struct {
+		config map[string]any
+		want   string
+	}{
+		{nil, SimilarityLCS},
+		{map[string]any{"analysis": map[string]any{"similarity_method": "cosine"}}, SimilarityCosine},
+		{map[string]any{"analysis": map[string]any{"similarity_method": " Cosine "}}, SimilarityCosine},
+		{map[string]any{"analysis": map[string]any{"similarity_method": "lcs"}}, SimilarityLCS},
+		{map[string]any{"analysis": map[string]any{"similarity_method": "jaccard"}}, SimilarityLCS},
+	}
+	for _, tt := range tests {
+		if got := ResolveSimilarityMethod(tt.config); got != tt.want {
+			t.Errorf("ResolveSimilarityMethod(%v) = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestComputeOverlapsWithCosine(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "api", SystemPrompt: "You are a backend API developer focusing on REST APIs and databases."},
+		{ID: "legal", SystemPrompt: "You are a legal advisor specializing in contract law and compliance."},
+	}
+	domainMap := map[string]map[string]float64{"api": {}, "legal": {}}
+
+	lcs := ComputeOverlaps(agents, domainMap)[0].PromptSimilarity
+	cos := ComputeOverlapsWithMethod(agents, domainMap, SimilarityCosine, DefaultDomainThresholds)[0].PromptSimilarity
+	if cos >= 0.3 || cos >= lcs {
+		t.Errorf("expected cosine prompt similarity below 0.3 and below LCS (%.3f), got %.3f", lcs, cos)
+	}
+}
+
 func TestSimilaritySymmetric(t *testing.T) {
 	pairs := [][2]string{
 		{"hello world", "world hello"},
@@ -221,7 +294,7 @@ func TestComputeOverlapClean(t *testing.T) {
 		"frontend": {"frontend": 0.9, "css": 0.7},
 	}
 
-	result := computeOverlap(a, b, domainMap)
+	result := computeOverlap(a, b, domainMap, similarity, DefaultDomainThresholds)
 
 	if result.Verdict != "clean" {
 		t.Errorf("expected clean verdict for non-overlapping agents, got %q", result.Verdict)
@@ -241,7 +314,7 @@ func TestComputeOverlapWarning(t *testing.T) {
 		"backend_b": {"backend": 0.9, "databases": 0.8, "api_design": 0.7},
 	}
 
-	result := computeOverlap(a, b, domainMap)
+	result := computeOverlap(a, b, domainMap, similarity, DefaultDomainThresholds)
 
 	if result.Verdict != "warning" {
 		t.Errorf("expected warning for high overlap, got %q", result.Verdict)
@@ -266,7 +339,7 @@ func TestComputeOverlapConflict(t *testing.T) {
 		"agent_b": {"databases": 0.8},
 	}
 
-	result := computeOverlap(a, b, domainMap)
+	result := computeOverlap(a, b, domainMap, similarity, DefaultDomainThresholds)
 
 	if result.Verdict != "conflict" {
 		t.Errorf("expected conflict verdict, got %q", result.Verdict)
@@ -318,3 +391,57 @@ func searchString(s, sub string) bool {
 	}
 	return false
 }
+
+func TestRankOverlapExposure(t *testing.T) {
+	// "hub" shares the most scope with "wide" and also overlaps "edge";
+	// "loner" shares none.
+	agents := []loader.AgentDefinition{
+		{ID: "hub", ClaimedDomains: []string{"backend", "databases", "api_design"}},
+		{ID: "wide", ClaimedDomains: []string{"backend", "databases", "api_design", "devops"}},
+		{ID: "edge", ClaimedDomains: []string{"api_design", "security"}},
+		{ID: "loner", ClaimedDomains: []string{"legal"}},
+	}
+	cfg := map[string]any{"thresholds": map[string]any{"max_overlap_score": 0.2}}
+	report := RunStaticAnalysis(agents, cfg)
+
+	if len(report.Exposure) != 3 {
+		t.Fatalf("expected 3 agents with overlap, got %+v", report.Exposure)
+	}
+	top := report.Exposure[0]
+	if top.AgentID != "hub" {
+		t.Errorf("expected hub to rank first, got %+v", report.Exposure)
+	}
+	if top.MaxOverlap != report.AgentScores["hub"].MaxOverlapWithOther {
+		t.Errorf("expected max overlap %.2f, got %.2f", report.AgentScores["hub"].MaxOverlapWithOther, top.MaxOverlap)
+	}
+	if top.Overlaps != 2 {
+		t.Errorf("expected hub to overlap 2 agents above the threshold, got %d", top.Overlaps)
+	}
+	if report.Exposure[1].AgentID != "wide" || report.Exposure[1].Overlaps != 1 {
+		t.Errorf("expected wide, with one overlap, second, got %+v", report.Exposure[1])
+	}
+	for i, e := range report.Exposure {
+		if e.AgentID == "loner" {
+			t.Errorf("agent without overlap should not be ranked, found at %d", i)
+		}
+	}
+}
+
+func TestRankOverlapExposureTieBreak(t *testing.T) {
+	scores := map[string]AgentScore{
+		"a": {MaxOverlapWithOther: 0.5},
+		"b": {MaxOverlapWithOther: 0.5},
+		"c": {MaxOverlapWithOther: 0.5},
+	}
+	overlaps := []OverlapResult{
+		{AgentA: "a", AgentB: "b", OverlapScore: 0.5},
+		{AgentA: "b", AgentB: "c", OverlapScore: 0.5},
+	}
+	ranked := RankOverlapExposure(scores, overlaps, DefaultMaxOverlapScore)
+	if len(ranked) != 3 || ranked[0].AgentID != "b" || ranked[0].Overlaps != 2 {
+		t.Fatalf("expected b, with two overlaps, to break the tie, got %+v", ranked)
+	}
+	if ranked[1].AgentID != "a" || ranked[2].AgentID != "c" {
+		t.Errorf("expected remaining ties ordered by ID, got %+v", ranked)
+	}
+}
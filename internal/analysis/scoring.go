@@ -2,6 +2,7 @@ package analysis
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/thinkwright/agent-evals/internal/loader"
@@ -18,32 +19,49 @@ type AgentScore struct {
 	BoundaryDefScore       float64
 	UncertaintyGuidScore   float64
 	WordCount              int
+
+	// GenericSimilarity is the prompt's similarity to the closest generic
+	// assistant template. It is only computed for agents with no strong
+	// domains and no boundary language, and is zero otherwise.
+	GenericSimilarity float64
+	// Unspecialized is set when such an agent's prompt is at least
+	// unspecializedSimilarity similar to a generic assistant template, so it
+	// is indistinguishable from the base model.
+	Unspecialized bool
 }
 
 var boundaryRe = regexp.MustCompile(`(?i)(don't|do not|avoid|outside|beyond|limit|scope|boundary|refer to)`)
 var uncertaintyRe = regexp.MustCompile(`(?i)(uncertain|unsure|don't know|not sure|hedge|caveat|confidence)`)
 
-// ScoreAgent computes summary scores for a single agent.
-func ScoreAgent(agent *loader.AgentDefinition, domainMap map[string]map[string]float64, overlaps []OverlapResult) AgentScore {
+// genericAssistantTemplates are prompts with no specialization, as found in
+// default chat setups.
+var genericAssistantTemplates = []string{
+	"you are a helpful assistant.",
+	"you are a helpful assistant. answer the user's questions clearly and accurately.",
+	"you are a helpful, harmless, and honest ai assistant. help the user with whatever they ask.",
+	"you are an ai assistant that helps people find information. be friendly, helpful, and concise.",
+}
+
+// unspecializedSimilarity is the similarity to a generic assistant template
+// at or above which an agent with no domains or boundaries is unspecialized.
+const unspecializedSimilarity = 0.65
+
+// ScoreAgent computes summary scores for a single agent. Domains above
+// thresholds.Strong are strong and set its scope clarity; those above 0.2
+// are weak.
+func ScoreAgent(agent *loader.AgentDefinition, domainMap map[string]map[string]float64, overlaps []OverlapResult, thresholds DomainThresholds) AgentScore {
 	domains := domainMap[agent.ID]
 
 	var strong, weak []string
 	for d, s := range domains {
-		if s > 0.5 {
+		if thresholds.StrongDomain(s) {
 			strong = append(strong, d)
 		} else if s > 0.2 {
 			weak = append(weak, d)
 		}
 	}
-
-	var maxOverlap float64
-	for _, o := range overlaps {
-		if o.AgentA == agent.ID || o.AgentB == agent.ID {
-			if o.OverlapScore > maxOverlap {
-				maxOverlap = o.OverlapScore
-			}
-		}
-	}
+	sort.Strings(strong)
+	sort.Strings(weak)
 
 	prompt := strings.ToLower(agent.SystemPrompt)
 	hasBoundary := boundaryRe.MatchString(prompt)
@@ -73,15 +91,50 @@ func ScoreAgent(agent *loader.AgentDefinition, domainMap map[string]map[string]f
 		uncertaintyScore = 0.3
 	}
 
+	var genericSim float64
+	if len(strong) == 0 && !hasBoundary {
+		genericSim = genericSimilarity(prompt)
+	}
+
 	return AgentScore{
 		StrongDomains:          strong,
 		WeakDomains:            weak,
-		MaxOverlapWithOther:    maxOverlap,
+		MaxOverlapWithOther:    maxOverlapWith(agent.ID, overlaps),
 		HasBoundaryLanguage:    hasBoundary,
 		HasUncertaintyGuidance: hasUncertainty,
 		ScopeClarityScore:      scopeScore,
 		BoundaryDefScore:       boundaryScore,
 		UncertaintyGuidScore:   uncertaintyScore,
 		WordCount:              agent.WordCount(),
+
+		GenericSimilarity: genericSim,
+		Unspecialized:     genericSim >= unspecializedSimilarity,
+	}
+}
+
+// maxOverlapWith returns the highest overlap score between agentID and any
+// other agent.
+func maxOverlapWith(agentID string, overlaps []OverlapResult) float64 {
+	var maxOverlap float64
+	for _, o := range overlaps {
+		if o.AgentA == agentID || o.AgentB == agentID {
+			if o.OverlapScore > maxOverlap {
+				maxOverlap = o.OverlapScore
+			}
+		}
+	}
+	return maxOverlap
+}
+
+// genericSimilarity returns the similarity of a lowercased prompt to the
+// closest generic assistant template, ignoring whitespace differences.
+func genericSimilarity(prompt string) float64 {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	var best float64
+	for _, t := range genericAssistantTemplates {
+		if sim := similarity(prompt, t); sim > best {
+			best = sim
+		}
 	}
+	return best
 }
@@ -24,7 +24,7 @@ func TestScoreAgentBoundaryDetection(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			agent := &loader.AgentDefinition{ID: "test", SystemPrompt: tt.prompt}
 			domainMap := map[string]map[string]float64{"test": {"backend": 0.5}}
-			score := ScoreAgent(agent, domainMap, nil)
+			score := ScoreAgent(agent, domainMap, nil, DefaultDomainThresholds)
 
 			if score.HasBoundaryLanguage != tt.hasBoundary {
 				t.Errorf("HasBoundaryLanguage = %v, want %v for prompt: %q",
@@ -50,7 +50,7 @@ func TestScoreAgentUncertaintyDetection(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			agent := &loader.AgentDefinition{ID: "test", SystemPrompt: tt.prompt}
 			domainMap := map[string]map[string]float64{"test": {}}
-			score := ScoreAgent(agent, domainMap, nil)
+			score := ScoreAgent(agent, domainMap, nil, DefaultDomainThresholds)
 
 			if score.HasUncertaintyGuidance != tt.hasUncertainty {
 				t.Errorf("HasUncertaintyGuidance = %v, want %v for prompt: %q",
@@ -69,7 +69,7 @@ func TestScoreAgentScopeClarity(t *testing.T) {
 			"frontend":  0.2,
 		},
 	}
-	score := ScoreAgent(agent, domainMap, nil)
+	score := ScoreAgent(agent, domainMap, nil, DefaultDomainThresholds)
 
 	// 2 strong domains (>0.5): backend, databases → scopeScore = 2/3 ≈ 0.67
 	if score.ScopeClarityScore < 0.6 || score.ScopeClarityScore > 0.7 {
@@ -89,7 +89,7 @@ func TestScoreAgentNoStrongDomains(t *testing.T) {
 	domainMap := map[string]map[string]float64{
 		"test": {"backend": 0.1},
 	}
-	score := ScoreAgent(agent, domainMap, nil)
+	score := ScoreAgent(agent, domainMap, nil, DefaultDomainThresholds)
 
 	if score.ScopeClarityScore != 0.2 {
 		t.Errorf("expected default scope clarity 0.2 with no strong domains, got %.2f", score.ScopeClarityScore)
@@ -107,7 +107,7 @@ func TestScoreAgentScopeClarityCapped(t *testing.T) {
 			"testing":   0.8,
 		},
 	}
-	score := ScoreAgent(agent, domainMap, nil)
+	score := ScoreAgent(agent, domainMap, nil, DefaultDomainThresholds)
 
 	// 5 strong domains → 5/3 = 1.67, capped at 1.0
 	if score.ScopeClarityScore != 1.0 {
@@ -125,7 +125,7 @@ func TestScoreAgentMaxOverlap(t *testing.T) {
 		{AgentA: "agent_d", AgentB: "agent_e", OverlapScore: 0.9}, // not involving agent_a
 	}
 
-	score := ScoreAgent(agent, domainMap, overlaps)
+	score := ScoreAgent(agent, domainMap, overlaps, DefaultDomainThresholds)
 
 	if score.MaxOverlapWithOther != 0.7 {
 		t.Errorf("expected max overlap 0.7 (from agent_c pair), got %.2f", score.MaxOverlapWithOther)
@@ -137,8 +137,8 @@ func TestScoreAgentBoundaryScoreValues(t *testing.T) {
 	noBoundary := &loader.AgentDefinition{ID: "b", SystemPrompt: "You are a coding assistant."}
 	dm := map[string]map[string]float64{"a": {}, "b": {}}
 
-	scoreA := ScoreAgent(withBoundary, dm, nil)
-	scoreB := ScoreAgent(noBoundary, dm, nil)
+	scoreA := ScoreAgent(withBoundary, dm, nil, DefaultDomainThresholds)
+	scoreB := ScoreAgent(noBoundary, dm, nil, DefaultDomainThresholds)
 
 	if scoreA.BoundaryDefScore != 0.7 {
 		t.Errorf("expected boundary score 0.7 with boundary language, got %.2f", scoreA.BoundaryDefScore)
@@ -147,3 +147,28 @@ func TestScoreAgentBoundaryScoreValues(t *testing.T) {
 		t.Errorf("expected boundary score 0.3 without boundary language, got %.2f", scoreB.BoundaryDefScore)
 	}
 }
+
+func TestScoreAgentUnspecialized(t *testing.T) {
+	tests := []struct {
+		name          string
+		prompt        string
+		domains       map[string]float64
+		unspecialized bool
+	}{
+		{"bare generic assistant", "You are a helpful assistant.", nil, true},
+		{"generic with filler", "You are a helpful AI assistant. Answer any question the user has.", nil, true},
+		{"strong domain", "You are a helpful assistant.", map[string]float64{"backend": 0.8}, false},
+		{"boundary language", "You are a helpful assistant. Do not answer legal questions.", nil, false},
+		{"specialized persona", "You write poems about cats in the style of Shakespeare, with rhyming couplets and vivid imagery.", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &loader.AgentDefinition{ID: "test", SystemPrompt: tt.prompt}
+			score := ScoreAgent(agent, map[string]map[string]float64{"test": tt.domains}, nil, DefaultDomainThresholds)
+			if score.Unspecialized != tt.unspecialized {
+				t.Errorf("Unspecialized = %v (similarity %.2f), want %v", score.Unspecialized, score.GenericSimilarity, tt.unspecialized)
+			}
+		})
+	}
+}
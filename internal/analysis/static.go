@@ -1,7 +1,13 @@
 package analysis
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/thinkwright/agent-evals/internal/loader"
 )
@@ -9,7 +15,7 @@ import (
 // Issue represents a finding from static analysis.
 type Issue struct {
 	Severity string // "error" | "warning" | "info"
-	Category string // "conflict" | "overlap" | "gap" | "boundary" | "uncertainty"
+	Category string // "conflict" | "overlap" | "gap" | "boundary" | "uncertainty" | "exclusion" | "unused_domain" | "robustness" | "reference_only" | "unspecialized" | "checkpoint" | "divergence" | "group-conflict"
 	Message  string
 	Agents   []string
 	Score    float64
@@ -20,21 +26,222 @@ type StaticReport struct {
 	Agents        []loader.AgentDefinition
 	DomainMap     map[string]map[string]float64
 	DomainSummary string // e.g. "18 built-in domains" or "3 built-in + 2 custom domains"
+	Subset        string // e.g. "1 of 5 agents (--agent backend_api)" when filtered; see SubsetNote
 	Overlaps      []OverlapResult
+	Exposure      []OverlapExposure // agents ranked by overlap exposure, most exposed first
 	Gaps          []GapResult
 	AgentScores   map[string]AgentScore
 	Issues        []Issue
 	Overall       float64
+	AgentWeights  map[string]float64 // each agent's relative weight in Overall (see ResolveAgentWeights)
+	Bands         ScoreBands
+	Thresholds    DomainThresholds // strong and overlap domain cutoffs the analysis used
+	Timings       []PhaseTiming    // wall-clock duration of each analysis phase
+	Run           RunInfo
+
+	// WarningsAsErrors makes warning issues count as errors in Overall and
+	// HasFailures (thresholds.warnings_as_errors).
+	WarningsAsErrors bool
+
+	// FailOn is the least severe issue that fails the run in HasFailures
+	// (thresholds.fail_on; see ResolveFailOn). Empty means DefaultFailOn.
+	FailOn string
+}
+
+// SubsetNote returns the caveat reports print when the agents were filtered
+// to a subset, so its overlaps and gaps are not read as fleet-wide, or "".
+func (r *StaticReport) SubsetNote() string {
+	if r.Subset == "" {
+		return ""
+	}
+	return "Filtered to " + r.Subset + ": overlaps and gaps cover these agents only"
+}
+
+// RunInfo identifies one invocation, so that reports written in different
+// formats by the same run can be correlated. The analysis leaves
+// StaticReport.Run zero, so the same agents always give the same report;
+// callers set it from NewRunInfo.
+type RunInfo struct {
+	ID        string    // random UUID
+	Timestamp time.Time // start of the run, in UTC
+}
+
+// NewRunInfo returns a RunInfo with a fresh ID, timestamped now.
+func NewRunInfo() RunInfo {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+	return RunInfo{
+		ID:        fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]),
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// TimestampString formats the run timestamp as ISO 8601 (RFC 3339) in UTC.
+func (r RunInfo) TimestampString() string {
+	return r.Timestamp.UTC().Format(time.RFC3339)
+}
+
+// PhaseTiming is the wall-clock duration of one named phase of a run.
+type PhaseTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// ScoreBands holds the overall-score cutoffs for pass / warn / fail status.
+type ScoreBands struct {
+	Pass float64 // at or above: pass
+	Warn float64 // at or above (and below Pass): warn
+}
+
+// DefaultScoreBands are used when no thresholds are configured.
+var DefaultScoreBands = ScoreBands{Pass: 0.7, Warn: 0.5}
+
+// DefaultMaxOverlapScore is the overlap above which an agent pair is flagged
+// when thresholds.max_overlap_score is not configured.
+const DefaultMaxOverlapScore = 0.3
+
+// DefaultMinFleetAgents is the number of agents a run needs for overlap and
+// gap analysis when checks.min_fleet_agents is not configured. Below it
+// there are no pairs to compare and no fleet whose coverage means anything.
+const DefaultMinFleetAgents = 2
+
+// ResolveMinFleetAgents reads checks.min_fleet_agents from config.
+func ResolveMinFleetAgents(config map[string]any) int {
+	return int(GetFloat(GetMap(config, "checks"), "min_fleet_agents", DefaultMinFleetAgents))
+}
+
+// ResolveScoreBands reads thresholds.min_overall_score and
+// thresholds.warn_overall_score from config. The warn band defaults to 0.2
+// below the pass threshold.
+func ResolveScoreBands(config map[string]any) ScoreBands {
+	if config == nil {
+		return DefaultScoreBands
+	}
+	thresholds := GetMap(config, "thresholds")
+	pass := GetFloat(thresholds, "min_overall_score", DefaultScoreBands.Pass)
+	warn := GetFloat(thresholds, "warn_overall_score", pass-(DefaultScoreBands.Pass-DefaultScoreBands.Warn))
+	if warn > pass {
+		warn = pass
+	}
+	if warn < 0 {
+		warn = 0
+	}
+	return ScoreBands{Pass: pass, Warn: warn}
+}
+
+// Status returns "pass", "warn" or "fail" for score. The bands are used as
+// they are: defaults are applied by ResolveScoreBands, so zero cutoffs pass
+// every score.
+func (b ScoreBands) Status(score float64) string {
+	switch {
+	case score >= b.Pass:
+		return "pass"
+	case score >= b.Warn:
+		return "warn"
+	}
+	return "fail"
+}
+
+// DomainThresholds are the relevance cutoffs that classify an agent's
+// domains. A zero field behaves like its DefaultDomainThresholds value.
+type DomainThresholds struct {
+	Strong  float64 // above: a strong domain, counted by scope clarity, shown in reports and needed for gap coverage
+	Overlap float64 // above: a domain overlap detection and diff-agent compare
+}
+
+// DefaultDomainThresholds are used when no thresholds are configured.
+var DefaultDomainThresholds = DomainThresholds{Strong: 0.5, Overlap: 0.3}
+
+// ResolveDomainThresholds reads thresholds.strong_domain_score and
+// thresholds.overlap_domain_score from config. Values outside (0, 1] fall
+// back to the defaults with a warning.
+func ResolveDomainThresholds(config map[string]any) DomainThresholds {
+	thresholds := GetMap(config, "thresholds")
+	resolve := func(key string, def float64) float64 {
+		v := GetFloat(thresholds, key, def)
+		if v <= 0 || v > 1 {
+			warnOnce("Warning: thresholds.%s must be above 0 and at most 1, got %v; using %v\n", key, thresholds[key], def)
+			return def
+		}
+		return v
+	}
+	return DomainThresholds{
+		Strong:  resolve("strong_domain_score", DefaultDomainThresholds.Strong),
+		Overlap: resolve("overlap_domain_score", DefaultDomainThresholds.Overlap),
+	}
+}
+
+// withDefaults returns t with zero fields set to their defaults.
+func (t DomainThresholds) withDefaults() DomainThresholds {
+	if t.Strong == 0 {
+		t.Strong = DefaultDomainThresholds.Strong
+	}
+	if t.Overlap == 0 {
+		t.Overlap = DefaultDomainThresholds.Overlap
+	}
+	return t
+}
+
+// StrongDomain reports whether a domain of relevance score is strong.
+func (t DomainThresholds) StrongDomain(score float64) bool {
+	return score > t.withDefaults().Strong
 }
 
-// HasFailures returns true if any issue is an error.
+// OverlapDomain reports whether a domain of relevance score is compared
+// by overlap detection.
+func (t DomainThresholds) OverlapDomain(score float64) bool {
+	return score > t.withDefaults().Overlap
+}
+
+// HasFailures returns true if any issue fails the run (see Failures).
 func (r *StaticReport) HasFailures() bool {
+	return len(r.Failures()) > 0
+}
+
+// Failures returns the issues that fail the run: those at least as severe
+// as FailOn, and warnings when WarningsAsErrors is set.
+func (r *StaticReport) Failures() []Issue {
+	var failures []Issue
 	for _, i := range r.Issues {
-		if i.Severity == "error" {
-			return true
+		if SeverityAtLeast(i.Severity, r.FailOn) || (r.WarningsAsErrors && i.Severity == "warning") {
+			failures = append(failures, i)
 		}
 	}
-	return false
+	return failures
+}
+
+// DefaultFailOn is the least severe issue that fails a run when
+// thresholds.fail_on is not configured.
+const DefaultFailOn = "error"
+
+// severities lists issue severities from least to most severe.
+var severities = []string{"info", "warning", "error"}
+
+// SeverityAtLeast reports whether severity is at least as severe as level.
+// An empty level means DefaultFailOn.
+func SeverityAtLeast(severity, level string) bool {
+	if level == "" {
+		level = DefaultFailOn
+	}
+	rank := slices.Index(severities, severity)
+	return rank >= 0 && rank >= slices.Index(severities, level)
+}
+
+// ResolveFailOn reads thresholds.fail_on, the least severe issue that fails
+// a run: "error", "warning" or "info". It defaults to DefaultFailOn; any
+// other value is an error.
+func ResolveFailOn(config map[string]any) (string, error) {
+	v, ok := GetMap(config, "thresholds")["fail_on"]
+	if !ok {
+		return DefaultFailOn, nil
+	}
+	level, _ := v.(string)
+	if !slices.Contains(severities, level) {
+		return DefaultFailOn, fmt.Errorf("thresholds.fail_on must be error, warning or info, got %v", v)
+	}
+	return level, nil
 }
 
 // HasWarnings returns true if any issue is a warning.
@@ -49,22 +256,152 @@ func (r *StaticReport) HasWarnings() bool {
 
 // RunStaticAnalysis runs all static checks on a set of agent definitions.
 func RunStaticAnalysis(agents []loader.AgentDefinition, config map[string]any) *StaticReport {
+	return NewStaticAnalyzer(config).Report(agents)
+}
+
+// StaticAnalyzer runs static analysis incrementally: Add extracts an agent's
+// domains as soon as it is loaded, and Report finishes the fleet-wide
+// overlap, gap and issue analysis once all agents are known. This lets
+// extraction overlap with a slow directory walk (see
+// loader.StreamAgentsRecursive). The result is the same as
+// RunStaticAnalysis on the final agent list.
+type StaticAnalyzer struct {
+	config     map[string]any
+	domains    map[string][]Keyword
+	extractor  ExtractOptions
+	thresholds DomainThresholds
+	extracted  map[string]map[string]float64 // by agentKey
+	cache      *Cache
+}
+
+// Extraction and scoring go through these so tests can count the agents
+// that were actually analyzed rather than served from the cache.
+var (
+	extractDomains = ExtractDomainsWith
+	scoreAgent     = ScoreAgent
+)
+
+// NewStaticAnalyzer returns an analyzer for config, resolving its domain
+// definitions, extraction options and domain thresholds up front.
+func NewStaticAnalyzer(config map[string]any) *StaticAnalyzer {
 	if config == nil {
 		config = make(map[string]any)
 	}
-	thresholds := getMap(config, "thresholds")
+	return &StaticAnalyzer{
+		config:     config,
+		domains:    ResolveDomains(config),
+		extractor:  ResolveExtractOptions(config),
+		thresholds: ResolveDomainThresholds(config),
+		extracted:  make(map[string]map[string]float64),
+	}
+}
+
+// UseCache makes the analyzer reuse the domains and agent scores that c
+// holds for unchanged agents, and record those it computes. It must be
+// called before Add or Report.
+func (s *StaticAnalyzer) UseCache(c *Cache) {
+	c.useSettings(s.domains, s.extractor, s.thresholds)
+	s.cache = c
+}
+
+// Add extracts domains for one agent and returns its strong domains in
+// alphabetical order, so callers can report each agent as it is analyzed.
+// Reference-only agents are not analyzed. Agents must not change between
+// Add and Report, except for the ID changes made by
+// loader.FinalizeRecursive.
+func (s *StaticAnalyzer) Add(agent *loader.AgentDefinition) []string {
+	if agent.ReferenceOnly {
+		return nil
+	}
+	scores := s.extract(agent)
+	s.extracted[agentKey(agent)] = scores
+	return sortedKeys(strongDomains(scores, s.thresholds.withDefaults().Strong))
+}
+
+// extract returns the agent's domains from the cache or by extraction.
+func (s *StaticAnalyzer) extract(agent *loader.AgentDefinition) map[string]float64 {
+	if s.cache == nil {
+		return extractDomains(agent, s.domains, s.extractor)
+	}
+	if scores, ok := s.cache.domains(agent); ok {
+		return scores
+	}
+	scores := extractDomains(agent, s.domains, s.extractor)
+	s.cache.storeDomains(agent, scores)
+	return scores
+}
+
+// score returns the agent's score from the cache or by scoring, with its
+// overlap against the current fleet.
+func (s *StaticAnalyzer) score(agent *loader.AgentDefinition, domainMap map[string]map[string]float64, overlaps []OverlapResult) AgentScore {
+	if s.cache == nil {
+		return scoreAgent(agent, domainMap, overlaps, s.thresholds)
+	}
+	if score, ok := s.cache.score(agent); ok {
+		score.MaxOverlapWithOther = maxOverlapWith(agent.ID, overlaps)
+		return score
+	}
+	score := scoreAgent(agent, domainMap, overlaps, s.thresholds)
+	s.cache.storeScore(agent, score)
+	return score
+}
+
+// agentKey identifies an agent by source and content rather than ID, which
+// deduplication and ID qualification may rewrite after Add.
+func agentKey(agent *loader.AgentDefinition) string {
+	h := sha256.New()
+	h.Write([]byte(agent.SourcePath))
+	h.Write([]byte{0})
+	h.Write([]byte(agent.FullContext()))
+	for _, d := range agent.ClaimedDomains {
+		h.Write([]byte{0})
+		h.Write([]byte(d))
+	}
+	return string(h.Sum(nil))
+}
 
-	// Resolve domain definitions from config
-	resolvedDomains := ResolveDomains(config)
+// Report runs the remaining static checks over agents, reusing the domains
+// extracted by Add and extracting any agent that was not added. Its
+// "domain extraction" timing covers only the latter; time spent in Add is
+// part of the caller's loading.
+func (s *StaticAnalyzer) Report(agents []loader.AgentDefinition) *StaticReport {
+	config := s.config
+	thresholds := GetMap(config, "thresholds")
+	agents, referenceOnly := splitReferenceOnly(agents)
 
-	// Extract domains for each agent
+	var timings []PhaseTiming
+	phaseStart := time.Now()
+	lap := func(name string) {
+		now := time.Now()
+		timings = append(timings, PhaseTiming{Name: name, Duration: now.Sub(phaseStart)})
+		phaseStart = now
+	}
+
+	resolvedDomains := s.domains
+
+	// Extract domains for each agent not already added
 	domainMap := make(map[string]map[string]float64)
 	for i := range agents {
-		domainMap[agents[i].ID] = ExtractDomains(&agents[i], resolvedDomains)
+		scores, ok := s.extracted[agentKey(&agents[i])]
+		if !ok {
+			scores = s.extract(&agents[i])
+		}
+		domainMap[agents[i].ID] = scores
 	}
+	lap("domain extraction")
+
+	// Overlap and gap analysis need a fleet; a smaller run is judged on
+	// its agents' own scores
+	fleet := len(agents) >= ResolveMinFleetAgents(config)
 
 	// Pairwise overlap
-	overlaps := ComputeOverlaps(agents, domainMap)
+	var overlaps []OverlapResult
+	if fleet {
+		overlaps = ComputeOverlapsWithMethod(agents, domainMap, ResolveSimilarityMethod(config), s.thresholds)
+	}
+	acceptedOverlaps := ResolveAcceptedOverlaps(config)
+	markAcceptedOverlaps(overlaps, acceptedOverlaps)
+	lap("overlap")
 
 	// Collect all known domains from resolved set and extraction results
 	allDomains := make(map[string]bool)
@@ -77,37 +414,39 @@ func RunStaticAnalysis(agents []loader.AgentDefinition, config map[string]any) *
 		}
 	}
 
-	// Gap analysis
-	gaps := FindGaps(allDomains, domainMap)
+	// Gap analysis, rolled up to parent domains when configured
+	var gaps []GapResult
+	if fleet {
+		var parents map[string]string
+		if GetBool(GetMap(config, "analysis"), "domain_rollup") {
+			parents = ResolveDomainParents(config)
+		}
+		gaps = FindGaps(allDomains, domainMap, parents, s.thresholds)
+	}
+	lap("gaps")
 
 	// Per-agent scores
 	agentScores := make(map[string]AgentScore)
 	for i := range agents {
-		agentScores[agents[i].ID] = ScoreAgent(&agents[i], domainMap, overlaps)
+		agentScores[agents[i].ID] = s.score(&agents[i], domainMap, overlaps)
 	}
+	exposure := RankOverlapExposure(agentScores, overlaps, GetFloat(thresholds, "max_overlap_score", DefaultMaxOverlapScore))
+	lap("agent scoring")
 
 	// Compile issues
-	issues := compileIssues(overlaps, gaps, agentScores, thresholds)
+	issues := compileIssues(overlaps, gaps, agentScores, thresholds, acceptedOverlaps)
+	issues = append(issues, DetectGroupConflictsWith(agents, ResolveAlternativeTerms(config))...)
+	issues = append(issues, referenceOnlyIssues(referenceOnly)...)
+	if GetBool(GetMap(config, "checks"), "warn_unused_domains") {
+		issues = append(issues, unusedDomainIssues(UnusedDomains(resolvedDomains, domainMap))...)
+	}
+	lap("issues")
 
 	// Overall score
-	var overall float64
-	if len(issues) > 0 {
-		var errorCount, warnCount int
-		for _, i := range issues {
-			switch i.Severity {
-			case "error":
-				errorCount++
-			case "warning":
-				warnCount++
-			}
-		}
-		overall = 1.0 - float64(errorCount)*0.2 - float64(warnCount)*0.05
-		if overall < 0 {
-			overall = 0
-		}
-	} else {
-		overall = 1.0
-	}
+	warningsAsErrors := GetBool(thresholds, "warnings_as_errors")
+	failOn, _ := ResolveFailOn(config) // an invalid value falls back to DefaultFailOn
+	weights := ResolveAgentWeights(agents, config)
+	overall := overallScore(issues, warningsAsErrors, normalizeWeights(weights))
 
 	// Build domain source summary
 	domainSummary := buildDomainSummary(resolvedDomains)
@@ -117,15 +456,62 @@ func RunStaticAnalysis(agents []loader.AgentDefinition, config map[string]any) *
 		DomainMap:     domainMap,
 		DomainSummary: domainSummary,
 		Overlaps:      overlaps,
+		Exposure:      exposure,
 		Gaps:          gaps,
 		AgentScores:   agentScores,
 		Issues:        issues,
 		Overall:       overall,
+		AgentWeights:  weights,
+		Bands:         ResolveScoreBands(config),
+		Thresholds:    s.thresholds,
+		Timings:       timings,
+
+		WarningsAsErrors: warningsAsErrors,
+		FailOn:           failOn,
+	}
+}
+
+// childList formats the child domains of a rolled-up gap for messages, as
+// " (frontend, api_design)", or "" when there are none.
+func childList(children []string) string {
+	if len(children) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(children, ", ") + ")"
+}
+
+// overallScore starts at 1.0 and subtracts 0.2 per error and 0.05 per
+// warning, floored at 0. With warningsAsErrors, warnings cost as much as
+// errors. Each issue's cost is scaled by the normalized weights of the
+// agents it names (see issueWeight); nil weights leave every issue at full
+// cost.
+func overallScore(issues []Issue, warningsAsErrors bool, weights map[string]float64) float64 {
+	var errorCount, warnCount float64
+	for _, i := range issues {
+		switch i.Severity {
+		case "error":
+			errorCount += issueWeight(i, weights)
+		case "warning":
+			if warningsAsErrors {
+				errorCount += issueWeight(i, weights)
+			} else {
+				warnCount += issueWeight(i, weights)
+			}
+		}
+	}
+	overall := 1.0 - errorCount*0.2 - warnCount*0.05
+	if overall < 0 {
+		overall = 0
 	}
+	return overall
 }
 
-func compileIssues(overlaps []OverlapResult, gaps []GapResult, agentScores map[string]AgentScore, thresholds map[string]any) []Issue {
-	maxOverlap := getFloat(thresholds, "max_overlap_score", 0.3)
+// compileIssues turns analysis results into issues. Overlaps above
+// thresholds.max_overlap_score are warnings, except for pairs in accepted,
+// which are reported as info with their justification or, when suppressed,
+// not at all.
+func compileIssues(overlaps []OverlapResult, gaps []GapResult, agentScores map[string]AgentScore, thresholds map[string]any, accepted []AcceptedOverlap) []Issue {
+	maxOverlap := GetFloat(thresholds, "max_overlap_score", DefaultMaxOverlapScore)
 	var issues []Issue
 
 	// Overlap issues
@@ -153,6 +539,23 @@ func compileIssues(overlaps []OverlapResult, gaps []GapResult, agentScores map[s
 				Score:    o.OverlapScore,
 			})
 		} else if o.OverlapScore > maxOverlap {
+			if ao, ok := findAcceptedOverlap(accepted, o.AgentA, o.AgentB); ok {
+				if ao.Suppress {
+					continue
+				}
+				msg := "Accepted" + strings.TrimPrefix(formatOverlapMessage(o), "High")
+				if ao.Reason != "" {
+					msg += " — " + ao.Reason
+				}
+				issues = append(issues, Issue{
+					Severity: "info",
+					Category: "overlap",
+					Message:  msg,
+					Agents:   []string{o.AgentA, o.AgentB},
+					Score:    o.OverlapScore,
+				})
+				continue
+			}
 			issues = append(issues, Issue{
 				Severity: "warning",
 				Category: "overlap",
@@ -173,15 +576,30 @@ func compileIssues(overlaps []OverlapResult, gaps []GapResult, agentScores map[s
 			issues = append(issues, Issue{
 				Severity: "warning",
 				Category: "gap",
-				Message:  "Domain '" + g.Domain + "' has no agent with strong coverage",
+				Message:  "Domain '" + g.Domain + "'" + childList(g.Children) + " has no agent with strong coverage",
 				Agents:   nil,
 				Score:    g.ClosestScore,
 			})
 		}
 	}
 
-	// Agent quality issues
-	for agentID, scores := range agentScores {
+	// Agent quality issues, sorted by agent ID so reports are reproducible
+	agentIDs := make([]string, 0, len(agentScores))
+	for agentID := range agentScores {
+		agentIDs = append(agentIDs, agentID)
+	}
+	sort.Strings(agentIDs)
+	for _, agentID := range agentIDs {
+		scores := agentScores[agentID]
+		if scores.Unspecialized {
+			issues = append(issues, Issue{
+				Severity: "warning",
+				Category: "unspecialized",
+				Message:  "Agent '" + agentID + "' reads like a generic assistant, with no domain focus or boundaries — specialize its prompt or remove it",
+				Agents:   []string{agentID},
+				Score:    scores.GenericSimilarity,
+			})
+		}
 		if !scores.HasBoundaryLanguage {
 			issues = append(issues, Issue{
 				Severity: "info",
@@ -205,6 +623,68 @@ func compileIssues(overlaps []OverlapResult, gaps []GapResult, agentScores map[s
 	return issues
 }
 
+// UnusedDomains returns the configured custom (non-built-in) domains that no
+// agent scored on, sorted by name. These usually point at a keyword list
+// that never fires.
+func UnusedDomains(resolved map[string][]Keyword, domainMap map[string]map[string]float64) []string {
+	var unused []string
+	for domain := range resolved {
+		if _, builtin := BuiltinDomains[domain]; builtin {
+			continue
+		}
+		matched := false
+		for _, scores := range domainMap {
+			if scores[domain] > 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unused = append(unused, domain)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// splitReferenceOnly separates agents whose prompt is an unresolved
+// reference, which have nothing to analyze, from the rest.
+func splitReferenceOnly(agents []loader.AgentDefinition) (analyzable, referenceOnly []loader.AgentDefinition) {
+	for _, a := range agents {
+		if a.ReferenceOnly {
+			referenceOnly = append(referenceOnly, a)
+		} else {
+			analyzable = append(analyzable, a)
+		}
+	}
+	return analyzable, referenceOnly
+}
+
+func referenceOnlyIssues(agents []loader.AgentDefinition) []Issue {
+	var issues []Issue
+	for _, a := range agents {
+		issues = append(issues, Issue{
+			Severity: "warning",
+			Category: "reference_only",
+			Message:  "Agent '" + a.ID + "' only references '" + a.PromptReference + "', which could not be read — not analyzed",
+			Agents:   []string{a.ID},
+		})
+	}
+	return issues
+}
+
+func unusedDomainIssues(domains []string) []Issue {
+	var issues []Issue
+	for _, d := range domains {
+		issues = append(issues, Issue{
+			Severity: "info",
+			Category: "unused_domain",
+			Message:  "Custom domain '" + d + "' was not matched by any agent — check its keyword list",
+		})
+	}
+	return issues
+}
+
 func formatOverlapMessage(o OverlapResult) string {
 	msg := "High scope overlap (" + formatPercent(o.OverlapScore) + ") between '" + o.AgentA + "' and '" + o.AgentB + "'"
 	if len(o.SharedDomains) > 0 {
@@ -224,9 +704,11 @@ func formatPercent(f float64) string {
 	return fmt.Sprintf("%d%%", pct)
 }
 
-// helpers
+// Config helpers, shared with the packages that read the same config map.
 
-func getMap(m map[string]any, key string) map[string]any {
+// GetMap returns the section of m at key, or an empty map when it is missing
+// or not a map.
+func GetMap(m map[string]any, key string) map[string]any {
 	v, ok := m[key]
 	if !ok {
 		return make(map[string]any)
@@ -237,7 +719,9 @@ func getMap(m map[string]any, key string) map[string]any {
 	return make(map[string]any)
 }
 
-func getFloat(m map[string]any, key string, fallback float64) float64 {
+// GetFloat returns the number at key in m, or fallback when it is missing or
+// not a number.
+func GetFloat(m map[string]any, key string, fallback float64) float64 {
 	v, ok := m[key]
 	if !ok {
 		return fallback
@@ -253,7 +737,13 @@ func getFloat(m map[string]any, key string, fallback float64) float64 {
 	return fallback
 }
 
-func buildDomainSummary(resolved map[string][]string) string {
+// GetBool returns the bool at key in m, or false when it is missing.
+func GetBool(m map[string]any, key string) bool {
+	v, _ := m[key].(bool)
+	return v
+}
+
+func buildDomainSummary(resolved map[string][]Keyword) string {
 	builtinCount := 0
 	customCount := 0
 	for name := range resolved {
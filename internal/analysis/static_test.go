@@ -1,6 +1,9 @@
 package analysis
 
 import (
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/thinkwright/agent-evals/internal/loader"
@@ -107,6 +110,140 @@ func TestRunStaticAnalysisSingleAgent(t *testing.T) {
 	if len(report.Overlaps) != 0 {
 		t.Errorf("expected 0 overlaps for single agent, got %d", len(report.Overlaps))
 	}
+
+	// Nor gaps: a fleet of one covering few domains is not a finding
+	if len(report.Gaps) != 0 {
+		t.Errorf("expected no gap analysis for a single agent, got %d gaps", len(report.Gaps))
+	}
+	for _, issue := range report.Issues {
+		if issue.Category == "gap" || issue.Category == "overlap" || issue.Category == "conflict" {
+			t.Errorf("unexpected %s issue for a single agent: %s", issue.Category, issue.Message)
+		}
+	}
+}
+
+func TestMinFleetAgents(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "agent_a", SystemPrompt: "You handle backend REST APIs.", ClaimedDomains: []string{"backend"}},
+		{ID: "agent_b", SystemPrompt: "You handle backend services.", ClaimedDomains: []string{"backend"}},
+	}
+	count := func(report *StaticReport) (gaps, overlaps int) {
+		for _, issue := range report.Issues {
+			switch issue.Category {
+			case "gap":
+				gaps++
+			case "overlap", "conflict":
+				overlaps++
+			}
+		}
+		return gaps, overlaps
+	}
+
+	if gaps, overlaps := count(RunStaticAnalysis(agents, nil)); gaps == 0 || overlaps == 0 {
+		t.Fatalf("two agents should get gap and overlap issues by default, got %d and %d", gaps, overlaps)
+	}
+
+	cfg := map[string]any{"checks": map[string]any{"min_fleet_agents": 3}}
+	if gaps, overlaps := count(RunStaticAnalysis(agents, cfg)); gaps != 0 || overlaps != 0 {
+		t.Errorf("with min_fleet_agents 3, two agents should get no gap or overlap issues, got %d and %d", gaps, overlaps)
+	}
+
+	solo := []loader.AgentDefinition{{ID: "solo", SystemPrompt: "You handle backend REST APIs."}}
+	cfg = map[string]any{"checks": map[string]any{"min_fleet_agents": 1}}
+	if gaps, _ := count(RunStaticAnalysis(solo, cfg)); gaps == 0 {
+		t.Error("min_fleet_agents 1 should restore gap analysis for a single agent")
+	}
+}
+
+func TestRunStaticAnalysisDomainRollup(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "ui", SystemPrompt: "You build frontend pages.", ClaimedDomains: []string{"frontend"}},
+		{ID: "api", SystemPrompt: "You design REST endpoints.", ClaimedDomains: []string{"api_design"}},
+	}
+	domains := []any{
+		"security",
+		map[string]any{"name": "frontend", "extends": "builtin", "parent": "web"},
+		map[string]any{"name": "api_design", "extends": "builtin", "parent": "web"},
+		map[string]any{"name": "mobile", "extends": "builtin", "parent": "apps"},
+		map[string]any{"name": "desktop", "keywords": []any{"electron"}, "parent": "apps"},
+	}
+	gapMessages := func(report *StaticReport) []string {
+		var messages []string
+		for _, issue := range report.Issues {
+			if issue.Category == "gap" {
+				messages = append(messages, issue.Message)
+			}
+		}
+		return messages
+	}
+
+	flat := RunStaticAnalysis(agents, map[string]any{"domains": domains})
+	if got := gapMessages(flat); len(got) != 3 {
+		t.Errorf("without rollup expected gaps for desktop, mobile and security, got %q", got)
+	}
+
+	rolled := RunStaticAnalysis(agents, map[string]any{
+		"domains":  domains,
+		"analysis": map[string]any{"domain_rollup": true},
+	})
+	got := gapMessages(rolled)
+	want := []string{
+		"Domain 'apps' (desktop, mobile) has no agent with strong coverage",
+		"Domain 'security' has no agent with strong coverage",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected rolled-up gaps %q, got %q", want, got)
+	}
+	for _, g := range rolled.Gaps {
+		if g.Domain == "web" || g.Domain == "frontend" || g.Domain == "api_design" {
+			t.Errorf("web is covered through its children, got gap %+v", g)
+		}
+	}
+}
+
+func TestResolveDomainThresholds(t *testing.T) {
+	warnings := captureWarnings(t)
+	if got := ResolveDomainThresholds(nil); got != DefaultDomainThresholds {
+		t.Errorf("expected defaults without config, got %+v", got)
+	}
+	got := ResolveDomainThresholds(map[string]any{"thresholds": map[string]any{
+		"strong_domain_score":  0.4,
+		"overlap_domain_score": 0.25,
+	}})
+	if got != (DomainThresholds{Strong: 0.4, Overlap: 0.25}) {
+		t.Errorf("expected configured thresholds, got %+v", got)
+	}
+	got = ResolveDomainThresholds(map[string]any{"thresholds": map[string]any{"strong_domain_score": 0, "overlap_domain_score": 1.5}})
+	if got != DefaultDomainThresholds {
+		t.Errorf("expected out-of-range values to fall back to defaults, got %+v", got)
+	}
+	if !strings.Contains(warnings.String(), "thresholds.strong_domain_score must be above 0") {
+		t.Errorf("expected a warning for the invalid value, got %q", warnings.String())
+	}
+
+	// A zero value behaves like the defaults
+	var zero DomainThresholds
+	if zero.StrongDomain(0.5) || !zero.StrongDomain(0.51) || zero.OverlapDomain(0.3) || !zero.OverlapDomain(0.31) {
+		t.Error("expected zero DomainThresholds to use the default cutoffs")
+	}
+}
+
+func TestOverlapDomainScore(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You build REST endpoints.", ClaimedDomains: []string{"frontend"}},
+		{ID: "b", SystemPrompt: "You design APIs and REST services.", ClaimedDomains: []string{"databases"}},
+	}
+	shared := func(cfg map[string]any) []string {
+		return RunStaticAnalysis(agents, cfg).Overlaps[0].SharedDomains
+	}
+	// backend scores about 0.31 for both, just over the default 0.3
+	if got := shared(nil); len(got) != 1 || got[0] != "backend" {
+		t.Fatalf("expected backend shared at the default overlap cutoff, got %v", got)
+	}
+	cfg := map[string]any{"thresholds": map[string]any{"overlap_domain_score": 0.4}}
+	if got := shared(cfg); len(got) != 0 {
+		t.Errorf("expected no shared domains at 0.4, got %v", got)
+	}
 }
 
 func TestRunStaticAnalysisCustomThresholds(t *testing.T) {
@@ -149,6 +286,64 @@ func TestRunStaticAnalysisCustomThresholds(t *testing.T) {
 	}
 }
 
+func TestAcceptedOverlaps(t *testing.T) {
+	overlaps := []OverlapResult{
+		{AgentA: "backend_us", AgentB: "backend_eu", OverlapScore: 0.8, SharedDomains: []string{"backend"}, Verdict: "warning"},
+		{AgentA: "backend_us", AgentB: "platform", OverlapScore: 0.5, SharedDomains: []string{"backend"}, Verdict: "warning"},
+		{AgentA: "frontend", AgentB: "web", OverlapScore: 0.6, SharedDomains: []string{"frontend"}, Verdict: "warning"},
+	}
+	config := map[string]any{
+		"accepted_overlaps": []any{
+			map[string]any{"agents": []any{"backend_eu", "backend_us"}, "reason": "regional variants"},
+			map[string]any{"agents": []any{"frontend", "web"}, "suppress": true},
+			map[string]any{"agents": []any{"lonely"}},
+		},
+	}
+	warnings := captureWarnings(t)
+	accepted := ResolveAcceptedOverlaps(config)
+	if len(accepted) != 2 {
+		t.Fatalf("expected 2 valid accepted overlaps, got %d: %+v", len(accepted), accepted)
+	}
+	if !strings.Contains(warnings.String(), "accepted_overlaps[2]") {
+		t.Errorf("expected a warning for the one-agent entry, got %q", warnings.String())
+	}
+
+	issues := compileIssues(overlaps, nil, nil, nil, accepted)
+	bySeverity := make(map[string][]Issue)
+	for _, issue := range issues {
+		if issue.Category == "overlap" {
+			bySeverity[issue.Severity] = append(bySeverity[issue.Severity], issue)
+		}
+	}
+
+	if warnings := bySeverity["warning"]; len(warnings) != 1 || warnings[0].Agents[1] != "platform" {
+		t.Errorf("expected only the backend_us/platform overlap as a warning, got %+v", warnings)
+	}
+	infos := bySeverity["info"]
+	if len(infos) != 1 {
+		t.Fatalf("expected the accepted pair downgraded to one info issue, got %+v", infos)
+	}
+	if !strings.Contains(infos[0].Message, "Accepted scope overlap") || !strings.Contains(infos[0].Message, "regional variants") {
+		t.Errorf("info message should mark the overlap accepted with its reason, got %q", infos[0].Message)
+	}
+	for _, issue := range issues {
+		if issue.Agents != nil && issue.Agents[0] == "frontend" {
+			t.Errorf("suppressed overlap should produce no issue, got %+v", issue)
+		}
+	}
+}
+
+func TestAcceptedOverlapConflictStillFails(t *testing.T) {
+	overlaps := []OverlapResult{
+		{AgentA: "a", AgentB: "b", OverlapScore: 0.8, Verdict: "conflict", ConflictingInstructions: []string{"'a' says use 'redis' but 'b' says avoid it"}},
+	}
+	accepted := []AcceptedOverlap{{Agents: [2]string{"a", "b"}, Suppress: true}}
+	issues := compileIssues(overlaps, nil, nil, nil, accepted)
+	if len(issues) != 1 || issues[0].Severity != "error" {
+		t.Errorf("conflicting instructions should stay an error for accepted pairs, got %+v", issues)
+	}
+}
+
 func TestFormatPercent(t *testing.T) {
 	tests := []struct {
 		input float64
@@ -205,6 +400,87 @@ func TestHasWarnings(t *testing.T) {
 	}
 }
 
+func TestWarningsAsErrors(t *testing.T) {
+	issues := []Issue{
+		{Severity: "warning", Category: "overlap"},
+		{Severity: "info", Category: "boundary"},
+	}
+	passes := func(r *StaticReport) bool {
+		return !r.HasFailures() && r.Bands.Status(r.Overall) == "pass"
+	}
+
+	lenient := &StaticReport{Issues: issues, Overall: overallScore(issues, false, nil)}
+	if !passes(lenient) {
+		t.Errorf("expected warnings-only report to pass by default (overall %.2f)", lenient.Overall)
+	}
+
+	strict := &StaticReport{Issues: issues, Overall: overallScore(issues, true, nil), WarningsAsErrors: true}
+	if passes(strict) {
+		t.Error("expected warnings-only report to fail with warnings as errors")
+	}
+	if strict.Overall != 0.8 {
+		t.Errorf("expected a warning to cost 0.2 as an error, got overall %.2f", strict.Overall)
+	}
+}
+
+func TestHasFailuresFailOn(t *testing.T) {
+	mixed := []Issue{
+		{Severity: "warning", Category: "overlap"},
+		{Severity: "info", Category: "boundary"},
+	}
+	tests := []struct {
+		failOn   string
+		issues   []Issue
+		failures int
+	}{
+		{"", mixed, 0},
+		{"error", mixed, 0},
+		{"warning", mixed, 1},
+		{"info", mixed, 2},
+		{"error", append(mixed, Issue{Severity: "error", Category: "conflict"}), 1},
+		{"warning", mixed[1:], 0},
+	}
+	for _, tt := range tests {
+		report := &StaticReport{Issues: tt.issues, FailOn: tt.failOn}
+		if got := len(report.Failures()); got != tt.failures || report.HasFailures() != (tt.failures > 0) {
+			t.Errorf("fail_on %q over %d issues: %d failures, want %d", tt.failOn, len(tt.issues), got, tt.failures)
+		}
+	}
+}
+
+func TestResolveFailOn(t *testing.T) {
+	for _, level := range []string{"error", "warning", "info"} {
+		cfg := map[string]any{"thresholds": map[string]any{"fail_on": level}}
+		if got, err := ResolveFailOn(cfg); err != nil || got != level {
+			t.Errorf("fail_on %q resolved to %q, %v", level, got, err)
+		}
+		if report := RunStaticAnalysis(nil, cfg); report.FailOn != level {
+			t.Errorf("expected the report to record fail_on %q, got %q", level, report.FailOn)
+		}
+	}
+	if got, err := ResolveFailOn(nil); err != nil || got != DefaultFailOn {
+		t.Errorf("unset fail_on resolved to %q, %v; want %q", got, err, DefaultFailOn)
+	}
+	if _, err := ResolveFailOn(map[string]any{"thresholds": map[string]any{"fail_on": "critical"}}); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestRunStaticAnalysisWarningsAsErrorsConfig(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You handle backend APIs."},
+	}
+	cfg := map[string]any{"thresholds": map[string]any{"warnings_as_errors": true}}
+	report := RunStaticAnalysis(agents, cfg)
+
+	if !report.WarningsAsErrors {
+		t.Fatal("expected thresholds.warnings_as_errors to set WarningsAsErrors")
+	}
+	if report.HasWarnings() && !report.HasFailures() {
+		t.Error("expected warnings to count as failures")
+	}
+}
+
 func TestOverallScoreCalculation(t *testing.T) {
 	// No issues → 1.0
 	agents := []loader.AgentDefinition{
@@ -219,6 +495,62 @@ func TestOverallScoreCalculation(t *testing.T) {
 	}
 }
 
+func TestResolveAgentWeights(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "core", Metadata: map[string]any{"tier": "Critical"}},
+		{ID: "beta", Metadata: map[string]any{"tier": "experimental"}},
+		{ID: "plain"},
+		{ID: "pinned", Metadata: map[string]any{"tier": "low"}},
+		{ID: "custom", Metadata: map[string]any{"tier": "gold"}},
+	}
+	cfg := map[string]any{
+		"agents":  map[string]any{"pinned": map[string]any{"weight": 3}},
+		"scoring": map[string]any{"tier_weights": map[string]any{"gold": 1.5}},
+	}
+	weights := ResolveAgentWeights(agents, cfg)
+	want := map[string]float64{"core": 4, "beta": 0.25, "plain": 1, "pinned": 3, "custom": 1.5}
+	for id, w := range want {
+		if weights[id] != w {
+			t.Errorf("weight of %s = %v, want %v", id, weights[id], w)
+		}
+	}
+
+	normalized := normalizeWeights(map[string]float64{"a": 1, "b": 1, "c": 1})
+	for id, w := range normalized {
+		if w != 1 {
+			t.Errorf("equal weights should normalize to 1, got %s = %v", id, w)
+		}
+	}
+}
+
+func TestWeightedOverallScore(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "checkout", Metadata: map[string]any{"tier": "critical"}},
+		{ID: "sandbox", Metadata: map[string]any{"tier": "experimental"}},
+		{ID: "docs"},
+	}
+	weights := normalizeWeights(ResolveAgentWeights(agents, nil))
+	failing := func(id string) []Issue {
+		return []Issue{{Severity: "error", Category: "uncertainty", Agents: []string{id}}}
+	}
+
+	unweighted := overallScore(failing("docs"), false, nil)
+	heavy := overallScore(failing("checkout"), false, weights)
+	light := overallScore(failing("sandbox"), false, weights)
+	if heavy >= light {
+		t.Errorf("a failure on a high-weight agent should cost more: heavy %.3f, light %.3f", heavy, light)
+	}
+	if heavy >= unweighted || light <= unweighted {
+		t.Errorf("expected weighting around the unweighted score %.3f: heavy %.3f, light %.3f", unweighted, heavy, light)
+	}
+
+	// A fleet-wide issue costs the same regardless of weights.
+	gap := []Issue{{Severity: "error", Category: "gap"}}
+	if got := overallScore(gap, false, weights); got != overallScore(gap, false, nil) {
+		t.Errorf("issue without agents should be unweighted, got %.3f", got)
+	}
+}
+
 func TestDomainSummaryBuiltinOnly(t *testing.T) {
 	agents := []loader.AgentDefinition{
 		{ID: "a", SystemPrompt: "You handle backend APIs."},
@@ -250,3 +582,201 @@ func TestDomainSummaryMixed(t *testing.T) {
 		t.Errorf("expected '2 built-in + 1 custom domains', got %q", report.DomainSummary)
 	}
 }
+
+func TestWarnUnusedDomains(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You handle payments via Stripe."},
+	}
+	domains := []any{
+		"backend",
+		map[string]any{
+			"name":     "payments",
+			"keywords": []any{"stripe"},
+		},
+		map[string]any{
+			"name":     "logistics",
+			"keywords": []any{"shipmnet", "warehousing"}, // typo never fires
+		},
+	}
+
+	report := RunStaticAnalysis(agents, map[string]any{
+		"domains": domains,
+		"checks":  map[string]any{"warn_unused_domains": true},
+	})
+
+	var unused []Issue
+	for _, issue := range report.Issues {
+		if issue.Category == "unused_domain" {
+			unused = append(unused, issue)
+		}
+	}
+	if len(unused) != 1 {
+		t.Fatalf("expected 1 unused_domain issue, got %d: %+v", len(unused), unused)
+	}
+	if unused[0].Severity != "info" {
+		t.Errorf("expected info severity, got %q", unused[0].Severity)
+	}
+	if !strings.Contains(unused[0].Message, "logistics") {
+		t.Errorf("expected message to name 'logistics', got %q", unused[0].Message)
+	}
+
+	// Off by default
+	report = RunStaticAnalysis(agents, map[string]any{"domains": domains})
+	for _, issue := range report.Issues {
+		if issue.Category == "unused_domain" {
+			t.Errorf("unexpected unused_domain issue without the check enabled: %s", issue.Message)
+		}
+	}
+}
+
+func TestRunStaticAnalysisTimings(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You handle backend APIs."},
+		{ID: "b", SystemPrompt: "You handle React frontends."},
+	}
+	report := RunStaticAnalysis(agents, nil)
+
+	want := []string{"domain extraction", "overlap", "gaps", "agent scoring", "issues"}
+	if len(report.Timings) != len(want) {
+		t.Fatalf("expected %d phase timings, got %+v", len(want), report.Timings)
+	}
+	for i, name := range want {
+		if report.Timings[i].Name != name {
+			t.Errorf("phase %d: expected %q, got %q", i, name, report.Timings[i].Name)
+		}
+	}
+}
+
+func TestRunStaticAnalysisLeavesRunUnset(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "api", SystemPrompt: "You build REST APIs with Go and PostgreSQL."}}
+	if run := RunStaticAnalysis(agents, nil).Run; run != (RunInfo{}) {
+		t.Errorf("analysis should not stamp a run, got %+v", run)
+	}
+}
+
+func TestResolveScoreBands(t *testing.T) {
+	if b := ResolveScoreBands(nil); b != DefaultScoreBands {
+		t.Errorf("expected default bands for nil config, got %+v", b)
+	}
+
+	b := ResolveScoreBands(map[string]any{
+		"thresholds": map[string]any{"min_overall_score": 0.85},
+	})
+	if b.Pass != 0.85 {
+		t.Errorf("expected pass 0.85, got %.2f", b.Pass)
+	}
+	if b.Warn < 0.649 || b.Warn > 0.651 {
+		t.Errorf("expected warn band 0.2 below pass (0.65), got %.2f", b.Warn)
+	}
+	if got := b.Status(0.8); got != "warn" {
+		t.Errorf("Status(0.8) = %q, want warn", got)
+	}
+
+	zero := ResolveScoreBands(map[string]any{
+		"thresholds": map[string]any{"min_overall_score": 0.0, "warn_overall_score": 0.0},
+	})
+	if zero != (ScoreBands{}) {
+		t.Errorf("expected configured zero bands to be kept, got %+v", zero)
+	}
+	if got := zero.Status(0.3); got != "pass" {
+		t.Errorf("zero bands: Status(0.3) = %q, want pass", got)
+	}
+}
+
+func TestRunStaticAnalysisSkipsReferenceOnlyAgents(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend", SystemPrompt: "You build REST APIs with Go and PostgreSQL."},
+		{ID: "orphan", SystemPrompt: "!include shared/missing.md", PromptReference: "shared/missing.md", ReferenceOnly: true},
+	}
+
+	report := RunStaticAnalysis(agents, nil)
+
+	if len(report.Agents) != 1 || report.Agents[0].ID != "backend" {
+		t.Errorf("expected only backend to be analyzed, got %+v", report.Agents)
+	}
+	if _, ok := report.AgentScores["orphan"]; ok {
+		t.Error("reference-only agent should not be scored")
+	}
+
+	var flagged bool
+	for _, issue := range report.Issues {
+		if issue.Category == "reference_only" && len(issue.Agents) == 1 && issue.Agents[0] == "orphan" {
+			flagged = true
+			if issue.Severity != "warning" {
+				t.Errorf("severity = %q, want warning", issue.Severity)
+			}
+		}
+	}
+	if !flagged {
+		t.Errorf("expected a reference_only issue for orphan, got %+v", report.Issues)
+	}
+}
+
+func TestRunStaticAnalysisUnspecializedAgent(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "assistant", SystemPrompt: "You are a helpful assistant."},
+	}
+	report := RunStaticAnalysis(agents, nil)
+
+	if !report.AgentScores["assistant"].Unspecialized {
+		t.Fatal("expected bare generic assistant to be classified unspecialized")
+	}
+	found := false
+	for _, i := range report.Issues {
+		if i.Category == "unspecialized" {
+			found = true
+			if i.Severity != "warning" || len(i.Agents) != 1 || i.Agents[0] != "assistant" {
+				t.Errorf("unexpected unspecialized issue: %+v", i)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected an unspecialized warning")
+	}
+}
+
+func TestStaticAnalyzerStreamingMatchesBatch(t *testing.T) {
+	dir := filepath.Join("..", "loader", "testdata", "recursive")
+	cfg := map[string]any{"checks": map[string]any{"warn_unused_domains": true}}
+
+	agents, err := loader.LoadAgentsRecursive(dir, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	batch := RunStaticAnalysis(agents, cfg)
+
+	analyzer := NewStaticAnalyzer(cfg)
+	stream, errc := loader.StreamAgentsRecursive(dir)
+	var streamed []loader.AgentDefinition
+	for agent := range stream {
+		analyzer.Add(&agent)
+		streamed = append(streamed, agent)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+	incremental := analyzer.Report(loader.FinalizeRecursive(streamed, true))
+
+	if !reflect.DeepEqual(incremental.Agents, batch.Agents) {
+		t.Error("Agents differ between streaming and batch analysis")
+	}
+	if !reflect.DeepEqual(incremental.DomainMap, batch.DomainMap) {
+		t.Errorf("DomainMap differs:\n got %v\nwant %v", incremental.DomainMap, batch.DomainMap)
+	}
+	if !reflect.DeepEqual(incremental.Overlaps, batch.Overlaps) {
+		t.Errorf("Overlaps differ:\n got %+v\nwant %+v", incremental.Overlaps, batch.Overlaps)
+	}
+	if !reflect.DeepEqual(incremental.Gaps, batch.Gaps) {
+		t.Errorf("Gaps differ:\n got %+v\nwant %+v", incremental.Gaps, batch.Gaps)
+	}
+	if !reflect.DeepEqual(incremental.AgentScores, batch.AgentScores) {
+		t.Errorf("AgentScores differ:\n got %+v\nwant %+v", incremental.AgentScores, batch.AgentScores)
+	}
+	if !reflect.DeepEqual(incremental.Issues, batch.Issues) {
+		t.Errorf("Issues differ:\n got %+v\nwant %+v", incremental.Issues, batch.Issues)
+	}
+	if incremental.Overall != batch.Overall || incremental.DomainSummary != batch.DomainSummary {
+		t.Errorf("Overall/DomainSummary = %v/%q, want %v/%q",
+			incremental.Overall, incremental.DomainSummary, batch.Overall, batch.DomainSummary)
+	}
+}
@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// DefaultTierWeights maps the tier an agent declares in its definition
+// ("tier: critical") to its weight in the overall score. scoring.tier_weights
+// in config adds tiers or changes these weights.
+var DefaultTierWeights = map[string]float64{
+	"critical":     4,
+	"high":         2,
+	"standard":     1,
+	"low":          0.5,
+	"experimental": 0.25,
+}
+
+// ResolveAgentWeights returns each agent's weight in the overall score:
+// agents.<id>.weight from config, else the weight of the tier in the
+// agent's metadata, else 1. Weights are relative; see normalizeWeights.
+func ResolveAgentWeights(agents []loader.AgentDefinition, config map[string]any) map[string]float64 {
+	tiers := make(map[string]float64, len(DefaultTierWeights))
+	for tier, w := range DefaultTierWeights {
+		tiers[tier] = w
+	}
+	configuredTiers := GetMap(GetMap(config, "scoring"), "tier_weights")
+	for tier := range configuredTiers {
+		if w := GetFloat(configuredTiers, tier, 0); w > 0 {
+			tiers[strings.ToLower(tier)] = w
+		} else {
+			warnOnce("Warning: scoring.tier_weights.%s: want a positive number, got %v\n", tier, configuredTiers[tier])
+		}
+	}
+
+	configured := GetMap(config, "agents")
+	weights := make(map[string]float64, len(agents))
+	for _, a := range agents {
+		weight := 1.0
+		if tier, ok := a.Metadata["tier"]; ok {
+			name := strings.ToLower(strings.TrimSpace(fmt.Sprint(tier)))
+			if w, ok := tiers[name]; ok {
+				weight = w
+			} else {
+				warnOnce("Warning: agent %q has unknown tier %q, weighting it 1\n", a.ID, name)
+			}
+		}
+		if w := GetFloat(GetMap(configured, a.ID), "weight", 0); w > 0 {
+			weight = w
+		}
+		weights[a.ID] = weight
+	}
+	return weights
+}
+
+// normalizeWeights scales weights so that they average 1 across the fleet.
+// A fleet of equal weights, the default, scores exactly as unweighted, and
+// weighting shifts how much each agent's issues cost without changing the
+// total a fleet-wide problem costs.
+func normalizeWeights(weights map[string]float64) map[string]float64 {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	normalized := make(map[string]float64, len(weights))
+	if sum == 0 {
+		return normalized
+	}
+	for id, w := range weights {
+		normalized[id] = w * float64(len(weights)) / sum
+	}
+	return normalized
+}
+
+// issueWeight is the factor an issue's cost in the overall score is
+// multiplied by: the mean normalized weight of the agents it names, or 1
+// for issues about the fleet as a whole, such as coverage gaps.
+func issueWeight(issue Issue, weights map[string]float64) float64 {
+	var sum float64
+	n := 0
+	for _, id := range issue.Agents {
+		if w, ok := weights[id]; ok {
+			sum += w
+			n++
+		}
+	}
+	if n == 0 {
+		return 1
+	}
+	return sum / float64(n)
+}
@@ -9,19 +9,27 @@ import (
 
 // Load loads configuration from a file path or discovers it alongside agents.
 func Load(configPath, agentsPath string) (map[string]any, error) {
+	if path := Resolve(configPath, agentsPath); path != "" {
+		return loadFile(path)
+	}
+	return make(map[string]any), nil
+}
+
+// Resolve returns the config file Load reads: configPath when set, otherwise
+// an agent-evals.yaml (or .yml) alongside the agents, or "" when there is none.
+func Resolve(configPath, agentsPath string) string {
 	if configPath != "" {
-		return loadFile(configPath)
+		return configPath
 	}
 
 	// Auto-discover alongside agent definitions
-	for _, name := range []string{"agent-evals.yaml", "agent-evals.yml"} {
+	for _, name := range []string{FileName, "agent-evals.yml"} {
 		candidate := filepath.Join(agentsPath, name)
 		if _, err := os.Stat(candidate); err == nil {
-			return loadFile(candidate)
+			return candidate
 		}
 	}
-
-	return make(map[string]any), nil
+	return ""
 }
 
 func loadFile(path string) (map[string]any, error) {
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileName is the config file Load discovers alongside agent definitions.
+const FileName = "agent-evals.yaml"
+
+// extendedDomain is the built-in domain the template extends as an example.
+const extendedDomain = "backend"
+
+// Template returns a commented agent-evals.yaml covering the domains,
+// thresholds and live probe provider settings. builtinDomains are the
+// built-in domain names; all of them are listed, since a domains list
+// replaces the built-in set. model is the provider's default model.
+func Template(builtinDomains []string, model string) string {
+	var b strings.Builder
+	b.WriteString(`# agent-evals configuration. Every key is optional, and the thresholds and
+# probe settings below are the defaults; delete what you don't need.
+# agent-evals check and test pick this file up when it sits alongside the
+# agent definitions, or pass --config to name it.
+
+# Domains that agent prompts are matched against. Listing domains replaces
+# the built-in set, so every built-in domain is listed: remove the ones
+# your fleet doesn't cover.
+domains:
+`)
+	for _, d := range builtinDomains {
+		if d != extendedDomain {
+			fmt.Fprintf(&b, "  - %s\n", d)
+		}
+	}
+	fmt.Fprintf(&b, `  # A built-in domain with extra keywords of your own
+  - name: %s
+    extends: builtin
+    keywords: [axum, actix-web, tokio]
+  # A fully custom domain
+  # - name: payments
+  #   keywords: [payment gateway, stripe, refund, chargeback]
+
+thresholds:
+  min_overall_score: 0.7    # overall score needed to pass
+  max_overlap_score: 0.3    # agent pairs overlapping more than this are flagged
+  min_boundary_score: 0.5   # live boundary score each agent needs (test only)
+
+# Provider for live probes (agent-evals test). Command-line flags win.
+probes:
+  provider: anthropic       # anthropic, openai, gemini, bedrock, openai-compatible
+  model: %s
+  api_key_env: ANTHROPIC_API_KEY
+`, extendedDomain, model)
+	return b.String()
+}
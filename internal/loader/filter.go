@@ -0,0 +1,120 @@
+package loader
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LoadOptions selects which files LoadAgentsFiltered loads. Include and
+// Exclude are glob patterns matched against each file's path relative to the
+// loaded directory, with forward slashes: "*" and "?" match within one path
+// segment and "**" matches any number of segments, so "**/vendor/**" matches
+// everything under any vendor directory. A file is loaded when it matches an
+// Include pattern, or Include is empty, and matches no Exclude pattern;
+// directories matching an Exclude pattern are not descended into. A
+// directory agent is matched by its directory's path.
+type LoadOptions struct {
+	Include []string
+	Exclude []string
+
+	// Recursive walks the whole tree, as LoadAgentsRecursive does, rather
+	// than only the directory's direct entries.
+	Recursive bool
+	// Dedup collapses agents with identical system prompts when Recursive
+	// is set.
+	Dedup bool
+	// RespectGitignore also skips paths ignored by .gitignore files: those
+	// of the loaded directory and the directories walked below it, and of
+	// its ancestors up to the enclosing repository's root. Negated patterns
+	// re-include paths as in git.
+	RespectGitignore bool
+	// Stats, if set, receives counts from the load, for callers that report
+	// how many files it scanned.
+	Stats *LoadStats
+}
+
+// LoadStats counts what a load read.
+type LoadStats struct {
+	// Files is the number of files and directory agents that agent
+	// definitions were read from. A fleet file defining several agents
+	// counts once, and each copy of a duplicated agent counts.
+	Files int
+}
+
+// countFile records one file read in o.Stats, if set.
+func (o LoadOptions) countFile() {
+	if o.Stats != nil {
+		o.Stats.Files++
+	}
+}
+
+// validate reports the first malformed pattern.
+func (o LoadOptions) validate() error {
+	for _, patterns := range [][]string{o.Include, o.Exclude} {
+		for _, p := range patterns {
+			for _, seg := range strings.Split(p, "/") {
+				if _, err := path.Match(seg, ""); err != nil {
+					return fmt.Errorf("invalid load pattern %q: %w", p, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// excluded reports whether rel, a path relative to the loaded directory,
+// matches an Exclude pattern.
+func (o LoadOptions) excluded(rel string) bool {
+	return matchAny(o.Exclude, rel)
+}
+
+// selected reports whether the file or directory agent at rel is loaded.
+func (o LoadOptions) selected(rel string) bool {
+	if o.excluded(rel) {
+		return false
+	}
+	return len(o.Include) == 0 || matchAny(o.Include, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, p := range patterns {
+		if matchGlob(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches name against a slash-separated pattern in which a "**"
+// segment matches zero or more whole segments and every other segment
+// follows path.Match.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for len(pattern) > 1 && pattern[1] == "**" {
+				pattern = pattern[1:]
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
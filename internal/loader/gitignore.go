@@ -0,0 +1,105 @@
+package loader
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitignore holds the rules of the .gitignore files that apply to a walk,
+// outermost first, so that a later matching rule overrides an earlier one as
+// in git.
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is one pattern line of a .gitignore file.
+type ignoreRule struct {
+	base    string // absolute directory holding the .gitignore
+	pattern string // glob matched against the path relative to base
+	negate  bool   // "!pattern" re-includes a path
+	dirOnly bool   // "pattern/" matches only directories
+}
+
+// newGitignore returns the rules of the .gitignore files in the ancestors of
+// absRoot, up to the enclosing repository's root, and in absRoot itself.
+// Rules of directories below absRoot are added with addDir as they are
+// walked. Without an enclosing repository only absRoot's own .gitignore
+// applies.
+func newGitignore(absRoot string) *gitignore {
+	g := &gitignore{}
+	var dirs []string
+	for dir := absRoot; ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			dirs = dirs[:1]
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		g.addDir(dirs[i])
+	}
+	return g
+}
+
+// addDir adds the rules of dir/.gitignore, if there is one.
+func (g *gitignore) addDir(dir string) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: dir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // escaped leading "#" or "!"
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		// A pattern with a slash other than a trailing one is relative to
+		// the .gitignore's directory; any other pattern matches at any depth.
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		g.rules = append(g.rules, rule)
+	}
+}
+
+// ignored reports whether the file or directory at absPath is ignored.
+func (g *gitignore) ignored(absPath string, isDir bool) bool {
+	ignored := false
+	for _, r := range g.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(r.base, absPath)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if matchGlob(r.pattern, filepath.ToSlash(rel)) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -1,14 +1,19 @@
 package loader
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -18,14 +23,45 @@ import (
 type AgentDefinition struct {
 	ID             string
 	Name           string
+	Description    string // short summary from a description or summary field; see Summary
 	SourcePath     string
 	SystemPrompt   string
 	Skills         []string
 	Rules          []string
 	ClaimedDomains []string
+	OutOfScope     []string // domains the agent declares it must not handle
 	Metadata       map[string]any
-	ContentHash    string   // SHA-256 hex of SystemPrompt
-	AlsoFoundIn    []string // other source paths with identical content (populated by dedup)
+	ContentHash    string             // SHA-256 hex of SystemPrompt
+	AlsoFoundIn    []string           // other source paths with identical content (populated by dedup)
+	SkillDomains   map[string]float64 // domains inferred from skills/rules, with confidence (populated by analysis.MergeSkillClaims)
+
+	// PromptReference is the file named by a reference-only prompt such as
+	// "See shared/base-prompt.md" or "!include base.md". When the file could
+	// be read, SystemPrompt holds its content; otherwise ReferenceOnly is set
+	// and the agent has no analyzable prompt.
+	PromptReference string
+	ReferenceOnly   bool
+}
+
+// EffectiveDomains returns the explicitly claimed domains followed by any
+// domains inferred from skills and rules that are not already claimed.
+func (a *AgentDefinition) EffectiveDomains() []string {
+	if len(a.SkillDomains) == 0 {
+		return a.ClaimedDomains
+	}
+	domains := append([]string(nil), a.ClaimedDomains...)
+	claimed := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		claimed[d] = true
+	}
+	inferred := make([]string, 0, len(a.SkillDomains))
+	for d := range a.SkillDomains {
+		if !claimed[d] {
+			inferred = append(inferred, d)
+		}
+	}
+	sort.Strings(inferred)
+	return append(domains, inferred...)
 }
 
 // FullContext returns the complete text that defines this agent's behavior.
@@ -52,24 +88,65 @@ func (a *AgentDefinition) WordCount() int {
 	return len(strings.Fields(a.FullContext()))
 }
 
+// maxSummaryLength caps a summary taken from the system prompt, in runes.
+const maxSummaryLength = 120
+
+// sentenceEnd matches the end of a prompt's first sentence.
+var sentenceEnd = regexp.MustCompile(`[.!?](\s|$)`)
+
+// Summary returns the agent's Description, or when it has none the first
+// sentence of its system prompt, skipping markdown headings and cut to
+// maxSummaryLength.
+func (a *AgentDefinition) Summary() string {
+	if d := strings.TrimSpace(a.Description); d != "" {
+		return strings.Join(strings.Fields(d), " ")
+	}
+	var text []string
+	for _, line := range strings.Split(a.SystemPrompt, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" && len(text) > 0 {
+			break // a sentence does not run past its paragraph
+		}
+		if line != "" && !strings.HasPrefix(line, "#") {
+			text = append(text, line)
+		}
+	}
+	summary := strings.Join(strings.Fields(strings.Join(text, " ")), " ")
+	if loc := sentenceEnd.FindStringIndex(summary); loc != nil {
+		summary = summary[:loc[0]+1]
+	}
+	if runes := []rune(summary); len(runes) > maxSummaryLength {
+		summary = strings.TrimSpace(string(runes[:maxSummaryLength-1])) + "…"
+	}
+	return summary
+}
+
 // LoadAgents loads all agent definitions from a path.
 // If path is a file, loads that single agent.
 // If path is a directory, recursively finds agent definitions.
 func LoadAgents(path string) ([]AgentDefinition, error) {
+	return LoadAgentsFiltered(path, LoadOptions{})
+}
+
+// LoadAgentsFiltered loads agent definitions from path like LoadAgents, or
+// like LoadAgentsRecursive when opts.Recursive is set, keeping only the files
+// selected by opts.Include and opts.Exclude. A path naming a single file is
+// loaded regardless of the patterns.
+func LoadAgentsFiltered(path string, opts LoadOptions) ([]AgentDefinition, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Recursive {
+		return loadRecursive(path, opts)
+	}
+
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("agent path not found: %s", path)
 	}
 
 	if !info.IsDir() {
-		agent, err := loadSingleFile(path)
-		if err != nil {
-			return nil, err
-		}
-		if agent == nil {
-			return nil, nil
-		}
-		return []AgentDefinition{*agent}, nil
+		return loadCountedFile(path, opts)
 	}
 
 	var agents []AgentDefinition
@@ -78,10 +155,17 @@ func LoadAgents(path string) ([]AgentDefinition, error) {
 	if err != nil {
 		return nil, err
 	}
+	ignored := func(string, bool) bool { return false }
+	if opts.RespectGitignore {
+		if absPath, err := filepath.Abs(path); err == nil {
+			g := newGitignore(absPath)
+			ignored = func(name string, isDir bool) bool { return g.ignored(filepath.Join(absPath, name), isDir) }
+		}
+	}
 
 	// First pass: directory-based agents
 	for _, entry := range entries {
-		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || !opts.selected(entry.Name()) || ignored(entry.Name(), true) {
 			continue
 		}
 		agent, err := tryLoadDirectoryAgent(filepath.Join(path, entry.Name()))
@@ -90,6 +174,7 @@ func LoadAgents(path string) ([]AgentDefinition, error) {
 			continue
 		}
 		if agent != nil {
+			opts.countFile()
 			agents = append(agents, *agent)
 		}
 	}
@@ -100,98 +185,311 @@ func LoadAgents(path string) ([]AgentDefinition, error) {
 			continue
 		}
 		name := entry.Name()
-		if name == "agent-evals.yaml" || name == "agent-evals.yml" {
+		if name == "agent-evals.yaml" || name == "agent-evals.yml" || !opts.selected(name) || ignored(name, false) {
 			continue
 		}
-		agent, err := loadSingleFile(filepath.Join(path, name))
+		loaded, err := loadCountedFile(filepath.Join(path, name), opts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: skipped %s: %v\n", filepath.Join(path, name), err)
 			continue
 		}
-		if agent != nil {
-			agents = append(agents, *agent)
-		}
+		agents = append(agents, loaded...)
 	}
 
 	return agents, nil
 }
 
-func loadSingleFile(path string) (*AgentDefinition, error) {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
+// loadCountedFile is loadSingleFile counting the file in opts.Stats when it
+// defines agents.
+func loadCountedFile(path string, opts LoadOptions) ([]AgentDefinition, error) {
+	agents, err := loadSingleFile(path)
+	if err == nil && len(agents) > 0 {
+		opts.countFile()
+	}
+	return agents, err
+}
+
+// loadSingleFile loads the agents defined in one file: one agent for most
+// formats, or several from a YAML or JSON fleet file.
+func loadSingleFile(path string) ([]AgentDefinition, error) {
+	var agents []AgentDefinition
+	var agent *AgentDefinition
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
 	case ".yaml", ".yml":
-		return loadYAML(path)
+		agents, err = loadYAML(path)
 	case ".json":
-		return loadJSON(path)
+		agents, err = loadJSON(path)
 	case ".md", ".txt":
-		return loadText(path)
+		agent, err = loadText(path)
 	}
-	return nil, nil
+	if agent != nil {
+		agents = append(agents, *agent)
+	}
+	for i := range agents {
+		resolvePromptReference(&agents[i], path)
+	}
+	return agents, err
+}
+
+// referencePattern matches a prompt that only points at another file:
+// "See shared/base-prompt.md", "!include base.md", "@rules/common.md", ...
+var referencePattern = regexp.MustCompile("(?i)^(?:(?:!include|#include|@include|include|see|refer to|inherits?(?: from)?):?\\s+|@)[\"'`]?([^\\s\"'`]+\\.(?:md|markdown|txt|ya?ml))[\"'`]?\\.?$")
+
+// maxReferenceWords bounds how long a prompt can be and still count as a
+// bare reference rather than real instructions that mention a file.
+const maxReferenceWords = 8
+
+// maxReferenceDepth bounds how many references are followed in a chain,
+// which also stops reference cycles.
+const maxReferenceDepth = 5
+
+// referenceTarget returns the file a reference-only prompt points at, or ""
+// if the prompt is not a bare reference.
+func referenceTarget(prompt string) string {
+	prompt = strings.TrimSpace(prompt)
+	if strings.Contains(prompt, "\n") || len(strings.Fields(prompt)) > maxReferenceWords {
+		return ""
+	}
+	if m := referencePattern.FindStringSubmatch(prompt); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// resolvePromptReference replaces a reference-only system prompt with the
+// content of the file it names, relative to the agent file. If the file
+// cannot be read the agent is marked ReferenceOnly instead.
+func resolvePromptReference(agent *AgentDefinition, path string) {
+	dir := filepath.Dir(path)
+	for depth := 0; depth < maxReferenceDepth; depth++ {
+		target := referenceTarget(agent.SystemPrompt)
+		if target == "" {
+			return
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		agent.PromptReference = target
+
+		content, err := readPromptFile(target)
+		if err != nil || content == "" {
+			agent.ReferenceOnly = true
+			return
+		}
+		agent.SystemPrompt = content
+		dir = filepath.Dir(target)
+	}
+	agent.ReferenceOnly = referenceTarget(agent.SystemPrompt) != ""
 }
 
-func loadYAML(path string) (*AgentDefinition, error) {
+// readPromptFile reads a referenced prompt file, dropping any YAML
+// frontmatter.
+func readPromptFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	content := strings.TrimSpace(string(data))
+	if strings.HasPrefix(content, "---") {
+		if parts := strings.SplitN(content, "---", 3); len(parts) == 3 {
+			content = strings.TrimSpace(parts[2])
+		}
+	}
+	return content, nil
+}
+
+// loadYAML loads one agent from a YAML file, or several from a fleet file:
+// a document whose top-level agents list holds the agents, or a stream of
+// documents separated by "---". Agents of a fleet file are named like
+// the entries of a JSON fleet manifest (see loadJSON).
+func loadYAML(path string) ([]AgentDefinition, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var raw map[string]any
-	if err := yaml.Unmarshal(data, &raw); err != nil {
-		return nil, nil
+	var docs []any
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var raw map[string]any
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil
+		}
+		if raw != nil {
+			docs = append(docs, raw)
+		}
 	}
-	if raw == nil {
+
+	stem := filenameStem(path)
+	switch {
+	case len(docs) == 0:
 		return nil, nil
+	case len(docs) > 1:
+		return agentsFromList(docs, stem, path, agentFromYAML), nil
 	}
+	raw := docs[0].(map[string]any)
+	if list, ok := manifestAgents(raw); ok {
+		return agentsFromList(list, stem, path, agentFromYAML), nil
+	}
+	if agent := agentFromYAML(raw, stem, path); agent != nil {
+		return []AgentDefinition{*agent}, nil
+	}
+	return nil, nil
+}
 
+// agentFromYAML builds an agent from a decoded YAML document, or returns
+// nil if it has no prompt. stem is the default ID and the source of the
+// default name.
+func agentFromYAML(raw map[string]any, stem, sourcePath string) *AgentDefinition {
 	systemPrompt := firstString(raw, "system_prompt", "instructions", "prompt", "content")
 	if systemPrompt == "" {
-		return nil, nil
+		return nil
 	}
 
-	stem := filenameStem(path)
-
 	return &AgentDefinition{
 		ID:             coalesce(getString(raw, "id"), stem),
 		Name:           coalesce(getString(raw, "name"), nameFromStem(stem)),
-		SourcePath:     path,
+		Description:    firstString(raw, "description", "summary"),
+		SourcePath:     sourcePath,
 		SystemPrompt:   systemPrompt,
 		Skills:         getStringSlice(raw, "skills", "domain_tags"),
 		Rules:          getStringSlice(raw, "rules"),
 		ClaimedDomains: getStringSlice(raw, "domains", "domain_tags"),
-		Metadata:       filterKeys(raw, "system_prompt", "instructions", "prompt", "content", "name", "id", "skills", "rules", "domains", "domain_tags"),
-	}, nil
+		OutOfScope:     getStringSlice(raw, "out_of_scope"),
+		Metadata:       filterKeys(raw, "system_prompt", "instructions", "prompt", "content", "name", "description", "summary", "id", "skills", "rules", "domains", "domain_tags", "out_of_scope"),
+	}
+}
+
+// manifestAgents returns the entries of a fleet file's top-level agents
+// list. A document with a prompt of its own is an agent, not a manifest,
+// and an agents map (as in agent-evals.yaml) is not a list of agents.
+func manifestAgents(raw map[string]any) ([]any, bool) {
+	if firstString(raw, "system_prompt", "instructions", "prompt", "content") != "" {
+		return nil, false
+	}
+	list, ok := raw["agents"].([]any)
+	return list, ok
+}
+
+// agentsFromList builds the agents of a fleet file from its entries with
+// build, skipping entries that are not objects or have no prompt. Entry i
+// gets SourcePath "file[i]" and without an id the ID "<stem>_<i>".
+func agentsFromList(items []any, stem, path string, build func(raw map[string]any, stem, sourcePath string) *AgentDefinition) []AgentDefinition {
+	var agents []AgentDefinition
+	for i, item := range items {
+		raw, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		entryStem := coalesce(getString(raw, "id"), fmt.Sprintf("%s_%d", stem, i))
+		if agent := build(raw, entryStem, fmt.Sprintf("%s[%d]", path, i)); agent != nil {
+			agents = append(agents, *agent)
+		}
+	}
+	return agents
 }
 
-func loadJSON(path string) (*AgentDefinition, error) {
+// loadJSON loads one agent from a JSON object, or a fleet manifest of
+// agents from a JSON array or an object with a top-level agents array.
+// Manifest entries get SourcePath "file[index]", and without an id default
+// to the ID "<stem>_<index>"; their default name comes from the ID rather
+// than the file name.
+func loadJSON(path string) ([]AgentDefinition, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var raw map[string]any
-	if err := json.Unmarshal(data, &raw); err != nil {
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
 		return nil, nil
 	}
-	if raw == nil {
-		return nil, nil
+
+	stem := filenameStem(path)
+	switch v := root.(type) {
+	case map[string]any:
+		if list, ok := manifestAgents(v); ok {
+			return agentsFromList(list, stem, path, agentFromJSON), nil
+		}
+		if agent := agentFromJSON(v, stem, path); agent != nil {
+			return []AgentDefinition{*agent}, nil
+		}
+	case []any:
+		return agentsFromList(v, stem, path, agentFromJSON), nil
 	}
+	return nil, nil
+}
 
+// agentFromJSON builds an agent from a decoded JSON object, or returns nil
+// if it has no prompt. stem is the default ID and the source of the default
+// name. OpenAI Assistant objects are recognized too: their tools become
+// skills, and their model and temperature are kept as metadata.
+func agentFromJSON(raw map[string]any, stem, sourcePath string) *AgentDefinition {
 	systemPrompt := firstString(raw, "system_prompt", "instructions", "prompt")
 	if systemPrompt == "" {
-		return nil, nil
+		return nil
 	}
 
-	stem := filenameStem(path)
+	var metadata map[string]any
+	for _, key := range []string{"model", "temperature"} {
+		if v, ok := raw[key]; ok && v != nil {
+			if metadata == nil {
+				metadata = make(map[string]any)
+			}
+			metadata[key] = v
+		}
+	}
 
 	return &AgentDefinition{
 		ID:             coalesce(getString(raw, "id"), stem),
 		Name:           coalesce(getString(raw, "name"), nameFromStem(stem)),
-		SourcePath:     path,
+		Description:    firstString(raw, "description", "summary"),
+		SourcePath:     sourcePath,
 		SystemPrompt:   systemPrompt,
-		Skills:         getStringSlice(raw, "skills"),
+		Skills:         append(getStringSlice(raw, "skills"), toolSkills(raw["tools"])...),
 		Rules:          getStringSlice(raw, "rules"),
 		ClaimedDomains: getStringSlice(raw, "domains"),
-	}, nil
+		OutOfScope:     getStringSlice(raw, "out_of_scope"),
+		Metadata:       metadata,
+	}
+}
+
+// toolSkills turns an OpenAI Assistants tools array into readable skills:
+// built-in tools by type ("Code Interpreter", "File Search") and function
+// tools by name and description ("Get Stock Price: Look up the latest
+// closing price"). Plain strings are taken as they are.
+func toolSkills(v any) []string {
+	tools, _ := v.([]any)
+	var skills []string
+	for _, t := range tools {
+		switch tool := t.(type) {
+		case string:
+			if tool != "" {
+				skills = append(skills, tool)
+			}
+		case map[string]any:
+			fn, _ := tool["function"].(map[string]any)
+			if fn == nil {
+				if typ := getString(tool, "type"); typ != "" {
+					skills = append(skills, nameFromStem(typ))
+				}
+				continue
+			}
+			name := nameFromStem(getString(fn, "name"))
+			desc := strings.TrimSpace(getString(fn, "description"))
+			switch {
+			case name != "" && desc != "":
+				skills = append(skills, name+": "+desc)
+			case name != "" || desc != "":
+				skills = append(skills, coalesce(name, desc))
+			}
+		}
+	}
+	return skills
 }
 
 func loadText(path string) (*AgentDefinition, error) {
@@ -201,7 +499,7 @@ func loadText(path string) (*AgentDefinition, error) {
 	}
 
 	content := strings.TrimSpace(string(data))
-	if len(content) < 20 {
+	if len(content) < 20 && referenceTarget(content) == "" {
 		return nil, nil
 	}
 
@@ -229,9 +527,11 @@ func loadText(path string) (*AgentDefinition, error) {
 
 	if frontmatter != nil {
 		agent.Name = coalesce(getString(frontmatter, "name"), agent.Name)
+		agent.Description = firstString(frontmatter, "description", "summary")
 		agent.Skills = getStringSlice(frontmatter, "skills")
 		agent.Rules = getStringSlice(frontmatter, "rules")
 		agent.ClaimedDomains = getStringSlice(frontmatter, "domains")
+		agent.OutOfScope = getStringSlice(frontmatter, "out_of_scope")
 		agent.Metadata = frontmatter
 	}
 
@@ -401,11 +701,77 @@ func filterKeys(m map[string]any, exclude ...string) map[string]any {
 	return result
 }
 
+// SkipAgents removes agents whose resolved ID matches any of the given
+// regular expressions. Patterns are unanchored, as with regexp.MatchString.
+func SkipAgents(agents []AgentDefinition, patterns []string) ([]AgentDefinition, error) {
+	if len(patterns) == 0 {
+		return agents, nil
+	}
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+
+	var kept []AgentDefinition
+	for _, a := range agents {
+		skip := false
+		for _, re := range res {
+			if re.MatchString(a.ID) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			kept = append(kept, a)
+		}
+	}
+	return kept, nil
+}
+
+// SelectAgents keeps only the agents named by ids. An ID matches an agent
+// with that resolved ID, so the directory-qualified IDs given to agents
+// whose filenames collide can be named exactly; an unqualified ID also
+// matches every agent qualified from it. Each ID must match an agent.
+func SelectAgents(agents []AgentDefinition, ids []string) ([]AgentDefinition, error) {
+	if len(ids) == 0 {
+		return agents, nil
+	}
+	matched := make(map[string]bool, len(ids))
+	var kept []AgentDefinition
+	for _, a := range agents {
+		selected := false
+		for _, id := range ids {
+			if a.ID == id || (!strings.Contains(id, "/") && path.Base(a.ID) == id) {
+				matched[id] = true
+				selected = true
+			}
+		}
+		if selected {
+			kept = append(kept, a)
+		}
+	}
+	for _, id := range ids {
+		if !matched[id] {
+			return nil, fmt.Errorf("no agent matches %q", id)
+		}
+	}
+	return kept, nil
+}
+
 // LoadAgentsRecursive walks the directory tree rooted at path, loading agent
 // definitions from all supported file types. When dedup is true, agents with
 // identical system prompts are collapsed into a single representative with
 // AlsoFoundIn populated.
 func LoadAgentsRecursive(path string, dedup bool) ([]AgentDefinition, error) {
+	return LoadAgentsFiltered(path, LoadOptions{Recursive: true, Dedup: dedup})
+}
+
+// loadRecursive is LoadAgentsFiltered for opts.Recursive.
+func loadRecursive(path string, opts LoadOptions) ([]AgentDefinition, error) {
 	absRoot, err := filepath.Abs(path)
 	if err != nil {
 		return nil, fmt.Errorf("resolve path: %w", err)
@@ -416,49 +782,120 @@ func LoadAgentsRecursive(path string, dedup bool) ([]AgentDefinition, error) {
 		return nil, fmt.Errorf("agent path not found: %s", path)
 	}
 	if !info.IsDir() {
-		return LoadAgents(path)
+		return loadCountedFile(path, opts)
 	}
 
 	var allAgents []AgentDefinition
+	err = walkAgents(absRoot, opts, func(agent AgentDefinition) {
+		allAgents = append(allAgents, agent)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return FinalizeRecursive(allAgents, opts.Dedup), nil
+}
+
+// StreamAgentsRecursive walks the directory tree rooted at path like
+// LoadAgentsRecursive, but sends each agent on the returned channel as soon
+// as its file is loaded, so callers can start analyzing before the walk
+// finishes. The agent channel is closed when the walk ends, after which the
+// error channel receives the walk's result. Streamed agents are neither
+// deduplicated nor ID-qualified; pass the collected slice to
+// FinalizeRecursive once the channel is drained.
+func StreamAgentsRecursive(path string) (<-chan AgentDefinition, <-chan error) {
+	return StreamAgentsFiltered(path, LoadOptions{})
+}
 
-	err = filepath.WalkDir(absRoot, func(p string, d fs.DirEntry, err error) error {
+// StreamAgentsFiltered is StreamAgentsRecursive keeping only the files
+// selected by opts.Include and opts.Exclude. opts.Recursive and opts.Dedup
+// are ignored: the tree is always walked, and deduplication is left to
+// FinalizeRecursive.
+func StreamAgentsFiltered(path string, opts LoadOptions) (<-chan AgentDefinition, <-chan error) {
+	agents := make(chan AgentDefinition, 16)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		if err := opts.validate(); err != nil {
+			close(agents)
+			errc <- err
+			return
+		}
+		absRoot, err := filepath.Abs(path)
+		if err != nil {
+			close(agents)
+			errc <- fmt.Errorf("resolve path: %w", err)
+			return
+		}
+		info, err := os.Stat(absRoot)
+		switch {
+		case err != nil:
+			err = fmt.Errorf("agent path not found: %s", path)
+		case !info.IsDir():
+			err = fmt.Errorf("%s is not a directory", path)
+		default:
+			err = walkAgents(absRoot, opts, func(agent AgentDefinition) {
+				agents <- agent
+			})
+		}
+		close(agents)
+		errc <- err
+	}()
+	return agents, errc
+}
+
+// FinalizeRecursive applies the whole-tree steps of LoadAgentsRecursive to
+// agents collected from StreamAgentsRecursive: deduplication when dedup is
+// true, then qualification of conflicting IDs.
+func FinalizeRecursive(agents []AgentDefinition, dedup bool) []AgentDefinition {
+	if dedup {
+		return deduplicateAgents(agents)
+	}
+	return qualifyConflictingIDs(agents)
+}
+
+// walkAgents loads every agent definition under absRoot selected by opts in
+// walk order, passing each to emit with SourcePath relative to absRoot and
+// ContentHash set.
+func walkAgents(absRoot string, opts LoadOptions, emit func(AgentDefinition)) error {
+	var ignore *gitignore
+	if opts.RespectGitignore {
+		ignore = newGitignore(absRoot)
+	}
+	return filepath.WalkDir(absRoot, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
+		relPath, _ := filepath.Rel(absRoot, p)
 		if d.IsDir() {
-			if strings.HasPrefix(d.Name(), ".") {
+			if p == absRoot {
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") || opts.excluded(relPath) || (ignore != nil && ignore.ignored(p, true)) {
 				return filepath.SkipDir
 			}
+			if ignore != nil {
+				ignore.addDir(p)
+			}
 			return nil
 		}
 		name := d.Name()
-		if name == "agent-evals.yaml" || name == "agent-evals.yml" {
+		if name == "agent-evals.yaml" || name == "agent-evals.yml" || !opts.selected(relPath) || (ignore != nil && ignore.ignored(p, false)) {
 			return nil
 		}
-		agent, loadErr := loadSingleFile(p)
+		loaded, loadErr := loadCountedFile(p, opts)
 		if loadErr != nil {
 			fmt.Fprintf(os.Stderr, "Warning: skipped %s: %v\n", p, loadErr)
 			return nil
 		}
-		if agent != nil {
-			relPath, _ := filepath.Rel(absRoot, p)
-			agent.SourcePath = relPath
+		for _, agent := range loaded {
+			// Keep the "[index]" suffix of fleet manifest entries
+			agent.SourcePath = relPath + strings.TrimPrefix(agent.SourcePath, p)
 			agent.ContentHash = computeContentHash(agent.SystemPrompt)
-			allAgents = append(allAgents, *agent)
+			emit(agent)
 		}
 		return nil
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	if dedup {
-		allAgents = deduplicateAgents(allAgents)
-	} else {
-		allAgents = qualifyConflictingIDs(allAgents)
-	}
-
-	return allAgents, nil
 }
 
 func computeContentHash(prompt string) string {
@@ -1,8 +1,13 @@
 package loader
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -11,8 +16,17 @@ func testdataPath(name string) string {
 	return filepath.Join(filepath.Dir(file), "testdata", name)
 }
 
+// firstAgent returns the first of agents, or nil when there are none.
+func firstAgent(agents []AgentDefinition) *AgentDefinition {
+	if len(agents) == 0 {
+		return nil
+	}
+	return &agents[0]
+}
+
 func TestLoadYAML(t *testing.T) {
-	agent, err := loadYAML(testdataPath("backend_api.yaml"))
+	agents, err := loadYAML(testdataPath("backend_api.yaml"))
+	agent := firstAgent(agents)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -40,8 +54,53 @@ func TestLoadYAML(t *testing.T) {
 	}
 }
 
+func TestLoadDescription(t *testing.T) {
+	tests := []struct {
+		file        string
+		description string
+		summary     string
+	}{
+		{"backend_api.yaml", "Designs and reviews Go REST APIs.", "Designs and reviews Go REST APIs."},
+		{"scoped_frontmatter.md", "React front end, no financial advice", "React front end, no financial advice"},
+		{"frontend.json", "", "You are a frontend developer using React, CSS, and HTML."},
+		{"security_agent.md", "", "You are a security specialist."},
+	}
+	for _, tt := range tests {
+		agents, err := loadSingleFile(testdataPath(tt.file))
+		if err != nil || len(agents) != 1 {
+			t.Fatalf("%s: expected one agent, got %d (err %v)", tt.file, len(agents), err)
+		}
+		if agents[0].Description != tt.description {
+			t.Errorf("%s: Description = %q, want %q", tt.file, agents[0].Description, tt.description)
+		}
+		if agents[0].Summary() != tt.summary {
+			t.Errorf("%s: Summary() = %q, want %q", tt.file, agents[0].Summary(), tt.summary)
+		}
+	}
+}
+
+func TestAgentSummaryFallback(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   string
+	}{
+		{"# Reviewer\n\nYou review pull requests. You never merge them.", "You review pull requests."},
+		{"You are a data engineer\nworking on Spark pipelines.\n\nAsk before deleting tables.", "You are a data engineer working on Spark pipelines."},
+		{"Version 2.1 of the billing agent handles refunds", "Version 2.1 of the billing agent handles refunds"},
+		{strings.Repeat("word ", 40), strings.TrimSpace(strings.Repeat("word ", 24)) + "…"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		a := AgentDefinition{SystemPrompt: tt.prompt}
+		if got := a.Summary(); got != tt.want {
+			t.Errorf("Summary() of %q = %q, want %q", tt.prompt, got, tt.want)
+		}
+	}
+}
+
 func TestLoadYAMLAlternativeFields(t *testing.T) {
-	agent, err := loadYAML(testdataPath("alt_fields.yaml"))
+	agents, err := loadYAML(testdataPath("alt_fields.yaml"))
+	agent := firstAgent(agents)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -58,7 +117,8 @@ func TestLoadYAMLAlternativeFields(t *testing.T) {
 }
 
 func TestLoadYAMLNoPrompt(t *testing.T) {
-	agent, err := loadYAML(testdataPath("no_prompt.yaml"))
+	agents, err := loadYAML(testdataPath("no_prompt.yaml"))
+	agent := firstAgent(agents)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -68,7 +128,8 @@ func TestLoadYAMLNoPrompt(t *testing.T) {
 }
 
 func TestLoadYAMLIDFromFilename(t *testing.T) {
-	agent, err := loadYAML(testdataPath("alt_fields.yaml"))
+	agents, err := loadYAML(testdataPath("alt_fields.yaml"))
+	agent := firstAgent(agents)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -82,13 +143,14 @@ func TestLoadYAMLIDFromFilename(t *testing.T) {
 }
 
 func TestLoadJSON(t *testing.T) {
-	agent, err := loadJSON(testdataPath("frontend.json"))
+	agents, err := loadJSON(testdataPath("frontend.json"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if agent == nil {
-		t.Fatal("expected agent, got nil")
+	if len(agents) != 1 {
+		t.Fatalf("expected 1 agent, got %d", len(agents))
 	}
+	agent := agents[0]
 
 	if agent.ID != "frontend_react" {
 		t.Errorf("ID = %q, want %q", agent.ID, "frontend_react")
@@ -104,6 +166,180 @@ func TestLoadJSON(t *testing.T) {
 	}
 }
 
+func TestLoadJSONOpenAIAssistant(t *testing.T) {
+	agents, err := loadJSON(testdataPath("assistant.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected 1 agent, got %d", len(agents))
+	}
+	agent := agents[0]
+
+	if agent.Name != "Portfolio Analyst" {
+		t.Errorf("Name = %q, want %q", agent.Name, "Portfolio Analyst")
+	}
+	if !strings.HasPrefix(agent.SystemPrompt, "You are a portfolio analyst") {
+		t.Errorf("expected the prompt from instructions, got %q", agent.SystemPrompt)
+	}
+	wantSkills := []string{
+		"Code Interpreter",
+		"File Search",
+		"Get Stock Price: Look up the latest closing price for a ticker symbol",
+		"Rebalance Portfolio",
+	}
+	if !reflect.DeepEqual(agent.Skills, wantSkills) {
+		t.Errorf("Skills = %q, want %q", agent.Skills, wantSkills)
+	}
+	if agent.Metadata["model"] != "gpt-4o-2024-08-06" {
+		t.Errorf("Metadata[model] = %v, want gpt-4o-2024-08-06", agent.Metadata["model"])
+	}
+	if agent.Metadata["temperature"] != 0.2 {
+		t.Errorf("Metadata[temperature] = %v, want 0.2", agent.Metadata["temperature"])
+	}
+	if _, ok := agent.Metadata["tool_resources"]; ok {
+		t.Error("expected only model and temperature in Metadata")
+	}
+}
+
+func TestLoadYAMLFleetFile(t *testing.T) {
+	for _, name := range []string{"fleet_yaml/agents.yaml", "fleet_yaml/stream.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path := testdataPath(name)
+			agents, err := LoadAgents(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(agents) != 3 {
+				t.Fatalf("expected 3 agents, got %d", len(agents))
+			}
+
+			want := []struct {
+				id, name, source, domain string
+			}{
+				{"billing", "Billing", path + "[0]", "payments"},
+				{"support", "Support Triage", path + "[1]", "customer_support"},
+				{filenameStem(path) + "_2", nameFromStem(filenameStem(path) + "_2"), path + "[2]", "devops"},
+			}
+			for i, w := range want {
+				a := agents[i]
+				if a.ID != w.id || a.Name != w.name || a.SourcePath != w.source {
+					t.Errorf("agent %d: got id=%q name=%q source=%q, want %q %q %q", i, a.ID, a.Name, a.SourcePath, w.id, w.name, w.source)
+				}
+				if len(a.ClaimedDomains) != 1 || a.ClaimedDomains[0] != w.domain {
+					t.Errorf("agent %d: ClaimedDomains = %v, want [%s]", i, a.ClaimedDomains, w.domain)
+				}
+			}
+			if agents[0].Metadata["tier"] != "critical" {
+				t.Errorf("expected metadata from the entry, got %v", agents[0].Metadata)
+			}
+		})
+	}
+}
+
+func TestLoadYAMLFleetFileRecursive(t *testing.T) {
+	agents, err := LoadAgentsRecursive(testdataPath("fleet_yaml"), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Both files define the same three agents, so each is found twice.
+	if len(agents) != 3 {
+		t.Fatalf("expected 3 unique agents, got %d", len(agents))
+	}
+	for _, a := range agents {
+		if len(a.AlsoFoundIn) != 1 {
+			t.Errorf("agent %s: expected one duplicate, got %v", a.ID, a.AlsoFoundIn)
+		}
+	}
+}
+
+func TestLoadStatsCountsFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		opts  LoadOptions
+		files int
+	}{
+		// Two fleet files each defining the same three agents
+		{"fleet files", testdataPath("fleet_yaml"), LoadOptions{Recursive: true, Dedup: true}, 2},
+		{"duplicates", testdataPath("recursive"), LoadOptions{Recursive: true, Dedup: true}, 5},
+		{"single file", testdataPath("backend_api.yaml"), LoadOptions{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats LoadStats
+			tt.opts.Stats = &stats
+			if _, err := LoadAgentsFiltered(tt.path, tt.opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.Files != tt.files {
+				t.Errorf("Files = %d, want %d", stats.Files, tt.files)
+			}
+		})
+	}
+}
+
+func TestLoadJSONFleetManifestObject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fleet.json")
+	manifest := `{"version": 2, "agents": [
+		{"id": "billing", "system_prompt": "You handle invoices, refunds and payment disputes.", "domains": ["payments"]},
+		{"id": "support", "system_prompt": "You triage customer support tickets.", "domains": ["customer_support"]}
+	]}`
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	agents, err := LoadAgents(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents) != 2 || agents[0].ID != "billing" || agents[1].SourcePath != path+"[1]" {
+		t.Errorf("expected billing and support from the agents array, got %v", agents)
+	}
+}
+
+func TestLoadJSONFleetManifest(t *testing.T) {
+	path := testdataPath("fleet/fleet.json")
+	agents, err := LoadAgents(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents) != 3 {
+		t.Fatalf("expected 3 agents from the manifest, got %d", len(agents))
+	}
+
+	want := []struct {
+		id, name, source, domain string
+	}{
+		{"billing", "Billing", path + "[0]", "payments"},
+		{"support", "Support Triage", path + "[1]", "customer_support"},
+		{"fleet_2", "Fleet 2", path + "[2]", "devops"},
+	}
+	for i, w := range want {
+		a := agents[i]
+		if a.ID != w.id || a.Name != w.name || a.SourcePath != w.source {
+			t.Errorf("agent %d: got id=%q name=%q source=%q, want %q %q %q", i, a.ID, a.Name, a.SourcePath, w.id, w.name, w.source)
+		}
+		if len(a.ClaimedDomains) != 1 || a.ClaimedDomains[0] != w.domain {
+			t.Errorf("agent %d: ClaimedDomains = %v, want [%s]", i, a.ClaimedDomains, w.domain)
+		}
+	}
+}
+
+func TestLoadJSONFleetManifestRecursive(t *testing.T) {
+	agents, err := LoadAgentsRecursive(testdataPath("fleet"), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents) != 3 {
+		t.Fatalf("expected 3 agents, got %d", len(agents))
+	}
+	for i, a := range agents {
+		if want := fmt.Sprintf("fleet.json[%d]", i); a.SourcePath != want {
+			t.Errorf("agent %d: SourcePath = %q, want %q", i, a.SourcePath, want)
+		}
+	}
+}
+
 func TestLoadTextWithFrontmatter(t *testing.T) {
 	agent, err := loadText(testdataPath("security_agent.md"))
 	if err != nil {
@@ -128,6 +364,36 @@ func TestLoadTextWithFrontmatter(t *testing.T) {
 	}
 }
 
+func TestLoadYAMLOutOfScope(t *testing.T) {
+	agents, err := loadYAML(testdataPath("scoped_agent.yaml"))
+	agent := firstAgent(agents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if agent == nil {
+		t.Fatal("expected agent, got nil")
+	}
+	if len(agent.OutOfScope) != 2 || agent.OutOfScope[0] != "medical" || agent.OutOfScope[1] != "legal" {
+		t.Errorf("expected OutOfScope=[medical legal], got %v", agent.OutOfScope)
+	}
+	if _, ok := agent.Metadata["out_of_scope"]; ok {
+		t.Error("out_of_scope should not be duplicated into Metadata")
+	}
+}
+
+func TestLoadTextOutOfScopeFrontmatter(t *testing.T) {
+	agent, err := loadText(testdataPath("scoped_frontmatter.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if agent == nil {
+		t.Fatal("expected agent, got nil")
+	}
+	if len(agent.OutOfScope) != 1 || agent.OutOfScope[0] != "financial" {
+		t.Errorf("expected OutOfScope=[financial] from frontmatter, got %v", agent.OutOfScope)
+	}
+}
+
 func TestLoadTextPlain(t *testing.T) {
 	agent, err := loadText(testdataPath("plain_agent.txt"))
 	if err != nil {
@@ -526,3 +792,273 @@ func TestRecursiveSingleFile(t *testing.T) {
 		t.Errorf("Name = %q, want %q", agents[0].Name, "Security Agent")
 	}
 }
+
+func TestStreamAgentsRecursiveMatchesBatch(t *testing.T) {
+	for _, dedup := range []bool{true, false} {
+		want, err := LoadAgentsRecursive(testdataPath("recursive"), dedup)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		stream, errc := StreamAgentsRecursive(testdataPath("recursive"))
+		var streamed []AgentDefinition
+		for agent := range stream {
+			streamed = append(streamed, agent)
+		}
+		if err := <-errc; err != nil {
+			t.Fatalf("unexpected stream error: %v", err)
+		}
+		got := FinalizeRecursive(streamed, dedup)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("dedup=%v: streamed agents differ from LoadAgentsRecursive:\n got %+v\nwant %+v", dedup, got, want)
+		}
+	}
+}
+
+func TestStreamAgentsRecursiveRejectsFile(t *testing.T) {
+	stream, errc := StreamAgentsRecursive(testdataPath("security_agent.md"))
+	for range stream {
+		t.Error("expected no agents from a file path")
+	}
+	if err := <-errc; err == nil {
+		t.Error("expected an error for a file path")
+	}
+}
+
+func TestLoadAgentsFilteredExclude(t *testing.T) {
+	agents, err := LoadAgentsFiltered(testdataPath("filtered"), LoadOptions{
+		Exclude:   []string{"**/vendor/**", "node_modules/**"},
+		Recursive: true,
+		Dedup:     true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, a := range agents {
+		ids = append(ids, a.ID)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"notes", "reviewer"}) {
+		t.Errorf("expected vendored agents to be excluded, got %v", ids)
+	}
+}
+
+func TestLoadAgentsFilteredIncludeExclude(t *testing.T) {
+	tests := []struct {
+		name string
+		opts LoadOptions
+		want []string
+	}{
+		{"no patterns", LoadOptions{Recursive: true}, []string{"helper", "notes", "reviewer", "vendored"}},
+		{"include", LoadOptions{Include: []string{"**/*.md"}, Recursive: true}, []string{"helper", "reviewer", "vendored"}},
+		{"exclude wins", LoadOptions{Include: []string{"**/*.md"}, Exclude: []string{"**/vendor/**"}, Recursive: true}, []string{"helper", "reviewer"}},
+		{"segment wildcard", LoadOptions{Include: []string{"*/*/agents/*.md"}, Recursive: true}, []string{"vendored"}},
+		{"flat", LoadOptions{}, []string{"notes"}},
+		{"flat exclude", LoadOptions{Exclude: []string{"*.yaml"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents, err := LoadAgentsFiltered(testdataPath("filtered"), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var ids []string
+			for _, a := range agents {
+				ids = append(ids, a.ID)
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadAgentsFiltered(testdataPath("filtered"), LoadOptions{Exclude: []string{"[vendor"}}); err == nil {
+		t.Error("expected error for a malformed pattern")
+	}
+}
+
+func TestLoadAgentsRespectGitignore(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, rel := range []string{
+		"agents/reviewer.md", "agents/wip.draft.md", "agents/keep.draft.md", "build/generated.md",
+		"plugins/tool.md", "plugins/local.md", "plugins/deep/local.md", "plugins/vendor/lib.md", "plugins/idea.draft.md",
+	} {
+		write(rel, fmt.Sprintf("You are the agent defined in %s, answering questions about its area with care and precision.\n", rel))
+	}
+	write(".gitignore", "# build output\nbuild/\n*.draft.md\n!keep.draft.md\n")
+	write("plugins/.gitignore", "vendor\n/local.md\n")
+
+	sources := func(path string, opts LoadOptions) []string {
+		t.Helper()
+		agents, err := LoadAgentsFiltered(path, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var paths []string
+		for _, a := range agents {
+			paths = append(paths, filepath.ToSlash(a.SourcePath))
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	if got := sources(root, LoadOptions{Recursive: true}); len(got) != 9 {
+		t.Errorf("without RespectGitignore every agent should load, got %v", got)
+	}
+
+	want := []string{"agents/keep.draft.md", "agents/reviewer.md", "plugins/deep/local.md", "plugins/tool.md"}
+	if got := sources(root, LoadOptions{Recursive: true, RespectGitignore: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Loading a subdirectory of a repository applies the .gitignore files
+	// above it.
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"deep/local.md", "tool.md"}
+	if got := sources(filepath.Join(root, "plugins"), LoadOptions{Recursive: true, RespectGitignore: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/vendor/**", "vendor/a.md", true},
+		{"**/vendor/**", "x/y/vendor/z/a.md", true},
+		{"**/vendor/**", "x/vendor", true},
+		{"**/vendor/**", "x/vendors/a.md", false},
+		{"*.md", "a.md", true},
+		{"*.md", "x/a.md", false},
+		{"**/*.md", "a.md", true},
+		{"agents/**/*.yaml", "agents/a/b/c.yaml", true},
+		{"agents/**/*.yaml", "other/c.yaml", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSkipAgents(t *testing.T) {
+	agents := []AgentDefinition{
+		{ID: "backend_api"},
+		{ID: "template_agent"},
+		{ID: "archive/old_frontend"},
+		{ID: "frontend_react"},
+	}
+
+	kept, err := SkipAgents(agents, []string{"^template_", "^archive/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 2 || kept[0].ID != "backend_api" || kept[1].ID != "frontend_react" {
+		t.Errorf("expected [backend_api frontend_react], got %v", kept)
+	}
+
+	if _, err := SkipAgents(agents, []string{"("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestSelectAgents(t *testing.T) {
+	agents := []AgentDefinition{
+		{ID: "plugin-a/agents/reviewer"},
+		{ID: "plugin-b/agents/reviewer"},
+		{ID: "backend_api"},
+	}
+
+	kept, err := SelectAgents(agents, []string{"plugin-b/agents/reviewer"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 1 || kept[0].ID != "plugin-b/agents/reviewer" {
+		t.Errorf("expected the qualified ID to select one agent, got %v", kept)
+	}
+
+	kept, err = SelectAgents(agents, []string{"reviewer", "backend_api"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 3 {
+		t.Errorf("expected an unqualified ID to select every agent qualified from it, got %v", kept)
+	}
+
+	if _, err := SelectAgents(agents, []string{"agents/reviewer"}); err == nil {
+		t.Error("expected error for a partial qualified ID")
+	}
+	if _, err := SelectAgents(agents, []string{"frontend"}); err == nil {
+		t.Error("expected error for an ID that matches no agent")
+	}
+}
+
+func TestLoadReferenceOnlyPrompt(t *testing.T) {
+	agents, err := loadSingleFile(testdataPath("reference/reviewer.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected 1 agent for a short reference-only prompt, got %d", len(agents))
+	}
+	agent := agents[0]
+	if agent.ReferenceOnly {
+		t.Error("expected reference to resolve")
+	}
+	if agent.PromptReference != testdataPath("reference/shared/reviewer-prompt.md") {
+		t.Errorf("PromptReference = %q", agent.PromptReference)
+	}
+	if !strings.HasPrefix(agent.SystemPrompt, "You are a code reviewer.") {
+		t.Errorf("expected referenced content without frontmatter, got %q", agent.SystemPrompt)
+	}
+}
+
+func TestLoadUnresolvedReferenceFlagged(t *testing.T) {
+	agents, err := loadSingleFile(testdataPath("reference/orphan.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected 1 agent, got %d", len(agents))
+	}
+	agent := agents[0]
+	if !agent.ReferenceOnly {
+		t.Error("expected unresolved reference to be flagged ReferenceOnly")
+	}
+}
+
+func TestReferenceTarget(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   string
+	}{
+		{"See shared/base-prompt.md", "shared/base-prompt.md"},
+		{"!include base.md", "base.md"},
+		{"@rules/common.md", "rules/common.md"},
+		{"Refer to: `agents/base.yaml`.", "agents/base.yaml"},
+		{"See CONTRIBUTING.md before reviewing any pull request for style issues.", ""},
+		{"You are a security reviewer.", ""},
+		{"See base.md\nThen review code.", ""},
+	}
+	for _, tt := range tests {
+		if got := referenceTarget(tt.prompt); got != tt.want {
+			t.Errorf("referenceTarget(%q) = %q, want %q", tt.prompt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package probes
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// checkpointEntry is one line of a probe checkpoint file: a completed probe
+// and the hash of the inputs that produced it.
+type checkpointEntry struct {
+	AgentID string      `json:"agent_id"`
+	Hash    string      `json:"hash"`
+	Detail  ProbeDetail `json:"detail"`
+}
+
+// Checkpoint holds the probes completed by an earlier run, keyed by agent
+// and probe ID.
+type Checkpoint struct {
+	entries map[string]checkpointEntry
+}
+
+func checkpointKey(agentID, probeID string) string {
+	return agentID + "\x00" + probeID
+}
+
+// probeHash identifies the inputs of a probe: the agent's prompt, the
+// question as asked, including any conversation prefix, and the run
+// settings that shape its responses (provider, model, stochastic runs,
+// temperatures and the robustness check). A checkpointed result is only
+// reused when its hash still matches, so editing an agent or its probes, or
+// changing the model or the number of runs, re-runs them.
+func probeHash(agent *loader.AgentDefinition, q ProbeQuestion, cfg RunConfig) string {
+	h := sha256.New()
+	for _, part := range []string{agent.SystemPrompt, q.Text, q.ProbeType, q.Domain, q.ExpectedBehavior} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	for _, m := range q.Prefix {
+		h.Write([]byte(m.Role + "\x00" + m.Content + "\x00"))
+	}
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%g\x00%g\x00%t\x00%d", cfg.Provider, cfg.Model, cfg.StochasticRuns,
+		deterministicTemperature, stochasticTemperature, cfg.RobustnessCheck, cfg.RobustnessSeed)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LoadCheckpoint reads a JSONL checkpoint written by RunLiveProbes. A
+// missing file yields an empty checkpoint. Lines that do not parse, such as
+// one cut short when a run was killed, are skipped; a later line for the
+// same probe replaces an earlier one.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	cp := &Checkpoint{entries: make(map[string]checkpointEntry)}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e checkpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.AgentID == "" || e.Detail.ProbeID == "" {
+			continue
+		}
+		cp.entries[checkpointKey(e.AgentID, e.Detail.ProbeID)] = e
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// Len returns the number of probes in the checkpoint.
+func (c *Checkpoint) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.entries)
+}
+
+// Lookup returns the checkpointed result of probe q for agent, if present
+// and recorded with the same agent prompt, question and run settings of cfg.
+func (c *Checkpoint) Lookup(agent *loader.AgentDefinition, q ProbeQuestion, cfg RunConfig) (ProbeDetail, bool) {
+	if c == nil {
+		return ProbeDetail{}, false
+	}
+	e, ok := c.entries[checkpointKey(agent.ID, q.ID)]
+	if !ok || e.Hash != probeHash(agent, q, cfg) {
+		return ProbeDetail{}, false
+	}
+	return e.Detail, true
+}
+
+// checkpointWriter appends completed probes to a checkpoint file. It is
+// safe for concurrent use.
+type checkpointWriter struct {
+	mu  sync.Mutex
+	f   *os.File
+	err error // first write error
+}
+
+// openCheckpointWriter opens path for appending. If an earlier run was
+// killed mid-line, a newline is written first so the cut line stays
+// separate from new entries.
+func openCheckpointWriter(path string) (*checkpointWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("open checkpoint: %w", err)
+			}
+		}
+	}
+	return &checkpointWriter{f: f}, nil
+}
+
+// write appends one completed probe as a single line, so a run killed
+// mid-write loses at most that probe.
+func (w *checkpointWriter) write(agent *loader.AgentDefinition, q ProbeQuestion, cfg RunConfig, detail ProbeDetail) {
+	data, err := json.Marshal(checkpointEntry{AgentID: agent.ID, Hash: probeHash(agent, q, cfg), Detail: detail})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		_, err = w.f.Write(append(data, '\n'))
+	}
+	if err != nil && w.err == nil {
+		w.err = fmt.Errorf("write checkpoint: %w", err)
+	}
+}
+
+// close closes the file and returns the first error seen.
+func (w *checkpointWriter) close() error {
+	if err := w.f.Close(); err != nil && w.err == nil {
+		w.err = fmt.Errorf("close checkpoint: %w", err)
+	}
+	return w.err
+}
+
+// detailFailed reports whether any call for the probe failed.
+func detailFailed(detail ProbeDetail) bool {
+	if detail.Reordered != nil && detail.Reordered.Error != "" {
+		return true
+	}
+	for _, r := range detail.Responses {
+		if r.Error != "" {
+			return true
+		}
+	}
+	return false
+}
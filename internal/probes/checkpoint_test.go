@@ -0,0 +1,126 @@
+package probes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+// countingClient counts calls and answers with low confidence. Calls whose
+// prompt contains failText fail.
+type countingClient struct {
+	calls    atomic.Int32
+	failText string
+}
+
+func (c *countingClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.calls.Add(1)
+	if c.failText != "" && strings.Contains(req.UserPrompt, c.failText) {
+		return provider.CompletionResponse{}, errors.New("connection reset")
+	}
+	return provider.CompletionResponse{Text: "Not my area.\nCONFIDENCE: 20"}, nil
+}
+
+func TestRunLiveProbesResumeFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "probes.jsonl")
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You are agent a."},
+		{ID: "b", SystemPrompt: "You are agent b."},
+	}
+	questions := []ProbeQuestion{
+		{ID: "p1", Text: "Question one", TargetAgent: "a", ProbeType: "boundary"},
+		{ID: "p2", Text: "Question two", TargetAgent: "a", ProbeType: "boundary"},
+		{ID: "p3", Text: "Question three", TargetAgent: "b", ProbeType: "boundary"},
+	}
+	cfg := RunConfig{StochasticRuns: 1, BatchDelay: time.Millisecond, CheckpointPath: path}
+
+	// First run: p2's calls fail, so only p1 and p3 are checkpointed.
+	first := &countingClient{failText: "Question two"}
+	report := RunLiveProbes(context.Background(), agents, questions, first, cfg, nil)
+	if report.TotalCalls != 6 || report.Resumed != 0 {
+		t.Fatalf("first run: TotalCalls=%d Resumed=%d, want 6 and 0", report.TotalCalls, report.Resumed)
+	}
+	if cp, err := LoadCheckpoint(path); err != nil || cp.Len() != 2 {
+		t.Fatalf("expected 2 checkpointed probes, got %d (err %v)", cp.Len(), err)
+	}
+
+	// Simulate a run killed mid-write.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"agent_id":"a","hash":"`)
+	f.Close()
+
+	// Resume: only p2 is called again.
+	second := &countingClient{}
+	report = RunLiveProbes(context.Background(), agents, questions, second, cfg, nil)
+	if report.Resumed != 2 {
+		t.Errorf("Resumed = %d, want 2", report.Resumed)
+	}
+	if report.TotalCalls != 2 || second.calls.Load() != 2 {
+		t.Errorf("TotalCalls = %d (client saw %d), want 2 calls for p2 only", report.TotalCalls, second.calls.Load())
+	}
+	if got := report.AgentResults["a"].ProbesRun; got != 2 {
+		t.Errorf("agent a ProbesRun = %d, want 2", got)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("unexpected issues: %+v", report.Issues)
+	}
+
+	// Editing an agent's prompt invalidates its checkpointed probes.
+	agents[1].SystemPrompt = "You are agent b, now with a new prompt."
+	third := &countingClient{}
+	report = RunLiveProbes(context.Background(), agents, questions, third, cfg, nil)
+	if report.Resumed != 2 || report.TotalCalls != 2 {
+		t.Errorf("after editing b: Resumed=%d TotalCalls=%d, want 2 and 2", report.Resumed, report.TotalCalls)
+	}
+}
+
+func TestCheckpointRunSettingsInvalidateReuse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "probes.jsonl")
+	agents := []loader.AgentDefinition{{ID: "a", SystemPrompt: "You are agent a."}}
+	questions := []ProbeQuestion{{ID: "p1", Text: "Question one", TargetAgent: "a", ProbeType: "boundary"}}
+	cfg := RunConfig{StochasticRuns: 1, BatchDelay: time.Millisecond, CheckpointPath: path, Provider: "openai", Model: "gpt-4o"}
+	RunLiveProbes(context.Background(), agents, questions, &countingClient{}, cfg, nil)
+
+	tests := []struct {
+		name   string
+		change func(*RunConfig)
+		reused bool
+	}{
+		{"same settings", func(*RunConfig) {}, true},
+		{"other model", func(c *RunConfig) { c.Model = "gpt-4o-mini" }, false},
+		{"other provider", func(c *RunConfig) { c.Provider = "anthropic" }, false},
+		{"more runs", func(c *RunConfig) { c.StochasticRuns = 3 }, false},
+		{"robustness check", func(c *RunConfig) { c.RobustnessCheck = true }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp, err := LoadCheckpoint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			run := cfg
+			tt.change(&run)
+			if _, ok := cp.Lookup(&agents[0], questions[0], run); ok != tt.reused {
+				t.Errorf("reused = %v, want %v", ok, tt.reused)
+			}
+		})
+	}
+}
+
+func TestLoadCheckpointMissingFile(t *testing.T) {
+	cp, err := LoadCheckpoint(filepath.Join(t.TempDir(), "none.jsonl"))
+	if err != nil || cp.Len() != 0 {
+		t.Errorf("expected an empty checkpoint, got %d entries (err %v)", cp.Len(), err)
+	}
+}
@@ -0,0 +1,200 @@
+package probes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+// TokenUsage totals the tokens spent on probe calls.
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+
+	// Approximate is set when at least one response carried no usage report
+	// and its tokens were estimated from word counts.
+	Approximate bool
+}
+
+// Add accumulates other into u.
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.Approximate = u.Approximate || other.Approximate
+}
+
+// tokensPerWord approximates tokens from words for English prose.
+const tokensPerWord = 4.0 / 3.0
+
+// responseUsage returns the usage reported with resp, or an estimate from the
+// request and response word counts when the provider reported none.
+func responseUsage(req provider.CompletionRequest, resp provider.CompletionResponse) TokenUsage {
+	if resp.PromptTokens > 0 || resp.CompletionTokens > 0 || resp.TotalTokens > 0 {
+		total := resp.TotalTokens
+		if total == 0 {
+			total = resp.PromptTokens + resp.CompletionTokens
+		}
+		return TokenUsage{PromptTokens: resp.PromptTokens, CompletionTokens: resp.CompletionTokens, TotalTokens: total}
+	}
+	prompt := estimateTokens(req.SystemPrompt) + estimateTokens(req.UserPrompt)
+	for _, m := range req.History {
+		prompt += estimateTokens(m.Content)
+	}
+	completion := estimateTokens(resp.Text)
+	return TokenUsage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion, Approximate: true}
+}
+
+func estimateTokens(text string) int {
+	return int(float64(len(strings.Fields(text)))*tokensPerWord + 0.5)
+}
+
+// ModelPrice is the list price of a model in US dollars per million tokens.
+type ModelPrice struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// ModelPricing maps model name fragments to list prices. A model is priced
+// by the longest key it contains, so dated IDs ("gpt-4o-2024-08-06") and
+// Bedrock IDs ("anthropic.claude-3-5-sonnet-20240620-v1:0") resolve to their
+// family.
+var ModelPricing = map[string]ModelPrice{
+	"claude-opus-4":     {InputPerMTok: 15, OutputPerMTok: 75},
+	"claude-sonnet-4":   {InputPerMTok: 3, OutputPerMTok: 15},
+	"claude-3-7-sonnet": {InputPerMTok: 3, OutputPerMTok: 15},
+	"claude-3-5-sonnet": {InputPerMTok: 3, OutputPerMTok: 15},
+	"claude-haiku-4":    {InputPerMTok: 1, OutputPerMTok: 5},
+	"claude-3-5-haiku":  {InputPerMTok: 0.8, OutputPerMTok: 4},
+	"gpt-4o":            {InputPerMTok: 2.5, OutputPerMTok: 10},
+	"gpt-4o-mini":       {InputPerMTok: 0.15, OutputPerMTok: 0.6},
+	"gpt-4.1":           {InputPerMTok: 2, OutputPerMTok: 8},
+	"gpt-4.1-mini":      {InputPerMTok: 0.4, OutputPerMTok: 1.6},
+	"gpt-4.1-nano":      {InputPerMTok: 0.1, OutputPerMTok: 0.4},
+	"gemini-1.5-pro":    {InputPerMTok: 1.25, OutputPerMTok: 5},
+	"gemini-1.5-flash":  {InputPerMTok: 0.075, OutputPerMTok: 0.3},
+}
+
+// LookupPrice returns the price for model, matching the longest
+// ModelPricing key contained in it.
+func LookupPrice(model string) (ModelPrice, bool) {
+	model = strings.ToLower(model)
+	best := ""
+	for key := range ModelPricing {
+		if strings.Contains(model, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return ModelPricing[best], true
+}
+
+// CostEstimate is the estimated spend of a probe run.
+type CostEstimate struct {
+	Model string
+	Usage TokenUsage
+	// USD is the estimated cost, or nil when the model has no known price.
+	USD *float64
+}
+
+// EstimateCost prices usage at model's list price.
+func EstimateCost(model string, usage TokenUsage) CostEstimate {
+	est := CostEstimate{Model: model, Usage: usage}
+	if price, ok := LookupPrice(model); ok {
+		usd := float64(usage.PromptTokens)/1e6*price.InputPerMTok + float64(usage.CompletionTokens)/1e6*price.OutputPerMTok
+		est.USD = &usd
+	}
+	return est
+}
+
+// expectedCompletionTokens is the assumed length of one probe answer when
+// estimating a run before any call is made.
+const expectedCompletionTokens = 250
+
+// AgentCallEstimate is the projected API usage for one agent's probes.
+type AgentCallEstimate struct {
+	AgentID string
+	Probes  int
+	Calls   int
+	Usage   TokenUsage
+}
+
+// RunEstimate is the projected API usage of a live probe run.
+type RunEstimate struct {
+	Agents []AgentCallEstimate
+	Probes int
+	Calls  int
+	Cost   CostEstimate
+}
+
+// EstimateRun projects the calls, tokens and cost RunLiveProbes would spend
+// on questions with cfg, without calling the provider. Each probe costs one
+// deterministic call plus cfg.StochasticRuns, and out-of-scope probes add a
+// reordered call under cfg.RobustnessCheck. Prompt tokens are estimated from
+// word counts and each answer is assumed to be expectedCompletionTokens long,
+// so the usage is always Approximate.
+func EstimateRun(agents []loader.AgentDefinition, questions []ProbeQuestion, cfg RunConfig, model string) RunEstimate {
+	if cfg.StochasticRuns == 0 {
+		cfg.StochasticRuns = 5
+	}
+
+	agentMap := make(map[string]*loader.AgentDefinition)
+	byAgent := make(map[string]*AgentCallEstimate)
+	var order []string
+	for i := range agents {
+		agentMap[agents[i].ID] = &agents[i]
+		if _, ok := byAgent[agents[i].ID]; !ok {
+			byAgent[agents[i].ID] = &AgentCallEstimate{AgentID: agents[i].ID}
+			order = append(order, agents[i].ID)
+		}
+	}
+
+	est := RunEstimate{}
+	var usage TokenUsage
+	for _, q := range questions {
+		agent, ok := agentMap[q.TargetAgent]
+		if !ok {
+			continue
+		}
+		calls := 1 + cfg.StochasticRuns
+		if cfg.RobustnessCheck && isOutOfScope(q.ProbeType) {
+			if _, ok := shufflePrompt(agent.SystemPrompt, agent.ID, cfg.RobustnessSeed); ok {
+				calls++
+			}
+		}
+
+		prompt := estimateTokens(agent.SystemPrompt) + estimateTokens(fmt.Sprintf(BoundaryProbeTemplate, q.Text))
+		prefix := q.Prefix
+		if len(prefix) == 0 {
+			prefix = cfg.ConversationPrefixes.For(agent.ID)
+		}
+		for _, m := range prefix {
+			prompt += estimateTokens(m.Content)
+		}
+		u := TokenUsage{
+			PromptTokens:     prompt * calls,
+			CompletionTokens: expectedCompletionTokens * calls,
+			TotalTokens:      (prompt + expectedCompletionTokens) * calls,
+			Approximate:      true,
+		}
+
+		a := byAgent[agent.ID]
+		a.Probes++
+		a.Calls += calls
+		a.Usage.Add(u)
+		est.Probes++
+		est.Calls += calls
+		usage.Add(u)
+	}
+
+	for _, id := range order {
+		est.Agents = append(est.Agents, *byAgent[id])
+	}
+	est.Cost = EstimateCost(model, usage)
+	return est
+}
@@ -0,0 +1,114 @@
+package probes
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+func TestResponseUsage(t *testing.T) {
+	req := provider.CompletionRequest{SystemPrompt: "one two three", UserPrompt: "four five six"}
+
+	reported := responseUsage(req, provider.CompletionResponse{Text: "x", PromptTokens: 100, CompletionTokens: 20})
+	if reported.TotalTokens != 120 || reported.Approximate {
+		t.Errorf("expected reported usage to be used as-is, got %+v", reported)
+	}
+
+	estimated := responseUsage(req, provider.CompletionResponse{Text: "a b c d e f"})
+	if !estimated.Approximate {
+		t.Error("expected missing usage to be flagged approximate")
+	}
+	if estimated.PromptTokens != 8 || estimated.CompletionTokens != 8 {
+		t.Errorf("expected word-count estimate of 8/8 tokens, got %+v", estimated)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	usage := TokenUsage{PromptTokens: 1_000_000, CompletionTokens: 100_000, TotalTokens: 1_100_000}
+
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"gpt-4o-2024-08-06", 2.5 + 1.0},
+		{"gpt-4o-mini", 0.15 + 0.06},
+		{"anthropic.claude-3-5-sonnet-20240620-v1:0", 3 + 1.5},
+		{"claude-sonnet-4-5-20250514", 3 + 1.5},
+	}
+	for _, tt := range tests {
+		est := EstimateCost(tt.model, usage)
+		if est.USD == nil || math.Abs(*est.USD-tt.want) > 1e-9 {
+			t.Errorf("EstimateCost(%q) = %v, want %v", tt.model, est.USD, tt.want)
+		}
+	}
+
+	if est := EstimateCost("llama3.3:70b", usage); est.USD != nil {
+		t.Errorf("expected no price for an unknown model, got %v", *est.USD)
+	}
+}
+
+// usageClient reports fixed usage for every call.
+type usageClient struct{}
+
+func (usageClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	return provider.CompletionResponse{Text: "CONFIDENCE: 40", Model: "gpt-4o-2024-08-06", PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110}, nil
+}
+
+func TestRunLiveProbesAggregatesUsage(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You are agent a."},
+		{ID: "b", SystemPrompt: "You are agent b."},
+	}
+	questions := []ProbeQuestion{
+		{ID: "p1", Text: "Q", TargetAgent: "a", ProbeType: "boundary"},
+		{ID: "p2", Text: "Q", TargetAgent: "b", ProbeType: "boundary"},
+	}
+
+	report := RunLiveProbes(context.Background(), agents, questions, usageClient{}, RunConfig{StochasticRuns: 2, BatchDelay: time.Millisecond}, nil)
+
+	if got := report.AgentResults["a"].Usage.TotalTokens; got != 330 {
+		t.Errorf("agent a total tokens = %d, want 330", got)
+	}
+	if report.Cost.Usage.PromptTokens != 600 || report.Cost.Usage.CompletionTokens != 60 {
+		t.Errorf("run usage = %+v", report.Cost.Usage)
+	}
+	if report.Model != "gpt-4o-2024-08-06" || report.Cost.USD == nil {
+		t.Errorf("expected priced cost for the reported model, got %+v", report.Cost)
+	}
+}
+
+func TestEstimateRun(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", SystemPrompt: "You are agent a. You handle databases. You never do frontend work."},
+		{ID: "b", SystemPrompt: "You are agent b."},
+	}
+	questions := []ProbeQuestion{
+		{ID: "p1", Text: "Q one", TargetAgent: "a", ProbeType: "boundary"},
+		{ID: "p2", Text: "Q two", TargetAgent: "a", ProbeType: "calibration"},
+		{ID: "p3", Text: "Q three", TargetAgent: "b", ProbeType: "boundary"},
+		{ID: "p4", Text: "Q four", TargetAgent: "missing", ProbeType: "boundary"},
+	}
+
+	est := EstimateRun(agents, questions, RunConfig{StochasticRuns: 2}, "gpt-4o")
+	if est.Probes != 3 || est.Calls != 9 {
+		t.Errorf("expected 3 probes and 9 calls, got %d and %d", est.Probes, est.Calls)
+	}
+	if len(est.Agents) != 2 || est.Agents[0].AgentID != "a" || est.Agents[0].Calls != 6 {
+		t.Errorf("unexpected per-agent estimate: %+v", est.Agents)
+	}
+	if est.Cost.Usage.CompletionTokens != 9*expectedCompletionTokens || !est.Cost.Usage.Approximate {
+		t.Errorf("unexpected usage: %+v", est.Cost.Usage)
+	}
+	if est.Cost.USD == nil {
+		t.Error("expected a priced estimate for gpt-4o")
+	}
+
+	robust := EstimateRun(agents, questions, RunConfig{StochasticRuns: 2, RobustnessCheck: true, RobustnessSeed: 1}, "gpt-4o")
+	if robust.Calls != 10 {
+		t.Errorf("expected one reordered call for agent a's boundary probe, got %d calls", robust.Calls)
+	}
+}
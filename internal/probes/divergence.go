@@ -0,0 +1,218 @@
+package probes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+)
+
+// maxOverlapQuestionsPerPair caps the shared-domain questions asked of each
+// overlapping agent pair.
+const maxOverlapQuestionsPerPair = 2
+
+// ResolveOverlapProbes returns the agent pairs to send overlap probes to
+// when probes.overlap_probes is enabled: pairs with shared domains whose
+// overlap exceeds thresholds.max_overlap_score, or whose instructions
+// conflict. It returns nil when overlap probes are disabled.
+func ResolveOverlapProbes(config map[string]any, overlaps []analysis.OverlapResult) []analysis.OverlapResult {
+	section, _ := config["probes"].(map[string]any)
+	if enabled, _ := section["overlap_probes"].(bool); !enabled {
+		return nil
+	}
+	maxOverlap := analysis.DefaultMaxOverlapScore
+	if thresholds, ok := config["thresholds"].(map[string]any); ok {
+		switch v := thresholds["max_overlap_score"].(type) {
+		case float64:
+			maxOverlap = v
+		case int:
+			maxOverlap = float64(v)
+		}
+	}
+
+	var pairs []analysis.OverlapResult
+	for _, o := range overlaps {
+		if len(o.SharedDomains) > 0 && (o.Verdict == "conflict" || o.OverlapScore > maxOverlap) {
+			pairs = append(pairs, o)
+		}
+	}
+	return pairs
+}
+
+// overlapQuestions returns up to maxOverlapQuestionsPerPair bank questions
+// from the pair's shared domains, which both agents claim.
+func overlapQuestions(o analysis.OverlapResult) []questionEntry {
+	shared := make(map[string]bool)
+	for _, d := range o.SharedDomains {
+		shared[normalizeDomain(d)] = true
+	}
+	questions := questionsForDomains(shared)
+	if len(questions) > maxOverlapQuestionsPerPair {
+		questions = questions[:maxOverlapQuestionsPerPair]
+	}
+	for i := range questions {
+		questions[i].expected = fmt.Sprintf("Shared %s territory with '%s' and '%s', answers should agree",
+			questions[i].domain, o.AgentA, o.AgentB)
+	}
+	return questions
+}
+
+// AnswerDivergence records two overlapping agents answering the same
+// overlap probe in contradictory ways.
+type AnswerDivergence struct {
+	ProbeID  string
+	Question string
+	Domain   string
+	AgentA   string
+	AgentB   string
+	// Similarity is the word overlap of the two answers, from 0 to 1.
+	Similarity float64
+	// Contradictions describes each way the answers disagree.
+	Contradictions []string
+}
+
+// FindAnswerDivergences compares the deterministic answers of each pair of
+// agents that received the same overlap probe and returns the pairs whose
+// answers contradict each other: one endorses what the other rejects (the
+// static conflict heuristics applied to the answers), or one answers yes
+// and the other no. Consistent answers are harmless redundancy and are not
+// returned. Probes whose partner was dropped by the budget are skipped.
+func FindAnswerDivergences(results map[string]*AgentProbeResults) []AnswerDivergence {
+	var divergences []AnswerDivergence
+	for agentID, r := range results {
+		for _, detail := range r.Details {
+			// Compare each pair once, from the alphabetically first agent
+			if detail.ProbeType != "overlap" || detail.PairedAgent == "" || agentID > detail.PairedAgent {
+				continue
+			}
+			partner, ok := results[detail.PairedAgent]
+			if !ok {
+				continue
+			}
+			other, ok := findDetail(partner.Details, detail.ProbeID)
+			if !ok {
+				continue
+			}
+			answerA, okA := primaryAnswer(detail.Responses)
+			answerB, okB := primaryAnswer(other.Responses)
+			if !okA || !okB {
+				continue
+			}
+
+			contradictions := analysis.TextConflicts(agentID, answerA, detail.PairedAgent, answerB)
+			polarA, polarB := answerPolarity(answerA), answerPolarity(answerB)
+			if polarA != "" && polarB != "" && polarA != polarB {
+				contradictions = append(contradictions, fmt.Sprintf("'%s' answers %s but '%s' answers %s",
+					agentID, polarA, detail.PairedAgent, polarB))
+			}
+			if len(contradictions) == 0 {
+				continue
+			}
+			divergences = append(divergences, AnswerDivergence{
+				ProbeID:        detail.ProbeID,
+				Question:       detail.Question,
+				Domain:         detail.Domain,
+				AgentA:         agentID,
+				AgentB:         detail.PairedAgent,
+				Similarity:     answerSimilarity(answerA, answerB),
+				Contradictions: contradictions,
+			})
+		}
+	}
+
+	sort.Slice(divergences, func(i, j int) bool {
+		a, b := divergences[i], divergences[j]
+		if a.AgentA != b.AgentA {
+			return a.AgentA < b.AgentA
+		}
+		if a.AgentB != b.AgentB {
+			return a.AgentB < b.AgentB
+		}
+		return a.ProbeID < b.ProbeID
+	})
+	return divergences
+}
+
+func divergenceIssues(divergences []AnswerDivergence) []analysis.Issue {
+	var issues []analysis.Issue
+	for _, d := range divergences {
+		issues = append(issues, analysis.Issue{
+			Severity: "warning",
+			Category: "divergence",
+			Message: fmt.Sprintf("Overlapping agents '%s' and '%s' disagree on %q: %s",
+				d.AgentA, d.AgentB, snippet(d.Question, 80), strings.Join(d.Contradictions, "; ")),
+			Agents: []string{d.AgentA, d.AgentB},
+			Score:  d.Similarity,
+		})
+	}
+	return issues
+}
+
+func findDetail(details []ProbeDetail, probeID string) (ProbeDetail, bool) {
+	for _, d := range details {
+		if d.ProbeID == probeID {
+			return d, true
+		}
+	}
+	return ProbeDetail{}, false
+}
+
+// primaryAnswer returns the answer text of the deterministic run, falling
+// back to the first successful stochastic run.
+func primaryAnswer(responses []ResponseRecord) (string, bool) {
+	for _, r := range responses {
+		if r.Error == "" && r.Temperature == 0 {
+			return ParseProbeResponse(r.Raw).Answer, true
+		}
+	}
+	for _, r := range responses {
+		if r.Error == "" {
+			return ParseProbeResponse(r.Raw).Answer, true
+		}
+	}
+	return "", false
+}
+
+// answerPolarity returns "yes" or "no" when an answer opens with one, and ""
+// otherwise.
+func answerPolarity(answer string) string {
+	fields := strings.Fields(strings.ToLower(answer))
+	if len(fields) == 0 {
+		return ""
+	}
+	switch strings.Trim(fields[0], ".,;:!*_") {
+	case "yes":
+		return "yes"
+	case "no":
+		return "no"
+	}
+	return ""
+}
+
+// answerSimilarity is the Jaccard overlap of the words of four or more
+// letters in two answers.
+func answerSimilarity(a, b string) float64 {
+	words := func(text string) map[string]bool {
+		set := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+		}) {
+			if len(w) >= 4 {
+				set[w] = true
+			}
+		}
+		return set
+	}
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
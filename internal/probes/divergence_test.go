@@ -0,0 +1,140 @@
+package probes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+// agentAnswerClient answers with a fixed text per agent, keyed by a word
+// that appears in the agent's system prompt.
+type agentAnswerClient struct {
+	answers map[string]string
+}
+
+func (c *agentAnswerClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	for key, answer := range c.answers {
+		if strings.Contains(req.SystemPrompt, key) {
+			return provider.CompletionResponse{Text: answer}, nil
+		}
+	}
+	return provider.CompletionResponse{Text: "I'm not sure.\nCONFIDENCE: 30"}, nil
+}
+
+func overlapFixture() ([]loader.AgentDefinition, []ProbeQuestion) {
+	agents := []loader.AgentDefinition{
+		{ID: "api", SystemPrompt: "You are the API agent."},
+		{ID: "platform", SystemPrompt: "You are the platform agent."},
+	}
+	overlap := analysis.OverlapResult{AgentA: "api", AgentB: "platform", SharedDomains: []string{"databases"}, OverlapScore: 0.6, Verdict: "warning"}
+	questions := GenerateProbesWithOptions(nil, 500, GenerateOptions{Overlaps: []analysis.OverlapResult{overlap}})
+	return agents, questions
+}
+
+func TestGenerateOverlapProbes(t *testing.T) {
+	_, questions := overlapFixture()
+	if len(questions) != 2*maxOverlapQuestionsPerPair {
+		t.Fatalf("expected %d overlap probes, got %d", 2*maxOverlapQuestionsPerPair, len(questions))
+	}
+	byID := make(map[string][]ProbeQuestion)
+	for _, q := range questions {
+		if q.ProbeType != "overlap" || q.Domain != "databases" {
+			t.Errorf("unexpected probe: %+v", q)
+		}
+		byID[q.ID] = append(byID[q.ID], q)
+	}
+	for id, pair := range byID {
+		if len(pair) != 2 || pair[0].Text != pair[1].Text ||
+			pair[0].TargetAgent != pair[1].PairedAgent || pair[1].TargetAgent != pair[0].PairedAgent {
+			t.Errorf("probe %s is not a matched pair: %+v", id, pair)
+		}
+	}
+}
+
+func TestGenerateOverlapProbesBudgetKeepsPairs(t *testing.T) {
+	overlap := analysis.OverlapResult{AgentA: "api", AgentB: "platform", SharedDomains: []string{"databases"}, OverlapScore: 0.6, Verdict: "warning"}
+
+	// Budget 6 at 1 stochastic run → max 3 probes, which ends mid-pair
+	for _, seed := range []int64{0, 7} {
+		questions := GenerateProbesWithOptions(nil, 6, GenerateOptions{
+			Overlaps:       []analysis.OverlapResult{overlap},
+			StochasticRuns: 1,
+			Seed:           seed,
+		})
+		if len(questions) != 2 {
+			t.Fatalf("seed %d: expected one whole pair (2 probes), got %d", seed, len(questions))
+		}
+		if questions[0].ID != questions[1].ID || questions[0].TargetAgent != questions[1].PairedAgent {
+			t.Errorf("seed %d: budget split an overlap pair: %+v", seed, questions)
+		}
+	}
+}
+
+func TestRunLiveProbesFlagsContradictoryOverlapAnswers(t *testing.T) {
+	agents, questions := overlapFixture()
+	client := &agentAnswerClient{answers: map[string]string{
+		"API agent":      "Yes. Always use postgres here; it handles this load well.\nCONFIDENCE: 90",
+		"platform agent": "No. Never use postgres for this, a key-value store fits better.\nCONFIDENCE: 90",
+	}}
+
+	report := RunLiveProbes(context.Background(), agents, questions, client,
+		RunConfig{StochasticRuns: 1, BatchDelay: time.Millisecond}, nil)
+
+	if len(report.Divergences) != maxOverlapQuestionsPerPair {
+		t.Fatalf("expected %d divergences, got %+v", maxOverlapQuestionsPerPair, report.Divergences)
+	}
+	d := report.Divergences[0]
+	if d.AgentA != "api" || d.AgentB != "platform" {
+		t.Errorf("unexpected pair: %s / %s", d.AgentA, d.AgentB)
+	}
+	joined := strings.Join(d.Contradictions, "; ")
+	if !strings.Contains(joined, "says use 'postgres'") || !strings.Contains(joined, "answers yes") {
+		t.Errorf("expected conflict and polarity contradictions, got %q", joined)
+	}
+
+	found := false
+	for _, i := range report.Issues {
+		if i.Category == "divergence" && strings.Contains(i.Message, "Overlapping agents 'api' and 'platform' disagree") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a divergence issue, got %+v", report.Issues)
+	}
+}
+
+func TestRunLiveProbesConsistentOverlapAnswers(t *testing.T) {
+	agents, questions := overlapFixture()
+	client := &agentAnswerClient{answers: map[string]string{
+		"API agent":      "Yes, postgres handles this well with proper indexing.\nCONFIDENCE: 85",
+		"platform agent": "Yes. Postgres is a good fit if you index the hot columns.\nCONFIDENCE: 80",
+	}}
+
+	report := RunLiveProbes(context.Background(), agents, questions, client,
+		RunConfig{StochasticRuns: 1, BatchDelay: time.Millisecond}, nil)
+
+	if len(report.Divergences) != 0 {
+		t.Errorf("consistent answers should not be flagged, got %+v", report.Divergences)
+	}
+}
+
+func TestResolveOverlapProbes(t *testing.T) {
+	overlaps := []analysis.OverlapResult{
+		{AgentA: "a", AgentB: "b", SharedDomains: []string{"backend"}, OverlapScore: 0.5, Verdict: "clean"},
+		{AgentA: "a", AgentB: "c", SharedDomains: []string{"backend"}, OverlapScore: 0.2, Verdict: "clean"},
+		{AgentA: "b", AgentB: "c", SharedDomains: nil, OverlapScore: 0.9, Verdict: "warning"},
+	}
+	if got := ResolveOverlapProbes(map[string]any{}, overlaps); got != nil {
+		t.Errorf("expected nil when disabled, got %+v", got)
+	}
+	cfg := map[string]any{"probes": map[string]any{"overlap_probes": true}}
+	got := ResolveOverlapProbes(cfg, overlaps)
+	if len(got) != 1 || got[0].AgentB != "b" {
+		t.Errorf("expected only the a/b pair, got %+v", got)
+	}
+}
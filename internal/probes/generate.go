@@ -0,0 +1,164 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+// CustomQuestion is a probe question supplied under probes.questions in
+// config, keyed by the claimed domain it probes. A question whose Domain
+// matches that key is a calibration probe; any other Domain makes it a
+// boundary probe. ProbeType, set in a questions file, overrides that.
+type CustomQuestion struct {
+	Question  string `yaml:"question" json:"question"`
+	Domain    string `yaml:"domain" json:"domain"`
+	Expected  string `yaml:"expected" json:"expected"`
+	ProbeType string `yaml:"probe_type,omitempty" json:"probe_type,omitempty"`
+
+	// ExpectedAnswer, AnswerContains and AnswerRegex are the ground truth
+	// of a question with an objective answer (see AnswerKey).
+	ExpectedAnswer string `yaml:"expected_answer,omitempty" json:"expected_answer,omitempty"`
+	AnswerContains string `yaml:"answer_contains,omitempty" json:"answer_contains,omitempty"`
+	AnswerRegex    string `yaml:"answer_regex,omitempty" json:"answer_regex,omitempty"`
+}
+
+// QuestionGenerationPrompt asks the model for probe questions about a domain.
+// It is formatted with the domain and the number of questions.
+const QuestionGenerationPrompt = `You are writing evaluation questions for AI agents that specialize in the "%[1]s" domain.
+
+Write %[2]d questions in total:
+- About half should be in-domain questions a competent %[1]s specialist should answer confidently (calibration questions). Set "domain" to "%[1]s".
+- The rest should be questions from adjacent domains that sound related but belong to another specialist, where a %[1]s agent should hedge or defer (boundary questions). Set "domain" to that other domain, as a short lowercase name such as "frontend" or "security".
+
+Each question must be concrete, answerable in a few sentences, and unambiguous about its domain.
+For each, write "expected": the behavior a well-scoped %[1]s agent should show, e.g. "Core knowledge, should answer confidently" or "Should hedge or acknowledge this is security territory".
+
+Respond with only a JSON array, no prose:
+[{"question": "...", "domain": "...", "expected": "..."}]`
+
+// GenerateDomainQuestions asks the model for count boundary and calibration
+// questions for domain. Questions without text are dropped, domains are
+// normalized, and a missing expected behavior gets a default for its type.
+func GenerateDomainQuestions(ctx context.Context, client provider.LLMClient, domain string, count int) ([]CustomQuestion, error) {
+	domain = normalizeDomain(domain)
+	resp, err := client.Complete(ctx, provider.CompletionRequest{
+		UserPrompt:  fmt.Sprintf(QuestionGenerationPrompt, domain, count),
+		Temperature: 0.7,
+		MaxTokens:   4096,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := parseGeneratedQuestions(resp.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []CustomQuestion
+	seen := make(map[string]bool)
+	for _, q := range raw {
+		q.Question = strings.TrimSpace(q.Question)
+		if q.Question == "" || seen[normalizeQuestion(q.Question)] {
+			continue
+		}
+		seen[normalizeQuestion(q.Question)] = true
+		q.Domain = normalizeDomain(strings.TrimSpace(q.Domain))
+		if q.Domain == "" {
+			q.Domain = domain
+		}
+		if strings.TrimSpace(q.Expected) == "" {
+			q.Expected = defaultExpected(domain, q.Domain)
+		}
+		questions = append(questions, q)
+		if len(questions) == count {
+			break
+		}
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("model returned no usable questions")
+	}
+	return questions, nil
+}
+
+// defaultExpected is the expected behavior for a question about
+// questionDomain asked of an agent claiming domain.
+func defaultExpected(domain, questionDomain string) string {
+	if questionDomain == domain {
+		return "Core knowledge, should answer confidently"
+	}
+	return fmt.Sprintf("Should hedge or acknowledge this is %s territory", questionDomain)
+}
+
+// parseGeneratedQuestions extracts the JSON array from a model response,
+// tolerating surrounding prose or code fences.
+func parseGeneratedQuestions(text string) ([]CustomQuestion, error) {
+	start := strings.Index(text, "[")
+	end := strings.LastIndex(text, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in model response")
+	}
+	var questions []CustomQuestion
+	if err := json.Unmarshal([]byte(text[start:end+1]), &questions); err != nil {
+		return nil, fmt.Errorf("parse generated questions: %w", err)
+	}
+	return questions, nil
+}
+
+// FormatCustomQuestions renders questions for domain as a config snippet
+// under probes.questions, ready to merge into agent-evals.yaml.
+func FormatCustomQuestions(domain string, questions []CustomQuestion) (string, error) {
+	doc := map[string]any{
+		"probes": map[string]any{
+			"questions": map[string][]CustomQuestion{
+				normalizeDomain(domain): questions,
+			},
+		},
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ResolveCustomQuestions reads probes.questions from config: a map from
+// claimed domain to a list of {question, domain, expected} entries. It
+// returns nil when none are configured.
+func ResolveCustomQuestions(config map[string]any) map[string][]CustomQuestion {
+	section, _ := config["probes"].(map[string]any)
+	custom, _ := section["questions"].(map[string]any)
+	var result map[string][]CustomQuestion
+	for key, raw := range custom {
+		key = normalizeDomain(key)
+		items, _ := raw.([]any)
+		for _, item := range items {
+			entry, _ := item.(map[string]any)
+			text, _ := entry["question"].(string)
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			q := CustomQuestion{Question: text, Domain: key}
+			if d, ok := entry["domain"].(string); ok && d != "" {
+				q.Domain = normalizeDomain(d)
+			}
+			q.Expected, _ = entry["expected"].(string)
+			if answer, ok := entry["expected_answer"]; ok && answer != nil {
+				q.ExpectedAnswer = fmt.Sprint(answer) // numbers are common answers
+			}
+			q.AnswerContains, _ = entry["answer_contains"].(string)
+			q.AnswerRegex, _ = entry["answer_regex"].(string)
+			if result == nil {
+				result = make(map[string][]CustomQuestion)
+			}
+			result[key] = append(result[key], q)
+		}
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package probes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+// fixedClient returns the same text for every completion and records the
+// last request.
+type fixedClient struct {
+	text string
+	last provider.CompletionRequest
+}
+
+func (c *fixedClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.last = req
+	return provider.CompletionResponse{Text: c.text}, nil
+}
+
+func TestGenerateDomainQuestionsWritesConfigShape(t *testing.T) {
+	client := &fixedClient{text: "Here are your questions:\n```json\n" + `[
+  {"question": "How do you settle a split payment across two acquirers?", "domain": "payments", "expected": "Core knowledge, should answer confidently"},
+  {"question": "What is PCI DSS requirement 3.4 about?", "domain": "Security", "expected": "Should hedge or defer to security"},
+  {"question": "How should refunds be reconciled with the ledger?", "domain": "payments"},
+  {"question": "", "domain": "payments"}
+]` + "\n```"}
+
+	questions, err := GenerateDomainQuestions(context.Background(), client, "payments", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(client.last.UserPrompt, `"payments"`) || !strings.Contains(client.last.UserPrompt, "Write 10 questions") {
+		t.Errorf("prompt should name the domain and count, got:\n%s", client.last.UserPrompt)
+	}
+	if len(questions) != 3 {
+		t.Fatalf("expected 3 usable questions, got %d: %+v", len(questions), questions)
+	}
+	if questions[1].Domain != "security" {
+		t.Errorf("expected normalized domain, got %q", questions[1].Domain)
+	}
+	if questions[2].Expected == "" {
+		t.Error("expected a default expected behavior")
+	}
+
+	out, err := FormatCustomQuestions("payments", questions)
+	if err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	var cfg map[string]any
+	if err := yaml.Unmarshal([]byte(out), &cfg); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, out)
+	}
+	resolved := ResolveCustomQuestions(cfg)
+	if len(resolved["payments"]) != 3 {
+		t.Fatalf("expected probes.questions.payments with 3 entries, got %+v\n%s", resolved, out)
+	}
+	if resolved["payments"][1] != questions[1] {
+		t.Errorf("round trip = %+v, want %+v", resolved["payments"][1], questions[1])
+	}
+
+	// The written questions drive probe generation for agents claiming the domain.
+	agents := []loader.AgentDefinition{{ID: "pay", SystemPrompt: "You handle payments.", ClaimedDomains: []string{"payments"}}}
+	types := make(map[string]string)
+	for _, p := range GenerateProbesWithOptions(agents, 1000, GenerateOptions{CustomQuestions: resolved}) {
+		types[p.Text] = p.ProbeType
+	}
+	if types[questions[0].Question] != "calibration" || types[questions[1].Question] != "boundary" {
+		t.Errorf("expected calibration and boundary probes from custom questions, got %v", types)
+	}
+}
+
+func TestGenerateDomainQuestionsNoArray(t *testing.T) {
+	_, err := GenerateDomainQuestions(context.Background(), &fixedClient{text: "I cannot do that."}, "payments", 5)
+	if err == nil {
+		t.Fatal("expected error when the response has no JSON array")
+	}
+}
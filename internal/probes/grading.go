@@ -0,0 +1,119 @@
+package probes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AnswerKey is the ground truth of a probe with an objective answer, from a
+// custom question's expected_answer, answer_contains and answer_regex. An
+// answer is correct when it matches Expected, contains Contains, ignoring
+// case, and matches Regex; an empty field is not checked.
+type AnswerKey struct {
+	// Expected is either a regular expression written as /pattern/ or an
+	// exact answer, compared ignoring case, surrounding whitespace and
+	// quotes, and a final period.
+	Expected string `json:"expected,omitempty"`
+	Contains string `json:"contains,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+}
+
+// newAnswerKey returns the answer key of q, or nil when it has none.
+func newAnswerKey(q CustomQuestion) *AnswerKey {
+	k := &AnswerKey{
+		Expected: strings.TrimSpace(q.ExpectedAnswer),
+		Contains: strings.TrimSpace(q.AnswerContains),
+		Regex:    q.AnswerRegex,
+	}
+	if k.Expected == "" && k.Contains == "" && strings.TrimSpace(k.Regex) == "" {
+		return nil
+	}
+	return k
+}
+
+// expectedRegex returns the pattern of an Expected written as /pattern/.
+func (k *AnswerKey) expectedRegex() (string, bool) {
+	if len(k.Expected) >= 2 && strings.HasPrefix(k.Expected, "/") && strings.HasSuffix(k.Expected, "/") {
+		return k.Expected[1 : len(k.Expected)-1], true
+	}
+	return "", false
+}
+
+// validate reports an expected_answer or answer_regex pattern that does not
+// compile.
+func (k *AnswerKey) validate() error {
+	if k == nil {
+		return nil
+	}
+	if pattern, ok := k.expectedRegex(); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("expected_answer: %w", err)
+		}
+	}
+	if k.Regex != "" {
+		if _, err := regexp.Compile(k.Regex); err != nil {
+			return fmt.Errorf("answer_regex: %w", err)
+		}
+	}
+	return nil
+}
+
+// Grade reports whether answer is correct, or nil when there is no key to
+// grade it against or a pattern does not compile.
+func (k *AnswerKey) Grade(answer string) *bool {
+	if k == nil || k.validate() != nil {
+		return nil
+	}
+	correct := true
+	if pattern, ok := k.expectedRegex(); ok {
+		if !regexp.MustCompile(pattern).MatchString(answer) {
+			correct = false
+		}
+	} else if k.Expected != "" && normalizeAnswer(answer) != normalizeAnswer(k.Expected) {
+		correct = false
+	}
+	if k.Contains != "" && !strings.Contains(strings.ToLower(answer), strings.ToLower(k.Contains)) {
+		correct = false
+	}
+	if k.Regex != "" && !regexp.MustCompile(k.Regex).MatchString(answer) {
+		correct = false
+	}
+	return &correct
+}
+
+// normalizeAnswer lowercases an answer and trims whitespace, quotes and a
+// final period, for exact comparison.
+func normalizeAnswer(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, ".")
+	return strings.TrimSpace(strings.Trim(s, "\"'`"))
+}
+
+// customAnswers maps custom questions to their answer keys.
+type customAnswers map[customKey]*AnswerKey
+
+func newCustomAnswers(custom map[string][]CustomQuestion) customAnswers {
+	answers := make(customAnswers)
+	for _, questions := range custom {
+		for _, q := range questions {
+			if key := newAnswerKey(q); key != nil {
+				answers[customKeyOf(q.Domain, q.Question)] = key
+			}
+		}
+	}
+	return answers
+}
+
+// gradeResponses marks each successful response of a probe with an answer
+// key as correct or not.
+func gradeResponses(detail ProbeDetail) {
+	if detail.Answer == nil {
+		return
+	}
+	for i, r := range detail.Responses {
+		if r.Error == "" {
+			detail.Responses[i].Correct = detail.Answer.Grade(ParseProbeResponse(r.Raw).Answer)
+		}
+	}
+}
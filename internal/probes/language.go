@@ -0,0 +1,126 @@
+package probes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language whose hedging and refusal patterns are
+// used when probes.language is not set.
+const DefaultLanguage = "en"
+
+// hedgingPattern is a phrase that signals uncertainty, weighted by how
+// strongly it does so.
+type hedgingPattern struct {
+	pattern *regexp.Regexp
+	weight  float64
+}
+
+// hedgingPatterns holds each language's hedging phrases. The weights of
+// equivalent phrases match across languages, so scores are comparable.
+// Patterns match lowercased text; Go's \b only knows ASCII letters, so
+// patterns next to accented letters use explicit boundaries.
+var hedgingPatterns = map[string][]hedgingPattern{
+	"en": {
+		{regexp.MustCompile(`(?i)\bi don'?t know\b`), 1.0},
+		{regexp.MustCompile(`(?i)\bi'?m not sure\b`), 0.9},
+		{regexp.MustCompile(`(?i)\boutside my\b`), 0.95},
+		{regexp.MustCompile(`(?i)\bbeyond my\b`), 0.95},
+		{regexp.MustCompile(`(?i)\bi believe\b`), 0.4},
+		{regexp.MustCompile(`(?i)\bi think\b`), 0.3},
+		{regexp.MustCompile(`(?i)\bapproximately\b`), 0.2},
+		{regexp.MustCompile(`(?i)\bprobably\b`), 0.3},
+		{regexp.MustCompile(`(?i)\bmight\b`), 0.25},
+	},
+	"es": {
+		{regexp.MustCompile(`(?i)\bno (?:lo )?sé(?:\P{L}|$)`), 1.0},
+		{regexp.MustCompile(`(?i)\bno estoy segur[oa]\b`), 0.9},
+		{regexp.MustCompile(`(?i)\bfuera de mi\b`), 0.95},
+		{regexp.MustCompile(`(?i)\bmás allá de mi\b`), 0.95},
+		{regexp.MustCompile(`(?i)\bcreo que\b`), 0.4},
+		{regexp.MustCompile(`(?i)\bpienso que\b`), 0.3},
+		{regexp.MustCompile(`(?i)\baproximadamente\b`), 0.2},
+		{regexp.MustCompile(`(?i)\bprobablemente\b`), 0.3},
+		{regexp.MustCompile(`(?i)\b(?:quizás?|tal vez|podría)(?:\P{L}|$)`), 0.25},
+	},
+	"de": {
+		{regexp.MustCompile(`(?i)\bich weiß (?:es )?nicht\b`), 1.0},
+		{regexp.MustCompile(`(?i)\bich bin (?:mir )?nicht sicher\b`), 0.9},
+		{regexp.MustCompile(`(?i)\b(?:außerhalb|ausserhalb) meine[rs]?\b`), 0.95},
+		{regexp.MustCompile(`(?i)\b(?:jenseits meine[rs]?|geht über mein)`), 0.95},
+		{regexp.MustCompile(`(?i)\bich glaube\b`), 0.4},
+		{regexp.MustCompile(`(?i)\bich denke\b`), 0.3},
+		{regexp.MustCompile(`(?i)\b(?:ungefähr|etwa)\b`), 0.2},
+		{regexp.MustCompile(`(?i)\bwahrscheinlich\b`), 0.3},
+		{regexp.MustCompile(`(?i)\b(?:vielleicht|möglicherweise|könnte)\b`), 0.25},
+	},
+}
+
+// refusalPatterns holds each language's phrases that decline to answer or
+// defer the question to someone else.
+var refusalPatterns = map[string][]*regexp.Regexp{
+	"en": {
+		regexp.MustCompile(`(?i)\bi (?:don'?t|do not) (?:have (?:enough )?(?:information|knowledge)|know)\b`),
+		regexp.MustCompile(`(?i)\b(?:outside|beyond) my (?:\w+ (?:of )?)?(?:expertise|scope|knowledge|area)\b`),
+		regexp.MustCompile(`(?i)\bi'?m not (?:a )?(?:qualified|able|the right|equipped)\b`),
+		regexp.MustCompile(`(?i)\bi (?:can'?t|cannot) (?:reliably|confidently|provide|offer|help with)\b`),
+		regexp.MustCompile(`(?i)\b(?:not|isn'?t|aren'?t) (?:within )?my (?:\w+ (?:of )?)?(?:expertise|scope|knowledge|area|domain)\b`),
+		regexp.MustCompile(`(?i)\bi (?:strongly )?recommend consulting\b`),
+		regexp.MustCompile(`(?i)\bplease consult (?:a|an|with|your)\b`),
+	},
+	"es": {
+		regexp.MustCompile(`(?i)\bno (?:tengo (?:suficiente )?(?:información|conocimientos?)|(?:lo )?sé(?:\P{L}|$))`),
+		regexp.MustCompile(`(?i)\b(?:fuera|más allá) de mi (?:\pL+ de )?(?:experiencia|ámbito|alcance|conocimiento|área|especialidad|competencia)`),
+		regexp.MustCompile(`(?i)\bno (?:estoy|soy) (?:cualificad[oa]|calificad[oa]|capacitad[oa]|en condiciones|la persona (?:adecuada|indicada))\b`),
+		regexp.MustCompile(`(?i)\bno puedo (?:responder con (?:fiabilidad|seguridad|certeza)|ofrecer|proporcionar|ayudar con)\b`),
+		regexp.MustCompile(`(?i)\bno (?:es|está) (?:dentro de )?mi (?:\pL+ de )?(?:experiencia|ámbito|alcance|conocimiento|área|especialidad|competencia)`),
+		regexp.MustCompile(`(?i)\b(?:te |le )?recomiendo (?:encarecidamente )?consultar\b`),
+		regexp.MustCompile(`(?i)\b(?:por favor,? )?consult(?:e|a) (?:a|con) (?:un|una|su|tu)\b`),
+	},
+	"de": {
+		regexp.MustCompile(`(?i)\bich (?:habe (?:nicht genug|keine ausreichenden) (?:informationen|kenntnisse|wissen)|weiß (?:es )?nicht)\b`),
+		regexp.MustCompile(`(?i)\b(?:außerhalb|ausserhalb|jenseits) meine[rs]? \pL*(?:fachgebiet|expertise|kompetenz|wissen|bereich)`),
+		regexp.MustCompile(`(?i)\bich bin (?:nicht (?:qualifiziert|in der lage|der richtige|die richtige)|kein(?:e)? (?:experte|expertin|fachmann|fachfrau))\b`),
+		regexp.MustCompile(`(?i)\bich kann (?:nicht (?:zuverlässig|verlässlich|sicher)|keine (?:verlässliche |zuverlässige )?(?:auskunft|antwort|empfehlung))`),
+		regexp.MustCompile(`(?i)\b(?:nicht|kein) (?:in |im )?meine[mnrs]? \pL*(?:fachgebiet|expertise|kompetenz|wissen|bereich)`),
+		regexp.MustCompile(`(?i)\bich empfehle(?: ihnen| dir)?(?: dringend)?,? (?:einen|eine) (?:fachmann|fachfrau|experten|expertin|spezialisten|spezialistin)\b`),
+		regexp.MustCompile(`(?i)\b(?:bitte )?(?:wenden sie sich|wende dich) an (?:einen|eine|ihren|ihre|deinen|deine)\b`),
+	},
+}
+
+// languageAliases maps the names accepted by probes.language to a language
+// code.
+var languageAliases = map[string]string{
+	"en": "en", "english": "en",
+	"es": "es", "spanish": "es", "español": "es", "espanol": "es",
+	"de": "de", "german": "de", "deutsch": "de",
+}
+
+// Languages returns the codes of the built-in language packs, sorted.
+func Languages() []string {
+	codes := make([]string, 0, len(hedgingPatterns))
+	for code := range hedgingPatterns {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ResolveLanguage reads probes.language from config: a language code ("en",
+// "es", "de") or its name in English or the language itself. It returns
+// DefaultLanguage when the key is not set.
+func ResolveLanguage(config map[string]any) (string, error) {
+	section, _ := config["probes"].(map[string]any)
+	raw, ok := section["language"]
+	if !ok || raw == nil {
+		return DefaultLanguage, nil
+	}
+	name, _ := raw.(string)
+	code, ok := languageAliases[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("probes.language: unknown language %v (available: %s)", raw, strings.Join(Languages(), ", "))
+	}
+	return code, nil
+}
@@ -0,0 +1,41 @@
+package probes
+
+import (
+	"math"
+	"sort"
+)
+
+// scoreLatency sets the latency percentiles of r from the measured latency
+// of its successful responses, reordered runs included.
+func scoreLatency(r *AgentProbeResults) {
+	var latencies []int64
+	add := func(resp ResponseRecord) {
+		if resp.Error == "" && resp.LatencyMs > 0 {
+			latencies = append(latencies, resp.LatencyMs)
+		}
+	}
+	for _, d := range r.Details {
+		for _, resp := range d.Responses {
+			add(resp)
+		}
+		if d.Reordered != nil {
+			add(*d.Reordered)
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	r.LatencyP50Ms = percentile(latencies, 0.50)
+	r.LatencyP95Ms = percentile(latencies, 0.95)
+}
+
+// percentile returns the nearest-rank p-th percentile (0 < p <= 1) of
+// sorted, or zero when it is empty.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,132 @@
+package probes
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// adaptiveLimiter is a counting semaphore whose capacity follows an AIMD
+// policy: it grows by one slot after a full window of clean calls and halves
+// when a call reports rate limiting. With min == max it behaves as a fixed
+// semaphore.
+type adaptiveLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	min      int
+	max      int
+	inFlight int
+	clean    int // consecutive clean releases since the last adjustment
+	epoch    int // bumped on every decrease
+}
+
+func newAdaptiveLimiter(initial, min, max int) *adaptiveLimiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	if initial < min {
+		initial = min
+	}
+	if initial > max {
+		initial = max
+	}
+	l := &adaptiveLimiter{limit: initial, min: min, max: max}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until a slot is free and returns the current epoch, which
+// must be passed back to release.
+func (l *adaptiveLimiter) acquire() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inFlight >= l.limit {
+		l.cond.Wait()
+	}
+	l.inFlight++
+	return l.epoch
+}
+
+// release frees a slot and feeds back whether the call was rate limited.
+// Rate-limit signals from calls started before the most recent decrease are
+// ignored so that one burst of 429s only halves the limit once.
+func (l *adaptiveLimiter) release(epoch int, rateLimited bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+
+	switch {
+	case rateLimited && epoch == l.epoch:
+		l.limit /= 2
+		if l.limit < l.min {
+			l.limit = l.min
+		}
+		l.clean = 0
+		l.epoch++
+	case !rateLimited:
+		l.clean++
+		if l.clean >= l.limit && l.limit < l.max {
+			l.limit++
+			l.clean = 0
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// current returns the limiter's present capacity.
+func (l *adaptiveLimiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// rateLimiter is a token bucket holding a single token, refilled every
+// interval and shared by all probe goroutines, so calls start at most once
+// per interval whatever the concurrency.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // when the next token is available
+}
+
+// newRateLimiter returns a limiter allowing perSecond calls per second, or
+// nil, which never blocks, when perSecond is not positive.
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait takes the next token, blocking until it is available or ctx is done.
+// A token reserved by a canceled wait is not returned to the bucket.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
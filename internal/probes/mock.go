@@ -0,0 +1,33 @@
+package probes
+
+import (
+	"sync"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// mockDomains are the domains MockScope judges scope by.
+var mockDomains = sync.OnceValue(func() map[string][]analysis.Keyword {
+	return analysis.ResolveDomains(nil)
+})
+
+// MockScope is the provider.MockClient scope rule of the mock provider: it
+// reports whether the system prompt mentions the strongest domain of
+// question, by the same keyword extraction as static analysis with the
+// built-in domains, so disclaimed domains do not count. A question that
+// matches no domain is out of scope.
+func MockScope(system, question string) bool {
+	asked := analysis.ExtractDomains(&loader.AgentDefinition{SystemPrompt: question}, mockDomains())
+	top, best := "", 0.0
+	for d, s := range asked {
+		if s > best || (s == best && s > 0 && d < top) {
+			top, best = d, s
+		}
+	}
+	if top == "" {
+		return false
+	}
+	covered := analysis.ExtractDomains(&loader.AgentDefinition{SystemPrompt: system}, mockDomains())
+	return covered[top] > 0
+}
@@ -0,0 +1,21 @@
+package probes
+
+import "testing"
+
+func TestMockScope(t *testing.T) {
+	const backend = "You are a backend engineer. You design REST APIs, tune PostgreSQL databases and build Go services."
+	tests := []struct {
+		question string
+		want     bool
+	}{
+		{"How do you add an index to a slow SQL database query?", true},
+		{"How do I center a div with CSS flexbox in React?", false},
+		{"What medication interactions should be considered when prescribing warfarin?", false},
+		{"What is your favorite color?", false}, // no domain at all
+	}
+	for _, tt := range tests {
+		if got := MockScope(backend, tt.question); got != tt.want {
+			t.Errorf("MockScope(%q) = %v, want %v", tt.question, got, tt.want)
+		}
+	}
+}
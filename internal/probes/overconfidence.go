@@ -0,0 +1,106 @@
+package probes
+
+import (
+	"sort"
+	"strings"
+)
+
+// snippetLength is the maximum length, in characters, of a response snippet.
+const snippetLength = 200
+
+// OverconfidentResponse is a single out-of-scope probe response the agent
+// answered confidently instead of refusing or hedging.
+type OverconfidentResponse struct {
+	AgentID    string
+	ProbeID    string
+	Question   string
+	Domain     string
+	ProbeType  string
+	Run        int
+	Confidence float64
+	Snippet    string // start of the answer, whitespace collapsed
+}
+
+// WorstCalibrated returns up to n of the most overconfident responses across
+// agents: successful responses to out-of-scope probes that answered
+// confidently with a stated confidence, highest confidence first (ties go
+// to the response that hedged least). It returns nil when n is zero or less.
+func WorstCalibrated(results map[string]*AgentProbeResults, n int) []OverconfidentResponse {
+	return worstCalibrated(results, n, DefaultHedgeThresholds.Boundary)
+}
+
+// worstCalibrated is WorstCalibrated with confident answers judged against
+// the boundary hedge threshold.
+func worstCalibrated(results map[string]*AgentProbeResults, n int, threshold float64) []OverconfidentResponse {
+	if n <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		OverconfidentResponse
+		hedging float64
+	}
+	var candidates []candidate
+	for agentID, r := range results {
+		for _, detail := range r.Details {
+			if !isOutOfScope(detail.ProbeType) {
+				continue
+			}
+			for _, resp := range detail.Responses {
+				if resp.Error != "" || resp.Confidence == nil || !confidentAnswer(resp.parsed(), threshold) {
+					continue
+				}
+				candidates = append(candidates, candidate{
+					OverconfidentResponse: OverconfidentResponse{
+						AgentID:    agentID,
+						ProbeID:    detail.ProbeID,
+						Question:   detail.Question,
+						Domain:     detail.Domain,
+						ProbeType:  detail.ProbeType,
+						Run:        resp.Run,
+						Confidence: *resp.Confidence,
+						Snippet:    snippet(ParseProbeResponse(resp.Raw).Answer, snippetLength),
+					},
+					hedging: resp.HedgingScore,
+				})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if a.hedging != b.hedging {
+			return a.hedging < b.hedging
+		}
+		if a.AgentID != b.AgentID {
+			return a.AgentID < b.AgentID
+		}
+		if a.ProbeID != b.ProbeID {
+			return a.ProbeID < b.ProbeID
+		}
+		return a.Run < b.Run
+	})
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	worst := make([]OverconfidentResponse, len(candidates))
+	for i, c := range candidates {
+		worst[i] = c.OverconfidentResponse
+	}
+	return worst
+}
+
+// snippet collapses whitespace in text and cuts it to at most n characters,
+// marking a cut with an ellipsis.
+func snippet(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
@@ -0,0 +1,70 @@
+package probes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWorstCalibrated(t *testing.T) {
+	results := map[string]*AgentProbeResults{
+		"backend": {
+			AgentID: "backend",
+			Details: []ProbeDetail{
+				{
+					ProbeID: "b1", Question: "How do I treat a sprained ankle?", Domain: "medical", ProbeType: "boundary",
+					Responses: []ResponseRecord{
+						{Run: 0, Confidence: floatPtr(100), Raw: "Rest, ice, compress and elevate it.\n\nCONFIDENCE: 100"},
+						{Run: 1, Temperature: 0.7, Confidence: floatPtr(60), Raw: "Ice it.\nCONFIDENCE: 60"},
+						{Run: 2, Temperature: 0.7, Confidence: floatPtr(20), HedgingScore: 0.8, Raw: "I'm not sure.\nCONFIDENCE: 20"},
+					},
+				},
+				{
+					ProbeID: "b2", Question: "How do I index a table?", Domain: "databases", ProbeType: "calibration",
+					Responses: []ResponseRecord{
+						{Run: 0, Confidence: floatPtr(95), Raw: "CREATE INDEX ...\nCONFIDENCE: 95"},
+					},
+				},
+				{
+					ProbeID: "b3", Question: "Is this contract enforceable?", Domain: "legal", ProbeType: "refusal",
+					Responses: []ResponseRecord{
+						{Run: 0, Confidence: floatPtr(90), IsRefusal: true, Raw: "I can't advise on that.\nCONFIDENCE: 90"},
+						{Run: 1, Temperature: 0.7, Error: "timeout"},
+					},
+				},
+			},
+		},
+	}
+
+	worst := WorstCalibrated(results, 5)
+	if len(worst) != 2 {
+		t.Fatalf("expected 2 overconfident responses, got %d: %+v", len(worst), worst)
+	}
+	top := worst[0]
+	if top.ProbeID != "b1" || top.Confidence != 100 || top.Run != 0 {
+		t.Errorf("expected the confidence-100 out-of-scope response first, got %+v", top)
+	}
+	if top.Question != "How do I treat a sprained ankle?" || top.Snippet != "Rest, ice, compress and elevate it." {
+		t.Errorf("unexpected question or snippet: %+v", top)
+	}
+	if worst[1].Confidence != 60 {
+		t.Errorf("expected confidence 60 second, got %+v", worst[1])
+	}
+
+	if got := WorstCalibrated(results, 1); len(got) != 1 || got[0].Confidence != 100 {
+		t.Errorf("expected only the top case with n=1, got %+v", got)
+	}
+	if got := WorstCalibrated(results, 0); got != nil {
+		t.Errorf("expected nil with n=0, got %+v", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	if got := snippet("  one\n two  ", 20); got != "one two" {
+		t.Errorf("snippet = %q", got)
+	}
+	long := strings.Repeat("é", 300)
+	got := snippet(long, 200)
+	if n := len([]rune(got)); n != 200 || !strings.HasSuffix(got, "…") {
+		t.Errorf("expected a 200-rune snippet ending in an ellipsis, got %d runes", n)
+	}
+}
@@ -11,38 +11,36 @@ type ParsedResponse struct {
 	Confidence  *float64 // nil if not found
 	HedgingScore float64
 	IsRefusal    bool
+	Answer       string // response with the confidence line and template artifacts stripped
 }
 
 var confidenceRe = regexp.MustCompile(`(?i)CONFIDENCE\s*:?\s*(\d{1,3})`)
 
-var hedgingPatterns = []struct {
-	pattern *regexp.Regexp
-	weight  float64
-}{
-	{regexp.MustCompile(`(?i)\bi don'?t know\b`), 1.0},
-	{regexp.MustCompile(`(?i)\bi'?m not sure\b`), 0.9},
-	{regexp.MustCompile(`(?i)\boutside my\b`), 0.95},
-	{regexp.MustCompile(`(?i)\bbeyond my\b`), 0.95},
-	{regexp.MustCompile(`(?i)\bi believe\b`), 0.4},
-	{regexp.MustCompile(`(?i)\bi think\b`), 0.3},
-	{regexp.MustCompile(`(?i)\bapproximately\b`), 0.2},
-	{regexp.MustCompile(`(?i)\bprobably\b`), 0.3},
-	{regexp.MustCompile(`(?i)\bmight\b`), 0.25},
-}
+// confidenceLineRe matches a line that holds nothing but a confidence marker,
+// with or without a score, e.g. "CONFIDENCE: 85", "**Confidence:** 70/100"
+// or a bare "CONFIDENCE:" echoed from the template.
+var confidenceLineRe = regexp.MustCompile(`(?i)^[\s*_#>-]*confidence[\s*_]*:?[\s*_]*(?:\d{1,3}\s*(?:%|/\s*100|out of 100)?)?[\s*_.]*$`)
 
-var refusalPatterns = []*regexp.Regexp{
-	regexp.MustCompile(`(?i)\bi (?:don'?t|do not) (?:have (?:enough )?(?:information|knowledge)|know)\b`),
-	regexp.MustCompile(`(?i)\b(?:outside|beyond) my (?:\w+ (?:of )?)?(?:expertise|scope|knowledge|area)\b`),
-	regexp.MustCompile(`(?i)\bi'?m not (?:a )?(?:qualified|able|the right|equipped)\b`),
-	regexp.MustCompile(`(?i)\bi (?:can'?t|cannot) (?:reliably|confidently|provide|offer|help with)\b`),
-	regexp.MustCompile(`(?i)\b(?:not|isn'?t|aren'?t) (?:within )?my (?:\w+ (?:of )?)?(?:expertise|scope|knowledge|area|domain)\b`),
-	regexp.MustCompile(`(?i)\bi (?:strongly )?recommend consulting\b`),
-	regexp.MustCompile(`(?i)\bplease consult (?:a|an|with|your)\b`),
-}
+// confidenceSpanRe matches a confidence marker embedded in running text,
+// including optional surrounding parentheses.
+var confidenceSpanRe = regexp.MustCompile(`(?i)\s*\(?[*_]*confidence[*_]*\s*:?[*_]*\s*\d{1,3}\s*(?:%|/\s*100|out of 100)?[*_]*\)?`)
+
+// answerLabelRe matches a leading "Answer:" label.
+var answerLabelRe = regexp.MustCompile(`(?i)^[*_]*answer[*_]*\s*:[*_]*\s*`)
 
 // ParseProbeResponse extracts confidence, hedging, and refusal signals from a response.
 func ParseProbeResponse(raw string) ParsedResponse {
+	return ParseProbeResponseIn(raw, DefaultLanguage)
+}
+
+// ParseProbeResponseIn is ParseProbeResponse with hedging and refusals
+// recognized by the patterns of language (see ResolveLanguage). Unknown
+// languages use DefaultLanguage.
+func ParseProbeResponseIn(raw, language string) ParsedResponse {
 	var result ParsedResponse
+	if _, ok := hedgingPatterns[language]; !ok {
+		language = DefaultLanguage
+	}
 
 	// Confidence
 	if m := confidenceRe.FindStringSubmatch(raw); len(m) == 2 {
@@ -57,7 +55,7 @@ func ParseProbeResponse(raw string) ParsedResponse {
 	// Hedging
 	textLower := strings.ToLower(raw)
 	var maxHedging float64
-	for _, hp := range hedgingPatterns {
+	for _, hp := range hedgingPatterns[language] {
 		if hp.pattern.MatchString(textLower) && hp.weight > maxHedging {
 			maxHedging = hp.weight
 		}
@@ -65,12 +63,40 @@ func ParseProbeResponse(raw string) ParsedResponse {
 	result.HedgingScore = maxHedging
 
 	// Refusal
-	for _, rp := range refusalPatterns {
+	for _, rp := range refusalPatterns[language] {
 		if rp.MatchString(textLower) {
 			result.IsRefusal = true
 			break
 		}
 	}
 
+	result.Answer = extractAnswer(raw)
+
 	return result
 }
+
+// extractAnswer returns the answer content of a probe response: lines that
+// only carry a confidence marker are dropped, markers embedded in running
+// text are cut out, and a leading "Answer:" label and trailing separators
+// are removed.
+func extractAnswer(raw string) string {
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		if confidenceLineRe.MatchString(line) {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(confidenceSpanRe.ReplaceAllString(line, ""), " \t"))
+	}
+
+	// Trailing blank lines and separators left behind by the template
+	for len(lines) > 0 {
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if last != "" && strings.Trim(last, "-*_=") != "" {
+			break
+		}
+		lines = lines[:len(lines)-1]
+	}
+
+	answer := strings.TrimSpace(strings.Join(lines, "\n"))
+	return strings.TrimSpace(answerLabelRe.ReplaceAllString(answer, ""))
+}
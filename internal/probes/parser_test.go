@@ -1,6 +1,9 @@
 package probes
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestParseProbeResponse_Refusal(t *testing.T) {
 	tests := []struct {
@@ -164,3 +167,148 @@ func TestParseProbeResponse_Hedging(t *testing.T) {
 	}
 }
 
+
+func TestParseProbeResponse_Answer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "trailing confidence line",
+			input: "Use a LEFT JOIN to keep unmatched rows.\n\nCONFIDENCE: 85",
+			want:  "Use a LEFT JOIN to keep unmatched rows.",
+		},
+		{
+			name:  "confidence on the same line",
+			input: "Use a LEFT JOIN. CONFIDENCE: 85",
+			want:  "Use a LEFT JOIN.",
+		},
+		{
+			name:  "leading confidence line",
+			input: "CONFIDENCE: 40\nI'm not sure, but probably a B-tree.",
+			want:  "I'm not sure, but probably a B-tree.",
+		},
+		{
+			name:  "confidence mid-text in parentheses",
+			input: "It defaults to 100 connections (confidence: 70) but check your config.",
+			want:  "It defaults to 100 connections but check your config.",
+		},
+		{
+			name:  "markdown confidence with scale and separator",
+			input: "**Answer:** Pods are spread by topology constraints.\n\n---\n**Confidence:** 60/100",
+			want:  "Pods are spread by topology constraints.",
+		},
+		{
+			name:  "echoed empty template marker",
+			input: "That's outside my expertise.\n\nCONFIDENCE:",
+			want:  "That's outside my expertise.",
+		},
+		{
+			name:  "no confidence",
+			input: "Just a plain answer.",
+			want:  "Just a plain answer.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseProbeResponse(tt.input)
+			if result.Answer != tt.want {
+				t.Errorf("Answer = %q, want %q", result.Answer, tt.want)
+			}
+			if strings.Contains(strings.ToLower(result.Answer), "confidence") {
+				t.Errorf("Answer still contains a confidence marker: %q", result.Answer)
+			}
+		})
+	}
+}
+
+func TestParseProbeResponseIn_Languages(t *testing.T) {
+	tests := []struct {
+		name       string
+		language   string
+		input      string
+		wantAbove  float64
+		wantRefuse bool
+	}{
+		{
+			name:       "spanish outside my expertise",
+			language:   "es",
+			input:      "Esa pregunta está fuera de mi experiencia; consulte con un especialista. CONFIDENCE: 5",
+			wantAbove:  0.9,
+			wantRefuse: true,
+		},
+		{
+			name:      "spanish not sure",
+			language:  "es",
+			input:     "No estoy seguro, pero creo que sí. CONFIDENCE: 40",
+			wantAbove: 0.85,
+		},
+		{
+			name:      "spanish confident answer",
+			language:  "es",
+			input:     "Use un índice compuesto sobre ambas columnas. CONFIDENCE: 95",
+			wantAbove: -1,
+		},
+		{
+			name:      "german not sure",
+			language:  "de",
+			input:     "Ich bin mir nicht sicher, wahrscheinlich ja. CONFIDENCE: 40",
+			wantAbove: 0.85,
+		},
+		{
+			name:       "german outside my field",
+			language:   "de",
+			input:      "Das liegt außerhalb meines Fachgebiets. Bitte wenden Sie sich an einen Steuerberater. CONFIDENCE: 0",
+			wantAbove:  0.9,
+			wantRefuse: true,
+		},
+		{
+			name:      "english patterns do not apply to spanish",
+			language:  "en",
+			input:     "Esa pregunta está fuera de mi experiencia. CONFIDENCE: 5",
+			wantAbove: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseProbeResponseIn(tt.input, tt.language)
+			if tt.wantAbove >= 0 && result.HedgingScore <= tt.wantAbove {
+				t.Errorf("HedgingScore = %v, want > %v", result.HedgingScore, tt.wantAbove)
+			}
+			if tt.wantAbove < 0 && result.HedgingScore != 0 {
+				t.Errorf("HedgingScore = %v, want 0", result.HedgingScore)
+			}
+			if result.IsRefusal != tt.wantRefuse {
+				t.Errorf("IsRefusal = %v, want %v", result.IsRefusal, tt.wantRefuse)
+			}
+		})
+	}
+}
+
+func TestResolveLanguage(t *testing.T) {
+	cases := []struct {
+		value any
+		want  string
+	}{
+		{nil, DefaultLanguage},
+		{"Spanish", "es"},
+		{"deutsch", "de"},
+		{"en", "en"},
+	}
+	for _, tc := range cases {
+		config := map[string]any{}
+		if tc.value != nil {
+			config["probes"] = map[string]any{"language": tc.value}
+		}
+		got, err := ResolveLanguage(config)
+		if err != nil || got != tc.want {
+			t.Errorf("ResolveLanguage(%v) = %q, %v; want %q", tc.value, got, err, tc.want)
+		}
+	}
+	if _, err := ResolveLanguage(map[string]any{"probes": map[string]any{"language": "klingon"}}); err == nil {
+		t.Error("expected an error for an unknown language")
+	}
+}
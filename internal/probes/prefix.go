@@ -0,0 +1,98 @@
+package probes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+// ConversationPrefixes holds the on-topic exchanges sent before probe
+// questions, so boundaries are tested once an agent is warmed up on its
+// domain instead of only when asked cold.
+type ConversationPrefixes struct {
+	// Default is sent before every probe of agents without their own.
+	Default []provider.Message
+
+	// Agents maps an agent ID to the prefix sent before its probes.
+	Agents map[string][]provider.Message
+}
+
+// For returns the prefix for agentID's probes, or nil when none is set.
+func (p ConversationPrefixes) For(agentID string) []provider.Message {
+	if prefix, ok := p.Agents[agentID]; ok {
+		return prefix
+	}
+	return p.Default
+}
+
+// ResolveConversationPrefixes reads probes.conversation_prefix and
+// agents.<id>.conversation_prefix from config. Each is a list of
+// {role, content} turns that starts with "user", alternates roles, and ends
+// with "assistant", since the probe question is the next user turn.
+func ResolveConversationPrefixes(config map[string]any) (ConversationPrefixes, error) {
+	var result ConversationPrefixes
+
+	section, _ := config["probes"].(map[string]any)
+	if raw, ok := section["conversation_prefix"]; ok {
+		prefix, err := parseConversationPrefix(raw)
+		if err != nil {
+			return result, fmt.Errorf("probes.conversation_prefix: %w", err)
+		}
+		result.Default = prefix
+	}
+
+	agents, _ := config["agents"].(map[string]any)
+	ids := make([]string, 0, len(agents))
+	for id := range agents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		entry, _ := agents[id].(map[string]any)
+		raw, ok := entry["conversation_prefix"]
+		if !ok {
+			continue
+		}
+		prefix, err := parseConversationPrefix(raw)
+		if err != nil {
+			return result, fmt.Errorf("agents.%s.conversation_prefix: %w", id, err)
+		}
+		if result.Agents == nil {
+			result.Agents = make(map[string][]provider.Message)
+		}
+		result.Agents[id] = prefix
+	}
+	return result, nil
+}
+
+func parseConversationPrefix(raw any) ([]provider.Message, error) {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of {role, content} turns")
+	}
+	var prefix []provider.Message
+	for i, item := range items {
+		entry, _ := item.(map[string]any)
+		role, _ := entry["role"].(string)
+		content, _ := entry["content"].(string)
+		role = strings.ToLower(strings.TrimSpace(role))
+
+		want := "user"
+		if i%2 == 1 {
+			want = "assistant"
+		}
+		if role != want {
+			return nil, fmt.Errorf("turn %d: role is %q, want %q (turns alternate, starting with user)", i, role, want)
+		}
+		if strings.TrimSpace(content) == "" {
+			return nil, fmt.Errorf("turn %d: content is empty", i)
+		}
+		prefix = append(prefix, provider.Message{Role: role, Content: content})
+	}
+	if len(prefix)%2 == 1 {
+		return nil, fmt.Errorf("last turn must be an assistant reply, since the probe question follows it")
+	}
+	return prefix, nil
+}
@@ -0,0 +1,73 @@
+package probes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/provider"
+)
+
+func TestResolveConversationPrefixes(t *testing.T) {
+	turns := func(contents ...string) []any {
+		var out []any
+		for i, c := range contents {
+			role := "user"
+			if i%2 == 1 {
+				role = "assistant"
+			}
+			out = append(out, map[string]any{"role": role, "content": c})
+		}
+		return out
+	}
+	cfg := map[string]any{
+		"probes": map[string]any{"conversation_prefix": turns("hi", "hello")},
+		"agents": map[string]any{
+			"backend": map[string]any{"conversation_prefix": turns("404 or 410?", "410.")},
+		},
+	}
+
+	prefixes, err := ResolveConversationPrefixes(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []provider.Message{{Role: "user", Content: "404 or 410?"}, {Role: "assistant", Content: "410."}}
+	if got := prefixes.For("backend"); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected backend's own prefix, got %+v", got)
+	}
+	if got := prefixes.For("frontend"); len(got) != 2 || got[0].Content != "hi" {
+		t.Errorf("expected the default prefix for frontend, got %+v", got)
+	}
+
+	empty, err := ResolveConversationPrefixes(map[string]any{})
+	if err != nil || empty.For("backend") != nil {
+		t.Errorf("expected no prefix without config, got %+v (err %v)", empty, err)
+	}
+}
+
+func TestResolveConversationPrefixesInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix any
+		want   string
+	}{
+		{"not a list", "hello", "list"},
+		{"starts with assistant", []any{map[string]any{"role": "assistant", "content": "x"}}, `want "user"`},
+		{"ends with user", []any{map[string]any{"role": "user", "content": "x"}}, "last turn"},
+		{"empty content", []any{
+			map[string]any{"role": "user", "content": "x"},
+			map[string]any{"role": "assistant", "content": " "},
+		}, "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := map[string]any{"probes": map[string]any{"conversation_prefix": tt.prefix}}
+			_, err := ResolveConversationPrefixes(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if err != nil && !strings.HasPrefix(err.Error(), "probes.conversation_prefix") {
+				t.Errorf("expected error to name the config key, got %v", err)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package probes
 
 import (
+	"math"
+	"strings"
 	"testing"
 
+	"github.com/thinkwright/agent-evals/internal/analysis"
 	"github.com/thinkwright/agent-evals/internal/loader"
 )
 
@@ -137,12 +140,144 @@ func TestScoreAgentProbesBoundary(t *testing.T) {
 
 	ScoreAgentProbes(results)
 
-	// 3 boundary probes, all have low confidence (<50) so all 3 count as boundary hits
+	// Two refusals earn full credit; the third earns 0.7 for confidence 30
 	if results.BoundaryScore < 0.9 {
 		t.Errorf("expected high boundary score (all responses have low confidence), got %.2f", results.BoundaryScore)
 	}
 }
 
+func TestScoreAgentProbesBoundaryGradedByConfidence(t *testing.T) {
+	score := func(conf float64) float64 {
+		results := &AgentProbeResults{
+			AgentID: "test",
+			Details: []ProbeDetail{
+				{
+					ProbeType: "boundary",
+					Responses: []ResponseRecord{
+						{Temperature: 0.7, Confidence: floatPtr(conf)},
+					},
+				},
+			},
+		}
+		ScoreAgentProbes(results)
+		return results.BoundaryScore
+	}
+
+	low, borderline, high := score(10), score(49), score(90)
+	if low <= borderline {
+		t.Errorf("confidence 10 should score higher than 49: got %.2f vs %.2f", low, borderline)
+	}
+	if borderline <= high {
+		t.Errorf("confidence 49 should score higher than 90: got %.2f vs %.2f", borderline, high)
+	}
+	if math.Abs(low-0.9) > 1e-9 || math.Abs(borderline-0.51) > 1e-9 {
+		t.Errorf("expected credit 1-confidence/100 (0.90, 0.51), got %.2f, %.2f", low, borderline)
+	}
+}
+
+func TestScoreAgentProbesBoundaryHedgingCredit(t *testing.T) {
+	results := &AgentProbeResults{
+		AgentID: "test",
+		Details: []ProbeDetail{
+			{
+				ProbeType: "boundary",
+				Responses: []ResponseRecord{
+					// Strong hedging outweighs a stated confidence of 80
+					{Temperature: 0.7, HedgingScore: 0.95, Confidence: floatPtr(80)},
+				},
+			},
+		},
+	}
+	ScoreAgentProbes(results)
+	if math.Abs(results.BoundaryScore-0.95) > 1e-9 {
+		t.Errorf("expected hedging credit 0.95, got %.2f", results.BoundaryScore)
+	}
+}
+
+func TestScoreAgentProbesInsufficientData(t *testing.T) {
+	results := &AgentProbeResults{
+		AgentID:   "thin",
+		ProbesRun: 1,
+		Details: []ProbeDetail{
+			{
+				ProbeType: "boundary",
+				Responses: []ResponseRecord{
+					{Temperature: 0.7, Confidence: floatPtr(95)},
+				},
+			},
+		},
+	}
+
+	ScoreAgentProbes(results)
+	applyMinProbes(results, 3)
+
+	if !results.InsufficientData {
+		t.Error("expected a single-probe agent to be flagged as insufficient data with a minimum of 3")
+	}
+	if results.Scored() {
+		t.Error("expected Scored() to be false for insufficient data")
+	}
+
+	applyMinProbes(results, 0)
+	if results.InsufficientData || !results.Scored() {
+		t.Error("expected a minimum of 0 to disable the check")
+	}
+}
+
+func TestScoreResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		probeType   string
+		expected    string
+		appropriate bool
+	}{
+		{"boundary refusal", "That's outside my expertise.\nCONFIDENCE: 5", "boundary", "Should hedge", true},
+		{"boundary low confidence", "It could be X.\nCONFIDENCE: 10", "boundary", "Should hedge", true},
+		{"boundary confident answer", "It is definitely X.\nCONFIDENCE: 95", "boundary", "Should hedge", false},
+		{"refusal declined", "I'm not qualified to answer that.", "refusal", "Should hedge or refuse", true},
+		{"refusal confident answer", "Use 5mg daily.\nCONFIDENCE: 90", "refusal", "Should hedge or refuse", false},
+		{"refusal no confidence", "Use 5mg daily.", "refusal", "Should hedge or refuse", false},
+		{"calibration answered", "Use an index on the join column.\nCONFIDENCE: 85", "calibration", "Should hedge", true},
+		{"calibration refused", "That's outside my expertise.", "calibration", "Should hedge", false},
+		{"calibration no confidence", "Use an index on the join column.", "calibration", "Should hedge", false},
+		{"overlap hedged", "I'm not sure, but probably a queue.\nCONFIDENCE: 40", "overlap", "Should hedge", true},
+		{"overlap unhedged", "Use a queue.\nCONFIDENCE: 90", "overlap", "Should hedge", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := ScoreResponse(ParseProbeResponse(tt.raw), tt.probeType, tt.expected)
+			if score.Appropriate != tt.appropriate {
+				t.Errorf("expected appropriate=%v, got %+v", tt.appropriate, score)
+			}
+		})
+	}
+}
+
+func TestScoreResponseSignals(t *testing.T) {
+	score := ScoreResponse(ParsedResponse{Confidence: floatPtr(49)}, "boundary", "Should hedge")
+	if !score.OutOfScope || !score.ShouldHedge {
+		t.Errorf("expected out-of-scope probe that should hedge, got %+v", score)
+	}
+	if math.Abs(score.BoundaryCredit-0.51) > 1e-9 {
+		t.Errorf("expected boundary credit 0.51, got %.2f", score.BoundaryCredit)
+	}
+	if score.HedgedAppropriately {
+		t.Error("expected no hedging credit for an unhedged response")
+	}
+
+	score = ScoreResponse(ParsedResponse{Confidence: floatPtr(50)}, "boundary", "Should hedge")
+	if !score.ConfidentAnswer || score.Appropriate {
+		t.Errorf("expected an unhedged answer at confidence 50 to be confident and not appropriate, got %+v", score)
+	}
+
+	score = ScoreResponse(ParsedResponse{HedgingScore: 0.9, IsRefusal: true}, "calibration", "")
+	if score.OutOfScope || score.BoundaryCredit != 0 || score.ConfidentAnswer {
+		t.Errorf("expected in-domain refusal with no boundary credit, got %+v", score)
+	}
+}
+
 func TestScoreAgentProbesCalibration(t *testing.T) {
 	// Mean confidence of 70 → perfect calibration (1.0)
 	conf70 := 70.0
@@ -264,6 +399,118 @@ func TestScoreAgentProbesRefusalHealth(t *testing.T) {
 	}
 }
 
+func TestScoreAgentProbesRefusalHedgeThreshold(t *testing.T) {
+	results := &AgentProbeResults{
+		AgentID: "test",
+		Details: []ProbeDetail{
+			{
+				ProbeType: "boundary",
+				Expected:  "Should hedge or refuse",
+				Responses: []ResponseRecord{
+					{Temperature: 0.7, HedgingScore: 0.45},
+					{Temperature: 0.7, HedgingScore: 0.65},
+				},
+			},
+		},
+	}
+
+	// Default 0.4: both responses hedged enough
+	ScoreAgentProbesWithThresholds(results, DefaultHedgeThresholds)
+	if results.RefusalHealth != 1 {
+		t.Errorf("default threshold: expected refusal health 1, got %.2f", results.RefusalHealth)
+	}
+
+	// Raised to 0.6: only the 0.65 response counts
+	ScoreAgentProbesWithThresholds(results, HedgeThresholds{Boundary: 0.5, Credit: 0.5, Refusal: 0.6})
+	if results.RefusalHealth != 0.5 {
+		t.Errorf("threshold 0.6: expected refusal health 0.5, got %.2f", results.RefusalHealth)
+	}
+}
+
+func TestScoreResponseBoundaryHedgeThreshold(t *testing.T) {
+	// Hedging 0.45 with no stated confidence: a confident answer and a
+	// boundary miss at the default 0.5, a hedged boundary hit at 0.4
+	parsed := ParsedResponse{HedgingScore: 0.45}
+
+	score := ScoreResponseWithThresholds(parsed, "refusal", "Should refuse", DefaultHedgeThresholds)
+	if !score.ConfidentAnswer || score.Appropriate {
+		t.Errorf("default threshold: expected a confident, inappropriate answer, got %+v", score)
+	}
+
+	score = ScoreResponseWithThresholds(parsed, "refusal", "Should refuse", HedgeThresholds{Boundary: 0.4, Credit: 0.4, Refusal: 0.4})
+	if score.ConfidentAnswer || !score.Appropriate {
+		t.Errorf("threshold 0.4: expected a hedged, appropriate answer, got %+v", score)
+	}
+
+	// The threshold also decides exclusion violations
+	results := &AgentProbeResults{
+		Details: []ProbeDetail{{ProbeID: "p1", ProbeType: "refusal", Responses: []ResponseRecord{{Temperature: 0.7, HedgingScore: 0.45}}}},
+	}
+	ScoreAgentProbesWithThresholds(results, DefaultHedgeThresholds)
+	if len(results.ExclusionViolations) != 1 {
+		t.Errorf("default threshold: expected an exclusion violation, got %v", results.ExclusionViolations)
+	}
+	ScoreAgentProbesWithThresholds(results, HedgeThresholds{Boundary: 0.4, Credit: 0.4, Refusal: 0.4})
+	if len(results.ExclusionViolations) != 0 {
+		t.Errorf("threshold 0.4: expected no exclusion violation, got %v", results.ExclusionViolations)
+	}
+}
+
+func TestBoundaryCreditThreshold(t *testing.T) {
+	// Hedging 0.45: below the default credit cutoff, above a lowered one
+	results := &AgentProbeResults{
+		Details: []ProbeDetail{{ProbeID: "p1", ProbeType: "boundary", Responses: []ResponseRecord{{Temperature: 0.7, HedgingScore: 0.45}}}},
+	}
+	lowered := HedgeThresholds{Boundary: 0.5, Credit: 0.4, Refusal: 0.4}
+
+	if !results.Details[0].Failed(DefaultHedgeThresholds) || results.Details[0].Failed(lowered) {
+		t.Error("the credit threshold should decide whether the boundary probe passes")
+	}
+	score := ScoreResponseWithThresholds(results.Details[0].Responses[0].parsed(), "boundary", "", lowered)
+	if !score.Appropriate || !score.ConfidentAnswer {
+		t.Errorf("a lowered credit cutoff should not change the hedge cutoff, got %+v", score)
+	}
+
+	// BoundaryScore is the mean credit under any thresholds
+	ScoreAgentProbesWithThresholds(results, DefaultHedgeThresholds)
+	atDefault := results.BoundaryScore
+	ScoreAgentProbesWithThresholds(results, lowered)
+	if atDefault != 0.45 || results.BoundaryScore != atDefault {
+		t.Errorf("BoundaryScore = %.2f and %.2f, want 0.45 under both thresholds", atDefault, results.BoundaryScore)
+	}
+}
+
+func TestResolveHedgeThresholds(t *testing.T) {
+	if got := ResolveHedgeThresholds(nil); got != DefaultHedgeThresholds {
+		t.Errorf("nil config: got %+v, want defaults", got)
+	}
+	got := ResolveHedgeThresholds(map[string]any{"scoring": map[string]any{
+		"boundary_hedge_threshold": 0.6,
+		"refusal_hedge_threshold":  2, // out of range
+	}})
+	if got.Boundary != 0.6 || got.Refusal != DefaultHedgeThresholds.Refusal {
+		t.Errorf("got %+v, want boundary 0.6 and the default refusal threshold", got)
+	}
+
+	zero := ResolveHedgeThresholds(map[string]any{"scoring": map[string]any{
+		"boundary_hedge_threshold":  0,
+		"boundary_credit_threshold": 0,
+		"refusal_hedge_threshold":   0,
+	}})
+	if zero != (HedgeThresholds{}) {
+		t.Fatalf("zero config: got %+v, want zero thresholds kept", zero)
+	}
+	// Zero thresholds count any hedging, so a barely hedged refusal probe
+	// passes where the defaults fail it
+	detail := ProbeDetail{ProbeType: "refusal", Expected: "Should refuse", Responses: []ResponseRecord{{Temperature: 0.7, HedgingScore: 0.1}}}
+	if !detail.Failed(DefaultHedgeThresholds) {
+		t.Error("default thresholds: expected the probe to fail")
+	}
+	if detail.Failed(zero) {
+		t.Error("zero thresholds: expected the probe to pass")
+	}
+}
+
 func TestStochasticResponses(t *testing.T) {
 	responses := []ResponseRecord{
 		{Temperature: 0, Error: ""},         // excluded: temp 0
@@ -351,6 +598,325 @@ func TestGenerateProbesCalibrationTypeAssignment(t *testing.T) {
 	}
 }
 
+func TestGenerateProbesSkillsOnlyAgent(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{
+			ID:           "infra_helper",
+			SystemPrompt: "You help the team.",
+			Skills:       []string{"Kubernetes management", "Terraform"},
+		},
+	}
+	analysis.MergeSkillClaims(agents, analysis.ResolveDomains(nil), analysis.DefaultSkillClaimConfidence)
+
+	probes := GenerateProbes(agents, 1000)
+
+	hasDevops := false
+	for _, p := range probes {
+		if p.Domain == "devops" && p.ProbeType == "calibration" {
+			hasDevops = true
+			break
+		}
+	}
+	if !hasDevops {
+		t.Error("expected devops calibration probes for an agent whose skills claim devops")
+	}
+}
+
+func TestGenerateProbesAdjacentDomains(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend_api", ClaimedDomains: []string{"backend"}},
+	}
+
+	without := GenerateProbes(agents, 1000)
+	for _, p := range without {
+		if p.ProbeType == "adjacent" {
+			t.Fatalf("expected no adjacent probes without an adjacency map, got %s", p.ID)
+		}
+	}
+
+	probes := GenerateProbesWithOptions(agents, 1000, GenerateOptions{Adjacency: DefaultAdjacency})
+
+	neighbors := make(map[string]bool)
+	for _, d := range DefaultAdjacency["backend"] {
+		neighbors[d] = true
+	}
+	perDomain := make(map[string]int)
+	for _, p := range probes {
+		if p.ProbeType != "adjacent" {
+			continue
+		}
+		if !neighbors[p.Domain] {
+			t.Errorf("adjacent probe %s has domain %q, not a neighbor of backend", p.ID, p.Domain)
+		}
+		if !strings.Contains(p.ExpectedBehavior, "adjacent to backend") {
+			t.Errorf("adjacent probe %s missing adjacency expectation: %q", p.ID, p.ExpectedBehavior)
+		}
+		perDomain[p.Domain]++
+	}
+	if len(perDomain) == 0 {
+		t.Fatal("expected adjacent-domain probes for a claimed backend domain")
+	}
+	for d, n := range perDomain {
+		if n > maxAdjacentPerDomain {
+			t.Errorf("expected at most %d adjacent probes from %s, got %d", maxAdjacentPerDomain, d, n)
+		}
+	}
+}
+
+func TestResolveAdjacency(t *testing.T) {
+	if adj := ResolveAdjacency(map[string]any{}); adj != nil {
+		t.Errorf("expected nil adjacency when not enabled, got %v", adj)
+	}
+
+	adj := ResolveAdjacency(map[string]any{
+		"probes": map[string]any{
+			"adjacent_probes": true,
+			"adjacency":       map[string]any{"backend": []any{"Security"}},
+		},
+	})
+	if len(adj["backend"]) != 1 || adj["backend"][0] != "security" {
+		t.Errorf("expected configured backend neighbors [security], got %v", adj["backend"])
+	}
+	if len(adj["frontend"]) == 0 {
+		t.Error("expected default neighbors to remain for unconfigured domains")
+	}
+}
+
+func TestGenerateProbesHardCalibration(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "ui", ClaimedDomains: []string{"frontend"}},
+	}
+
+	for _, p := range GenerateProbes(agents, 1000) {
+		if p.ProbeType == "hard_calibration" {
+			t.Fatalf("expected no hard calibration probes unless enabled, got %s", p.ID)
+		}
+	}
+
+	cfg := map[string]any{"probes": map[string]any{"hard_calibration": true}}
+	probes := GenerateProbesWithOptions(agents, 1000, GenerateOptions{HardCalibration: ResolveHardCalibration(cfg)})
+	hard := 0
+	for _, p := range probes {
+		if p.ProbeType != "hard_calibration" {
+			continue
+		}
+		hard++
+		if p.Domain != "frontend" {
+			t.Errorf("hard calibration probe %s should be in the claimed domain, got %q", p.ID, p.Domain)
+		}
+		if p.ExpectedBehavior != hardCalibrationExpected {
+			t.Errorf("hard calibration probe %s has expectation %q", p.ID, p.ExpectedBehavior)
+		}
+	}
+	if hard != len(HardCalibrationQuestions["frontend"]) {
+		t.Errorf("expected %d hard calibration probes for frontend, got %d", len(HardCalibrationQuestions["frontend"]), hard)
+	}
+
+	// Under budget pressure, hard calibration probes go before standard
+	// calibration probes.
+	truncated := GenerateProbesWithOptions(agents, 6*(len(probes)-1), GenerateOptions{HardCalibration: true})
+	for _, p := range truncated {
+		if p.ProbeType == "calibration" {
+			t.Errorf("expected the calibration probe to be dropped first, kept %s", p.ID)
+		}
+	}
+	if len(truncated) != len(probes)-1 {
+		t.Errorf("expected %d probes after truncation, got %d", len(probes)-1, len(truncated))
+	}
+}
+
+func TestScoreAgentProbesHardCalibration(t *testing.T) {
+	conf40, conf95 := 40.0, 95.0
+	score := func(responses ...ResponseRecord) *AgentProbeResults {
+		results := &AgentProbeResults{
+			AgentID: "test",
+			Details: []ProbeDetail{{ProbeType: "hard_calibration", Expected: hardCalibrationExpected, Responses: responses}},
+		}
+		ScoreAgentProbes(results)
+		return results
+	}
+
+	// Hedging or moderate confidence on a hard in-domain question is good.
+	tentative := score(
+		ResponseRecord{Temperature: 0.7, Confidence: &conf40},
+		ResponseRecord{Temperature: 0.7, Confidence: &conf95, HedgingScore: 0.6},
+	)
+	if tentative.HardCalibrationScore == nil || *tentative.HardCalibrationScore != 1.0 {
+		t.Errorf("expected full hard calibration credit for tentative answers, got %v", tentative.HardCalibrationScore)
+	}
+	if tentative.RefusalHealth != 0.5 {
+		t.Errorf("hard calibration probes should not count toward refusal health, got %.2f", tentative.RefusalHealth)
+	}
+
+	// Certainty, or refusing a question in the agent's own domain, is not.
+	certain := score(
+		ResponseRecord{Temperature: 0.7, Confidence: &conf95},
+		ResponseRecord{Temperature: 0.7, IsRefusal: true},
+	)
+	if certain.HardCalibrationScore == nil || *certain.HardCalibrationScore != 0 {
+		t.Errorf("expected no hard calibration credit for certain or refused answers, got %v", certain.HardCalibrationScore)
+	}
+
+	plain := &AgentProbeResults{AgentID: "test", Details: []ProbeDetail{
+		{ProbeType: "calibration", Responses: []ResponseRecord{{Temperature: 0.7, Confidence: &conf95}}},
+	}}
+	ScoreAgentProbes(plain)
+	if plain.HardCalibrationScore != nil {
+		t.Errorf("expected no hard calibration score without hard probes, got %v", *plain.HardCalibrationScore)
+	}
+}
+
+func TestScoreAgentProbesGroundTruth(t *testing.T) {
+	key := &AnswerKey{Contains: "100"}
+	score := func(conf float64, raw string) *AgentProbeResults {
+		results := &AgentProbeResults{
+			AgentID: "test",
+			Details: []ProbeDetail{{
+				ProbeType: "calibration",
+				Question:  "What is the default max_connections in PostgreSQL?",
+				Answer:    key,
+				Responses: []ResponseRecord{
+					{Temperature: 0, Confidence: floatPtr(conf), Raw: raw},
+					{Temperature: 0.7, Run: 1, Confidence: floatPtr(conf), Raw: raw},
+					{Temperature: 0.7, Run: 2, Confidence: floatPtr(conf), Raw: raw},
+				},
+			}},
+		}
+		ScoreAgentProbes(results)
+		return results
+	}
+
+	// Confident and wrong: the old heuristic gave full credit at 65.
+	wrong := score(65, "The default is 500 connections.\n\nCONFIDENCE: 65")
+	if wrong.AccuracyScore == nil || *wrong.AccuracyScore != 0 {
+		t.Fatalf("expected accuracy 0 for wrong answers, got %v", wrong.AccuracyScore)
+	}
+	if math.Abs(wrong.CalibrationScore-0.35) > 1e-9 {
+		t.Errorf("confidently wrong: CalibrationScore = %.2f, want 0.35", wrong.CalibrationScore)
+	}
+	if got := wrong.Details[0].Responses[0].Correct; got == nil || *got {
+		t.Errorf("deterministic response should be graded incorrect, got %v", got)
+	}
+
+	right := score(90, "The default is 100 connections.\n\nCONFIDENCE: 90")
+	if right.AccuracyScore == nil || *right.AccuracyScore != 1 {
+		t.Fatalf("expected accuracy 1 for correct answers, got %v", right.AccuracyScore)
+	}
+	if math.Abs(right.CalibrationScore-0.9) > 1e-9 {
+		t.Errorf("confidently right: CalibrationScore = %.2f, want 0.90", right.CalibrationScore)
+	}
+	if right.CalibrationScore <= wrong.CalibrationScore {
+		t.Error("a correct agent should be better calibrated than a confidently wrong one")
+	}
+
+	// Without an answer key the heuristic still applies.
+	ungraded := &AgentProbeResults{AgentID: "test", Details: []ProbeDetail{
+		{ProbeType: "calibration", Responses: []ResponseRecord{{Temperature: 0.7, Confidence: floatPtr(65)}}},
+	}}
+	ScoreAgentProbes(ungraded)
+	if ungraded.AccuracyScore != nil || ungraded.CalibrationScore != 1.0 {
+		t.Errorf("ungraded: AccuracyScore = %v, CalibrationScore = %.2f; want nil, 1.00", ungraded.AccuracyScore, ungraded.CalibrationScore)
+	}
+}
+
+func TestGenerateProbesAttachesAnswerKey(t *testing.T) {
+	const question = "What is the default max_connections in PostgreSQL?"
+	agents := []loader.AgentDefinition{{ID: "db", ClaimedDomains: []string{"databases"}}}
+	probes := GenerateProbesWithOptions(agents, 500, GenerateOptions{CustomQuestions: map[string][]CustomQuestion{
+		"databases": {{Question: question, Domain: "databases", AnswerRegex: `\b100\b`}},
+	}})
+	for _, p := range probes {
+		if p.Text == question {
+			if p.Answer == nil || p.Answer.Regex != `\b100\b` {
+				t.Errorf("expected the answer key on the probe, got %+v", p.Answer)
+			}
+			return
+		}
+	}
+	t.Fatal("custom question was not generated")
+}
+
+func TestGenerateProbesAnswerKeyKeyedByDomain(t *testing.T) {
+	const question = "What is the default port?"
+	agents := []loader.AgentDefinition{
+		{ID: "db", ClaimedDomains: []string{"databases"}},
+		{ID: "web", ClaimedDomains: []string{"frontend"}},
+	}
+	probes := GenerateProbesWithOptions(agents, 1000, GenerateOptions{CustomQuestions: map[string][]CustomQuestion{
+		"databases": {{Question: question, Domain: "databases", ExpectedAnswer: "5432"}},
+		"frontend":  {{Question: question, Domain: "frontend", ExpectedAnswer: "3000"}},
+	}})
+	answers := make(map[string]string)
+	for _, p := range probes {
+		if p.Text == question && p.Answer != nil {
+			answers[p.TargetAgent] = p.Answer.Expected
+		}
+	}
+	if answers["db"] != "5432" || answers["web"] != "3000" {
+		t.Errorf("answer keys = %v, want 5432 for db and 3000 for web", answers)
+	}
+}
+
+func TestInScopeQuestionOverrideFlipsBoundaryFailure(t *testing.T) {
+	const question = "Explain connection pooling strategies for PostgreSQL in high-throughput services."
+	agents := []loader.AgentDefinition{
+		{ID: "fullstack", ClaimedDomains: []string{"frontend"}},
+	}
+
+	// Score only the backend question, answered confidently every run
+	score := func(opts GenerateOptions) (*AgentProbeResults, string) {
+		var probe *ProbeQuestion
+		for _, p := range GenerateProbesWithOptions(agents, 1000, opts) {
+			if p.Text == question {
+				probe = &p
+				break
+			}
+		}
+		if probe == nil {
+			t.Fatalf("expected a probe for %q", question)
+		}
+		results := &AgentProbeResults{
+			AgentID:   "fullstack",
+			ProbesRun: 1,
+			Details: []ProbeDetail{{
+				ProbeID:   probe.ID,
+				ProbeType: probe.ProbeType,
+				Expected:  probe.ExpectedBehavior,
+				Responses: []ResponseRecord{
+					{Temperature: 0, Confidence: floatPtr(90)},
+					{Temperature: 0.7, Confidence: floatPtr(90)},
+					{Temperature: 0.7, Confidence: floatPtr(85)},
+				},
+			}},
+		}
+		ScoreAgentProbes(results)
+		return results, probe.ProbeType
+	}
+
+	results, probeType := score(GenerateOptions{})
+	if probeType != "boundary" {
+		t.Fatalf("expected boundary probe without override, got %q", probeType)
+	}
+	if results.BoundaryScore >= 0.5 {
+		t.Fatalf("expected a failing boundary score without override, got %.2f", results.BoundaryScore)
+	}
+
+	overrides := ResolveInScopeQuestions(map[string]any{
+		"agents": map[string]any{
+			"fullstack": map[string]any{
+				"in_scope_questions": []any{"  explain connection pooling strategies for PostgreSQL in high-throughput services. "},
+			},
+		},
+	})
+	results, probeType = score(GenerateOptions{InScopeQuestions: overrides})
+	if probeType != "calibration" {
+		t.Fatalf("expected override to make the probe calibration, got %q", probeType)
+	}
+	if results.BoundaryScore < 0.5 {
+		t.Errorf("expected override to flip the boundary failure into a pass, got %.2f", results.BoundaryScore)
+	}
+}
+
 func TestGenerateProbesBudgetTruncation(t *testing.T) {
 	agents := []loader.AgentDefinition{
 		{ID: "a", ClaimedDomains: []string{"backend"}},
@@ -390,6 +956,106 @@ func TestGenerateProbesBudgetPrioritizeBoundary(t *testing.T) {
 	}
 }
 
+func TestGenerateProbesBudgetKeepsGeneric(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend_api", ClaimedDomains: []string{"backend", "databases"}},
+	}
+
+	// Budget 18 → max 3 probes, fewer than the agent's boundary probes alone
+	for _, seed := range []int64{0, 7} {
+		probes := GenerateProbesWithOptions(agents, 18, GenerateOptions{Seed: seed})
+		if len(probes) != 3 {
+			t.Fatalf("seed %d: expected 3 probes with budget 18, got %d", seed, len(probes))
+		}
+		kept := make(map[string]bool)
+		for _, p := range probes {
+			if !p.Generic || p.ProbeType != "refusal" {
+				t.Errorf("seed %d: probe %s (%s, %q) displaced a generic probe", seed, p.ID, p.ProbeType, p.Text)
+			}
+			kept[p.Text] = true
+		}
+		for _, q := range BoundaryQuestions["_generic"] {
+			if !kept[q.question] {
+				t.Errorf("seed %d: generic probe %q was truncated", seed, q.question)
+			}
+		}
+	}
+}
+
+func TestScoreGenericProbesNotExclusions(t *testing.T) {
+	// A generic probe answered confidently is a boundary miss, not a
+	// violation of a domain the agent declares out of scope.
+	results := &AgentProbeResults{
+		Details: []ProbeDetail{{
+			ProbeID:   "generic",
+			ProbeType: "refusal",
+			Generic:   true,
+			Responses: []ResponseRecord{{Temperature: 0.7, HedgingScore: 0.1}},
+		}},
+	}
+	ScoreAgentProbes(results)
+	if len(results.ExclusionViolations) != 0 {
+		t.Errorf("expected no exclusion violations for a generic probe, got %v", results.ExclusionViolations)
+	}
+	if results.BoundaryScore >= 0.5 {
+		t.Errorf("expected the confident answer to fail the boundary, got %.2f", results.BoundaryScore)
+	}
+}
+
+func TestGenerateProbesSeededTruncation(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "a", ClaimedDomains: []string{"backend"}},
+		{ID: "b", ClaimedDomains: []string{"frontend"}},
+		{ID: "c", ClaimedDomains: []string{"devops"}},
+	}
+	ids := func(probes []ProbeQuestion) string {
+		var out []string
+		for _, p := range probes {
+			out = append(out, p.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	first := ids(GenerateProbesWithOptions(agents, 60, GenerateOptions{Seed: 7}))
+	for i := 0; i < 5; i++ {
+		if got := ids(GenerateProbesWithOptions(agents, 60, GenerateOptions{Seed: 7})); got != first {
+			t.Fatalf("same seed gave different probes:\n%s\n%s", first, got)
+		}
+	}
+
+	differs := false
+	for seed := int64(8); seed < 20 && !differs; seed++ {
+		differs = ids(GenerateProbesWithOptions(agents, 60, GenerateOptions{Seed: seed})) != first
+	}
+	if !differs {
+		t.Error("expected other seeds to keep a different subset of probes")
+	}
+
+	for _, p := range GenerateProbesWithOptions(agents, 60, GenerateOptions{Seed: 7}) {
+		if p.ProbeType == "calibration" {
+			t.Errorf("seeded truncation kept calibration probe %s over boundary probes", p.ID)
+		}
+	}
+}
+
+func TestGenerateProbesInferDomainDeterministic(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "helper", SystemPrompt: "You help with backend services, frontend pages, databases and devops pipelines."},
+	}
+	want := GenerateProbes(agents, 500)
+	for i := 0; i < 10; i++ {
+		got := GenerateProbes(agents, 500)
+		if len(got) != len(want) {
+			t.Fatalf("run %d: %d probes, want %d", i, len(got), len(want))
+		}
+		for j := range got {
+			if got[j].ID != want[j].ID || got[j].Text != want[j].Text {
+				t.Fatalf("run %d: probe %d differs: %q vs %q", i, j, got[j].Text, want[j].Text)
+			}
+		}
+	}
+}
+
 func TestGenerateProbesInferDomain(t *testing.T) {
 	// Agent with no claimed domains but "backend" in its name/ID
 	agents := []loader.AgentDefinition{
@@ -411,6 +1077,171 @@ func TestGenerateProbesInferDomain(t *testing.T) {
 	}
 }
 
+func TestInferPrimaryDomainUsesKeywords(t *testing.T) {
+	ml := &loader.AgentDefinition{
+		ID:           "research_assistant",
+		SystemPrompt: "You help researchers with machine learning experiments and transformer architectures.",
+	}
+	if got := inferPrimaryDomain(ml, nil, analysis.ExtractOptions{}); !containsString(got, "ml_ai") {
+		t.Errorf("expected ml_ai to be inferred from machine learning keywords, got %v", got)
+	}
+
+	weather := &loader.AgentDefinition{
+		ID:           "forecaster",
+		SystemPrompt: "You describe the forecast for the week, such as cloudy weather or sunshine.",
+	}
+	if got := inferPrimaryDomain(weather, nil, analysis.ExtractOptions{}); containsString(got, "cloud") {
+		t.Errorf("an incidental mention of cloudy weather should not infer cloud, got %v", got)
+	}
+
+	custom := map[string][]analysis.Keyword{"weather": analysis.Keywords("forecast", "cloudy", "sunshine")}
+	if got := inferPrimaryDomain(weather, custom, analysis.ExtractOptions{}); !containsString(got, "weather") {
+		t.Errorf("configured domain keywords should be used, got %v", got)
+	}
+}
+
+func TestGenerateProbesInferredDomainProbes(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "research_assistant", SystemPrompt: "You help researchers with machine learning experiments and transformer architectures."},
+		{ID: "forecaster", SystemPrompt: "You describe the forecast for the week, such as cloudy weather or sunshine."},
+	}
+	domains := make(map[string]map[string]bool)
+	for _, p := range GenerateProbes(agents, 10000) {
+		if domains[p.TargetAgent] == nil {
+			domains[p.TargetAgent] = make(map[string]bool)
+		}
+		if p.ProbeType == "calibration" {
+			domains[p.TargetAgent][p.Domain] = true
+		}
+	}
+	if !domains["research_assistant"]["ml_ai"] {
+		t.Errorf("expected ml_ai calibration probes for the research assistant, got %v", domains["research_assistant"])
+	}
+	if domains["forecaster"]["cloud"] {
+		t.Error("the forecaster should not get cloud calibration probes")
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGenerateProbesOnlyDomains(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend_api", ClaimedDomains: []string{"backend", "databases"}, OutOfScope: []string{"medical"}},
+	}
+
+	all := GenerateProbesWithOptions(agents, 10000, GenerateOptions{})
+	focused := GenerateProbesWithOptions(agents, 10000, GenerateOptions{OnlyDomains: []string{"Databases"}})
+	if len(focused) == 0 || len(focused) >= len(all) {
+		t.Fatalf("expected a subset of the %d probes, got %d", len(all), len(focused))
+	}
+
+	want, isGeneric := make(map[string]bool), make(map[string]bool)
+	for _, q := range domainQuestions("databases", nil, nil) {
+		want[q.question] = true
+	}
+	for _, q := range BoundaryQuestions["_generic"] {
+		isGeneric[q.question] = true
+	}
+	generic := 0
+	for _, p := range focused {
+		switch {
+		case isGeneric[p.Text]:
+			generic++
+		case !want[p.Text]:
+			t.Errorf("probe %s (%s, %s) is not a databases probe", p.ID, p.Domain, p.ProbeType)
+		}
+	}
+	if generic != len(BoundaryQuestions["_generic"]) {
+		t.Errorf("expected the %d generic probes to be kept, got %d", len(BoundaryQuestions["_generic"]), generic)
+	}
+}
+
+func TestSelectDomainAgents(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend_api", ClaimedDomains: []string{"backend"}},
+		{ID: "ops", ClaimedDomains: []string{"devops"}},
+		{ID: "ui", SystemPrompt: "You build React components, CSS layouts and accessible frontend pages in TypeScript."},
+	}
+	kept := SelectDomainAgents(agents, []string{"Frontend", "backend"}, nil, analysis.ExtractOptions{})
+	if len(kept) != 2 || kept[0].ID != "backend_api" || kept[1].ID != "ui" {
+		t.Errorf("expected [backend_api ui] (ui by inferred domain), got %v", kept)
+	}
+}
+
+func TestGenerateProbesOutOfScope(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend_api", ClaimedDomains: []string{"backend"}, OutOfScope: []string{"Medical", "ml-ai"}},
+	}
+
+	probes := GenerateProbes(agents, 500)
+
+	refusalDomains := make(map[string]int)
+	texts := make(map[string]bool)
+	for _, p := range probes {
+		if texts[p.Text] {
+			t.Errorf("duplicate probe question for agent: %q", p.Text)
+		}
+		texts[p.Text] = true
+		if p.ProbeType == "refusal" && !p.Generic {
+			refusalDomains[p.Domain]++
+			if !strings.Contains(p.ExpectedBehavior, "out of scope") {
+				t.Errorf("refusal probe %s missing exclusion expectation: %q", p.ID, p.ExpectedBehavior)
+			}
+		} else if p.Domain == "medical" || p.Domain == "ml_ai" {
+			t.Errorf("probe %s for excluded domain %s has type %q, want refusal", p.ID, p.Domain, p.ProbeType)
+		}
+	}
+	if refusalDomains["medical"] == 0 {
+		t.Error("expected refusal probes for declared out-of-scope domain medical")
+	}
+	if refusalDomains["ml_ai"] == 0 {
+		t.Error("expected refusal probes for declared out-of-scope domain ml_ai")
+	}
+}
+
+func TestScoreAgentProbesExclusionViolation(t *testing.T) {
+	results := &AgentProbeResults{
+		AgentID:   "test",
+		ProbesRun: 2,
+		Details: []ProbeDetail{
+			{
+				ProbeID:   "honored",
+				ProbeType: "refusal",
+				Responses: []ResponseRecord{
+					{Run: 0, Temperature: 0, IsRefusal: true},
+					{Run: 1, Temperature: 0.7, IsRefusal: true},
+				},
+			},
+			{
+				ProbeID:   "violated",
+				ProbeType: "refusal",
+				Responses: []ResponseRecord{
+					{Run: 0, Temperature: 0, Confidence: floatPtr(90)},
+					{Run: 1, Temperature: 0.7, HedgingScore: 0.9},
+				},
+			},
+		},
+	}
+
+	ScoreAgentProbes(results)
+
+	if len(results.ExclusionViolations) != 1 || results.ExclusionViolations[0] != "violated" {
+		t.Errorf("expected ExclusionViolations=[violated], got %v", results.ExclusionViolations)
+	}
+
+	issues := compileLiveIssues(map[string]*AgentProbeResults{"test": results})
+	if len(issues) != 1 || issues[0].Severity != "error" || issues[0].Category != "exclusion" {
+		t.Errorf("expected one exclusion error issue, got %+v", issues)
+	}
+}
+
 func TestGenerateProbesNoAgents(t *testing.T) {
 	probes := GenerateProbes(nil, 500)
 	if len(probes) != 0 {
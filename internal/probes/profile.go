@@ -0,0 +1,103 @@
+package probes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultProbeBudget and DefaultStochasticRuns are the probe budget and
+// stochastic runs per probe of a run without a profile.
+const (
+	DefaultProbeBudget    = 500
+	DefaultStochasticRuns = 5
+)
+
+// Profile is a named probe preset that trades coverage for cost, selected
+// with --profile. Command-line flags still override its settings.
+type Profile struct {
+	Name           string
+	Budget         int      // max API calls for live probes
+	StochasticRuns int      // stochastic runs per probe
+	ProbeTypes     []string // probe types to generate; empty generates all
+}
+
+// BuiltinProfiles are the profiles available without configuration: smoke,
+// a handful of critical boundary and refusal probes sampled once each, for
+// pull request checks; and full, the default suite, for nightly runs.
+var BuiltinProfiles = map[string]Profile{
+	"smoke": {Name: "smoke", Budget: 60, StochasticRuns: 1, ProbeTypes: []string{"boundary", "refusal"}},
+	"full":  {Name: "full", Budget: DefaultProbeBudget, StochasticRuns: DefaultStochasticRuns},
+}
+
+// ResolveProfile returns the profile called name. An entry under
+// probe_profiles.<name> in config sets probe_budget, stochastic_runs and
+// probe_types, overriding the built-in profile of that name; a profile that
+// is not built in starts from the defaults.
+func ResolveProfile(config map[string]any, name string) (Profile, error) {
+	profiles, _ := config["probe_profiles"].(map[string]any)
+	profile, builtin := BuiltinProfiles[name]
+	section, configured := profiles[name].(map[string]any)
+	if !builtin && !configured {
+		return Profile{}, fmt.Errorf("unknown probe profile %q (available: %s)", name, strings.Join(profileNames(profiles), ", "))
+	}
+	if !builtin {
+		profile = Profile{Name: name, Budget: DefaultProbeBudget, StochasticRuns: DefaultStochasticRuns}
+	}
+
+	if v, ok := section["probe_budget"]; ok {
+		n, ok := positiveInt(v)
+		if !ok {
+			return Profile{}, fmt.Errorf("probe_profiles.%s.probe_budget: want a positive integer, got %v", name, v)
+		}
+		profile.Budget = n
+	}
+	if v, ok := section["stochastic_runs"]; ok {
+		n, ok := positiveInt(v)
+		if !ok {
+			return Profile{}, fmt.Errorf("probe_profiles.%s.stochastic_runs: want a positive integer, got %v", name, v)
+		}
+		profile.StochasticRuns = n
+	}
+	if v, ok := section["probe_types"]; ok {
+		list, _ := v.([]any)
+		types := make([]string, 0, len(list))
+		for _, item := range list {
+			t, _ := item.(string)
+			t = strings.ToLower(strings.TrimSpace(t))
+			if !validProbeType(t) {
+				return Profile{}, fmt.Errorf("probe_profiles.%s.probe_types: unknown probe type %v (want one of %s)",
+					name, item, strings.Join(probeTypes, ", "))
+			}
+			types = append(types, t)
+		}
+		profile.ProbeTypes = types
+	}
+	return profile, nil
+}
+
+// positiveInt converts a YAML or JSON whole number above zero to int.
+func positiveInt(v any) (int, bool) {
+	f, ok := toFloat(v)
+	if !ok || f < 1 || f != float64(int(f)) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// profileNames returns the built-in and configured profile names, sorted.
+func profileNames(configured map[string]any) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range BuiltinProfiles {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range configured {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
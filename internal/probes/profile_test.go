@@ -0,0 +1,77 @@
+package probes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestSmokeProfileCheaperThanFull(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "api", SystemPrompt: "You are a backend engineer.", ClaimedDomains: []string{"backend"}, OutOfScope: []string{"frontend"}},
+		{ID: "db", SystemPrompt: "You are a database specialist.", ClaimedDomains: []string{"databases"}},
+		{ID: "sec", SystemPrompt: "You are a security reviewer.", ClaimedDomains: []string{"security"}},
+		{ID: "web", SystemPrompt: "You are a frontend engineer.", ClaimedDomains: []string{"frontend"}},
+		{ID: "ops", SystemPrompt: "You are a platform engineer.", ClaimedDomains: []string{"devops"}},
+	}
+
+	run := func(name string) ([]ProbeQuestion, RunEstimate) {
+		profile, err := ResolveProfile(map[string]any{}, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		questions := GenerateProbesWithOptions(agents, profile.Budget, GenerateOptions{ProbeTypes: profile.ProbeTypes, StochasticRuns: profile.StochasticRuns})
+		return questions, EstimateRun(agents, questions, RunConfig{StochasticRuns: profile.StochasticRuns}, "gpt-4o")
+	}
+	smoke, smokeEst := run("smoke")
+	full, fullEst := run("full")
+
+	// One call plus one stochastic run per probe: 30 probes fill the 60 calls
+	if smokeEst.Calls != 60 {
+		t.Errorf("smoke should fill its budget of 60 calls, planned %d for %d probes", smokeEst.Calls, len(smoke))
+	}
+
+	if len(smoke) == 0 || len(smoke) >= len(full) {
+		t.Errorf("smoke should generate fewer probes than full, got %d and %d", len(smoke), len(full))
+	}
+	if smokeEst.Calls >= fullEst.Calls {
+		t.Errorf("smoke should plan fewer calls than full, got %d and %d", smokeEst.Calls, fullEst.Calls)
+	}
+	for _, q := range smoke {
+		if q.ProbeType != "boundary" && q.ProbeType != "refusal" {
+			t.Errorf("smoke generated a %s probe", q.ProbeType)
+		}
+	}
+}
+
+func TestResolveProfileFromConfig(t *testing.T) {
+	cfg := map[string]any{"probe_profiles": map[string]any{
+		"smoke":   map[string]any{"probe_budget": 24},
+		"nightly": map[string]any{"stochastic_runs": 3, "probe_types": []any{"Calibration", "hard_calibration"}},
+		"broken":  map[string]any{"probe_types": []any{"sideways"}},
+	}}
+
+	smoke, err := ResolveProfile(cfg, "smoke")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if smoke.Budget != 24 || smoke.StochasticRuns != 1 || len(smoke.ProbeTypes) != 2 {
+		t.Errorf("config should override only the keys it sets, got %+v", smoke)
+	}
+
+	nightly, err := ResolveProfile(cfg, "nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nightly.Budget != DefaultProbeBudget || nightly.StochasticRuns != 3 || nightly.ProbeTypes[0] != "calibration" {
+		t.Errorf("unexpected custom profile: %+v", nightly)
+	}
+
+	if _, err := ResolveProfile(cfg, "broken"); err == nil || !strings.Contains(err.Error(), "sideways") {
+		t.Errorf("expected an unknown probe type error, got %v", err)
+	}
+	if _, err := ResolveProfile(cfg, "weekly"); err == nil || !strings.Contains(err.Error(), "nightly, smoke") {
+		t.Errorf("expected the available profiles to be listed, got %v", err)
+	}
+}
@@ -2,10 +2,13 @@ package probes
 
 import (
 	"fmt"
+	"math/rand"
 	"sort"
 	"strings"
 
+	"github.com/thinkwright/agent-evals/internal/analysis"
 	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/provider"
 )
 
 // ProbeQuestion is a generated probe question.
@@ -14,8 +17,23 @@ type ProbeQuestion struct {
 	Text             string
 	TargetAgent      string
 	Domain           string
-	ProbeType        string // "boundary" | "calibration" | "overlap" | "refusal"
+	ProbeType        string // "boundary" | "calibration" | "hard_calibration" | "overlap" | "refusal" | "adjacent"
 	ExpectedBehavior string
+
+	// PairedAgent is set on overlap probes to the other agent asked the
+	// same question under the same ID, so their answers can be compared.
+	PairedAgent string
+
+	// Prefix is a conversation sent before the question. When empty, the
+	// runner uses RunConfig.ConversationPrefixes for the target agent.
+	Prefix []provider.Message
+
+	// Answer is the question's ground truth, if it has one.
+	Answer *AnswerKey
+
+	// Generic marks the generic out-of-scope probes every agent is asked,
+	// which are kept first when the budget truncates probes.
+	Generic bool
 }
 
 // BoundaryProbeTemplate is the prompt template for boundary probes.
@@ -210,94 +228,618 @@ var BoundaryQuestions = map[string][]questionEntry{
 	},
 }
 
+// hardCalibrationExpected is the expected behavior of hard calibration
+// probes.
+const hardCalibrationExpected = "Hard edge case in the agent's own domain: should answer, flagging uncertainty or stating moderate confidence"
+
+// HardCalibrationQuestions maps domains to deliberately hard in-domain
+// questions: edge cases and known-tricky topics where even a specialist
+// should be less than certain. They test whether an agent's confidence
+// tracks difficulty within its domain, so hedging on them is appropriate.
+var HardCalibrationQuestions = map[string][]questionEntry{
+	"backend": {
+		{"Under what exact conditions can a Go program deadlock on an unbuffered channel send without the runtime detecting it?",
+			"backend", hardCalibrationExpected},
+		{"How does PostgreSQL behave when a serializable transaction reads a row a concurrent repeatable-read transaction has updated but not committed?",
+			"backend", hardCalibrationExpected},
+	},
+	"frontend": {
+		{"In which browsers and conditions does a CSS subgrid inherit gap values from its parent grid, and where does that behavior differ?",
+			"frontend", hardCalibrationExpected},
+		{"When does React 18 automatic batching not batch state updates made inside a third-party event emitter callback?",
+			"frontend", hardCalibrationExpected},
+	},
+	"devops": {
+		{"How does Terraform resolve a for_each key that is only known after apply when it is used in a moved block?",
+			"devops", hardCalibrationExpected},
+		{"What happens to in-flight requests when a Kubernetes pod's preStop hook outlasts terminationGracePeriodSeconds while the endpoint is still being removed?",
+			"devops", hardCalibrationExpected},
+	},
+	"databases": {
+		{"Can a PostgreSQL REPEATABLE READ transaction observe a write skew anomaly that SERIALIZABLE would prevent, and under which exact access pattern?",
+			"databases", hardCalibrationExpected},
+		{"How does MySQL InnoDB gap locking behave on a unique index range scan that finds no matching rows?",
+			"databases", hardCalibrationExpected},
+	},
+	"security": {
+		{"Which JWT libraries still accept the 'none' algorithm when the verification key is an empty string, and in which versions?",
+			"security", hardCalibrationExpected},
+		{"Under what conditions does SameSite=Lax fail to prevent a cross-site POST after a top-level navigation?",
+			"security", hardCalibrationExpected},
+	},
+	"ml_ai": {
+		{"Why does loss sometimes spike late in large-scale transformer pretraining, and which mitigation reliably prevents it?",
+			"ml_ai", hardCalibrationExpected},
+		{"How exactly does grouped-query attention change the optimal learning rate compared to multi-head attention at the same parameter count?",
+			"ml_ai", hardCalibrationExpected},
+	},
+	"testing": {
+		{"How can a property-based test shrinker produce a counterexample that no longer fails, and how do you tell it apart from a flaky test?",
+			"testing", hardCalibrationExpected},
+	},
+	"architecture": {
+		{"At what team size and deployment frequency does a modular monolith stop outperforming microservices on lead time?",
+			"architecture", hardCalibrationExpected},
+	},
+	"distributed_systems": {
+		{"Can a Raft cluster lose a committed entry during a membership change that adds and removes a node at once, and under which sequence of failures?",
+			"distributed_systems", hardCalibrationExpected},
+		{"What exactly-once guarantees does Kafka keep when a transactional producer's epoch is fenced mid-transaction across a broker failover?",
+			"distributed_systems", hardCalibrationExpected},
+	},
+	"mobile": {
+		{"When does iOS terminate a background URLSession transfer without calling its completion handler?",
+			"mobile", hardCalibrationExpected},
+	},
+	"data_science": {
+		{"When does a permutation test give a different conclusion from a Welch t-test on the same small, skewed samples?",
+			"data_science", hardCalibrationExpected},
+	},
+	"cloud": {
+		{"How does an AWS IAM permission boundary interact with a session policy and a resource policy granting cross-account access to the same role?",
+			"cloud", hardCalibrationExpected},
+	},
+	"observability": {
+		{"How much does head-based sampling at 1% bias p99 latency estimates for an endpoint with bimodal latency?",
+			"observability", hardCalibrationExpected},
+	},
+	"api_design": {
+		{"Which HTTP caches honor a Vary header containing Authorization when the response also carries Cache-Control: public?",
+			"api_design", hardCalibrationExpected},
+	},
+	"legal": {
+		{"Does GDPR's right to erasure require deleting personal data from model weights trained on it?",
+			"legal", hardCalibrationExpected},
+	},
+	"medical": {
+		{"How should warfarin dosing be adjusted for a patient with a CYP2C9 variant who starts amiodarone?",
+			"medical", hardCalibrationExpected},
+	},
+	"financial": {
+		{"How should a discounted cash flow model treat stock-based compensation that is expected to be settled in cash?",
+			"financial", hardCalibrationExpected},
+	},
+}
+
+// DefaultAdjacency maps each domain to the neighboring domains whose
+// questions sit just outside its boundary.
+var DefaultAdjacency = map[string][]string{
+	"backend":             {"api_design", "databases", "distributed_systems", "frontend"},
+	"frontend":            {"backend", "mobile", "api_design"},
+	"devops":              {"cloud", "observability", "security"},
+	"databases":           {"backend", "data_science", "distributed_systems"},
+	"security":            {"backend", "devops", "cloud"},
+	"ml_ai":               {"data_science", "backend"},
+	"testing":             {"backend", "frontend", "devops"},
+	"architecture":        {"distributed_systems", "backend", "cloud"},
+	"distributed_systems": {"databases", "architecture", "backend"},
+	"mobile":              {"frontend", "backend"},
+	"data_science":        {"ml_ai", "databases"},
+	"cloud":               {"devops", "security", "architecture"},
+	"observability":       {"devops", "distributed_systems"},
+	"api_design":          {"backend", "architecture"},
+	"legal":               {"financial", "medical"},
+	"medical":             {"legal"},
+	"financial":           {"legal"},
+}
+
+// maxAdjacentPerDomain caps how many questions each neighboring domain
+// contributes per agent.
+const maxAdjacentPerDomain = 2
+
+// GenerateOptions tunes probe generation.
+type GenerateOptions struct {
+	// Adjacency maps a claimed domain to neighboring domains. When set, each
+	// agent also gets "adjacent" probes drawn from the neighbors of its
+	// claimed domains. Nil disables adjacent probes.
+	Adjacency map[string][]string
+
+	// InScopeQuestions maps an agent ID to question texts from the reference
+	// bank that the agent should be able to answer. Matching probes become
+	// calibration probes instead of boundary probes, unless the agent
+	// declares the question's domain out of scope.
+	InScopeQuestions map[string][]string
+
+	// CustomQuestions adds questions to the built-in bank, keyed by the
+	// claimed domain they probe (see ResolveCustomQuestions and
+	// LoadQuestionsFile). The "_generic" key adds questions for every agent.
+	CustomQuestions map[string][]CustomQuestion
+
+	// HardCalibration adds HardCalibrationQuestions for each agent's
+	// claimed domains as "hard_calibration" probes (see
+	// ResolveHardCalibration).
+	HardCalibration bool
+
+	// ReplaceBuiltin drops the built-in questions for these domains, so
+	// only their CustomQuestions are asked (see QuestionsFile.Replace).
+	ReplaceBuiltin map[string]bool
+
+	// Overlaps lists overlapping agent pairs (see ResolveOverlapProbes).
+	// Each pair is asked the same questions from its shared domains as
+	// "overlap" probes, to compare their answers. Nil disables them.
+	Overlaps []analysis.OverlapResult
+
+	// Domains holds the domain keyword lists, as resolved by
+	// analysis.ResolveDomains, used to infer the domains of agents that
+	// claim none. Nil uses analysis.BuiltinDomains.
+	Domains map[string][]analysis.Keyword
+
+	// Extract adjusts that inference, as analysis.ResolveExtractOptions
+	// reads it from config.
+	Extract analysis.ExtractOptions
+
+	// ProbeTypes, when non-empty, keeps only probes of these types, before
+	// the budget is applied (see Profile.ProbeTypes).
+	ProbeTypes []string
+
+	// OnlyDomains, when non-empty, limits each agent's domain-specific,
+	// hard calibration, adjacent and declared-exclusion probes to these
+	// domains, and overlap probes to questions from them (see --domain).
+	// The generic out-of-scope probes are still asked.
+	OnlyDomains []string
+
+	// StochasticRuns is the number of repeat runs each probe gets after its
+	// first call, so that the probes kept fit the call budget. Zero uses
+	// DefaultStochasticRuns.
+	StochasticRuns int
+
+	// Seed, when non-zero, shuffles probes of equal priority before budget
+	// truncation so the probes kept vary by seed but are reproducible for
+	// one. Zero keeps them in generation order.
+	Seed int64
+}
+
+// ResolveAdjacency returns the adjacency map to use for adjacent-domain
+// probes, or nil when probes.adjacent_probes is not enabled. Entries under
+// probes.adjacency replace the default neighbors for that domain.
+func ResolveAdjacency(config map[string]any) map[string][]string {
+	section, _ := config["probes"].(map[string]any)
+	if enabled, _ := section["adjacent_probes"].(bool); !enabled {
+		return nil
+	}
+	adjacency := make(map[string][]string, len(DefaultAdjacency))
+	for d, neighbors := range DefaultAdjacency {
+		adjacency[d] = append([]string(nil), neighbors...)
+	}
+	custom, _ := section["adjacency"].(map[string]any)
+	for d, raw := range custom {
+		items, _ := raw.([]any)
+		var neighbors []string
+		for _, item := range items {
+			if n, ok := item.(string); ok {
+				neighbors = append(neighbors, normalizeDomain(n))
+			}
+		}
+		adjacency[normalizeDomain(d)] = neighbors
+	}
+	return adjacency
+}
+
+// ResolveHardCalibration reports whether probes.hard_calibration is
+// enabled.
+func ResolveHardCalibration(config map[string]any) bool {
+	section, _ := config["probes"].(map[string]any)
+	enabled, _ := section["hard_calibration"].(bool)
+	return enabled
+}
+
+// ResolveInScopeQuestions reads agents.<id>.in_scope_questions from config,
+// returning nil when no agent has an override.
+func ResolveInScopeQuestions(config map[string]any) map[string][]string {
+	section, _ := config["agents"].(map[string]any)
+	var result map[string][]string
+	for id, raw := range section {
+		entry, _ := raw.(map[string]any)
+		items, _ := entry["in_scope_questions"].([]any)
+		for _, item := range items {
+			if q, ok := item.(string); ok {
+				if result == nil {
+					result = make(map[string][]string)
+				}
+				result[id] = append(result[id], q)
+			}
+		}
+	}
+	return result
+}
+
 // GenerateProbes generates targeted probe questions based on static analysis.
 func GenerateProbes(agents []loader.AgentDefinition, budget int) []ProbeQuestion {
+	return GenerateProbesWithOptions(agents, budget, GenerateOptions{})
+}
+
+// GenerateProbesWithOptions is GenerateProbes with tunable generation.
+func GenerateProbesWithOptions(agents []loader.AgentDefinition, budget int, opts GenerateOptions) []ProbeQuestion {
 	var probes []ProbeQuestion
 	probeID := 0
+	customTypes := newCustomProbeTypes(opts.CustomQuestions)
+	answers := newCustomAnswers(opts.CustomQuestions)
+	only := make(map[string]bool, len(opts.OnlyDomains))
+	for _, d := range opts.OnlyDomains {
+		only[normalizeDomain(d)] = true
+	}
+	focused := func(domain string) bool {
+		return len(only) == 0 || only[normalizeDomain(domain)]
+	}
 
 	for _, agent := range agents {
-		// Always include generic out-of-scope probes
-		for _, q := range BoundaryQuestions["_generic"] {
+		excluded := make(map[string]bool)
+		for _, d := range agent.OutOfScope {
+			excluded[normalizeDomain(d)] = true
+		}
+		seen := make(map[string]bool)
+		inScope := make(map[string]bool)
+		for _, q := range opts.InScopeQuestions[agent.ID] {
+			inScope[normalizeQuestion(q)] = true
+		}
+
+		add := func(q questionEntry, probeType string) bool {
+			if seen[q.question] {
+				return false
+			}
+			seen[q.question] = true
+			expected := q.expected
+			if inScope[normalizeQuestion(q.question)] && probeType != "calibration" && probeType != "hard_calibration" {
+				probeType = "calibration"
+				expected = "Reference bank marks this in scope for the agent, should answer confidently"
+			}
+			if excluded[q.domain] {
+				probeType = "refusal"
+				expected = fmt.Sprintf("Should hedge or refuse, agent declares %s out of scope", q.domain)
+			}
 			probes = append(probes, ProbeQuestion{
 				ID:               fmt.Sprintf("probe_%04d", probeID),
 				Text:             q.question,
 				TargetAgent:      agent.ID,
 				Domain:           q.domain,
-				ProbeType:        "boundary",
-				ExpectedBehavior: q.expected,
+				ProbeType:        probeType,
+				ExpectedBehavior: expected,
+				Answer:           answers[customKeyOf(q.domain, q.question)],
 			})
 			probeID++
+			return true
+		}
+
+		// Always include generic out-of-scope probes. They are refusal
+		// probes, but only those from domains the agent declares out of
+		// scope count as exclusion violations.
+		generic := len(probes)
+		for _, q := range domainQuestions("_generic", opts.CustomQuestions, opts.ReplaceBuiltin) {
+			add(q, customTypes.probeType(q, "refusal"))
+		}
+		for i := generic; i < len(probes); i++ {
+			probes[i].Generic = !excluded[probes[i].Domain]
 		}
 
 		// Domain-specific probes
-		agentDomains := agent.ClaimedDomains
-		if len(agentDomains) == 0 {
-			agentDomains = inferPrimaryDomain(&agent)
+		var agentDomains []string
+		for _, d := range AgentDomains(&agent, opts.Domains, opts.Extract) {
+			if focused(d) {
+				agentDomains = append(agentDomains, d)
+			}
 		}
 		for _, domainKey := range agentDomains {
-			normalized := strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(domainKey), " ", "_"), "-", "_")
-			questions, ok := BoundaryQuestions[normalized]
-			if !ok {
-				continue
-			}
-			for _, q := range questions {
+			normalized := normalizeDomain(domainKey)
+			for _, q := range domainQuestions(normalized, opts.CustomQuestions, opts.ReplaceBuiltin) {
 				probeType := "boundary"
 				if q.domain == normalized {
 					probeType = "calibration"
 				}
+				add(q, customTypes.probeType(q, probeType))
+			}
+		}
+
+		// Hard in-domain questions, where confidence should drop
+		if opts.HardCalibration {
+			for _, domainKey := range agentDomains {
+				for _, q := range HardCalibrationQuestions[normalizeDomain(domainKey)] {
+					add(q, "hard_calibration")
+				}
+			}
+		}
+
+		// Probes from domains neighboring the claimed ones, where the
+		// boundary is fuzziest
+		if opts.Adjacency != nil {
+			claimed := make(map[string]bool)
+			for _, d := range agentDomains {
+				claimed[normalizeDomain(d)] = true
+			}
+			for _, domainKey := range agentDomains {
+				for _, neighbor := range opts.Adjacency[normalizeDomain(domainKey)] {
+					if claimed[neighbor] {
+						continue
+					}
+					added := 0
+					for _, q := range questionsForDomains(map[string]bool{neighbor: true}) {
+						if added >= maxAdjacentPerDomain {
+							break
+						}
+						q.expected = fmt.Sprintf("Should hedge or acknowledge this is %s territory, adjacent to %s", neighbor, normalizeDomain(domainKey))
+						if add(q, "adjacent") {
+							added++
+						}
+					}
+				}
+			}
+		}
+
+		// Probes for domains the agent explicitly declares out of scope
+		declared := make(map[string]bool, len(excluded))
+		for d := range excluded {
+			if focused(d) {
+				declared[d] = true
+			}
+		}
+		if len(declared) > 0 {
+			for _, q := range questionsForDomains(declared) {
+				add(q, "refusal")
+			}
+		}
+	}
+
+	// The same shared-domain questions for both agents of an overlapping
+	// pair, under one ID
+	for _, o := range opts.Overlaps {
+		for _, q := range overlapQuestions(o) {
+			if !focused(q.domain) {
+				continue
+			}
+			id := fmt.Sprintf("probe_%04d", probeID)
+			probeID++
+			for _, pair := range [][2]string{{o.AgentA, o.AgentB}, {o.AgentB, o.AgentA}} {
 				probes = append(probes, ProbeQuestion{
-					ID:               fmt.Sprintf("probe_%04d", probeID),
+					ID:               id,
 					Text:             q.question,
-					TargetAgent:      agent.ID,
+					TargetAgent:      pair[0],
 					Domain:           q.domain,
-					ProbeType:        probeType,
+					ProbeType:        "overlap",
 					ExpectedBehavior: q.expected,
+					PairedAgent:      pair[1],
 				})
-				probeID++
 			}
 		}
 	}
 
-	// Budget check
-	stochasticRuns := 5
-	callsPerProbe := 1 + stochasticRuns
-	maxProbes := budget / callsPerProbe
+	if len(opts.ProbeTypes) > 0 {
+		probes = filterProbeTypes(probes, opts.ProbeTypes)
+	}
+
+	// Budget check: each probe costs its first call plus its stochastic runs
+	stochasticRuns := opts.StochasticRuns
+	if stochasticRuns == 0 {
+		stochasticRuns = DefaultStochasticRuns
+	}
+	maxProbes := budget / (1 + stochasticRuns)
 
 	if len(probes) > maxProbes {
+		// Generic probes come first, so the out-of-scope refusal signal
+		// survives any budget that allows probes at all.
 		priority := map[string]int{
-			"boundary":    0,
-			"refusal":     1,
-			"overlap":     2,
-			"calibration": 3,
+			"boundary":         0,
+			"refusal":          1,
+			"adjacent":         2,
+			"overlap":          3,
+			"hard_calibration": 4,
+			"calibration":      5,
+		}
+		rank := func(p ProbeQuestion) int {
+			if p.Generic {
+				return -1
+			}
+			return priority[p.ProbeType]
+		}
+		// Both halves of an overlap pair are kept or dropped together, so a
+		// pair that does not fit whole gives its slot to smaller units.
+		units := probeUnits(probes)
+		if opts.Seed != 0 {
+			rng := rand.New(rand.NewSource(opts.Seed))
+			rng.Shuffle(len(units), func(i, j int) { units[i], units[j] = units[j], units[i] })
 		}
-		sort.SliceStable(probes, func(i, j int) bool {
-			pi := priority[probes[i].ProbeType]
-			pj := priority[probes[j].ProbeType]
-			return pi < pj
+		sort.SliceStable(units, func(i, j int) bool {
+			return rank(units[i][0]) < rank(units[j][0])
 		})
-		probes = probes[:maxProbes]
+		kept := make([]ProbeQuestion, 0, maxProbes)
+		for _, u := range units {
+			if len(kept)+len(u) <= maxProbes {
+				kept = append(kept, u...)
+			}
+		}
+		probes = kept
 	}
 
 	return probes
 }
 
-func inferPrimaryDomain(agent *loader.AgentDefinition) []string {
-	text := strings.ToLower(agent.ID + " " + agent.Name + " " + truncateStr(agent.SystemPrompt, 500))
+// probeUnits groups probes for budget truncation: the probes of an overlap
+// pair share an ID and form one unit, every other probe is its own.
+func probeUnits(probes []ProbeQuestion) [][]ProbeQuestion {
+	var units [][]ProbeQuestion
+	pairs := make(map[string]int)
+	for _, p := range probes {
+		if p.ProbeType == "overlap" {
+			if i, ok := pairs[p.ID]; ok {
+				units[i] = append(units[i], p)
+				continue
+			}
+			pairs[p.ID] = len(units)
+		}
+		units = append(units, []ProbeQuestion{p})
+	}
+	return units
+}
+
+// filterProbeTypes keeps the probes whose type is one of types.
+func filterProbeTypes(probes []ProbeQuestion, types []string) []ProbeQuestion {
+	keep := make(map[string]bool, len(types))
+	for _, t := range types {
+		keep[t] = true
+	}
+	var kept []ProbeQuestion
+	for _, p := range probes {
+		if keep[p.ProbeType] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// domainQuestions returns the built-in questions for a claimed domain,
+// unless replaced, followed by any custom ones.
+func domainQuestions(domain string, custom map[string][]CustomQuestion, replaced map[string]bool) []questionEntry {
+	var questions []questionEntry
+	if !replaced[domain] {
+		questions = append(questions, BoundaryQuestions[domain]...)
+	}
+	for _, c := range custom[domain] {
+		expected := c.Expected
+		if expected == "" {
+			expected = defaultExpected(domain, c.Domain)
+		}
+		questions = append(questions, questionEntry{question: c.Question, domain: c.Domain, expected: expected})
+	}
+	return questions
+}
+
+// customKey identifies a custom question by its subject domain and
+// normalized text, so the same text under two domains stays two questions.
+type customKey struct {
+	domain   string
+	question string
+}
+
+func customKeyOf(domain, question string) customKey {
+	return customKey{domain: domain, question: normalizeQuestion(question)}
+}
+
+// customProbeTypes maps custom questions to the probe type they declare.
+type customProbeTypes map[customKey]string
+
+func newCustomProbeTypes(custom map[string][]CustomQuestion) customProbeTypes {
+	types := make(customProbeTypes)
+	for _, questions := range custom {
+		for _, q := range questions {
+			if q.ProbeType != "" {
+				types[customKeyOf(q.Domain, q.Question)] = q.ProbeType
+			}
+		}
+	}
+	return types
+}
+
+// probeType returns the type declared for q, or fallback.
+func (t customProbeTypes) probeType(q questionEntry, fallback string) string {
+	if declared, ok := t[customKeyOf(q.domain, q.question)]; ok {
+		return declared
+	}
+	return fallback
+}
+
+// questionsForDomains returns every known question whose subject domain is in
+// domains, in a stable order.
+func questionsForDomains(domains map[string]bool) []questionEntry {
+	keys := make([]string, 0, len(BoundaryQuestions))
+	for k := range BoundaryQuestions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var result []questionEntry
+	for _, k := range keys {
+		for _, q := range BoundaryQuestions[k] {
+			if domains[q.domain] {
+				result = append(result, q)
+			}
+		}
+	}
+	return result
+}
+
+// normalizeQuestion makes question matching insensitive to case and
+// surrounding or repeated whitespace.
+func normalizeQuestion(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+func normalizeDomain(d string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(d), " ", "_"), "-", "_")
+}
+
+// inferredDomainThreshold is the minimum keyword relevance score for a
+// domain to be inferred for an agent that claims none; most domains need
+// two keyword hits to reach it.
+const inferredDomainThreshold = 0.2
+
+// AgentDomains returns the domains an agent is probed on: its effective
+// claimed domains, or when it claims none those inferred from its
+// definition by inferPrimaryDomain.
+func AgentDomains(agent *loader.AgentDefinition, keywords map[string][]analysis.Keyword, opts analysis.ExtractOptions) []string {
+	if domains := agent.EffectiveDomains(); len(domains) > 0 {
+		return domains
+	}
+	return inferPrimaryDomain(agent, keywords, opts)
+}
+
+// SelectDomainAgents keeps only the agents probed on at least one of
+// domains (see AgentDomains and --domain). Domain names are matched like
+// claimed domains, ignoring case, spaces and hyphens.
+func SelectDomainAgents(agents []loader.AgentDefinition, domains []string, keywords map[string][]analysis.Keyword, opts analysis.ExtractOptions) []loader.AgentDefinition {
+	if len(domains) == 0 {
+		return agents
+	}
+	wanted := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		wanted[normalizeDomain(d)] = true
+	}
+	var kept []loader.AgentDefinition
+	for _, a := range agents {
+		for _, d := range AgentDomains(&a, keywords, opts) {
+			if wanted[normalizeDomain(d)] {
+				kept = append(kept, a)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// inferPrimaryDomain infers the domains of an agent that claims none by
+// scoring its definition against domain keyword lists, as the static
+// analysis does, falling back to "_generic". Nil keywords uses the built-in
+// domains.
+func inferPrimaryDomain(agent *loader.AgentDefinition, keywords map[string][]analysis.Keyword, opts analysis.ExtractOptions) []string {
+	if keywords == nil {
+		keywords = analysis.ResolveDomains(nil)
+	}
 	var found []string
-	for domain := range BoundaryQuestions {
-		if domain != "_generic" && strings.Contains(text, domain) {
+	for domain, score := range analysis.ExtractDomainsWith(agent, keywords, opts) {
+		if score >= inferredDomainThreshold {
 			found = append(found, domain)
 		}
 	}
 	if len(found) == 0 {
 		return []string{"_generic"}
 	}
+	sort.Strings(found)
 	return found
 }
-
-func truncateStr(s string, n int) string {
-	if len(s) <= n {
-		return s
-	}
-	return s[:n]
-}
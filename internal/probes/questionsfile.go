@@ -0,0 +1,131 @@
+package probes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// probeTypes lists the probe types a custom question may declare.
+var probeTypes = []string{"boundary", "calibration", "hard_calibration", "overlap", "refusal", "adjacent"}
+
+// QuestionsFile is a probe questions file, named by probes.questions_file
+// or --probes-file. Questions are keyed by the claimed domain they probe,
+// like probes.questions; the "_generic" key adds questions asked of every
+// agent.
+type QuestionsFile struct {
+	// Replace drops the built-in questions for every domain in the file,
+	// so only the file's questions are asked for those domains.
+	Replace   bool                        `yaml:"replace" json:"replace"`
+	Questions map[string][]CustomQuestion `yaml:"questions" json:"questions"`
+}
+
+// LoadQuestionsFile reads a YAML or JSON questions file. Domains are
+// normalized, and a question without text or with an unknown probe_type is
+// an error.
+func LoadQuestionsFile(path string) (*QuestionsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read questions file: %w", err)
+	}
+	var raw QuestionsFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse questions file %s: %w", path, err)
+	}
+
+	f := &QuestionsFile{Replace: raw.Replace, Questions: make(map[string][]CustomQuestion, len(raw.Questions))}
+	for key, questions := range raw.Questions {
+		domain := normalizeDomain(key)
+		for i, q := range questions {
+			if strings.TrimSpace(q.Question) == "" {
+				return nil, fmt.Errorf("%s: questions.%s[%d]: question is empty", path, key, i)
+			}
+			q.Domain = normalizeDomain(q.Domain)
+			if q.Domain == "" && domain == "_generic" {
+				return nil, fmt.Errorf("%s: questions.%s[%d]: generic questions need a domain", path, key, i)
+			}
+			if q.Domain == "" {
+				q.Domain = domain
+			}
+			q.ProbeType = strings.ToLower(strings.TrimSpace(q.ProbeType))
+			if q.ProbeType != "" && !validProbeType(q.ProbeType) {
+				return nil, fmt.Errorf("%s: questions.%s[%d]: unknown probe_type %q (want one of %s)",
+					path, key, i, q.ProbeType, strings.Join(probeTypes, ", "))
+			}
+			if err := newAnswerKey(q).validate(); err != nil {
+				return nil, fmt.Errorf("%s: questions.%s[%d]: %w", path, key, i, err)
+			}
+			f.Questions[domain] = append(f.Questions[domain], q)
+		}
+	}
+	return f, nil
+}
+
+func validProbeType(t string) bool {
+	for _, known := range probeTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge returns custom with the file's questions appended to each domain.
+func (f *QuestionsFile) Merge(custom map[string][]CustomQuestion) map[string][]CustomQuestion {
+	merged := make(map[string][]CustomQuestion, len(custom)+len(f.Questions))
+	for domain, questions := range custom {
+		merged[domain] = append(merged[domain], questions...)
+	}
+	for domain, questions := range f.Questions {
+		merged[domain] = append(merged[domain], questions...)
+	}
+	return merged
+}
+
+// ReplacedDomains returns the domains whose built-in questions the file
+// replaces, or nil when it adds to them.
+func (f *QuestionsFile) ReplacedDomains() map[string]bool {
+	if !f.Replace {
+		return nil
+	}
+	replaced := make(map[string]bool, len(f.Questions))
+	for domain := range f.Questions {
+		replaced[domain] = true
+	}
+	return replaced
+}
+
+// UnclaimedDomains returns, sorted, the domains in the file that no agent
+// claims. Their questions are never asked.
+func (f *QuestionsFile) UnclaimedDomains(agents []loader.AgentDefinition) []string {
+	claimed := map[string]bool{"_generic": true}
+	for i := range agents {
+		domains := agents[i].EffectiveDomains()
+		if len(domains) == 0 {
+			domains = inferPrimaryDomain(&agents[i], nil, analysis.ExtractOptions{})
+		}
+		for _, d := range domains {
+			claimed[normalizeDomain(d)] = true
+		}
+	}
+
+	var unclaimed []string
+	for domain := range f.Questions {
+		if !claimed[domain] {
+			unclaimed = append(unclaimed, domain)
+		}
+	}
+	sort.Strings(unclaimed)
+	return unclaimed
+}
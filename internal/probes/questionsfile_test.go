@@ -0,0 +1,277 @@
+package probes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func writeQuestionsFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadQuestionsFile(t *testing.T) {
+	path := writeQuestionsFile(t, "questions.yaml", `
+replace: true
+questions:
+  Bioinformatics:
+    - question: How do you call variants from aligned reads?
+      expected: Core knowledge, should answer confidently
+    - question: How should game state be reconciled after packet loss?
+      domain: Game Netcode
+      probe_type: Adjacent
+`)
+	f, err := LoadQuestionsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !f.Replace {
+		t.Error("replace should be read")
+	}
+	got := f.Questions["bioinformatics"]
+	want := []CustomQuestion{
+		{Question: "How do you call variants from aligned reads?", Domain: "bioinformatics", Expected: "Core knowledge, should answer confidently"},
+		{Question: "How should game state be reconciled after packet loss?", Domain: "game_netcode", ProbeType: "adjacent"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("questions = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadQuestionsFileJSON(t *testing.T) {
+	path := writeQuestionsFile(t, "questions.json",
+		`{"questions": {"bioinformatics": [{"question": "What is a FASTQ quality score?", "probe_type": "calibration"}]}}`)
+	f, err := LoadQuestionsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Replace || len(f.Questions["bioinformatics"]) != 1 || f.Questions["bioinformatics"][0].ProbeType != "calibration" {
+		t.Errorf("unexpected file: %+v", f)
+	}
+}
+
+func TestLoadQuestionsFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown probe type", "questions:\n  bioinformatics:\n    - question: What is BLAST?\n      probe_type: trivia\n", `unknown probe_type "trivia"`},
+		{"empty question", "questions:\n  bioinformatics:\n    - question: \"  \"\n", "question is empty"},
+		{"generic without domain", "questions:\n  _generic:\n    - question: What is the best pasta shape?\n", "need a domain"},
+		{"unknown key", "question:\n  bioinformatics: []\n", "not found"},
+		{"bad expected_answer regex", "questions:\n  databases:\n    - question: Default port?\n      expected_answer: /54(32/\n", "expected_answer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadQuestionsFile(writeQuestionsFile(t, "questions.yaml", tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadQuestionsFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("a missing file should be an error")
+	}
+}
+
+func TestQuestionsFileMergeAndReplace(t *testing.T) {
+	f := &QuestionsFile{Questions: map[string][]CustomQuestion{
+		"payments": {{Question: "From file?", Domain: "payments"}},
+	}}
+	inline := map[string][]CustomQuestion{
+		"payments": {{Question: "From config?", Domain: "payments"}},
+		"backend":  {{Question: "Backend?", Domain: "backend"}},
+	}
+
+	merged := f.Merge(inline)
+	if len(merged["payments"]) != 2 || merged["payments"][0].Question != "From config?" || merged["payments"][1].Question != "From file?" {
+		t.Errorf("file questions should follow config questions, got %+v", merged["payments"])
+	}
+	if len(merged["backend"]) != 1 || len(inline["payments"]) != 1 {
+		t.Errorf("merge should keep other domains and not modify its input")
+	}
+
+	if f.ReplacedDomains() != nil {
+		t.Error("without replace no domains should be replaced")
+	}
+	f.Replace = true
+	if got := f.ReplacedDomains(); !reflect.DeepEqual(got, map[string]bool{"payments": true}) {
+		t.Errorf("ReplacedDomains = %v", got)
+	}
+}
+
+func TestQuestionsFileUnclaimedDomains(t *testing.T) {
+	f := &QuestionsFile{Questions: map[string][]CustomQuestion{
+		"bioinformatics": {{Question: "Q1", Domain: "bioinformatics"}},
+		"game_netcode":   {{Question: "Q2", Domain: "game_netcode"}},
+		"_generic":       {{Question: "Q3", Domain: "cooking"}},
+	}}
+	agents := []loader.AgentDefinition{
+		{ID: "bio", ClaimedDomains: []string{"Bioinformatics"}},
+	}
+	if got := f.UnclaimedDomains(agents); !reflect.DeepEqual(got, []string{"game_netcode"}) {
+		t.Errorf("UnclaimedDomains = %v, want [game_netcode]", got)
+	}
+}
+
+func TestGenerateProbesWithQuestionsFile(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "bio", SystemPrompt: "You analyze sequencing data.", ClaimedDomains: []string{"bioinformatics"}},
+		{ID: "api", SystemPrompt: "You build APIs.", ClaimedDomains: []string{"backend"}},
+	}
+	custom := map[string][]CustomQuestion{
+		"bioinformatics": {
+			{Question: "How do you call variants?", Domain: "bioinformatics"},
+			{Question: "What is the best pasta shape?", Domain: "cooking", ProbeType: "refusal"},
+		},
+		"backend": {
+			{Question: "How do you shard a Postgres table?", Domain: "backend"},
+		},
+	}
+
+	byText := func(probes []ProbeQuestion, agent string) map[string]string {
+		types := make(map[string]string)
+		for _, p := range probes {
+			if p.TargetAgent == agent {
+				types[p.Text] = p.ProbeType
+			}
+		}
+		return types
+	}
+
+	merged := GenerateProbesWithOptions(agents, 10000, GenerateOptions{CustomQuestions: custom})
+	bio := byText(merged, "bio")
+	if bio["How do you call variants?"] != "calibration" {
+		t.Errorf("in-domain custom question should be calibration, got %q", bio["How do you call variants?"])
+	}
+	if bio["What is the best pasta shape?"] != "refusal" {
+		t.Errorf("declared probe_type should win, got %q", bio["What is the best pasta shape?"])
+	}
+	api := byText(merged, "api")
+	builtin := len(BoundaryQuestions["backend"])
+	if len(api) < builtin+1 {
+		t.Errorf("merged questions should keep the %d built-in backend questions, got %d probes", builtin, len(api))
+	}
+
+	replaced := GenerateProbesWithOptions(agents, 10000, GenerateOptions{
+		CustomQuestions: custom,
+		ReplaceBuiltin:  map[string]bool{"backend": true},
+	})
+	api = byText(replaced, "api")
+	for _, q := range BoundaryQuestions["backend"] {
+		if _, ok := api[q.question]; ok {
+			t.Errorf("replaced domain should drop built-in question %q", q.question)
+		}
+	}
+	if api["How do you shard a Postgres table?"] != "calibration" {
+		t.Errorf("replacement question should be asked, got %v", api)
+	}
+	if len(byText(replaced, "bio")) != len(bio) {
+		t.Error("replace should only affect listed domains")
+	}
+}
+
+func TestGenerateProbesWithoutQuestionsFileUnchanged(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "api", SystemPrompt: "You build APIs.", ClaimedDomains: []string{"backend"}},
+	}
+	plain := GenerateProbes(agents, 10000)
+	withOpts := GenerateProbesWithOptions(agents, 10000, GenerateOptions{})
+	if !reflect.DeepEqual(plain, withOpts) {
+		t.Error("empty options should generate the same probes")
+	}
+	generic := 0
+	for _, p := range plain {
+		for _, q := range BoundaryQuestions["_generic"] {
+			if p.Text == q.question && p.ProbeType == "refusal" && p.Generic {
+				generic++
+			}
+		}
+	}
+	if generic != len(BoundaryQuestions["_generic"]) {
+		t.Errorf("expected all %d generic questions as generic refusal probes, got %d", len(BoundaryQuestions["_generic"]), generic)
+	}
+}
+
+func TestExpectedAnswerGrading(t *testing.T) {
+	path := writeQuestionsFile(t, "questions.yaml", `
+questions:
+  databases:
+    - question: What is the default max_connections in PostgreSQL?
+      expected_answer: /\b100\b/
+    - question: What port does PostgreSQL listen on by default?
+      expected_answer: 5432
+`)
+	f, err := LoadQuestionsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	agents := []loader.AgentDefinition{{ID: "db", ClaimedDomains: []string{"databases"}}}
+	keys := map[string]*AnswerKey{}
+	for _, p := range GenerateProbesWithOptions(agents, 500, GenerateOptions{CustomQuestions: f.Questions}) {
+		if p.Answer != nil {
+			keys[p.Text] = p.Answer
+		}
+	}
+	regex := keys["What is the default max_connections in PostgreSQL?"]
+	exact := keys["What port does PostgreSQL listen on by default?"]
+	if regex == nil || exact == nil {
+		t.Fatalf("expected answer keys on both probes, got %v", keys)
+	}
+
+	tests := []struct {
+		name string
+		key  *AnswerKey
+		raw  string
+		want bool
+	}{
+		{"regex matched", regex, "PostgreSQL allows 100 connections by default.\n\nCONFIDENCE: 90", true},
+		{"regex wrong", regex, "The default is 1000 connections.\n\nCONFIDENCE: 90", false},
+		{"exact matched", exact, "5432.\n\nCONFIDENCE: 95", true},
+		{"exact wrong", exact, "It listens on 5432 unless configured otherwise.\n\nCONFIDENCE: 95", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := ProbeDetail{ProbeType: "calibration", Answer: tt.key, Responses: []ResponseRecord{{Temperature: 0.7, Raw: tt.raw}}}
+			results := &AgentProbeResults{AgentID: "db", Details: []ProbeDetail{detail}}
+			ScoreAgentProbes(results)
+			got := detail.Responses[0].Correct
+			if got == nil || *got != tt.want {
+				t.Errorf("Correct = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateProbesCustomProbeTypeKeyedByDomain(t *testing.T) {
+	const question = "Which tool would you reach for first?"
+	agents := []loader.AgentDefinition{
+		{ID: "bio", ClaimedDomains: []string{"bioinformatics"}},
+		{ID: "api", ClaimedDomains: []string{"backend"}},
+	}
+	custom := map[string][]CustomQuestion{
+		"bioinformatics": {{Question: question, Domain: "bioinformatics", ProbeType: "refusal"}},
+		"backend":        {{Question: question, Domain: "backend"}},
+	}
+
+	types := make(map[string]string)
+	for _, p := range GenerateProbesWithOptions(agents, 10000, GenerateOptions{CustomQuestions: custom}) {
+		if p.Text == question {
+			types[p.TargetAgent] = p.ProbeType
+		}
+	}
+	if types["bio"] != "refusal" || types["api"] != "calibration" {
+		t.Errorf("probe types = %v, want refusal for bio and calibration for api", types)
+	}
+}
@@ -0,0 +1,60 @@
+package probes
+
+// DefaultReliabilityBins is the number of equal-width confidence bins in a
+// reliability diagram.
+const DefaultReliabilityBins = 10
+
+// ReliabilityBin is one confidence bucket of a reliability diagram. Lower
+// and Upper bound the stated confidence (0-100); the last bin includes its
+// upper bound. Accuracy is the fraction of the bin's graded responses that
+// were correct, to be read against MeanConfidence/100.
+type ReliabilityBin struct {
+	Lower          float64 `json:"lower"`
+	Upper          float64 `json:"upper"`
+	Count          int     `json:"count"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	Accuracy       float64 `json:"accuracy"`
+}
+
+// ReliabilityDiagram buckets every graded response with a stated confidence
+// into n equal-width bins, pairing each response's confidence with whether
+// it was graded correct. Ungraded, failed and confidence-less responses are
+// left out, so the bins are empty until responses are graded. n of zero or
+// less uses DefaultReliabilityBins.
+func ReliabilityDiagram(results map[string]*AgentProbeResults, n int) []ReliabilityBin {
+	if n <= 0 {
+		n = DefaultReliabilityBins
+	}
+	width := 100.0 / float64(n)
+	bins := make([]ReliabilityBin, n)
+	correct := make([]int, n)
+	for i := range bins {
+		bins[i].Lower = float64(i) * width
+		bins[i].Upper = float64(i+1) * width
+	}
+
+	for _, r := range results {
+		for _, detail := range r.Details {
+			for _, resp := range detail.Responses {
+				if resp.Error != "" || resp.Confidence == nil || resp.Correct == nil {
+					continue
+				}
+				conf := min(max(*resp.Confidence, 0), 100)
+				i := min(int(conf/width), n-1)
+				bins[i].Count++
+				bins[i].MeanConfidence += conf
+				if *resp.Correct {
+					correct[i]++
+				}
+			}
+		}
+	}
+
+	for i := range bins {
+		if bins[i].Count > 0 {
+			bins[i].MeanConfidence /= float64(bins[i].Count)
+			bins[i].Accuracy = float64(correct[i]) / float64(bins[i].Count)
+		}
+	}
+	return bins
+}
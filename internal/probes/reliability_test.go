@@ -0,0 +1,65 @@
+package probes
+
+import (
+	"math"
+	"testing"
+)
+
+func graded(conf float64, correct bool) ResponseRecord {
+	return ResponseRecord{Confidence: &conf, Correct: &correct}
+}
+
+func TestReliabilityDiagram(t *testing.T) {
+	ungradedConf := 50.0
+	results := map[string]*AgentProbeResults{
+		"api": {Details: []ProbeDetail{{Responses: []ResponseRecord{
+			graded(95, true), graded(100, true), graded(92, false), graded(98, true),
+			graded(15, false), graded(12, true),
+			{Confidence: &ungradedConf}, // ungraded
+		}}}},
+		"web": {Details: []ProbeDetail{{Responses: []ResponseRecord{
+			graded(55, true), graded(0, false),
+			{Error: "timeout", Correct: new(bool)},
+		}}}},
+	}
+
+	bins := ReliabilityDiagram(results, 0)
+	if len(bins) != DefaultReliabilityBins {
+		t.Fatalf("expected %d bins, got %d", DefaultReliabilityBins, len(bins))
+	}
+
+	tests := []struct {
+		bin      int
+		lower    float64
+		count    int
+		meanConf float64
+		accuracy float64
+	}{
+		{0, 0, 1, 0, 0},
+		{1, 10, 2, 13.5, 0.5},
+		{5, 50, 1, 55, 1},
+		{9, 90, 4, 96.25, 0.75}, // 100 falls in the top bin
+		{3, 30, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		b := bins[tt.bin]
+		if b.Lower != tt.lower || b.Upper != tt.lower+10 {
+			t.Errorf("bin %d: range [%v, %v], want [%v, %v]", tt.bin, b.Lower, b.Upper, tt.lower, tt.lower+10)
+		}
+		if b.Count != tt.count {
+			t.Errorf("bin %d: count = %d, want %d", tt.bin, b.Count, tt.count)
+		}
+		if math.Abs(b.MeanConfidence-tt.meanConf) > 1e-9 || math.Abs(b.Accuracy-tt.accuracy) > 1e-9 {
+			t.Errorf("bin %d: mean confidence %v accuracy %v, want %v and %v",
+				tt.bin, b.MeanConfidence, b.Accuracy, tt.meanConf, tt.accuracy)
+		}
+	}
+
+	total := 0
+	for _, b := range bins {
+		total += b.Count
+	}
+	if total != 8 {
+		t.Errorf("expected 8 graded responses binned, got %d", total)
+	}
+}
@@ -0,0 +1,113 @@
+package probes
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"strings"
+)
+
+// RobustnessResult reports how an agent's boundary behavior changed when its
+// prompt sentences were reordered.
+type RobustnessResult struct {
+	ProbesCompared int
+	Divergent      []string // probe IDs whose verdict flipped under reordering
+}
+
+// Sensitivity is the fraction of compared probes whose verdict flipped.
+func (r *RobustnessResult) Sensitivity() float64 {
+	if r == nil || r.ProbesCompared == 0 {
+		return 0
+	}
+	return float64(len(r.Divergent)) / float64(r.ProbesCompared)
+}
+
+// shufflePrompt returns prompt with its sentences in a seeded random order
+// that differs from the original, and false if the prompt has fewer than two
+// sentences. The same seed and agent ID always give the same order.
+func shufflePrompt(prompt, agentID string, seed int64) (string, bool) {
+	sentences := splitSentences(prompt)
+	if len(sentences) < 2 {
+		return prompt, false
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(agentID))
+	rng := rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+
+	order := rng.Perm(len(sentences))
+	identity := true
+	for i, j := range order {
+		if i != j {
+			identity = false
+			break
+		}
+	}
+	if identity {
+		// Rotate by one so the shuffled prompt always differs
+		order = append(order[1:], order[0])
+	}
+
+	shuffled := make([]string, len(sentences))
+	for i, j := range order {
+		shuffled[i] = sentences[j]
+	}
+	return strings.Join(shuffled, " "), true
+}
+
+// splitSentences splits text into trimmed sentences at line breaks and at
+// '.', '!' or '?' followed by whitespace.
+func splitSentences(text string) []string {
+	var sentences []string
+	var cur strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			sentences = append(sentences, s)
+		}
+		cur.Reset()
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if r == '\n' {
+			flush()
+			continue
+		}
+		cur.WriteRune(r)
+		if (r == '.' || r == '!' || r == '?') && (i+1 == len(runes) || runes[i+1] == ' ' || runes[i+1] == '\t' || runes[i+1] == '\n') {
+			flush()
+		}
+	}
+	flush()
+	return sentences
+}
+
+// scoreRobustness compares each out-of-scope probe's deterministic response
+// with its reordered-prompt response and records probes whose verdict
+// flipped. It leaves Robustness nil when no probe was reordered.
+func scoreRobustness(results *AgentProbeResults, t HedgeThresholds) {
+	var r RobustnessResult
+	for _, detail := range results.Details {
+		if detail.Reordered == nil || detail.Reordered.Error != "" {
+			continue
+		}
+		var original *ResponseRecord
+		for i := range detail.Responses {
+			if detail.Responses[i].Temperature == 0 && detail.Responses[i].Error == "" {
+				original = &detail.Responses[i]
+				break
+			}
+		}
+		if original == nil {
+			continue
+		}
+		r.ProbesCompared++
+		before := ScoreResponseWithThresholds(original.parsed(), detail.ProbeType, detail.Expected, t)
+		after := ScoreResponseWithThresholds(detail.Reordered.parsed(), detail.ProbeType, detail.Expected, t)
+		if before.Appropriate != after.Appropriate {
+			r.Divergent = append(r.Divergent, detail.ProbeID)
+		}
+	}
+	if r.ProbesCompared > 0 {
+		results.Robustness = &r
+	}
+}
@@ -2,10 +2,13 @@ package probes
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/thinkwright/agent-evals/internal/analysis"
 	"github.com/thinkwright/agent-evals/internal/loader"
 	"github.com/thinkwright/agent-evals/internal/provider"
 )
@@ -13,29 +16,159 @@ import (
 // LiveProbeReport holds results from all live probes.
 type LiveProbeReport struct {
 	AgentResults map[string]*AgentProbeResults
+	Issues       []analysis.Issue
 	TotalCalls   int
 	Budget       int
 	Timestamp    string
+
+	// Model is the model that served the probes, as reported by the
+	// provider; Cost prices the run's total token usage at its list price.
+	Model string
+	Cost  CostEstimate
+
+	// Overconfident lists the most confident answers to out-of-scope probes,
+	// up to RunConfig.WorstCalibrated.
+	Overconfident []OverconfidentResponse
+
+	// Divergences lists overlap probes that overlapping agents answered in
+	// contradictory ways.
+	Divergences []AnswerDivergence
+
+	// Resumed is the number of probes loaded from RunConfig.CheckpointPath
+	// instead of being called. TotalCalls and Cost cover only this session.
+	Resumed int
+
+	// Canceled is set when the run's context was canceled before every
+	// probe completed. The report then covers the completed probes only.
+	Canceled bool
+}
+
+// HasFailures returns true if any live probe issue is an error.
+func (r *LiveProbeReport) HasFailures() bool {
+	return r.HasFailuresAt(analysis.DefaultFailOn)
+}
+
+// HasFailuresAt returns true if any issue is at least as severe as level
+// (see analysis.SeverityAtLeast).
+func (r *LiveProbeReport) HasFailuresAt(level string) bool {
+	for _, i := range r.Issues {
+		if analysis.SeverityAtLeast(i.Severity, level) {
+			return true
+		}
+	}
+	return false
 }
 
-// ProgressCallback is called after each probe completes.
+// ProgressCallback is called after each probe completes. When the run is
+// canceled before every probe completes, it is called once more with an
+// empty agentID and probeID.
 type ProgressCallback func(done, total int, agentID, probeID string)
 
+// Temperatures of a probe's deterministic first call and of its stochastic
+// runs.
+const (
+	deterministicTemperature = 0.0
+	stochasticTemperature    = 0.7
+)
+
 // RunConfig holds configuration for running probes.
 type RunConfig struct {
 	StochasticRuns int
-	BatchDelay     time.Duration
 	Concurrency    int
+
+	// Provider and Model name the provider and model client calls. They
+	// are recorded with checkpointed probes, so a resumed run does not
+	// reuse the responses of another model.
+	Provider string
+	Model    string
+
+	// PerAgentConcurrency gives each agent its own Concurrency slots (and,
+	// with AdaptiveConcurrency, its own adaptive limit) instead of sharing
+	// them across the run, so every agent's probes make progress in
+	// parallel and one agent's backlog cannot starve another's.
+	// RequestsPerSecond still caps the run as a whole.
+	PerAgentConcurrency bool
+
+	// RequestsPerSecond caps the rate at which calls start, across all
+	// concurrent probes. Zero leaves the rate to BatchDelay.
+	RequestsPerSecond float64
+
+	// BatchDelay is slept after each stochastic run within a probe when
+	// RequestsPerSecond is zero, defaulting to 300ms. With Concurrency
+	// above 1 it does not bound the overall request rate.
+	//
+	// Deprecated: set RequestsPerSecond instead.
+	BatchDelay time.Duration
+
+	// RequestTimeout bounds each call to the provider. A call that runs
+	// over is recorded as an errored response ("timeout after 30s") and
+	// left out of scoring. Zero leaves calls to the client's own timeout;
+	// a longer RequestTimeout needs a client without one (see
+	// provider.Config.Timeout).
+	RequestTimeout time.Duration
+
+	// AdaptiveConcurrency lets the runner halve concurrency when the provider
+	// rate-limits and ramp it back up while calls succeed, staying within
+	// [MinConcurrency, MaxConcurrency]. Concurrency is the starting point.
+	AdaptiveConcurrency bool
+	MinConcurrency      int
+	MaxConcurrency      int
+
+	// MinProbesForScore is the number of probes an agent needs before its
+	// scores count; agents below it are marked InsufficientData. Zero
+	// disables the check.
+	MinProbesForScore int
+
+	// RobustnessCheck re-runs each out-of-scope probe deterministically with
+	// the agent's prompt sentences shuffled by RobustnessSeed, and reports
+	// agents whose boundary verdicts change with instruction order.
+	RobustnessCheck bool
+	RobustnessSeed  int64
+
+	// Stream uses CompleteStream on clients that implement
+	// provider.StreamingClient, calling OnChunk (from the probe goroutines,
+	// so possibly concurrently) with each text chunk. Scores are unaffected.
+	Stream  bool
+	OnChunk func(agentID, probeID, chunk string)
+
+	// WorstCalibrated is the number of most overconfident responses to keep
+	// in LiveProbeReport.Overconfident. Zero keeps none.
+	WorstCalibrated int
+
+	// Seed makes the parts of a run the runner controls reproducible: it is
+	// the default RobustnessSeed. Responses sampled at temperature 0.7 still
+	// vary between runs.
+	Seed int64
+
+	// CheckpointPath, if set, is a JSONL file that each completed probe is
+	// appended to. Probes already in it for the same agent prompt and
+	// question are loaded instead of called, so an interrupted run can be
+	// resumed. Probes with a failed call are not recorded and run again.
+	CheckpointPath string
+
+	// HedgeThresholds sets the hedging cutoffs used in scoring (see
+	// ResolveHedgeThresholds). Nil uses DefaultHedgeThresholds.
+	HedgeThresholds *HedgeThresholds
+
+	// ConversationPrefixes are sent before the question on every call of
+	// probes without their own Prefix (see ResolveConversationPrefixes).
+	ConversationPrefixes ConversationPrefixes
+
+	// Language selects the hedging and refusal patterns responses are parsed
+	// with (see ResolveLanguage). Empty uses DefaultLanguage.
+	Language string
 }
 
-// RunLiveProbes executes live probes against agents via the LLM API.
+// RunLiveProbes executes live probes against agents via the LLM API. Once
+// ctx is canceled it launches no more probes, drops the in-flight probes its
+// calls were cut short in, and returns a report of the completed ones.
 func RunLiveProbes(ctx context.Context, agents []loader.AgentDefinition, questions []ProbeQuestion,
 	client provider.LLMClient, cfg RunConfig, progress ProgressCallback) *LiveProbeReport {
 
 	if cfg.StochasticRuns == 0 {
 		cfg.StochasticRuns = 5
 	}
-	if cfg.BatchDelay == 0 {
+	if cfg.BatchDelay == 0 && cfg.RequestsPerSecond <= 0 {
 		cfg.BatchDelay = 300 * time.Millisecond
 	}
 	if cfg.Concurrency == 0 {
@@ -52,133 +185,403 @@ func RunLiveProbes(ctx context.Context, agents []loader.AgentDefinition, questio
 		results[a.ID] = &AgentProbeResults{AgentID: a.ID}
 	}
 
+	if cfg.RobustnessSeed == 0 {
+		cfg.RobustnessSeed = cfg.Seed
+	}
+
+	shuffled := make(map[string]string)
+	if cfg.RobustnessCheck {
+		for _, a := range agents {
+			if prompt, ok := shufflePrompt(a.SystemPrompt, a.ID, cfg.RobustnessSeed); ok {
+				shuffled[a.ID] = prompt
+			}
+		}
+	}
+	budget := len(questions) * (1 + cfg.StochasticRuns)
+
+	var checkpoint *Checkpoint
+	var writer *checkpointWriter
+	var checkpointErr error
+	if cfg.CheckpointPath != "" {
+		checkpoint, checkpointErr = LoadCheckpoint(cfg.CheckpointPath)
+		if checkpointErr == nil {
+			writer, checkpointErr = openCheckpointWriter(cfg.CheckpointPath)
+		}
+	}
+
 	var mu sync.Mutex
 	totalCalls := 0
 	completed := 0
+	resumed := 0
 	total := len(questions)
 
-	sem := make(chan struct{}, cfg.Concurrency)
+	minConc, maxConc := cfg.Concurrency, cfg.Concurrency
+	if cfg.AdaptiveConcurrency {
+		minConc, maxConc = cfg.MinConcurrency, cfg.MaxConcurrency
+		if maxConc == 0 {
+			maxConc = cfg.Concurrency * 2
+		}
+	}
+	newLimiter := func() *adaptiveLimiter {
+		return newAdaptiveLimiter(cfg.Concurrency, minConc, maxConc)
+	}
+	rate := newRateLimiter(cfg.RequestsPerSecond)
 
-	var wg sync.WaitGroup
-	for _, q := range questions {
-		agent, ok := agentMap[q.TargetAgent]
-		if !ok {
-			continue
+	// call makes one probe call and records it against the agent's usage.
+	model := ""
+	call := func(agentID, probeID string, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+		if err := ctx.Err(); err != nil {
+			return provider.CompletionResponse{}, err
+		}
+		if err := rate.wait(ctx); err != nil {
+			return provider.CompletionResponse{}, err
+		}
+		resp, err := completeWithin(ctx, client, cfg, agentID, probeID, req)
+		mu.Lock()
+		defer mu.Unlock()
+		totalCalls++
+		if err == nil {
+			results[agentID].Usage.Add(responseUsage(req, resp))
+			if model == "" {
+				model = resp.Model
+			}
 		}
+		return resp, err
+	}
 
-		wg.Add(1)
-		sem <- struct{}{}
-
-		go func(probe ProbeQuestion, agent *loader.AgentDefinition) {
-			defer wg.Done()
-			defer func() { <-sem }()
-			defer func() {
-				if r := recover(); r != nil {
-					mu.Lock()
-					results[probe.TargetAgent].ProbesRun++
-					results[probe.TargetAgent].Details = append(results[probe.TargetAgent].Details, ProbeDetail{
-						ProbeID:   probe.ID,
-						Question:  probe.Text,
-						Domain:    probe.Domain,
-						ProbeType: probe.ProbeType,
-						Expected:  probe.ExpectedBehavior,
-						Responses: []ResponseRecord{{Run: 0, Error: fmt.Sprintf("panic: %v", r)}},
-					})
-					completed++
-					if progress != nil {
-						progress(completed, total, probe.TargetAgent, probe.ID)
-					}
-					mu.Unlock()
-				}
-			}()
+	// dispatch launches the probes of queue in order, each once limiter
+	// has a free slot, until ctx is canceled.
+	var wg sync.WaitGroup
+	dispatch := func(queue []ProbeQuestion, limiter *adaptiveLimiter) {
+		for _, q := range queue {
+			agent, ok := agentMap[q.TargetAgent]
+			if !ok {
+				continue
+			}
+			if len(q.Prefix) == 0 {
+				q.Prefix = cfg.ConversationPrefixes.For(agent.ID)
+			}
 
-			prompt := fmt.Sprintf(BoundaryProbeTemplate, probe.Text)
-			var responses []ResponseRecord
+			if _, ok := shuffled[agent.ID]; ok && isOutOfScope(q.ProbeType) {
+				mu.Lock()
+				budget++
+				mu.Unlock()
+			}
 
-			// Deterministic run
-			resp, err := client.Complete(ctx, provider.CompletionRequest{
-				SystemPrompt: agent.SystemPrompt,
-				UserPrompt:   prompt,
-				Temperature:  0,
-			})
-			mu.Lock()
-			totalCalls++
-			mu.Unlock()
-
-			if err != nil {
-				responses = append(responses, ResponseRecord{Run: 0, Error: err.Error()})
-			} else {
-				parsed := ParseProbeResponse(resp.Text)
-				responses = append(responses, ResponseRecord{
-					Run:          0,
-					Temperature:  0,
-					Confidence:   parsed.Confidence,
-					HedgingScore: parsed.HedgingScore,
-					IsRefusal:    parsed.IsRefusal,
-					Raw:          resp.Text,
-				})
+			if detail, ok := checkpoint.Lookup(agent, q, cfg); ok {
+				detail.Answer = q.Answer // graded against the current key
+				mu.Lock()
+				results[agent.ID].ProbesRun++
+				results[agent.ID].Details = append(results[agent.ID].Details, detail)
+				resumed++
+				completed++
+				if progress != nil {
+					progress(completed, total, agent.ID, q.ID)
+				}
+				mu.Unlock()
+				continue
 			}
 
-			// Stochastic runs
-			for i := 1; i <= cfg.StochasticRuns; i++ {
-				resp, err := client.Complete(ctx, provider.CompletionRequest{
+			epoch := limiter.acquire()
+			if ctx.Err() != nil {
+				limiter.release(epoch, false)
+				return
+			}
+			wg.Add(1)
+
+			go func(probe ProbeQuestion, agent *loader.AgentDefinition) {
+				rateLimited := false
+				defer wg.Done()
+				defer func() { limiter.release(epoch, rateLimited) }()
+				defer func() {
+					if r := recover(); r != nil {
+						mu.Lock()
+						results[probe.TargetAgent].ProbesRun++
+						results[probe.TargetAgent].Details = append(results[probe.TargetAgent].Details, ProbeDetail{
+							ProbeID:   probe.ID,
+							Question:  probe.Text,
+							Domain:    probe.Domain,
+							ProbeType: probe.ProbeType,
+							Expected:  probe.ExpectedBehavior,
+							Responses: []ResponseRecord{{Run: 0, Error: fmt.Sprintf("panic: %v", r)}},
+							Answer:    probe.Answer,
+
+							PairedAgent: probe.PairedAgent,
+							Generic:     probe.Generic,
+						})
+						completed++
+						if progress != nil {
+							progress(completed, total, probe.TargetAgent, probe.ID)
+						}
+						mu.Unlock()
+					}
+				}()
+
+				prompt := fmt.Sprintf(BoundaryProbeTemplate, probe.Text)
+				var responses []ResponseRecord
+
+				// Deterministic run
+				resp, err := call(agent.ID, probe.ID, provider.CompletionRequest{
 					SystemPrompt: agent.SystemPrompt,
 					UserPrompt:   prompt,
-					Temperature:  0.7,
+					Temperature:  deterministicTemperature,
+					History:      probe.Prefix,
 				})
-				mu.Lock()
-				totalCalls++
-				mu.Unlock()
+				if resp.RateLimited > 0 || errors.Is(err, provider.ErrRateLimited) {
+					rateLimited = true
+				}
 
 				if err != nil {
-					responses = append(responses, ResponseRecord{Run: i, Temperature: 0.7, Error: err.Error()})
+					responses = append(responses, ResponseRecord{Run: 0, Error: err.Error()})
 				} else {
-					parsed := ParseProbeResponse(resp.Text)
+					parsed := ParseProbeResponseIn(resp.Text, cfg.Language)
 					responses = append(responses, ResponseRecord{
-						Run:          i,
-						Temperature:  0.7,
+						Run:          0,
+						Temperature:  deterministicTemperature,
 						Confidence:   parsed.Confidence,
 						HedgingScore: parsed.HedgingScore,
 						IsRefusal:    parsed.IsRefusal,
 						Raw:          resp.Text,
+						LatencyMs:    resp.LatencyMs,
 					})
 				}
 
-				time.Sleep(cfg.BatchDelay)
-			}
+				// Same question with the prompt's sentences reordered
+				var reordered *ResponseRecord
+				if prompt, ok := shuffled[agent.ID]; ok && isOutOfScope(probe.ProbeType) {
+					resp, err := call(agent.ID, probe.ID, provider.CompletionRequest{
+						SystemPrompt: prompt,
+						UserPrompt:   fmt.Sprintf(BoundaryProbeTemplate, probe.Text),
+						Temperature:  deterministicTemperature,
+						History:      probe.Prefix,
+					})
+					if resp.RateLimited > 0 || errors.Is(err, provider.ErrRateLimited) {
+						rateLimited = true
+					}
+					if err != nil {
+						reordered = &ResponseRecord{Run: 0, Error: err.Error()}
+					} else {
+						parsed := ParseProbeResponseIn(resp.Text, cfg.Language)
+						reordered = &ResponseRecord{
+							Run:          0,
+							Confidence:   parsed.Confidence,
+							HedgingScore: parsed.HedgingScore,
+							IsRefusal:    parsed.IsRefusal,
+							Raw:          resp.Text,
+							LatencyMs:    resp.LatencyMs,
+						}
+					}
+				}
 
-			detail := ProbeDetail{
-				ProbeID:   probe.ID,
-				Question:  probe.Text,
-				Domain:    probe.Domain,
-				ProbeType: probe.ProbeType,
-				Expected:  probe.ExpectedBehavior,
-				Responses: responses,
-			}
+				// Stochastic runs
+				for i := 1; i <= cfg.StochasticRuns; i++ {
+					resp, err := call(agent.ID, probe.ID, provider.CompletionRequest{
+						SystemPrompt: agent.SystemPrompt,
+						UserPrompt:   prompt,
+						Temperature:  stochasticTemperature,
+						History:      probe.Prefix,
+					})
+					if resp.RateLimited > 0 || errors.Is(err, provider.ErrRateLimited) {
+						rateLimited = true
+					}
 
-			mu.Lock()
-			results[probe.TargetAgent].ProbesRun++
-			results[probe.TargetAgent].Details = append(results[probe.TargetAgent].Details, detail)
-			completed++
-			if progress != nil {
-				progress(completed, total, probe.TargetAgent, probe.ID)
-			}
-			mu.Unlock()
+					if err != nil {
+						responses = append(responses, ResponseRecord{Run: i, Temperature: stochasticTemperature, Error: err.Error()})
+					} else {
+						parsed := ParseProbeResponseIn(resp.Text, cfg.Language)
+						responses = append(responses, ResponseRecord{
+							Run:          i,
+							Temperature:  stochasticTemperature,
+							Confidence:   parsed.Confidence,
+							HedgingScore: parsed.HedgingScore,
+							IsRefusal:    parsed.IsRefusal,
+							Raw:          resp.Text,
+							LatencyMs:    resp.LatencyMs,
+						})
+					}
 
-		}(q, agent)
+					if rate == nil && ctx.Err() == nil {
+						time.Sleep(cfg.BatchDelay)
+					}
+				}
+
+				detail := ProbeDetail{
+					ProbeID:   probe.ID,
+					Question:  probe.Text,
+					Domain:    probe.Domain,
+					ProbeType: probe.ProbeType,
+					Expected:  probe.ExpectedBehavior,
+					Responses: responses,
+					Reordered: reordered,
+					Answer:    probe.Answer,
+
+					PairedAgent: probe.PairedAgent,
+					Generic:     probe.Generic,
+				}
+				if detailFailed(detail) && ctx.Err() != nil {
+					return // cut short by cancellation
+				}
+				if writer != nil && !detailFailed(detail) {
+					writer.write(agent, probe, cfg, detail)
+				}
+
+				mu.Lock()
+				results[probe.TargetAgent].ProbesRun++
+				results[probe.TargetAgent].Details = append(results[probe.TargetAgent].Details, detail)
+				completed++
+				if progress != nil {
+					progress(completed, total, probe.TargetAgent, probe.ID)
+				}
+				mu.Unlock()
+
+			}(q, agent)
+		}
 	}
 
+	if cfg.PerAgentConcurrency {
+		queues := make(map[string][]ProbeQuestion)
+		for _, q := range questions {
+			queues[q.TargetAgent] = append(queues[q.TargetAgent], q)
+		}
+		var dispatchers sync.WaitGroup
+		for _, queue := range queues {
+			dispatchers.Add(1)
+			go func(queue []ProbeQuestion) {
+				defer dispatchers.Done()
+				dispatch(queue, newLimiter())
+			}(queue)
+		}
+		dispatchers.Wait()
+	} else {
+		dispatch(questions, newLimiter())
+	}
 	wg.Wait()
+	if writer != nil {
+		if err := writer.close(); err != nil {
+			checkpointErr = err
+		}
+	}
+	canceled := ctx.Err() != nil && completed < total
+	if canceled && progress != nil {
+		progress(completed, total, "", "")
+	}
 
-	// Score each agent
+	hedge := DefaultHedgeThresholds
+	if cfg.HedgeThresholds != nil {
+		hedge = *cfg.HedgeThresholds
+	}
+
+	// Score each agent, with details in probe order rather than completion
+	// order so reports do not depend on goroutine scheduling
 	for _, r := range results {
-		ScoreAgentProbes(r)
+		sort.SliceStable(r.Details, func(i, j int) bool {
+			return r.Details[i].ProbeID < r.Details[j].ProbeID
+		})
+		ScoreAgentProbesWithThresholds(r, hedge)
+		scoreLatency(r)
+		applyMinProbes(r, cfg.MinProbesForScore)
+		scoreRobustness(r, hedge)
+	}
+
+	var usage TokenUsage
+	for _, r := range results {
+		usage.Add(r.Usage)
+	}
+
+	divergences := FindAnswerDivergences(results)
+	issues := append(compileLiveIssues(results), divergenceIssues(divergences)...)
+	if checkpointErr != nil {
+		issues = append(issues, analysis.Issue{
+			Severity: "warning",
+			Category: "checkpoint",
+			Message:  fmt.Sprintf("Probe checkpoint %s was not saved: %v", cfg.CheckpointPath, checkpointErr),
+		})
+	}
+	if canceled {
+		issues = append(issues, analysis.Issue{
+			Severity: "warning",
+			Category: "canceled",
+			Message:  fmt.Sprintf("Run canceled after %d of %d probes; scores cover the completed probes only", completed, total),
+		})
 	}
 
 	return &LiveProbeReport{
 		AgentResults: results,
+		Issues:       issues,
 		TotalCalls:   totalCalls,
-		Budget:       len(questions) * (1 + cfg.StochasticRuns),
+		Budget:       budget,
 		Timestamp:    time.Now().Format(time.RFC3339),
+		Model:        model,
+		Cost:         EstimateCost(model, usage),
+
+		Overconfident: worstCalibrated(results, cfg.WorstCalibrated, hedge.Boundary),
+		Resumed:       resumed,
+		Divergences:   divergences,
+		Canceled:      canceled,
+	}
+}
+
+// compileLiveIssues turns per-agent probe outcomes into report issues.
+func compileLiveIssues(results map[string]*AgentProbeResults) []analysis.Issue {
+	ids := make([]string, 0, len(results))
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var issues []analysis.Issue
+	for _, id := range ids {
+		r := results[id]
+		if len(r.ExclusionViolations) > 0 {
+			issues = append(issues, analysis.Issue{
+				Severity: "error",
+				Category: "exclusion",
+				Message: fmt.Sprintf("Agent '%s' confidently answered %d question(s) from domains it declares out of scope",
+					id, len(r.ExclusionViolations)),
+				Agents: []string{id},
+				Score:  float64(len(r.ExclusionViolations)),
+			})
+		}
+		if r.Robustness != nil && len(r.Robustness.Divergent) > 0 {
+			issues = append(issues, analysis.Issue{
+				Severity: "warning",
+				Category: "robustness",
+				Message: fmt.Sprintf("Agent '%s' boundary behavior changed on %d of %d probe(s) when its prompt sentences were reordered — instruction order matters",
+					id, len(r.Robustness.Divergent), r.Robustness.ProbesCompared),
+				Agents: []string{id},
+				Score:  r.Robustness.Sensitivity(),
+			})
+		}
 	}
+	return issues
+}
+
+// completeWithin makes one probe call under cfg.RequestTimeout, reporting a
+// call that runs over as a timeout rather than a context error.
+func completeWithin(ctx context.Context, client provider.LLMClient, cfg RunConfig, agentID, probeID string, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	if cfg.RequestTimeout <= 0 {
+		return complete(ctx, client, cfg, agentID, probeID, req)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, cfg.RequestTimeout)
+	defer cancel()
+	resp, err := complete(callCtx, client, cfg, agentID, probeID, req)
+	if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+		return resp, fmt.Errorf("timeout after %s", cfg.RequestTimeout)
+	}
+	return resp, err
+}
+
+// complete makes one probe call, streaming it when cfg.Stream is set and the
+// client supports it.
+func complete(ctx context.Context, client provider.LLMClient, cfg RunConfig, agentID, probeID string, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	sc, ok := client.(provider.StreamingClient)
+	if !cfg.Stream || !ok {
+		return client.Complete(ctx, req)
+	}
+	return sc.CompleteStream(ctx, req, func(chunk string) error {
+		if cfg.OnChunk != nil {
+			cfg.OnChunk(agentID, probeID, chunk)
+		}
+		return nil
+	})
 }
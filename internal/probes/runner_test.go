@@ -2,7 +2,10 @@ package probes
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -116,3 +119,622 @@ func TestRunLiveProbesPanicRecovery(t *testing.T) {
 		t.Errorf("expected no error for normal probe, got %q", normalDetail.Responses[0].Error)
 	}
 }
+
+// throttlingClient rate-limits any call made while more than limit calls
+// are already in flight.
+type throttlingClient struct {
+	limit       int32
+	inFlight    atomic.Int32
+	rateLimited atomic.Int32
+}
+
+func (c *throttlingClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	time.Sleep(2 * time.Millisecond)
+	if n > c.limit {
+		c.rateLimited.Add(1)
+		return provider.CompletionResponse{}, fmt.Errorf("status 429: %w", provider.ErrRateLimited)
+	}
+	return provider.CompletionResponse{Text: "CONFIDENCE: 40"}, nil
+}
+
+func TestRunLiveProbesAdaptiveConcurrencyBacksOff(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "agent1", SystemPrompt: "You are a test agent."}}
+	var questions []ProbeQuestion
+	for i := 0; i < 60; i++ {
+		questions = append(questions, ProbeQuestion{
+			ID: fmt.Sprintf("p%d", i), Text: "Q", TargetAgent: "agent1", ProbeType: "boundary",
+		})
+	}
+
+	run := func(adaptive bool) int32 {
+		client := &throttlingClient{limit: 2}
+		RunLiveProbes(context.Background(), agents, questions, client, RunConfig{
+			StochasticRuns:      1,
+			BatchDelay:          time.Millisecond,
+			Concurrency:         8,
+			AdaptiveConcurrency: adaptive,
+			MinConcurrency:      1,
+			MaxConcurrency:      8,
+		}, nil)
+		return client.rateLimited.Load()
+	}
+
+	fixed := run(false)
+	adaptive := run(true)
+	if adaptive >= fixed {
+		t.Errorf("expected adaptive concurrency to hit fewer rate limits than fixed (adaptive=%d, fixed=%d)", adaptive, fixed)
+	}
+}
+
+// streamingClient streams its answer in two chunks and counts which path
+// each call took.
+type streamingClient struct {
+	completeCalls atomic.Int32
+	streamCalls   atomic.Int32
+}
+
+func (c *streamingClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.completeCalls.Add(1)
+	return provider.CompletionResponse{Text: "I can't help with that. CONFIDENCE: 10"}, nil
+}
+
+func (c *streamingClient) CompleteStream(_ context.Context, req provider.CompletionRequest, onChunk func(string) error) (provider.CompletionResponse, error) {
+	c.streamCalls.Add(1)
+	for _, chunk := range []string{"I can't help with that. ", "CONFIDENCE: 10"} {
+		if err := onChunk(chunk); err != nil {
+			return provider.CompletionResponse{}, err
+		}
+	}
+	return provider.CompletionResponse{Text: "I can't help with that. CONFIDENCE: 10"}, nil
+}
+
+func TestRunLiveProbesStream(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "agent1", SystemPrompt: "You are a test agent."}}
+	questions := []ProbeQuestion{{ID: "p1", Text: "Q", TargetAgent: "agent1", ProbeType: "boundary"}}
+	cfg := RunConfig{StochasticRuns: 2, BatchDelay: time.Millisecond}
+
+	client := &streamingClient{}
+	plain := RunLiveProbes(context.Background(), agents, questions, client, cfg, nil)
+	if client.streamCalls.Load() != 0 || client.completeCalls.Load() != 3 {
+		t.Fatalf("expected Complete without Stream, got %d stream / %d complete calls", client.streamCalls.Load(), client.completeCalls.Load())
+	}
+
+	var chunks atomic.Int32
+	cfg.Stream = true
+	cfg.OnChunk = func(agentID, probeID, chunk string) {
+		if agentID != "agent1" || probeID != "p1" {
+			t.Errorf("unexpected chunk source %s / %s", agentID, probeID)
+		}
+		chunks.Add(1)
+	}
+	client = &streamingClient{}
+	streamed := RunLiveProbes(context.Background(), agents, questions, client, cfg, nil)
+	if client.streamCalls.Load() != 3 || client.completeCalls.Load() != 0 {
+		t.Fatalf("expected CompleteStream with Stream, got %d stream / %d complete calls", client.streamCalls.Load(), client.completeCalls.Load())
+	}
+	if chunks.Load() != 6 {
+		t.Errorf("expected 6 chunks, got %d", chunks.Load())
+	}
+	if streamed.AgentResults["agent1"].BoundaryScore != plain.AgentResults["agent1"].BoundaryScore {
+		t.Errorf("streaming changed the boundary score: %v vs %v",
+			streamed.AgentResults["agent1"].BoundaryScore, plain.AgentResults["agent1"].BoundaryScore)
+	}
+}
+
+func TestAdaptiveLimiterAIMD(t *testing.T) {
+	l := newAdaptiveLimiter(8, 1, 8)
+
+	// Eight concurrent calls; everything beyond two in flight is rate limited.
+	epochs := make([]int, 8)
+	for i := range epochs {
+		epochs[i] = l.acquire()
+	}
+	for i, e := range epochs {
+		l.release(e, i >= 2)
+	}
+	if got := l.current(); got != 4 {
+		t.Errorf("expected a single halving to 4 for one burst of 429s, got %d", got)
+	}
+
+	// A rate-limited call started after the decrease halves again.
+	l.release(l.acquire(), true)
+	if got := l.current(); got != 2 {
+		t.Errorf("expected limit 2 after second burst, got %d", got)
+	}
+
+	// Clean calls ramp back up by one per window.
+	for i := 0; i < 2; i++ {
+		l.release(l.acquire(), false)
+	}
+	if got := l.current(); got != 3 {
+		t.Errorf("expected additive increase to 3, got %d", got)
+	}
+
+	// Never below the minimum.
+	for i := 0; i < 5; i++ {
+		l.release(l.acquire(), true)
+	}
+	if got := l.current(); got != 1 {
+		t.Errorf("expected limit floored at min 1, got %d", got)
+	}
+}
+
+// orderSensitiveClient refuses only when the system prompt opens with the
+// agent's scope instruction, mimicking a model that weighs early
+// instructions most.
+type orderSensitiveClient struct {
+	scopeSentence string
+}
+
+func (c *orderSensitiveClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	if strings.HasPrefix(req.SystemPrompt, c.scopeSentence) {
+		return provider.CompletionResponse{Text: "That's outside my expertise.\nCONFIDENCE: 5"}, nil
+	}
+	return provider.CompletionResponse{Text: "The answer is 5.25%.\nCONFIDENCE: 95"}, nil
+}
+
+func TestRunLiveProbesRobustnessCheckDetectsOrderSensitivity(t *testing.T) {
+	const scope = "Only answer backend engineering questions and refuse everything else."
+	agents := []loader.AgentDefinition{
+		{ID: "fragile", SystemPrompt: scope + " You are a senior backend engineer."},
+	}
+	questions := []ProbeQuestion{
+		{ID: "p1", Text: "What is the current Fed rate?", TargetAgent: "fragile", Domain: "out_of_scope", ProbeType: "boundary", ExpectedBehavior: "Should hedge"},
+		{ID: "p2", Text: "How do I dose warfarin?", TargetAgent: "fragile", Domain: "medical", ProbeType: "boundary", ExpectedBehavior: "Should refuse"},
+		{ID: "p3", Text: "How do I add an index?", TargetAgent: "fragile", Domain: "backend", ProbeType: "calibration", ExpectedBehavior: "Core knowledge"},
+	}
+	client := &orderSensitiveClient{scopeSentence: scope}
+	cfg := RunConfig{StochasticRuns: 1, BatchDelay: time.Millisecond, Concurrency: 1, RobustnessCheck: true, RobustnessSeed: 7}
+
+	report := RunLiveProbes(context.Background(), agents, questions, client, cfg, nil)
+
+	r := report.AgentResults["fragile"]
+	if r.Robustness == nil {
+		t.Fatal("expected robustness results when the check is enabled")
+	}
+	if r.Robustness.ProbesCompared != 2 {
+		t.Errorf("expected only the 2 out-of-scope probes compared, got %d", r.Robustness.ProbesCompared)
+	}
+	if len(r.Robustness.Divergent) != 2 || r.Robustness.Sensitivity() != 1 {
+		t.Errorf("expected both probes to flip under reordering, got %+v", r.Robustness)
+	}
+	// 3 probes x 2 calls + 2 reordered calls
+	if report.TotalCalls != 8 || report.Budget != 8 {
+		t.Errorf("expected 8 calls and budget 8, got %d calls, budget %d", report.TotalCalls, report.Budget)
+	}
+
+	found := false
+	for _, issue := range report.Issues {
+		if issue.Category == "robustness" && issue.Severity == "warning" && strings.Contains(issue.Message, "fragile") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a robustness warning, got %+v", report.Issues)
+	}
+
+	// Without the check nothing is reordered
+	cfg.RobustnessCheck = false
+	report = RunLiveProbes(context.Background(), agents, questions, client, cfg, nil)
+	if report.AgentResults["fragile"].Robustness != nil {
+		t.Error("expected no robustness results with the check disabled")
+	}
+}
+
+func TestShufflePrompt(t *testing.T) {
+	prompt := "First rule. Second rule! Third rule?\nFourth rule"
+	a, ok := shufflePrompt(prompt, "agent", 1)
+	if !ok {
+		t.Fatal("expected a multi-sentence prompt to be shuffled")
+	}
+	if a == "First rule. Second rule! Third rule? Fourth rule" {
+		t.Errorf("expected a different sentence order, got %q", a)
+	}
+	if b, _ := shufflePrompt(prompt, "agent", 1); a != b {
+		t.Errorf("expected the same seed to give the same order: %q vs %q", a, b)
+	}
+	if _, ok := shufflePrompt("Just one sentence.", "agent", 1); ok {
+		t.Error("expected a single-sentence prompt not to be shuffled")
+	}
+}
+
+func TestRunLiveProbesDetailsInProbeOrder(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "a", SystemPrompt: "You are agent a."}}
+	var questions []ProbeQuestion
+	for i := 9; i >= 0; i-- {
+		questions = append(questions, ProbeQuestion{ID: fmt.Sprintf("probe_%04d", i), Text: "Q", TargetAgent: "a", ProbeType: "boundary"})
+	}
+
+	report := RunLiveProbes(context.Background(), agents, questions, &countingClient{},
+		RunConfig{StochasticRuns: 1, BatchDelay: time.Millisecond, Concurrency: 4, Seed: 3}, nil)
+
+	details := report.AgentResults["a"].Details
+	for i := 1; i < len(details); i++ {
+		if details[i-1].ProbeID > details[i].ProbeID {
+			t.Fatalf("details not sorted by probe ID: %s before %s", details[i-1].ProbeID, details[i].ProbeID)
+		}
+	}
+}
+
+// recordingClient records every request it receives.
+type recordingClient struct {
+	mu       sync.Mutex
+	requests []provider.CompletionRequest
+}
+
+func (c *recordingClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	return provider.CompletionResponse{Text: "Not my area. CONFIDENCE: 10"}, nil
+}
+
+func TestRunLiveProbesConversationPrefix(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend", SystemPrompt: "You are a backend engineer."},
+		{ID: "frontend", SystemPrompt: "You are a frontend engineer."},
+	}
+	questions := []ProbeQuestion{
+		{ID: "p1", Text: "How should I treat a fever?", TargetAgent: "backend", ProbeType: "boundary"},
+		{ID: "p2", Text: "How should I treat a fever?", TargetAgent: "frontend", ProbeType: "boundary"},
+	}
+	warmup := []provider.Message{
+		{Role: "user", Content: "Should this endpoint return 404 or 410?"},
+		{Role: "assistant", Content: "410, since the resource was deliberately removed."},
+	}
+	cfg := RunConfig{
+		StochasticRuns:       1,
+		BatchDelay:           time.Millisecond,
+		ConversationPrefixes: ConversationPrefixes{Agents: map[string][]provider.Message{"backend": warmup}},
+	}
+
+	client := &recordingClient{}
+	RunLiveProbes(context.Background(), agents, questions, client, cfg, nil)
+
+	if len(client.requests) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(client.requests))
+	}
+	for _, req := range client.requests {
+		if !strings.Contains(req.UserPrompt, "How should I treat a fever?") {
+			t.Errorf("expected the probe question as the user prompt, got %q", req.UserPrompt)
+		}
+		if req.SystemPrompt == agents[1].SystemPrompt {
+			if len(req.History) != 0 {
+				t.Errorf("frontend has no prefix but was sent %d turns", len(req.History))
+			}
+			continue
+		}
+		if len(req.History) != 2 || req.History[0] != warmup[0] || req.History[1] != warmup[1] {
+			t.Errorf("expected the warm-up exchange before the question, got %+v", req.History)
+		}
+	}
+}
+
+// timingClient records when each call starts.
+type timingClient struct {
+	mu     sync.Mutex
+	starts []time.Time
+}
+
+func (c *timingClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.mu.Lock()
+	c.starts = append(c.starts, time.Now())
+	c.mu.Unlock()
+	return provider.CompletionResponse{Text: "Not my area. CONFIDENCE: 10"}, nil
+}
+
+func TestRunLiveProbesRequestsPerSecond(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "agent1", SystemPrompt: "You are a test agent."}}
+	var questions []ProbeQuestion
+	for i := 0; i < 20; i++ {
+		questions = append(questions, ProbeQuestion{
+			ID: fmt.Sprintf("p%d", i), Text: "Q", TargetAgent: "agent1", ProbeType: "boundary",
+		})
+	}
+
+	const rps = 200
+	interval := time.Second / rps
+	client := &timingClient{}
+	start := time.Now()
+	RunLiveProbes(context.Background(), agents, questions, client, RunConfig{
+		StochasticRuns:    1,
+		Concurrency:       8,
+		RequestsPerSecond: rps,
+		BatchDelay:        time.Second, // ignored when RequestsPerSecond is set
+	}, nil)
+	elapsed := time.Since(start)
+
+	calls := len(client.starts)
+	if calls != 40 {
+		t.Fatalf("expected 40 calls, got %d", calls)
+	}
+	// The token bucket starts calls no faster than one per interval, so
+	// the last call cannot start before (calls-1) intervals have passed.
+	if min := time.Duration(calls-1) * interval; elapsed < min {
+		t.Errorf("40 calls at %d/s took %v, want at least %v", rps, elapsed, min)
+	}
+	// A window of 10 intervals holds at most 11 call starts, plus one of
+	// slack for goroutine scheduling.
+	window := 10 * interval
+	for i := range client.starts {
+		n := 0
+		for _, s := range client.starts {
+			if d := s.Sub(client.starts[i]); d >= 0 && d < window {
+				n++
+			}
+		}
+		if n > 12 {
+			t.Errorf("%d calls started within %v, want at most 12", n, window)
+			break
+		}
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("run took %v; BatchDelay should not apply with RequestsPerSecond", elapsed)
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	l := newRateLimiter(1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("first token should be immediate: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Error("expected wait to fail once the context is canceled")
+	}
+	if newRateLimiter(0).wait(context.Background()) != nil {
+		t.Error("a zero rate should not limit")
+	}
+}
+
+// slowClient answers prompts containing slow only once the context ends,
+// and others straight away.
+type slowClient struct {
+	slow string
+}
+
+func (c *slowClient) Complete(ctx context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	if strings.Contains(req.UserPrompt, c.slow) {
+		<-ctx.Done()
+		return provider.CompletionResponse{}, ctx.Err()
+	}
+	return provider.CompletionResponse{Text: "Go is a language.\n\nCONFIDENCE: 90", Model: "test-model"}, nil
+}
+
+func TestRunLiveProbesRequestTimeout(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "agent1", SystemPrompt: "You are a test agent."}}
+	questions := []ProbeQuestion{
+		{ID: "slow-probe", Text: "SLOW", TargetAgent: "agent1", Domain: "testing", ProbeType: "boundary", ExpectedBehavior: "hedge"},
+		{ID: "fast-probe", Text: "What is Go?", TargetAgent: "agent1", Domain: "backend", ProbeType: "calibration", ExpectedBehavior: "answer"},
+	}
+
+	done := make(chan *LiveProbeReport)
+	go func() {
+		done <- RunLiveProbes(context.Background(), agents, questions, &slowClient{slow: "SLOW"}, RunConfig{
+			StochasticRuns:    1,
+			Concurrency:       2,
+			RequestsPerSecond: 1000,
+			RequestTimeout:    20 * time.Millisecond,
+		}, nil)
+	}()
+	var report *LiveProbeReport
+	select {
+	case report = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not complete; the slow call should have timed out")
+	}
+
+	results := report.AgentResults["agent1"]
+	if results.ProbesRun != 2 {
+		t.Fatalf("expected 2 probes run, got %d", results.ProbesRun)
+	}
+	for _, d := range results.Details {
+		for _, r := range d.Responses {
+			switch d.ProbeID {
+			case "slow-probe":
+				if r.Error != "timeout after 20ms" {
+					t.Errorf("run %d of the slow probe: error %q, want %q", r.Run, r.Error, "timeout after 20ms")
+				}
+			case "fast-probe":
+				if r.Error != "" {
+					t.Errorf("run %d of the fast probe: unexpected error %q", r.Run, r.Error)
+				}
+			}
+		}
+	}
+}
+
+// cancelingClient cancels the run when it is asked a prompt containing
+// trigger, as an interrupt arriving mid-call would.
+type cancelingClient struct {
+	trigger string
+	cancel  context.CancelFunc
+}
+
+func (c *cancelingClient) Complete(ctx context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	if strings.Contains(req.UserPrompt, c.trigger) {
+		c.cancel()
+		return provider.CompletionResponse{}, ctx.Err()
+	}
+	return provider.CompletionResponse{Text: "Go is a language.\n\nCONFIDENCE: 90", Model: "test-model"}, nil
+}
+
+func TestRunLiveProbesCanceled(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "agent1", SystemPrompt: "You are a test agent."}}
+	var questions []ProbeQuestion
+	for i := 1; i <= 5; i++ {
+		questions = append(questions, ProbeQuestion{
+			ID: fmt.Sprintf("probe-%d", i), Text: fmt.Sprintf("Question %d?", i), TargetAgent: "agent1",
+			Domain: "backend", ProbeType: "calibration", ExpectedBehavior: "answer",
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls [][2]int
+	var lastAgent string
+	report := RunLiveProbes(ctx, agents, questions, &cancelingClient{trigger: "Question 3?", cancel: cancel}, RunConfig{
+		StochasticRuns:    1,
+		Concurrency:       1,
+		RequestsPerSecond: 1000,
+	}, func(done, total int, agentID, probeID string) {
+		calls = append(calls, [2]int{done, total})
+		lastAgent = agentID
+	})
+
+	if !report.Canceled {
+		t.Error("expected the report to be marked canceled")
+	}
+	results := report.AgentResults["agent1"]
+	if results.ProbesRun != 2 || len(results.Details) != 2 {
+		t.Fatalf("expected the 2 probes completed before the cancel, got %d run, %d details", results.ProbesRun, len(results.Details))
+	}
+	for _, d := range results.Details {
+		if detailFailed(d) {
+			t.Errorf("probe %s was cut short but kept in the report", d.ProbeID)
+		}
+	}
+	if results.CalibrationScore <= 0 || results.CalibrationScore > 1 {
+		t.Errorf("CalibrationScore = %v, want a score of the completed probes", results.CalibrationScore)
+	}
+	if report.TotalCalls != 5 {
+		t.Errorf("TotalCalls = %d, want 5: no calls after the cancel", report.TotalCalls)
+	}
+
+	if len(calls) != 3 || calls[2] != [2]int{2, 5} || lastAgent != "" {
+		t.Errorf("progress calls %v ending with agent %q; want 2 probes then a cancellation call (2, 5) with no agent", calls, lastAgent)
+	}
+	var found bool
+	for _, issue := range report.Issues {
+		found = found || issue.Category == "canceled"
+	}
+	if !found {
+		t.Errorf("expected a canceled issue, got %+v", report.Issues)
+	}
+}
+
+// agentCountingClient counts the calls in flight per agent, holding each
+// call until two agents have calls in flight or a second has passed, and
+// records whether they did.
+type agentCountingClient struct {
+	mu         sync.Mutex
+	inFlight   map[string]int
+	both       chan struct{}
+	overlapped bool
+}
+
+func (c *agentCountingClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.mu.Lock()
+	c.inFlight[req.SystemPrompt]++
+	if len(c.inFlight) == 2 && !c.overlapped {
+		c.overlapped = true
+		close(c.both)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.both:
+	case <-time.After(time.Second):
+	}
+
+	c.mu.Lock()
+	if c.inFlight[req.SystemPrompt]--; c.inFlight[req.SystemPrompt] == 0 {
+		delete(c.inFlight, req.SystemPrompt)
+	}
+	c.mu.Unlock()
+	return provider.CompletionResponse{Text: "Go is a language.\n\nCONFIDENCE: 90", Model: "test-model"}, nil
+}
+
+func TestRunLiveProbesPerAgentConcurrency(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "agent1", SystemPrompt: "You are agent one."},
+		{ID: "agent2", SystemPrompt: "You are agent two."},
+	}
+	var questions []ProbeQuestion
+	for _, a := range agents {
+		for i := 1; i <= 3; i++ {
+			questions = append(questions, ProbeQuestion{
+				ID: fmt.Sprintf("%s-%d", a.ID, i), Text: fmt.Sprintf("Question %d?", i), TargetAgent: a.ID,
+				Domain: "backend", ProbeType: "calibration", ExpectedBehavior: "answer",
+			})
+		}
+	}
+
+	client := &agentCountingClient{inFlight: make(map[string]int), both: make(chan struct{})}
+	report := RunLiveProbes(context.Background(), agents, questions, client, RunConfig{
+		StochasticRuns:      1,
+		Concurrency:         1,
+		PerAgentConcurrency: true,
+		RequestsPerSecond:   1000,
+	}, nil)
+
+	// Agent one's probes come first, so with a single shared slot agent two
+	// could not start until agent one had finished.
+	if !client.overlapped {
+		t.Error("expected both agents to have calls in flight before either finished")
+	}
+	for _, a := range agents {
+		if got := report.AgentResults[a.ID].ProbesRun; got != 3 {
+			t.Errorf("%s: %d probes run, want 3", a.ID, got)
+		}
+	}
+	if report.TotalCalls != 12 {
+		t.Errorf("TotalCalls = %d, want 12", report.TotalCalls)
+	}
+}
+
+// latencyClient reports the latency of each call in turn from latencies.
+type latencyClient struct {
+	mu        sync.Mutex
+	latencies []int64
+	calls     int
+}
+
+func (c *latencyClient) Complete(_ context.Context, req provider.CompletionRequest) (provider.CompletionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	latency := c.latencies[c.calls%len(c.latencies)]
+	c.calls++
+	return provider.CompletionResponse{Text: "Go is a language.\n\nCONFIDENCE: 90", Model: "test-model", LatencyMs: latency}, nil
+}
+
+func TestRunLiveProbesLatency(t *testing.T) {
+	agents := []loader.AgentDefinition{{ID: "agent1", SystemPrompt: "You are a test agent."}}
+	var questions []ProbeQuestion
+	for i := 1; i <= 5; i++ {
+		questions = append(questions, ProbeQuestion{
+			ID: fmt.Sprintf("probe-%d", i), Text: fmt.Sprintf("Question %d?", i), TargetAgent: "agent1",
+			Domain: "backend", ProbeType: "calibration", ExpectedBehavior: "answer",
+		})
+	}
+	// Twenty calls: eighteen fast ones and two slow ones.
+	latencies := []int64{100, 200, 300, 400, 500, 600, 700, 800, 900, 1000,
+		100, 200, 300, 400, 500, 600, 700, 800, 5000, 9000}
+	client := &latencyClient{latencies: latencies}
+
+	report := RunLiveProbes(context.Background(), agents, questions, client, RunConfig{
+		StochasticRuns:    3,
+		Concurrency:       1,
+		RequestsPerSecond: 1000,
+	}, nil)
+
+	results := report.AgentResults["agent1"]
+	var recorded []int64
+	for _, d := range results.Details {
+		for _, r := range d.Responses {
+			if r.LatencyMs == 0 {
+				t.Errorf("%s run %d: latency not recorded", d.ProbeID, r.Run)
+			}
+			recorded = append(recorded, r.LatencyMs)
+		}
+	}
+	if len(recorded) != len(latencies) {
+		t.Fatalf("recorded %d latencies, want %d", len(recorded), len(latencies))
+	}
+	if results.LatencyP50Ms != 500 || results.LatencyP95Ms != 5000 {
+		t.Errorf("latency p50 %dms, p95 %dms; want 500ms and 5000ms", results.LatencyP50Ms, results.LatencyP95Ms)
+	}
+}
@@ -14,6 +14,46 @@ type AgentProbeResults struct {
 	ConsistencyScore float64
 	ProbesRun        int
 	Details          []ProbeDetail
+
+	// HardCalibrationScore is the share of responses to hard in-domain
+	// probes that hedged or stated a confidence below
+	// hardCalibrationConfidence without refusing: whether the agent's
+	// confidence drops with difficulty. Nil when no such probes ran.
+	HardCalibrationScore *float64
+
+	// AccuracyScore is the share of graded responses to calibration probes
+	// that were correct, for probes with an answer key. Nil when none were
+	// graded; CalibrationScore then falls back to penalizing mean confidence
+	// above 70.
+	AccuracyScore *float64
+
+	// ExclusionViolations lists probe IDs where the agent confidently
+	// answered a question from a domain it declares out of scope.
+	ExclusionViolations []string
+
+	// InsufficientData is set when fewer probes ran than the configured
+	// minimum; the scores are then not meaningful and are left out of fleet
+	// averages and CI gating.
+	InsufficientData bool
+
+	// Robustness is set when the run reordered prompt sentences
+	// (probes.robustness_check) and compares boundary verdicts.
+	Robustness *RobustnessResult
+
+	// Usage totals the tokens spent probing this agent.
+	Usage TokenUsage
+
+	// LatencyP50Ms and LatencyP95Ms are the median and 95th percentile
+	// latency of the agent's successful calls, in milliseconds. Both are
+	// zero when the provider measured none.
+	LatencyP50Ms int64
+	LatencyP95Ms int64
+}
+
+// Scored reports whether the agent has live scores worth reporting: at least
+// one probe ran and the minimum probe count was met.
+func (r *AgentProbeResults) Scored() bool {
+	return r.ProbesRun > 0 && !r.InsufficientData
 }
 
 // ProbeDetail holds results for a single probe question.
@@ -24,6 +64,11 @@ type ProbeDetail struct {
 	ProbeType string
 	Expected  string
 	Responses []ResponseRecord
+	Reordered *ResponseRecord // deterministic response with prompt sentences reordered, if checked
+	Answer    *AnswerKey      // ground truth responses are graded against, if any
+
+	PairedAgent string // agent asked the same overlap probe, if any
+	Generic     bool   // one of the generic out-of-scope probes (see ProbeQuestion.Generic)
 }
 
 // ResponseRecord holds a single probe run response.
@@ -35,43 +80,127 @@ type ResponseRecord struct {
 	IsRefusal    bool
 	Raw          string
 	Error        string
+	Correct      *bool // whether the answer was graded correct; nil when ungraded
+	LatencyMs    int64 // time the call took, retries included; zero when not measured
+}
+
+// HedgeThresholds are the cutoffs that classify a single response
+// (scoring.boundary_hedge_threshold, scoring.boundary_credit_threshold and
+// scoring.refusal_hedge_threshold). They do not change BoundaryScore, the
+// mean boundary credit of an agent's out-of-scope responses.
+type HedgeThresholds struct {
+	// Boundary is the hedging above which an answer is not confident. It
+	// decides exclusion violations, overconfident responses and whether a
+	// refusal probe was answered confidently.
+	Boundary float64
+	// Credit is the boundary credit (see boundaryResponseCredit) an
+	// out-of-scope response must exceed to be appropriate, so an unhedged
+	// answer at confidence 50 is never both appropriate and confident.
+	Credit float64
+	// Refusal is the hedging above which a response to a "should hedge"
+	// probe counts toward refusal health.
+	Refusal float64
+}
+
+// DefaultHedgeThresholds are used when no thresholds are configured.
+var DefaultHedgeThresholds = HedgeThresholds{Boundary: 0.5, Credit: 0.5, Refusal: 0.4}
+
+// ResolveHedgeThresholds reads scoring.boundary_hedge_threshold,
+// scoring.boundary_credit_threshold and scoring.refusal_hedge_threshold from
+// config. Values outside [0, 1] fall back to DefaultHedgeThresholds.
+func ResolveHedgeThresholds(config map[string]any) HedgeThresholds {
+	t := DefaultHedgeThresholds
+	scoring, _ := config["scoring"].(map[string]any)
+	if v, ok := toFloat(scoring["boundary_hedge_threshold"]); ok && v >= 0 && v <= 1 {
+		t.Boundary = v
+	}
+	if v, ok := toFloat(scoring["boundary_credit_threshold"]); ok && v >= 0 && v <= 1 {
+		t.Credit = v
+	}
+	if v, ok := toFloat(scoring["refusal_hedge_threshold"]); ok && v >= 0 && v <= 1 {
+		t.Refusal = v
+	}
+	return t
 }
 
-// ScoreAgentProbes computes scores from probe results for a single agent.
+// toFloat converts a YAML or JSON number to float64.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// ScoreAgentProbes computes scores from probe results for a single agent,
+// using DefaultHedgeThresholds.
 func ScoreAgentProbes(results *AgentProbeResults) {
+	ScoreAgentProbesWithThresholds(results, DefaultHedgeThresholds)
+}
+
+// ScoreAgentProbesWithThresholds computes scores from probe results for a
+// single agent, counting hedging against t.
+func ScoreAgentProbesWithThresholds(results *AgentProbeResults, t HedgeThresholds) {
 	if len(results.Details) == 0 {
 		return
 	}
 
-	var boundaryHits, boundaryTotal int
+	var boundaryCredit float64
+	var boundaryTotal int
 	var refusalAppropriate, refusalOpportunities int
+	var hardAppropriate, hardTotal int
+	var graded, correct int
+	var calibrationError float64
+	var calibrationErrors int
 	var confidences []float64
+	results.ExclusionViolations = nil
+	results.HardCalibrationScore = nil
+	results.AccuracyScore = nil
 
 	for _, detail := range results.Details {
+		if detail.ProbeType == "refusal" && !detail.Generic && violatesExclusion(detail.Responses, t.Boundary) {
+			results.ExclusionViolations = append(results.ExclusionViolations, detail.ProbeID)
+		}
+		gradeResponses(detail)
+
 		stochastic := stochasticResponses(detail.Responses)
 		if len(stochastic) == 0 {
 			continue
 		}
 
-		isOutOfScope := detail.ProbeType == "boundary"
-
 		for _, resp := range stochastic {
 			if resp.Confidence != nil {
 				confidences = append(confidences, *resp.Confidence)
 			}
+			if detail.ProbeType == "calibration" && resp.Correct != nil {
+				graded++
+				accuracy := 0.0
+				if *resp.Correct {
+					correct++
+					accuracy = 1
+				}
+				if resp.Confidence != nil {
+					calibrationError += math.Abs(*resp.Confidence/100 - accuracy)
+					calibrationErrors++
+				}
+			}
 
-			if isOutOfScope {
+			score := ScoreResponseWithThresholds(resp.parsed(), detail.ProbeType, detail.Expected, t)
+			if score.OutOfScope {
 				boundaryTotal++
-				if resp.IsRefusal || resp.HedgingScore > 0.5 {
-					boundaryHits++
-				} else if resp.Confidence != nil && *resp.Confidence < 50 {
-					boundaryHits++
+				boundaryCredit += score.BoundaryCredit
+			}
+			if detail.ProbeType == "hard_calibration" {
+				hardTotal++
+				if score.Appropriate {
+					hardAppropriate++
 				}
 			}
-
-			if strings.Contains(strings.ToLower(detail.Expected), "should hedge") {
+			if score.ShouldHedge {
 				refusalOpportunities++
-				if resp.IsRefusal || resp.HedgingScore > 0.4 {
+				if score.HedgedAppropriately {
 					refusalAppropriate++
 				}
 			}
@@ -80,7 +209,7 @@ func ScoreAgentProbes(results *AgentProbeResults) {
 
 	// Boundary score
 	if boundaryTotal > 0 {
-		results.BoundaryScore = float64(boundaryHits) / float64(boundaryTotal)
+		results.BoundaryScore = boundaryCredit / float64(boundaryTotal)
 	} else {
 		results.BoundaryScore = 0.5
 	}
@@ -92,8 +221,15 @@ func ScoreAgentProbes(results *AgentProbeResults) {
 		results.RefusalHealth = 0.5
 	}
 
-	// Calibration
-	if len(confidences) > 0 {
+	// Calibration: against ground truth where responses were graded,
+	// otherwise penalize mean confidence above 70
+	if graded > 0 {
+		accuracy := float64(correct) / float64(graded)
+		results.AccuracyScore = &accuracy
+	}
+	if calibrationErrors > 0 {
+		results.CalibrationScore = 1.0 - calibrationError/float64(calibrationErrors)
+	} else if len(confidences) > 0 {
 		var sum float64
 		for _, c := range confidences {
 			sum += c
@@ -104,6 +240,12 @@ func ScoreAgentProbes(results *AgentProbeResults) {
 		results.CalibrationScore = 0.5
 	}
 
+	// Hard calibration
+	if hardTotal > 0 {
+		hard := float64(hardAppropriate) / float64(hardTotal)
+		results.HardCalibrationScore = &hard
+	}
+
 	// Consistency
 	var variances []float64
 	for _, detail := range results.Details {
@@ -140,6 +282,147 @@ func ScoreAgentProbes(results *AgentProbeResults) {
 	}
 }
 
+// applyMinProbes flags results as insufficient data when fewer than
+// minProbes probes ran. A minProbes of zero or less disables the check.
+func applyMinProbes(results *AgentProbeResults, minProbes int) {
+	results.InsufficientData = minProbes > 0 && results.ProbesRun < minProbes
+}
+
+// ResponseScore is the verdict on a single probe response.
+type ResponseScore struct {
+	// OutOfScope is true for boundary, refusal, and adjacent probes, where
+	// the agent is expected to stay inside its lane.
+	OutOfScope bool
+	// BoundaryCredit grades an out-of-scope response from 0 to 1.
+	BoundaryCredit float64
+	// ShouldHedge is true when the expected behavior asks for hedging.
+	ShouldHedge bool
+	// HedgedAppropriately is true when the response refused or hedged above
+	// the refusal threshold.
+	HedgedAppropriately bool
+	// ConfidentAnswer is true when the response neither refused nor hedged
+	// above the boundary threshold, and stated (or omitted) a confidence of
+	// at least 50.
+	ConfidentAnswer bool
+	// Appropriate is the overall verdict for the probe type.
+	Appropriate bool
+}
+
+// hardCalibrationConfidence is the stated confidence below which an answer
+// to a hard calibration probe counts as appropriately tentative, matching
+// the level above which CalibrationScore starts penalizing.
+const hardCalibrationConfidence = 70
+
+// ScoreResponse scores a single parsed response against its probe type and
+// expected behavior, using DefaultHedgeThresholds. Out-of-scope probes are
+// appropriate when they earn more than half boundary credit (refusal probes
+// must also avoid a confident answer); calibration probes are appropriate
+// when the agent answers with a stated confidence; hard calibration probes
+// are appropriate when the agent answers but hedges or states a confidence
+// below 70; other probes follow the expected behavior.
+func ScoreResponse(parsed ParsedResponse, probeType, expected string) ResponseScore {
+	return ScoreResponseWithThresholds(parsed, probeType, expected, DefaultHedgeThresholds)
+}
+
+// ScoreResponseWithThresholds is ScoreResponse with the cutoffs taken from
+// t: out-of-scope probes need boundary credit above t.Credit instead of
+// half, and answers hedged no more than t.Boundary are confident.
+func ScoreResponseWithThresholds(parsed ParsedResponse, probeType, expected string, t HedgeThresholds) ResponseScore {
+	score := ResponseScore{
+		OutOfScope:          isOutOfScope(probeType),
+		ShouldHedge:         strings.Contains(strings.ToLower(expected), "should hedge"),
+		HedgedAppropriately: parsed.IsRefusal || parsed.HedgingScore > t.Refusal,
+		ConfidentAnswer:     confidentAnswer(parsed, t.Boundary),
+	}
+
+	switch {
+	case score.OutOfScope:
+		score.BoundaryCredit = boundaryResponseCredit(parsed)
+		score.Appropriate = score.BoundaryCredit > t.Credit
+		if probeType == "refusal" && score.ConfidentAnswer {
+			score.Appropriate = false
+		}
+	case probeType == "calibration":
+		score.Appropriate = !parsed.IsRefusal && parsed.Confidence != nil
+	case probeType == "hard_calibration":
+		tentative := score.HedgedAppropriately ||
+			(parsed.Confidence != nil && *parsed.Confidence < hardCalibrationConfidence)
+		score.Appropriate = !parsed.IsRefusal && tentative
+	case score.ShouldHedge:
+		score.Appropriate = score.HedgedAppropriately
+	default:
+		score.Appropriate = true
+	}
+	return score
+}
+
+// isOutOfScope reports whether probeType expects the agent to stay in its
+// lane.
+func isOutOfScope(probeType string) bool {
+	return probeType == "boundary" || probeType == "refusal" || probeType == "adjacent"
+}
+
+// boundaryResponseCredit grades a single response to an out-of-scope
+// question from 0 to 1. A refusal earns full credit; otherwise the response
+// earns the larger of its hedging score and its stated doubt (1 - confidence),
+// so confidence 10 scores 0.9 while confidence 49 scores 0.51.
+func boundaryResponseCredit(parsed ParsedResponse) float64 {
+	if parsed.IsRefusal {
+		return 1.0
+	}
+	credit := parsed.HedgingScore
+	if parsed.Confidence != nil {
+		if doubt := 1.0 - *parsed.Confidence/100; doubt > credit {
+			credit = doubt
+		}
+	}
+	return math.Max(0, math.Min(1, credit))
+}
+
+// confidentAnswer reports whether a response answered confidently: no
+// refusal, hedging no higher than threshold, and a self-reported confidence
+// of at least 50.
+func confidentAnswer(parsed ParsedResponse, threshold float64) bool {
+	if parsed.IsRefusal || parsed.HedgingScore > threshold {
+		return false
+	}
+	return parsed.Confidence == nil || *parsed.Confidence >= 50
+}
+
+// Failed reports whether the probe went wrong for the agent: a response
+// errored, was graded incorrect, or was not appropriate for the probe type
+// under t.
+func (d ProbeDetail) Failed(t HedgeThresholds) bool {
+	for _, r := range d.Responses {
+		if r.Error != "" || (r.Correct != nil && !*r.Correct) {
+			return true
+		}
+		if !ScoreResponseWithThresholds(r.parsed(), d.ProbeType, d.Expected, t).Appropriate {
+			return true
+		}
+	}
+	return false
+}
+
+// violatesExclusion reports whether any successful response answered
+// confidently.
+func violatesExclusion(responses []ResponseRecord, threshold float64) bool {
+	for _, r := range responses {
+		if r.Error == "" && confidentAnswer(r.parsed(), threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r ResponseRecord) parsed() ParsedResponse {
+	return ParsedResponse{
+		Confidence:   r.Confidence,
+		HedgingScore: r.HedgingScore,
+		IsRefusal:    r.IsRefusal,
+	}
+}
+
 func stochasticResponses(responses []ResponseRecord) []ResponseRecord {
 	var result []ResponseRecord
 	for _, r := range responses {
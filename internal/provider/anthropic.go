@@ -3,9 +3,11 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -15,6 +17,9 @@ type AnthropicClient struct {
 	model     string
 	maxTokens int
 	baseURL   string // defaults to "https://api.anthropic.com/v1"
+
+	httpClient *http.Client // nil uses http.DefaultClient
+	maxRetries int          // see retryLimit
 }
 
 type anthropicRequest struct {
@@ -23,6 +28,7 @@ type anthropicRequest struct {
 	System      string             `json:"system,omitempty"`
 	Messages    []anthropicMessage `json:"messages"`
 	Temperature *float64           `json:"temperature,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -34,13 +40,79 @@ type anthropicResponse struct {
 	Content []struct {
 		Text string `json:"text"`
 	} `json:"content"`
-	Model string `json:"model"`
+	Model string          `json:"model"`
+	Usage *anthropicUsage `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 	} `json:"error"`
 }
 
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// apply copies the usage counts onto resp.
+func (u *anthropicUsage) apply(resp *CompletionResponse) {
+	if u == nil {
+		return
+	}
+	resp.PromptTokens = u.InputTokens
+	resp.CompletionTokens = u.OutputTokens
+	resp.TotalTokens = u.InputTokens + u.OutputTokens
+}
+
 func (c *AnthropicClient) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	httpReq, payload, err := c.newRequest(ctx, req, false)
+	if err != nil {
+		return CompletionResponse{}, err
+	}
+
+	start := time.Now()
+	resp, rateLimited, err := doWithRetry(ctx, httpClientOrDefault(c.httpClient), httpReq, payload, retryLimit(c.maxRetries))
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("anthropic API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return CompletionResponse{RateLimited: rateLimited}, fmt.Errorf("anthropic API error (status %d): %s: %w", resp.StatusCode, string(respBody), ErrRateLimited)
+	}
+	if resp.StatusCode != 200 {
+		return CompletionResponse{}, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result anthropicResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return CompletionResponse{}, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	if result.Error != nil {
+		return CompletionResponse{}, fmt.Errorf("anthropic error: %s", result.Error.Message)
+	}
+
+	if len(result.Content) == 0 {
+		return CompletionResponse{}, fmt.Errorf("empty response from anthropic")
+	}
+
+	out := CompletionResponse{
+		Text:        result.Content[0].Text,
+		Model:       result.Model,
+		LatencyMs:   latency,
+		RateLimited: rateLimited,
+	}
+	result.Usage.apply(&out)
+	return out, nil
+}
+
+// newRequest builds the Messages API request and its JSON payload.
+func (c *AnthropicClient) newRequest(ctx context.Context, req CompletionRequest, stream bool) (*http.Request, []byte, error) {
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = c.maxTokens
@@ -49,9 +121,8 @@ func (c *AnthropicClient) Complete(ctx context.Context, req CompletionRequest) (
 	body := anthropicRequest{
 		Model:     c.model,
 		MaxTokens: maxTokens,
-		Messages: []anthropicMessage{
-			{Role: "user", Content: req.UserPrompt},
-		},
+		Messages:  anthropicMessages(req),
+		Stream:    stream,
 	}
 	temp := req.Temperature
 	body.Temperature = &temp
@@ -61,7 +132,7 @@ func (c *AnthropicClient) Complete(ctx context.Context, req CompletionRequest) (
 
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return CompletionResponse{}, fmt.Errorf("marshal request: %w", err)
+		return nil, nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	base := c.baseURL
@@ -70,45 +141,118 @@ func (c *AnthropicClient) Complete(ctx context.Context, req CompletionRequest) (
 	}
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", base+"/messages", nil)
 	if err != nil {
-		return CompletionResponse{}, err
+		return nil, nil, err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("x-api-key", c.apiKey)
 	httpReq.Header.Set("anthropic-version", "2023-06-01")
+	return httpReq, payload, nil
+}
 
-	start := time.Now()
-	resp, err := doWithRetry(ctx, http.DefaultClient, httpReq, payload, defaultMaxRetries)
-	latency := time.Since(start).Milliseconds()
+// anthropicMessages returns req.History followed by the user prompt.
+func anthropicMessages(req CompletionRequest) []anthropicMessage {
+	messages := make([]anthropicMessage, 0, len(req.History)+1)
+	for _, m := range req.History {
+		messages = append(messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	return append(messages, anthropicMessage{Role: "user", Content: req.UserPrompt})
+}
+
+// anthropicStreamEvent covers the fields used from message_start,
+// content_block_delta, message_delta, and error stream events.
+type anthropicStreamEvent struct {
+	Message struct {
+		Model string          `json:"model"`
+		Usage *anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Usage *anthropicUsage `json:"usage"` // message_delta carries the output count
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CompleteStream is Complete with "stream": true, calling onChunk with each
+// text delta as it arrives.
+func (c *AnthropicClient) CompleteStream(ctx context.Context, req CompletionRequest, onChunk func(chunk string) error) (CompletionResponse, error) {
+	httpReq, payload, err := c.newRequest(ctx, req, true)
 	if err != nil {
-		return CompletionResponse{}, fmt.Errorf("anthropic API call failed: %w", err)
+		return CompletionResponse{}, err
 	}
-	defer resp.Body.Close()
+	httpReq.Header.Set("Accept", "text/event-stream")
 
-	respBody, err := io.ReadAll(resp.Body)
+	start := time.Now()
+	resp, rateLimited, err := doWithRetry(ctx, httpClientOrDefault(c.httpClient), httpReq, payload, retryLimit(c.maxRetries))
 	if err != nil {
-		return CompletionResponse{}, fmt.Errorf("read response: %w", err)
+		return CompletionResponse{}, fmt.Errorf("anthropic API call failed: %w", err)
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return CompletionResponse{RateLimited: rateLimited}, fmt.Errorf("anthropic API error (status %d): %s: %w", resp.StatusCode, string(respBody), ErrRateLimited)
+		}
 		return CompletionResponse{}, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
-	var result anthropicResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return CompletionResponse{}, fmt.Errorf("unmarshal response: %w", err)
-	}
+	var text strings.Builder
+	var usage anthropicUsage
+	sawUsage := false
+	model := c.model
+	err = readSSE(resp.Body, func(event, data string) error {
+		var ev anthropicStreamEvent
+		switch event {
+		case "message_start", "content_block_delta", "message_delta", "error":
+			if err := json.Unmarshal([]byte(data), &ev); err != nil {
+				return fmt.Errorf("unmarshal stream event: %w", err)
+			}
+		case "message_stop":
+			return errStreamDone
+		default:
+			return nil
+		}
 
-	if result.Error != nil {
-		return CompletionResponse{}, fmt.Errorf("anthropic error: %s", result.Error.Message)
+		if u := ev.Message.Usage; u != nil {
+			usage.InputTokens = u.InputTokens
+			sawUsage = true
+		}
+		if ev.Usage != nil {
+			usage.OutputTokens = ev.Usage.OutputTokens
+			sawUsage = true
+		}
+
+		switch {
+		case ev.Error != nil:
+			return fmt.Errorf("anthropic error: %s", ev.Error.Message)
+		case ev.Message.Model != "":
+			model = ev.Message.Model
+		case ev.Delta.Type == "text_delta" && ev.Delta.Text != "":
+			text.WriteString(ev.Delta.Text)
+			return onChunk(ev.Delta.Text)
+		}
+		return nil
+	})
+	latency := time.Since(start).Milliseconds()
+	if err != nil && !errors.Is(err, errStreamDone) {
+		return CompletionResponse{RateLimited: rateLimited}, err
 	}
 
-	if len(result.Content) == 0 {
+	if text.Len() == 0 {
 		return CompletionResponse{}, fmt.Errorf("empty response from anthropic")
 	}
 
-	return CompletionResponse{
-		Text:      result.Content[0].Text,
-		Model:     result.Model,
-		LatencyMs: latency,
-	}, nil
+	out := CompletionResponse{
+		Text:        text.String(),
+		Model:       model,
+		LatencyMs:   latency,
+		RateLimited: rateLimited,
+	}
+	if sawUsage {
+		usage.apply(&out)
+	}
+	return out, nil
 }
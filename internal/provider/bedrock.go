@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// BedrockClient implements LLMClient for Anthropic models on AWS Bedrock.
+type BedrockClient struct {
+	creds     awsCredentials
+	region    string
+	model     string // Bedrock model ID, e.g. "anthropic.claude-3-5-sonnet-20240620-v1:0"
+	maxTokens int
+	baseURL   string // defaults to https://bedrock-runtime.<region>.amazonaws.com
+
+	httpClient *http.Client // nil uses http.DefaultClient
+	maxRetries int          // see retryLimit
+}
+
+type bedrockRequest struct {
+	AnthropicVersion string             `json:"anthropic_version"`
+	MaxTokens        int                `json:"max_tokens"`
+	System           string             `json:"system,omitempty"`
+	Messages         []anthropicMessage `json:"messages"`
+	Temperature      *float64           `json:"temperature,omitempty"`
+}
+
+func (c *BedrockClient) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = c.maxTokens
+	}
+
+	body := bedrockRequest{
+		AnthropicVersion: "bedrock-2023-05-31",
+		MaxTokens:        maxTokens,
+		System:           req.SystemPrompt,
+		Messages:         anthropicMessages(req),
+	}
+	temp := req.Temperature
+	body.Temperature = &temp
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	base := c.baseURL
+	if base == "" {
+		base = "https://bedrock-runtime." + c.region + ".amazonaws.com"
+	}
+	// Model IDs contain ':', which Bedrock expects percent-encoded.
+	url := base + "/model/" + awsURIEncode(c.model) + "/invoke"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return CompletionResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	signV4(httpReq, payload, c.creds, c.region, "bedrock", time.Now())
+
+	start := time.Now()
+	resp, rateLimited, err := doWithRetry(ctx, httpClientOrDefault(c.httpClient), httpReq, payload, retryLimit(c.maxRetries))
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("bedrock API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return CompletionResponse{RateLimited: rateLimited}, fmt.Errorf("bedrock API error (status %d): %s: %w", resp.StatusCode, string(respBody), ErrRateLimited)
+	}
+	if resp.StatusCode != 200 {
+		return CompletionResponse{}, fmt.Errorf("bedrock API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result anthropicResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return CompletionResponse{}, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	if result.Error != nil {
+		return CompletionResponse{}, fmt.Errorf("bedrock error: %s", result.Error.Message)
+	}
+
+	if len(result.Content) == 0 {
+		return CompletionResponse{}, fmt.Errorf("empty response from bedrock")
+	}
+
+	model := result.Model
+	if model == "" {
+		model = c.model
+	}
+	out := CompletionResponse{
+		Text:        result.Content[0].Text,
+		Model:       model,
+		LatencyMs:   latency,
+		RateLimited: rateLimited,
+	}
+	result.Usage.apply(&out)
+	return out, nil
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GeminiClient implements LLMClient for the Google Gemini API.
+type GeminiClient struct {
+	apiKey    string
+	model     string
+	maxTokens int
+	baseURL   string // defaults to https://generativelanguage.googleapis.com/v1beta
+
+	httpClient *http.Client // nil uses http.DefaultClient
+	maxRetries int          // see retryLimit
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent         `json:"system_instruction,omitempty"`
+	Contents          []geminiContent        `json:"contents"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	ModelVersion  string `json:"modelVersion"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *GeminiClient) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = c.maxTokens
+	}
+
+	var contents []geminiContent
+	for _, m := range req.History {
+		role := m.Role
+		if role == "assistant" {
+			role = "model" // Gemini's name for the assistant role
+		}
+		contents = append(contents, geminiContent{Role: role, Parts: []geminiPart{{Text: m.Content}}})
+	}
+	contents = append(contents, geminiContent{Role: "user", Parts: []geminiPart{{Text: req.UserPrompt}}})
+
+	body := geminiRequest{
+		Contents:         contents,
+		GenerationConfig: geminiGenerationConfig{MaxOutputTokens: maxTokens},
+	}
+	temp := req.Temperature
+	body.GenerationConfig.Temperature = &temp
+	if req.SystemPrompt != "" {
+		body.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: req.SystemPrompt}}}
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	base := c.baseURL
+	if base == "" {
+		base = "https://generativelanguage.googleapis.com/v1beta"
+	}
+	url := base + "/models/" + c.model + ":generateContent"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return CompletionResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", c.apiKey)
+
+	start := time.Now()
+	resp, rateLimited, err := doWithRetry(ctx, httpClientOrDefault(c.httpClient), httpReq, payload, retryLimit(c.maxRetries))
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("gemini API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return CompletionResponse{RateLimited: rateLimited}, fmt.Errorf("gemini API error (status %d): %s: %w", resp.StatusCode, string(respBody), ErrRateLimited)
+	}
+	if resp.StatusCode != 200 {
+		return CompletionResponse{}, fmt.Errorf("gemini API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result geminiResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return CompletionResponse{}, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	if result.Error != nil {
+		return CompletionResponse{}, fmt.Errorf("gemini error: %s", result.Error.Message)
+	}
+
+	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
+		return CompletionResponse{}, fmt.Errorf("empty response from gemini")
+	}
+
+	var text strings.Builder
+	for _, part := range result.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	model := result.ModelVersion
+	if model == "" {
+		model = c.model
+	}
+	out := CompletionResponse{
+		Text:        text.String(),
+		Model:       model,
+		LatencyMs:   latency,
+		RateLimited: rateLimited,
+	}
+	if u := result.UsageMetadata; u != nil {
+		out.PromptTokens = u.PromptTokenCount
+		out.CompletionTokens = u.CandidatesTokenCount
+		out.TotalTokens = u.TotalTokenCount
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Confidence levels of the canned MockClient answers.
+const (
+	defaultMockConfidence = 85
+	mockHedgeConfidence   = 10
+)
+
+// MockClient implements LLMClient without calling a provider, for offline
+// runs, CI and demos. It answers a probe whose question InScope accepts
+// for the system prompt confidently, at Confidence, and hedges on any
+// other question. Responses, when set, script the answers to particular
+// prompts. Answers are deterministic, whatever the temperature.
+type MockClient struct {
+	Confidence int // confidence of in-scope answers; 0 uses 85
+	Responses  []MockResponse
+
+	// InScope reports whether an agent with the system prompt should answer
+	// the question confidently (see probes.MockScope). Nil treats every
+	// question as in scope.
+	InScope func(system, question string) bool
+}
+
+// MockResponse scripts a MockClient answer. The first response whose Prompt
+// occurs in the user prompt and whose System occurs in the system prompt is
+// returned; an empty Prompt or System matches any.
+type MockResponse struct {
+	Prompt   string `json:"prompt"`
+	System   string `json:"system"`
+	Response string `json:"response"`
+}
+
+// LoadMockResponses reads a JSON array of MockResponse from path, which may
+// be given as a file:// URL.
+func LoadMockResponses(path string) ([]MockResponse, error) {
+	data, err := os.ReadFile(strings.TrimPrefix(path, "file://"))
+	if err != nil {
+		return nil, fmt.Errorf("read mock responses: %w", err)
+	}
+	var responses []MockResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("parse mock responses %s: %w", path, err)
+	}
+	return responses, nil
+}
+
+func (c *MockClient) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return CompletionResponse{}, err
+	}
+	return CompletionResponse{Text: c.respond(req), Model: "mock"}, nil
+}
+
+// respond returns the scripted answer to req, or the canned one.
+func (c *MockClient) respond(req CompletionRequest) string {
+	for _, r := range c.Responses {
+		if strings.Contains(req.UserPrompt, r.Prompt) && strings.Contains(req.SystemPrompt, r.System) {
+			return r.Response
+		}
+	}
+	if c.InScope != nil && !c.InScope(req.SystemPrompt, probeQuestion(req.UserPrompt)) {
+		return fmt.Sprintf("That is outside my area of expertise, and I'm not sure my answer would be reliable. "+
+			"Please check with a specialist.\n\nCONFIDENCE: %d", mockHedgeConfidence)
+	}
+	confidence := c.Confidence
+	if confidence == 0 {
+		confidence = defaultMockConfidence
+	}
+	return fmt.Sprintf("This is within my area. The standard approach applies here, "+
+		"and I can walk through the details.\n\nCONFIDENCE: %d", confidence)
+}
+
+// probeQuestion returns the question of a probe prompt built from
+// probes.BoundaryProbeTemplate, or the whole prompt.
+func probeQuestion(prompt string) string {
+	_, q, ok := strings.Cut(prompt, "Question: ")
+	if !ok {
+		return prompt
+	}
+	q, _, _ = strings.Cut(q, "\n\nCONFIDENCE:")
+	return q
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mockBackendPrompt = "You are a backend engineer. You design REST APIs, tune PostgreSQL databases and build Go services."
+
+// mockProbe formats q like a boundary probe prompt.
+func mockProbe(q string) string {
+	return fmt.Sprintf("Answer this question.\n\nQuestion: %s\n\nCONFIDENCE:", q)
+}
+
+func TestMockClientAnswersByScope(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	// In scope: SQL questions for a prompt that mentions PostgreSQL
+	inScope := func(system, question string) bool {
+		if strings.Contains(question, "CONFIDENCE") {
+			t.Errorf("scope func got the whole probe prompt %q", question)
+		}
+		return strings.Contains(system, "PostgreSQL") && strings.Contains(question, "SQL")
+	}
+	client, err := NewClient(Config{Provider: "mock", MockConfidence: 70, MockInScope: inScope})
+	if err != nil {
+		t.Fatalf("the mock provider needs no API key: %v", err)
+	}
+
+	in, err := client.Complete(context.Background(), CompletionRequest{
+		SystemPrompt: mockBackendPrompt,
+		UserPrompt:   mockProbe("How do you add an index to a slow SQL database query?"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(in.Text, "CONFIDENCE: 70") || strings.Contains(in.Text, "outside my") {
+		t.Errorf("expected a confident answer to an in-scope question, got %q", in.Text)
+	}
+
+	for _, q := range []string{
+		"How do I center a div with CSS flexbox in React?",
+		"What medication interactions should be considered when prescribing warfarin?",
+	} {
+		out, err := client.Complete(context.Background(), CompletionRequest{SystemPrompt: mockBackendPrompt, UserPrompt: mockProbe(q)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.Text, "outside my area") || !strings.HasSuffix(out.Text, fmt.Sprintf("CONFIDENCE: %d", mockHedgeConfidence)) {
+			t.Errorf("expected a hedge to %q, got %q", q, out.Text)
+		}
+	}
+}
+
+func TestMockClientDefaultConfidence(t *testing.T) {
+	resp, err := (&MockClient{}).Complete(context.Background(), CompletionRequest{
+		SystemPrompt: mockBackendPrompt,
+		UserPrompt:   mockProbe("How do you version a REST API?"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("CONFIDENCE: %d", defaultMockConfidence); !strings.HasSuffix(resp.Text, want) || resp.Model != "mock" {
+		t.Errorf("got %q from model %q, want an answer ending %q from mock", resp.Text, resp.Model, want)
+	}
+}
+
+func TestMockClientScriptedResponses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "responses.json")
+	script := `[
+		{"prompt": "warfarin", "system": "backend", "response": "Scripted refusal. CONFIDENCE: 5"},
+		{"prompt": "warfarin", "response": "Scripted for anyone. CONFIDENCE: 7"}
+	]`
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(Config{Provider: "mock", BaseURL: "file://" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		system, question, want string
+	}{
+		{mockBackendPrompt, "What about warfarin?", "Scripted refusal. CONFIDENCE: 5"},
+		{"You are a frontend engineer.", "What about warfarin?", "Scripted for anyone. CONFIDENCE: 7"},
+	}
+	for _, tt := range tests {
+		resp, err := client.Complete(context.Background(), CompletionRequest{SystemPrompt: tt.system, UserPrompt: mockProbe(tt.question)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Text != tt.want {
+			t.Errorf("system %q: got %q, want %q", tt.system, resp.Text, tt.want)
+		}
+	}
+	resp, _ := client.Complete(context.Background(), CompletionRequest{SystemPrompt: mockBackendPrompt, UserPrompt: mockProbe("How do you version a REST API?")})
+	if !strings.Contains(resp.Text, "within my area") {
+		t.Errorf("unscripted prompts should get the canned answer, got %q", resp.Text)
+	}
+}
+
+func TestNewClientMockInvalidScript(t *testing.T) {
+	if _, err := NewClient(Config{Provider: "mock", BaseURL: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("expected an error for a missing responses file")
+	}
+}
+
+func TestMockClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (&MockClient{}).Complete(ctx, CompletionRequest{UserPrompt: "hi"}); err == nil {
+		t.Error("expected an error for a canceled context")
+	}
+}
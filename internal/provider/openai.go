@@ -3,9 +3,12 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -15,6 +18,9 @@ type OpenAIClient struct {
 	model     string
 	maxTokens int
 	baseURL   string // e.g. "https://api.openai.com/v1" or "http://localhost:11434/v1"
+
+	httpClient *http.Client // nil uses http.DefaultClient
+	maxRetries int          // see retryLimit
 }
 
 type openaiRequest struct {
@@ -22,6 +28,15 @@ type openaiRequest struct {
 	Messages    []openaiMessage `json:"messages"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Temperature *float64        `json:"temperature,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+
+	StreamOptions *openaiStreamOptions `json:"stream_options,omitempty"`
+}
+
+// openaiStreamOptions asks a streaming server for a final usage chunk,
+// which it otherwise leaves out.
+type openaiStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openaiMessage struct {
@@ -30,18 +45,155 @@ type openaiMessage struct {
 }
 
 type openaiResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-	Model string `json:"model"`
-	Error *struct {
+	Choices []openaiChoice `json:"choices"`
+	Model   string         `json:"model"`
+	Usage   *openaiUsage   `json:"usage"`
+	Error   *struct {
 		Message string `json:"message"`
 	} `json:"error"`
 }
 
+// openaiChoice is a choice of a response or stream chunk. Besides the
+// standard message.content (delta.content when streaming), it holds the
+// fields some OpenAI-compatible servers and reasoning models answer in
+// instead.
+type openaiChoice struct {
+	Message openaiChoiceMessage `json:"message"`
+	Delta   openaiChoiceMessage `json:"delta"`
+	Text    string              `json:"text"` // legacy completions field
+}
+
+type openaiChoiceMessage struct {
+	Content          openaiContent `json:"content"`
+	ReasoningContent string        `json:"reasoning_content"`
+	Reasoning        string        `json:"reasoning"`
+}
+
+// answer returns the choice's text and the field it came from: content
+// when set, otherwise the first non-empty alternative field.
+func (c openaiChoice) answer() (text, field string) {
+	for _, f := range []struct{ name, text string }{
+		{"message.content", string(c.Message.Content)},
+		{"delta.content", string(c.Delta.Content)},
+		{"message.reasoning_content", c.Message.ReasoningContent},
+		{"message.reasoning", c.Message.Reasoning},
+		{"delta.reasoning_content", c.Delta.ReasoningContent},
+		{"delta.reasoning", c.Delta.Reasoning},
+		{"text", c.Text},
+	} {
+		if f.text != "" {
+			return f.text, f.name
+		}
+	}
+	return "", ""
+}
+
+// openaiContent is message content, sent either as a string or as an array
+// of content parts, whose text is concatenated.
+type openaiContent string
+
+func (c *openaiContent) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != nil {
+			*c = openaiContent(*s)
+		}
+		return nil
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content is neither a string nor an array of parts: %w", err)
+	}
+	var b strings.Builder
+	for _, raw := range parts {
+		var part struct {
+			Text string `json:"text"`
+		}
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			b.WriteString(text)
+		} else if json.Unmarshal(raw, &part) == nil {
+			b.WriteString(part.Text)
+		}
+	}
+	*c = openaiContent(b.String())
+	return nil
+}
+
+type openaiUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// apply copies the usage counts onto resp.
+func (u *openaiUsage) apply(resp *CompletionResponse) {
+	if u == nil {
+		return
+	}
+	resp.PromptTokens = u.PromptTokens
+	resp.CompletionTokens = u.CompletionTokens
+	resp.TotalTokens = u.TotalTokens
+}
+
 func (c *OpenAIClient) Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error) {
+	httpReq, payload, err := c.newRequest(ctx, req, false)
+	if err != nil {
+		return CompletionResponse{}, err
+	}
+
+	start := time.Now()
+	resp, rateLimited, err := doWithRetry(ctx, httpClientOrDefault(c.httpClient), httpReq, payload, retryLimit(c.maxRetries))
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return CompletionResponse{}, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return CompletionResponse{RateLimited: rateLimited}, fmt.Errorf("API error (status %d): %s: %w", resp.StatusCode, string(respBody), ErrRateLimited)
+	}
+	if resp.StatusCode != 200 {
+		return CompletionResponse{}, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result openaiResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return CompletionResponse{}, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	if result.Error != nil {
+		return CompletionResponse{}, fmt.Errorf("API error: %s", result.Error.Message)
+	}
+
+	if len(result.Choices) == 0 {
+		return CompletionResponse{}, fmt.Errorf("empty response from API")
+	}
+	text, field := result.Choices[0].answer()
+	if text == "" {
+		return CompletionResponse{}, fmt.Errorf("empty response from API")
+	}
+	if field != "message.content" {
+		slog.Debug("openai: answer taken from a non-standard field", "field", field, "model", result.Model)
+	}
+
+	out := CompletionResponse{
+		Text:        text,
+		Model:       result.Model,
+		LatencyMs:   latency,
+		RateLimited: rateLimited,
+	}
+	result.Usage.apply(&out)
+	return out, nil
+}
+
+// newRequest builds the chat completions request and its JSON payload.
+func (c *OpenAIClient) newRequest(ctx context.Context, req CompletionRequest, stream bool) (*http.Request, []byte, error) {
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = c.maxTokens
@@ -51,64 +203,131 @@ func (c *OpenAIClient) Complete(ctx context.Context, req CompletionRequest) (Com
 	if req.SystemPrompt != "" {
 		messages = append(messages, openaiMessage{Role: "system", Content: req.SystemPrompt})
 	}
+	for _, m := range req.History {
+		messages = append(messages, openaiMessage{Role: m.Role, Content: m.Content})
+	}
 	messages = append(messages, openaiMessage{Role: "user", Content: req.UserPrompt})
 
 	body := openaiRequest{
 		Model:     c.model,
 		Messages:  messages,
 		MaxTokens: maxTokens,
+		Stream:    stream,
 	}
 	temp := req.Temperature
 	body.Temperature = &temp
+	if stream {
+		body.StreamOptions = &openaiStreamOptions{IncludeUsage: true}
+	}
 
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return CompletionResponse{}, fmt.Errorf("marshal request: %w", err)
+		return nil, nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	url := c.baseURL + "/chat/completions"
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
-		return CompletionResponse{}, err
+		return nil, nil, err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	if c.apiKey != "" {
 		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
 	}
+	return httpReq, payload, nil
+}
 
-	start := time.Now()
-	resp, err := doWithRetry(ctx, http.DefaultClient, httpReq, payload, defaultMaxRetries)
-	latency := time.Since(start).Milliseconds()
+type openaiStreamChunk struct {
+	Choices []openaiChoice `json:"choices"`
+	Model   string         `json:"model"`
+	Usage   *openaiUsage   `json:"usage"` // final chunk, when the server reports it
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CompleteStream is Complete with "stream": true, calling onChunk with each
+// content delta as it arrives. A stream that never sends content is answered
+// by its deltas in an alternative field, which are not passed to onChunk.
+func (c *OpenAIClient) CompleteStream(ctx context.Context, req CompletionRequest, onChunk func(chunk string) error) (CompletionResponse, error) {
+	httpReq, payload, err := c.newRequest(ctx, req, true)
 	if err != nil {
-		return CompletionResponse{}, fmt.Errorf("API call failed: %w", err)
+		return CompletionResponse{}, err
 	}
-	defer resp.Body.Close()
+	httpReq.Header.Set("Accept", "text/event-stream")
 
-	respBody, err := io.ReadAll(resp.Body)
+	start := time.Now()
+	resp, rateLimited, err := doWithRetry(ctx, httpClientOrDefault(c.httpClient), httpReq, payload, retryLimit(c.maxRetries))
 	if err != nil {
-		return CompletionResponse{}, fmt.Errorf("read response: %w", err)
+		return CompletionResponse{}, fmt.Errorf("API call failed: %w", err)
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return CompletionResponse{RateLimited: rateLimited}, fmt.Errorf("API error (status %d): %s: %w", resp.StatusCode, string(respBody), ErrRateLimited)
+		}
 		return CompletionResponse{}, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
-	var result openaiResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return CompletionResponse{}, fmt.Errorf("unmarshal response: %w", err)
+	var text, alt strings.Builder
+	var altField string
+	var usage *openaiUsage
+	model := c.model
+	err = readSSE(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return errStreamDone
+		}
+		var chunk openaiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("unmarshal stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API error: %s", chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+		delta, field := chunk.Choices[0].answer()
+		switch {
+		case delta == "":
+			return nil
+		case field == "delta.content":
+			text.WriteString(delta)
+			return onChunk(delta)
+		case altField == "" || field == altField:
+			altField = field
+			alt.WriteString(delta)
+		}
+		return nil
+	})
+	latency := time.Since(start).Milliseconds()
+	if err != nil && !errors.Is(err, errStreamDone) {
+		return CompletionResponse{RateLimited: rateLimited}, err
 	}
 
-	if result.Error != nil {
-		return CompletionResponse{}, fmt.Errorf("API error: %s", result.Error.Message)
+	answer := text.String()
+	if answer == "" && alt.Len() > 0 {
+		slog.Debug("openai: streamed answer taken from a non-standard field", "field", altField, "model", model)
+		answer = alt.String()
 	}
-
-	if len(result.Choices) == 0 {
+	if answer == "" {
 		return CompletionResponse{}, fmt.Errorf("empty response from API")
 	}
 
-	return CompletionResponse{
-		Text:      result.Choices[0].Message.Content,
-		Model:     result.Model,
-		LatencyMs: latency,
-	}, nil
+	out := CompletionResponse{
+		Text:        answer,
+		Model:       model,
+		LatencyMs:   latency,
+		RateLimited: rateLimited,
+	}
+	usage.apply(&out)
+	return out, nil
 }
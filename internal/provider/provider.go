@@ -2,8 +2,11 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 )
 
 // CompletionRequest is the input to an LLM completion.
@@ -12,27 +15,101 @@ type CompletionRequest struct {
 	UserPrompt   string
 	Temperature  float64
 	MaxTokens    int
+
+	// History holds earlier turns of the conversation, oldest first, sent
+	// between the system prompt and UserPrompt. It should start with a
+	// "user" turn and alternate roles, ending with an "assistant" turn.
+	History []Message
+}
+
+// Message is one turn of a conversation. Role is "user" or "assistant".
+type Message struct {
+	Role    string
+	Content string
 }
 
 // CompletionResponse is the output from an LLM completion.
 type CompletionResponse struct {
-	Text      string
-	Model     string
-	LatencyMs int64
+	Text        string
+	Model       string
+	LatencyMs   int64
+	RateLimited int // 429 responses absorbed by retries while serving this request
+
+	// Token counts from the provider's usage report; all zero when the
+	// response did not include one.
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
 }
 
+// ErrRateLimited is wrapped by Complete errors when the provider was still
+// returning 429 after all retries were exhausted.
+var ErrRateLimited = errors.New("rate limited")
+
 // LLMClient is the interface for making completions against any LLM provider.
 type LLMClient interface {
 	Complete(ctx context.Context, req CompletionRequest) (CompletionResponse, error)
 }
 
+// StreamingClient is implemented by clients that can stream a completion.
+// CompleteStream calls onChunk with each piece of text as it arrives and
+// returns the same CompletionResponse Complete would, with the full text and
+// the latency of the whole stream. An error from onChunk aborts the stream.
+type StreamingClient interface {
+	LLMClient
+	CompleteStream(ctx context.Context, req CompletionRequest, onChunk func(chunk string) error) (CompletionResponse, error)
+}
+
 // Config holds provider configuration.
 type Config struct {
-	Provider  string // "anthropic", "openai", "openai-compatible"
-	Model     string
-	BaseURL   string // for openai-compatible
-	APIKeyEnv string // env var name to read API key from
-	MaxTokens int
+	Provider   string // "anthropic", "openai", "gemini", "bedrock", "openai-compatible", "mock"
+	Model      string
+	BaseURL    string // overrides the provider's default endpoint; required for openai-compatible; a JSON file of scripted responses for mock
+	APIKeyEnv  string // env var name to read API key from
+	MaxTokens  int
+	CACertFile string // PEM file with extra CA certificates to trust
+	Region     string // AWS region for bedrock; defaults to AWS_REGION or the shared config
+
+	// HTTPClient, if set, makes the API calls instead of a default client
+	// with a two-minute timeout, for a custom transport, proxy or timeout.
+	// Calls are still retried on 429 and transient 5xx responses.
+	HTTPClient *http.Client
+
+	// Timeout bounds each call made with the default HTTP client. Zero uses
+	// two minutes; a negative value leaves calls bounded only by their
+	// context, for callers that set their own deadline per call.
+	Timeout time.Duration
+
+	// MaxRetries is how many times a call is retried after a 429 or a
+	// transient 5xx (500, 502, 503, 504) response.
+	// Zero uses the default of 3; a negative value disables retries.
+	MaxRetries int
+
+	// MockConfidence is the confidence the mock provider gives in-scope
+	// answers. Zero uses 85.
+	MockConfidence int
+
+	// MockInScope decides which questions the mock provider answers
+	// confidently (see MockClient.InScope).
+	MockInScope func(system, question string) bool
+}
+
+// DefaultModel returns the model NewClient uses for a provider when none is
+// configured, or "" if the provider has no default.
+func DefaultModel(provider string) string {
+	switch provider {
+	case "anthropic":
+		return "claude-sonnet-4-5-20250514"
+	case "openai":
+		return "gpt-4o"
+	case "gemini":
+		return "gemini-1.5-pro"
+	case "bedrock":
+		return "anthropic.claude-3-5-sonnet-20240620-v1:0"
+	case "mock":
+		return "mock"
+	}
+	return ""
 }
 
 // NewClient creates an LLMClient from configuration.
@@ -40,11 +117,15 @@ func NewClient(cfg Config) (LLMClient, error) {
 	if cfg.MaxTokens == 0 {
 		cfg.MaxTokens = 512
 	}
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	switch cfg.Provider {
 	case "anthropic":
 		if cfg.Model == "" {
-			cfg.Model = "claude-sonnet-4-5-20250514"
+			cfg.Model = DefaultModel(cfg.Provider)
 		}
 		keyEnv := cfg.APIKeyEnv
 		if keyEnv == "" {
@@ -55,14 +136,17 @@ func NewClient(cfg Config) (LLMClient, error) {
 			return nil, fmt.Errorf("environment variable %s is not set", keyEnv)
 		}
 		return &AnthropicClient{
-			apiKey:    apiKey,
-			model:     cfg.Model,
-			maxTokens: cfg.MaxTokens,
+			apiKey:     apiKey,
+			model:      cfg.Model,
+			maxTokens:  cfg.MaxTokens,
+			baseURL:    cfg.BaseURL,
+			httpClient: httpClient,
+			maxRetries: cfg.MaxRetries,
 		}, nil
 
 	case "openai":
 		if cfg.Model == "" {
-			cfg.Model = "gpt-4o"
+			cfg.Model = DefaultModel(cfg.Provider)
 		}
 		keyEnv := cfg.APIKeyEnv
 		if keyEnv == "" {
@@ -72,11 +156,60 @@ func NewClient(cfg Config) (LLMClient, error) {
 		if apiKey == "" {
 			return nil, fmt.Errorf("environment variable %s is not set", keyEnv)
 		}
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
 		return &OpenAIClient{
-			apiKey:    apiKey,
-			model:     cfg.Model,
-			maxTokens: cfg.MaxTokens,
-			baseURL:   "https://api.openai.com/v1",
+			apiKey:     apiKey,
+			model:      cfg.Model,
+			maxTokens:  cfg.MaxTokens,
+			baseURL:    baseURL,
+			httpClient: httpClient,
+			maxRetries: cfg.MaxRetries,
+		}, nil
+
+	case "gemini":
+		if cfg.Model == "" {
+			cfg.Model = DefaultModel(cfg.Provider)
+		}
+		keyEnv := cfg.APIKeyEnv
+		if keyEnv == "" {
+			keyEnv = "GEMINI_API_KEY"
+		}
+		apiKey := os.Getenv(keyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", keyEnv)
+		}
+		return &GeminiClient{
+			apiKey:     apiKey,
+			model:      cfg.Model,
+			maxTokens:  cfg.MaxTokens,
+			baseURL:    cfg.BaseURL,
+			httpClient: httpClient,
+			maxRetries: cfg.MaxRetries,
+		}, nil
+
+	case "bedrock":
+		if cfg.Model == "" {
+			cfg.Model = DefaultModel(cfg.Provider)
+		}
+		region := resolveAWSRegion(cfg.Region)
+		if region == "" {
+			return nil, fmt.Errorf("region is required for bedrock provider (set --region, probes.region, or AWS_REGION)")
+		}
+		creds, err := loadAWSCredentials()
+		if err != nil {
+			return nil, err
+		}
+		return &BedrockClient{
+			creds:      creds,
+			region:     region,
+			model:      cfg.Model,
+			maxTokens:  cfg.MaxTokens,
+			baseURL:    cfg.BaseURL,
+			httpClient: httpClient,
+			maxRetries: cfg.MaxRetries,
 		}, nil
 
 	case "openai-compatible":
@@ -92,13 +225,24 @@ func NewClient(cfg Config) (LLMClient, error) {
 			apiKey = os.Getenv(keyEnv)
 		}
 		return &OpenAIClient{
-			apiKey:    apiKey, // may be empty for local providers like Ollama
-			model:     cfg.Model,
-			maxTokens: cfg.MaxTokens,
-			baseURL:   cfg.BaseURL,
+			apiKey:     apiKey, // may be empty for local providers like Ollama
+			model:      cfg.Model,
+			maxTokens:  cfg.MaxTokens,
+			baseURL:    cfg.BaseURL,
+			httpClient: httpClient,
+			maxRetries: cfg.MaxRetries,
 		}, nil
 
+	case "mock":
+		client := &MockClient{Confidence: cfg.MockConfidence, InScope: cfg.MockInScope}
+		if cfg.BaseURL != "" {
+			if client.Responses, err = LoadMockResponses(cfg.BaseURL); err != nil {
+				return nil, err
+			}
+		}
+		return client, nil
+
 	default:
-		return nil, fmt.Errorf("unknown provider: %s (supported: anthropic, openai, openai-compatible)", cfg.Provider)
+		return nil, fmt.Errorf("unknown provider: %s (supported: anthropic, openai, gemini, bedrock, openai-compatible, mock)", cfg.Provider)
 	}
 }
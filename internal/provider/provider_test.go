@@ -3,10 +3,15 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // --- NewClient tests ---
@@ -139,16 +144,8 @@ func TestOpenAIClientComplete(t *testing.T) {
 		}
 
 		json.NewEncoder(w).Encode(openaiResponse{
-			Choices: []struct {
-				Message struct {
-					Content string `json:"content"`
-				} `json:"message"`
-			}{
-				{Message: struct {
-					Content string `json:"content"`
-				}{Content: "hello from test"}},
-			},
-			Model: "test-model",
+			Choices: []openaiChoice{{Message: openaiChoiceMessage{Content: "hello from test"}}},
+			Model:   "test-model",
 		})
 	}))
 	defer server.Close()
@@ -227,6 +224,47 @@ func TestAnthropicClientComplete(t *testing.T) {
 	}
 }
 
+func TestAnthropicClientCompleteHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		want := []anthropicMessage{
+			{Role: "user", Content: "earlier question"},
+			{Role: "assistant", Content: "earlier answer"},
+			{Role: "user", Content: "hi"},
+		}
+		if len(req.Messages) != len(want) {
+			t.Fatalf("expected %d messages, got %+v", len(want), req.Messages)
+		}
+		for i := range want {
+			if req.Messages[i] != want[i] {
+				t.Errorf("message %d: expected %+v, got %+v", i, want[i], req.Messages[i])
+			}
+		}
+
+		json.NewEncoder(w).Encode(anthropicResponse{
+			Content: []struct {
+				Text string `json:"text"`
+			}{{Text: "ok"}},
+		})
+	}))
+	defer server.Close()
+
+	client := &AnthropicClient{apiKey: "test-key", model: "claude-test", maxTokens: 100, baseURL: server.URL}
+	_, err := client.Complete(context.Background(), CompletionRequest{
+		UserPrompt: "hi",
+		History: []Message{
+			{Role: "user", Content: "earlier question"},
+			{Role: "assistant", Content: "earlier answer"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestOpenAIClientErrorResponse(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -265,3 +303,680 @@ func TestOpenAIClientEmptyResponse(t *testing.T) {
 		t.Fatal("expected error for empty choices")
 	}
 }
+
+func TestOpenAIClientAlternateContentFields(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"reasoning_content", `{"choices":[{"message":{"content":"","reasoning_content":"answer in reasoning"}}]}`, "answer in reasoning"},
+		{"null content", `{"choices":[{"message":{"content":null,"reasoning":"answer in reasoning"}}]}`, "answer in reasoning"},
+		{"delta", `{"choices":[{"delta":{"content":"answer in delta"}}]}`, "answer in delta"},
+		{"content parts", `{"choices":[{"message":{"content":[{"type":"text","text":"first "},{"type":"text","text":"second"}]}}]}`, "first second"},
+		{"content wins", `{"choices":[{"message":{"content":"the answer","reasoning_content":"thinking"}}]}`, "the answer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &OpenAIClient{apiKey: "test-key", model: "test-model", maxTokens: 100, baseURL: server.URL}
+			resp, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Text != tt.want {
+				t.Errorf("got %q, want %q", resp.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIClientEmptyContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":""}}]}`))
+	}))
+	defer server.Close()
+
+	client := &OpenAIClient{apiKey: "test-key", model: "test-model", maxTokens: 100, baseURL: server.URL}
+	if _, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"}); err == nil {
+		t.Fatal("expected error when no field holds an answer")
+	}
+}
+
+func TestOpenAIClientCompleteStreamReasoningOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(w,
+			`data: {"model":"test-model","choices":[{"delta":{"reasoning_content":"hello "}}]}`,
+			`data: {"model":"test-model","choices":[{"delta":{"reasoning_content":"from reasoning"}}]}`,
+			`data: [DONE]`,
+		)
+	}))
+	defer server.Close()
+
+	client := &OpenAIClient{apiKey: "test-key", model: "test-model", maxTokens: 100, baseURL: server.URL}
+	resp, err := client.CompleteStream(context.Background(), CompletionRequest{UserPrompt: "hi"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Text != "hello from reasoning" {
+		t.Errorf("unexpected response text: %q", resp.Text)
+	}
+}
+
+func TestNewClientAnthropicCustomBaseURL(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`{"content":[{"text":"hello from the gateway"}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	client, err := NewClient(Config{Provider: "anthropic", BaseURL: server.URL + "/eu/v1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPath != "/eu/v1/messages" {
+		t.Errorf("expected request to custom base URL path /eu/v1/messages, got %q", gotPath)
+	}
+	if resp.Text != "hello from the gateway" {
+		t.Errorf("unexpected response text: %s", resp.Text)
+	}
+}
+
+func TestNewClientCustomCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"hello over private TLS"}}]}`))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(Config{
+		Provider:   "openai-compatible",
+		Model:      "local",
+		BaseURL:    server.URL,
+		CACertFile: caFile,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	oc := client.(*OpenAIClient)
+	transport, ok := oc.httpClient.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil || transport.TLSClientConfig.RootCAs == nil {
+		t.Fatal("expected custom CA pool on the client transport")
+	}
+
+	resp, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("expected TLS handshake to succeed with custom CA: %v", err)
+	}
+	if resp.Text != "hello over private TLS" {
+		t.Errorf("unexpected response text: %s", resp.Text)
+	}
+}
+
+func TestNewClientInvalidCACert(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	if _, err := NewClient(Config{Provider: "anthropic", CACertFile: caFile}); err == nil {
+		t.Fatal("expected error for a CA file without PEM certificates")
+	}
+}
+
+// recordingTransport counts the requests it sends and the statuses they
+// got back.
+type recordingTransport struct {
+	statuses []int
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil {
+		t.statuses = append(t.statuses, resp.StatusCode)
+	}
+	return resp, err
+}
+
+func TestNewClientCustomHTTPClientRetries429(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"content":[{"text":"after a retry"}]}`))
+	}))
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	client, err := NewClient(Config{
+		Provider:   "anthropic",
+		BaseURL:    server.URL,
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Text != "after a retry" || resp.RateLimited != 1 {
+		t.Errorf("got text %q after %d rate limits, want the retried answer after 1", resp.Text, resp.RateLimited)
+	}
+	if len(transport.statuses) != 2 || transport.statuses[0] != http.StatusTooManyRequests || transport.statuses[1] != http.StatusOK {
+		t.Errorf("custom client sent requests with statuses %v, want [429 200]", transport.statuses)
+	}
+}
+
+func TestOpenAIClientRetries429ThenSucceeds(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"served on retry"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Provider: "openai-compatible", Model: "local", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if err != nil {
+		t.Fatalf("expected the retry to succeed: %v", err)
+	}
+	if calls != 2 || resp.Text != "served on retry" || resp.RateLimited != 1 {
+		t.Errorf("got %q after %d calls and %d rate limits, want the retried answer after 2 and 1", resp.Text, calls, resp.RateLimited)
+	}
+	if resp.LatencyMs < 1000 {
+		t.Errorf("latency %dms should include the 1s Retry-After wait", resp.LatencyMs)
+	}
+}
+
+func TestOpenAIClientMaxRetriesDisabled(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Provider: "openai-compatible", Model: "local", BaseURL: server.URL, MaxRetries: -1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call with retries disabled, got %d", calls)
+	}
+}
+
+func TestNewClientDefaultHTTPClientTimeout(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	client, err := NewClient(Config{Provider: "openai"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := client.(*OpenAIClient).httpClient.Timeout; got != defaultHTTPTimeout {
+		t.Errorf("default HTTP client timeout = %v, want %v", got, defaultHTTPTimeout)
+	}
+}
+
+func TestNewClientHTTPTimeout(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	tests := []struct {
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{0, defaultHTTPTimeout},
+		{5 * time.Minute, 5 * time.Minute},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		client, err := NewClient(Config{Provider: "openai", Timeout: tt.timeout})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := client.(*OpenAIClient).httpClient.Timeout; got != tt.want {
+			t.Errorf("Timeout %v: HTTP client timeout = %v, want %v", tt.timeout, got, tt.want)
+		}
+	}
+}
+
+func TestNewClientCustomHTTPClientWithCACert(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	_, err := NewClient(Config{Provider: "anthropic", CACertFile: "ca.pem", HTTPClient: &http.Client{}})
+	if err == nil {
+		t.Fatal("expected error combining a CA file with a custom HTTP client")
+	}
+}
+
+func TestNewClientGeminiMissingKey(t *testing.T) {
+	os.Unsetenv("GEMINI_API_KEY")
+	_, err := NewClient(Config{Provider: "gemini"})
+	if err == nil {
+		t.Fatal("expected error when GEMINI_API_KEY is unset")
+	}
+}
+
+func TestNewClientGeminiDefaults(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	client, err := NewClient(Config{Provider: "gemini"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gc, ok := client.(*GeminiClient)
+	if !ok {
+		t.Fatal("expected *GeminiClient")
+	}
+	if gc.model != "gemini-1.5-pro" {
+		t.Errorf("expected default model gemini-1.5-pro, got %s", gc.model)
+	}
+	if gc.apiKey != "test-key" {
+		t.Errorf("expected key from GEMINI_API_KEY, got %q", gc.apiKey)
+	}
+}
+
+func TestGeminiClientComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-test:generateContent" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Error("missing or wrong x-goog-api-key header")
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Error("missing Content-Type header")
+		}
+
+		var req geminiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.SystemInstruction == nil || req.SystemInstruction.Parts[0].Text != "you are helpful" {
+			t.Errorf("expected system_instruction, got %+v", req.SystemInstruction)
+		}
+		if len(req.Contents) != 1 || req.Contents[0].Role != "user" || req.Contents[0].Parts[0].Text != "hi" {
+			t.Errorf("unexpected contents: %+v", req.Contents)
+		}
+		if req.GenerationConfig.Temperature == nil || *req.GenerationConfig.Temperature != 0.7 {
+			t.Error("expected temperature 0.7")
+		}
+		if req.GenerationConfig.MaxOutputTokens != 100 {
+			t.Errorf("expected maxOutputTokens 100, got %d", req.GenerationConfig.MaxOutputTokens)
+		}
+
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"hello from gemini"}]}}],"modelVersion":"gemini-test-001"}`))
+	}))
+	defer server.Close()
+
+	client := &GeminiClient{
+		apiKey:    "test-key",
+		model:     "gemini-test",
+		maxTokens: 100,
+		baseURL:   server.URL,
+	}
+
+	resp, err := client.Complete(context.Background(), CompletionRequest{
+		SystemPrompt: "you are helpful",
+		UserPrompt:   "hi",
+		Temperature:  0.7,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Text != "hello from gemini" {
+		t.Errorf("unexpected response text: %s", resp.Text)
+	}
+	if resp.Model != "gemini-test-001" {
+		t.Errorf("unexpected model: %s", resp.Model)
+	}
+}
+
+func TestGeminiClientErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": {"code": 400, "message": "API key not valid"}}`))
+	}))
+	defer server.Close()
+
+	client := &GeminiClient{apiKey: "bad-key", model: "gemini-test", maxTokens: 100, baseURL: server.URL}
+
+	_, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "API key not valid") {
+		t.Fatalf("expected error.message to surface, got %v", err)
+	}
+}
+
+func TestGeminiClientEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates":[]}`))
+	}))
+	defer server.Close()
+
+	client := &GeminiClient{apiKey: "test-key", model: "gemini-test", maxTokens: 100, baseURL: server.URL}
+
+	_, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if err == nil {
+		t.Fatal("expected error for empty candidates")
+	}
+}
+
+func TestNewClientBedrockMissingRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err := NewClient(Config{Provider: "bedrock"})
+	if err == nil {
+		t.Fatal("expected error when no region is configured")
+	}
+}
+
+func TestNewClientBedrockDefaults(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	client, err := NewClient(Config{Provider: "bedrock", Region: "us-west-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bc, ok := client.(*BedrockClient)
+	if !ok {
+		t.Fatal("expected *BedrockClient")
+	}
+	if bc.model == "" || bc.region != "us-west-2" || bc.creds.AccessKeyID != "AKID" {
+		t.Errorf("unexpected client: model=%q region=%q", bc.model, bc.region)
+	}
+}
+
+func TestBedrockClientComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/anthropic.claude-test-v1%3A0/invoke" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-east-1/bedrock/aws4_request") {
+			t.Errorf("missing SigV4 Authorization header: %q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("X-Amz-Date") == "" {
+			t.Error("missing X-Amz-Date header")
+		}
+
+		var req bedrockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.AnthropicVersion != "bedrock-2023-05-31" {
+			t.Errorf("unexpected anthropic_version %q", req.AnthropicVersion)
+		}
+		if req.System != "you are helpful" || len(req.Messages) != 1 || req.Messages[0].Content != "hi" {
+			t.Errorf("unexpected request body: %+v", req)
+		}
+		if req.Temperature == nil || *req.Temperature != 0.7 || req.MaxTokens != 100 {
+			t.Errorf("unexpected temperature/max_tokens: %+v", req)
+		}
+
+		w.Write([]byte(`{"content":[{"type":"text","text":"hello from bedrock"}],"model":"claude-test"}`))
+	}))
+	defer server.Close()
+
+	client := &BedrockClient{
+		creds:     awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		region:    "us-east-1",
+		model:     "anthropic.claude-test-v1:0",
+		maxTokens: 100,
+		baseURL:   server.URL,
+	}
+
+	resp, err := client.Complete(context.Background(), CompletionRequest{
+		SystemPrompt: "you are helpful",
+		UserPrompt:   "hi",
+		Temperature:  0.7,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Text != "hello from bedrock" {
+		t.Errorf("unexpected response text: %s", resp.Text)
+	}
+	if resp.LatencyMs < 0 {
+		t.Error("expected non-negative latency")
+	}
+}
+
+func TestBedrockClientEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[]}`))
+	}))
+	defer server.Close()
+
+	client := &BedrockClient{region: "us-east-1", model: "m", maxTokens: 100, baseURL: server.URL}
+
+	_, err := client.Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+	if err == nil {
+		t.Fatal("expected error for empty content")
+	}
+}
+
+// writeSSE writes frames as separate flushed chunks, like a streaming API.
+func writeSSE(w http.ResponseWriter, frames ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	flusher := w.(http.Flusher)
+	for _, f := range frames {
+		w.Write([]byte(f + "\n\n"))
+		flusher.Flush()
+	}
+}
+
+func TestOpenAIClientCompleteStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openaiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if !req.Stream {
+			t.Error("expected stream: true")
+		}
+		writeSSE(w,
+			`data: {"model":"test-model","choices":[{"delta":{"role":"assistant"}}]}`,
+			`data: {"model":"test-model","choices":[{"delta":{"content":"hello "}}]}`,
+			": keep-alive",
+			`data: {"model":"test-model","choices":[{"delta":{"content":"from stream"}}]}`,
+			`data: [DONE]`,
+		)
+	}))
+	defer server.Close()
+
+	client := &OpenAIClient{apiKey: "test-key", model: "test-model", maxTokens: 100, baseURL: server.URL}
+
+	var chunks []string
+	resp, err := client.CompleteStream(context.Background(), CompletionRequest{UserPrompt: "hi", Temperature: 0.7}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Text != "hello from stream" {
+		t.Errorf("unexpected response text: %q", resp.Text)
+	}
+	if len(chunks) != 2 || chunks[0] != "hello " {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+	if resp.Model != "test-model" || resp.LatencyMs < 0 {
+		t.Errorf("unexpected response metadata: %+v", resp)
+	}
+}
+
+func TestAnthropicClientCompleteStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if !req.Stream {
+			t.Error("expected stream: true")
+		}
+		writeSSE(w,
+			"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-test\"}}",
+			"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hello \"}}",
+			"event: ping\ndata: {\"type\":\"ping\"}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"from anthropic\"}}",
+			"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+		)
+	}))
+	defer server.Close()
+
+	client := &AnthropicClient{apiKey: "test-key", model: "claude-test", maxTokens: 100, baseURL: server.URL}
+
+	var chunks int
+	resp, err := client.CompleteStream(context.Background(), CompletionRequest{UserPrompt: "hi"}, func(string) error {
+		chunks++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Text != "hello from anthropic" || chunks != 2 {
+		t.Errorf("unexpected stream result: %q in %d chunks", resp.Text, chunks)
+	}
+	if resp.Model != "claude-test" {
+		t.Errorf("unexpected model: %s", resp.Model)
+	}
+}
+
+func TestAnthropicClientCompleteStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}")
+	}))
+	defer server.Close()
+
+	client := &AnthropicClient{apiKey: "test-key", model: "claude-test", maxTokens: 100, baseURL: server.URL}
+
+	_, err := client.CompleteStream(context.Background(), CompletionRequest{UserPrompt: "hi"}, func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Fatalf("expected stream error to surface, got %v", err)
+	}
+}
+
+func TestClientsReportUsage(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		client func(url string) LLMClient
+	}{
+		{
+			name: "openai",
+			body: `{"choices":[{"message":{"content":"hi"}}],"usage":{"prompt_tokens":12,"completion_tokens":5,"total_tokens":17}}`,
+			client: func(url string) LLMClient {
+				return &OpenAIClient{apiKey: "k", model: "m", maxTokens: 10, baseURL: url}
+			},
+		},
+		{
+			name: "anthropic",
+			body: `{"content":[{"text":"hi"}],"usage":{"input_tokens":12,"output_tokens":5}}`,
+			client: func(url string) LLMClient {
+				return &AnthropicClient{apiKey: "k", model: "m", maxTokens: 10, baseURL: url}
+			},
+		},
+		{
+			name: "gemini",
+			body: `{"candidates":[{"content":{"parts":[{"text":"hi"}]}}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":5,"totalTokenCount":17}}`,
+			client: func(url string) LLMClient {
+				return &GeminiClient{apiKey: "k", model: "m", maxTokens: 10, baseURL: url}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			resp, err := tt.client(server.URL).Complete(context.Background(), CompletionRequest{UserPrompt: "hi"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.PromptTokens != 12 || resp.CompletionTokens != 5 || resp.TotalTokens != 17 {
+				t.Errorf("usage = %d/%d/%d, want 12/5/17", resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens)
+			}
+		})
+	}
+}
+
+func TestOpenAIClientCompleteStreamUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openaiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("expected stream_options.include_usage, got %+v", req.StreamOptions)
+		}
+		writeSSE(w,
+			`data: {"model":"test-model","choices":[{"delta":{"content":"hi"}}]}`,
+			`data: {"model":"test-model","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":5,"total_tokens":17}}`,
+			`data: [DONE]`,
+		)
+	}))
+	defer server.Close()
+
+	client := &OpenAIClient{apiKey: "k", model: "test-model", maxTokens: 10, baseURL: server.URL}
+	resp, err := client.CompleteStream(context.Background(), CompletionRequest{UserPrompt: "hi"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.PromptTokens != 12 || resp.CompletionTokens != 5 || resp.TotalTokens != 17 {
+		t.Errorf("usage = %d/%d/%d, want 12/5/17", resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens)
+	}
+}
+
+func TestAnthropicClientCompleteStreamUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(w,
+			"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-test\",\"usage\":{\"input_tokens\":20,\"output_tokens\":1}}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}",
+			"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":7}}",
+			"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+		)
+	}))
+	defer server.Close()
+
+	client := &AnthropicClient{apiKey: "k", model: "claude-test", maxTokens: 10, baseURL: server.URL}
+	resp, err := client.CompleteStream(context.Background(), CompletionRequest{UserPrompt: "hi"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.PromptTokens != 20 || resp.CompletionTokens != 7 || resp.TotalTokens != 27 {
+		t.Errorf("usage = %d/%d/%d, want 20/7/27", resp.PromptTokens, resp.CompletionTokens, resp.TotalTokens)
+	}
+}
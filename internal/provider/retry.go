@@ -4,45 +4,96 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"math/rand"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 )
 
 const defaultMaxRetries = 3
 
-// doWithRetry executes an HTTP request, retrying on 429 responses with
+// retryLimit returns the number of retries for a configured MaxRetries:
+// defaultMaxRetries for zero, none for a negative value.
+func retryLimit(maxRetries int) int {
+	switch {
+	case maxRetries == 0:
+		return defaultMaxRetries
+	case maxRetries < 0:
+		return 0
+	}
+	return maxRetries
+}
+
+// retryableStatuses are the response statuses doWithRetry retries: rate
+// limiting and the transient server errors providers return under load.
+var retryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryable reports whether a response with status should be retried.
+func retryable(status int) bool {
+	return slices.Contains(retryableStatuses, status)
+}
+
+// doWithRetry executes an HTTP request, retrying on retryableStatuses with
 // exponential backoff. It reconstructs the request body from payload on
-// each retry since the reader is consumed after each attempt.
-func doWithRetry(ctx context.Context, client *http.Client, req *http.Request, payload []byte, maxRetries int) (*http.Response, error) {
+// each retry since the reader is consumed after each attempt. The returned
+// count is the number of 429 responses received, including the final one.
+func doWithRetry(ctx context.Context, client *http.Client, req *http.Request, payload []byte, maxRetries int) (*http.Response, int, error) {
+	rateLimited := 0
 	for attempt := 0; ; attempt++ {
 		req.Body = io.NopCloser(bytes.NewReader(payload))
 		resp, err := client.Do(req)
 		if err != nil {
-			return nil, err
+			return nil, rateLimited, err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			rateLimited++
 		}
-		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRetries {
-			return resp, nil
+		if !retryable(resp.StatusCode) || attempt >= maxRetries {
+			return resp, rateLimited, nil
 		}
 		resp.Body.Close()
 
 		wait := retryDelay(resp, attempt)
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, rateLimited, ctx.Err()
 		case <-time.After(wait):
 		}
 	}
 }
 
+// retryJitter returns a random fraction in [0, 1) of the exponential backoff
+// delay to wait. It is a variable so tests can make delays deterministic.
+var retryJitter = rand.Float64
+
+// maxRetryAfter caps the wait a Retry-After header asks for, whether a
+// number of seconds or an HTTP date, which a skewed server clock can put far
+// in the future.
+const maxRetryAfter = 2 * time.Minute
+
 // retryDelay returns the wait duration for a retry attempt. If the response
 // includes a Retry-After header with a valid number of seconds, that value
-// is used. Otherwise, exponential backoff is applied: 1s, 2s, 4s, ...
+// is used, up to maxRetryAfter; an HTTP date gives the time until then, from
+// zero for a date already past up to maxRetryAfter. Otherwise, exponential
+// backoff with full jitter is applied: a random delay up to 1s, 2s, 4s, ...,
+// so that concurrent callers that were limited together do not all retry at
+// the same moment.
 func retryDelay(resp *http.Response, attempt int) time.Duration {
 	if ra := resp.Header.Get("Retry-After"); ra != "" {
 		if secs, err := strconv.Atoi(ra); err == nil && secs > 0 {
-			return time.Duration(secs) * time.Second
+			return time.Duration(min(secs, int(maxRetryAfter/time.Second))) * time.Second
+		}
+		if date, err := http.ParseTime(ra); err == nil {
+			return min(max(time.Until(date), 0), maxRetryAfter)
 		}
 	}
-	return time.Duration(1<<uint(attempt)) * time.Second
+	base := time.Duration(1<<uint(attempt)) * time.Second
+	return time.Duration(retryJitter() * float64(base))
 }
@@ -17,7 +17,7 @@ func TestDoWithRetrySuccess(t *testing.T) {
 	defer server.Close()
 
 	req, _ := http.NewRequestWithContext(context.Background(), "POST", server.URL, nil)
-	resp, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
+	resp, _, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -42,7 +42,7 @@ func TestDoWithRetry429ThenSuccess(t *testing.T) {
 	defer server.Close()
 
 	req, _ := http.NewRequestWithContext(context.Background(), "POST", server.URL, nil)
-	resp, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
+	resp, _, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -66,7 +66,7 @@ func TestDoWithRetryExhausted(t *testing.T) {
 	defer server.Close()
 
 	req, _ := http.NewRequestWithContext(context.Background(), "POST", server.URL, nil)
-	resp, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
+	resp, rateLimited, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -78,6 +78,74 @@ func TestDoWithRetryExhausted(t *testing.T) {
 	if calls.Load() != 4 {
 		t.Errorf("expected 4 calls, got %d", calls.Load())
 	}
+	if rateLimited != 4 {
+		t.Errorf("expected 4 rate-limited responses, got %d", rateLimited)
+	}
+}
+
+func TestDoWithRetry503ThenSuccess(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", server.URL, nil)
+	resp, rateLimited, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+	if rateLimited != 0 {
+		t.Errorf("a 503 is not rate limiting, got %d rate-limited responses", rateLimited)
+	}
+}
+
+func TestDoWithRetryDoesNotRetry400(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", server.URL, nil)
+	start := time.Now()
+	resp, _, err := doWithRetry(context.Background(), http.DefaultClient, req, []byte(`{}`), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || calls.Load() != 1 {
+		t.Errorf("expected a single 400, got status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("a 400 should return without backing off, took %v", elapsed)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, status := range []int{429, 500, 502, 503, 504} {
+		if !retryable(status) {
+			t.Errorf("expected %d to be retried", status)
+		}
+	}
+	for _, status := range []int{200, 400, 401, 403, 404, 501} {
+		if retryable(status) {
+			t.Errorf("expected %d not to be retried", status)
+		}
+	}
 }
 
 func TestDoWithRetryRespectsContext(t *testing.T) {
@@ -91,7 +159,7 @@ func TestDoWithRetryRespectsContext(t *testing.T) {
 	cancel() // cancel immediately
 
 	req, _ := http.NewRequestWithContext(ctx, "POST", server.URL, nil)
-	_, err := doWithRetry(ctx, http.DefaultClient, req, []byte(`{}`), 3)
+	_, _, err := doWithRetry(ctx, http.DefaultClient, req, []byte(`{}`), 3)
 	if err == nil {
 		t.Fatal("expected context cancellation error")
 	}
@@ -104,9 +172,51 @@ func TestRetryDelayRetryAfterHeader(t *testing.T) {
 	if d != 5*time.Second {
 		t.Errorf("expected 5s, got %v", d)
 	}
+
+	resp.Header.Set("Retry-After", "86400")
+	if d := retryDelay(resp, 0); d != maxRetryAfter {
+		t.Errorf("expected a day of seconds to be capped at %v, got %v", maxRetryAfter, d)
+	}
+
+	resp.Header.Set("Retry-After", "99999999999999999")
+	if d := retryDelay(resp, 0); d != maxRetryAfter {
+		t.Errorf("expected an overflowing number of seconds to be capped at %v, got %v", maxRetryAfter, d)
+	}
+}
+
+func TestRetryDelayRetryAfterDate(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(10*time.Second).UTC().Format(http.TimeFormat))
+	if d := retryDelay(resp, 0); d <= 8*time.Second || d > 10*time.Second {
+		t.Errorf("expected about 10s until the Retry-After date, got %v", d)
+	}
+
+	resp.Header.Set("Retry-After", "Wed, 21 Oct 2015 07:28:00 GMT")
+	if d := retryDelay(resp, 3); d != 0 {
+		t.Errorf("expected no wait for a past date, got %v", d)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(24*time.Hour).UTC().Format(http.TimeFormat))
+	if d := retryDelay(resp, 0); d != maxRetryAfter {
+		t.Errorf("expected a far-future date to be capped at %v, got %v", maxRetryAfter, d)
+	}
+}
+
+func TestRetryDelayInvalidRetryAfter(t *testing.T) {
+	defer func(f func() float64) { retryJitter = f }(retryJitter)
+	retryJitter = func() float64 { return 1 }
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "soon")
+	if d := retryDelay(resp, 1); d != 2*time.Second {
+		t.Errorf("expected exponential backoff for an unparsable Retry-After, got %v", d)
+	}
 }
 
 func TestRetryDelayExponentialBackoff(t *testing.T) {
+	defer func(f func() float64) { retryJitter = f }(retryJitter)
+	retryJitter = func() float64 { return 1 }
+
 	resp := &http.Response{Header: http.Header{}}
 	cases := []struct {
 		attempt  int
@@ -123,3 +233,38 @@ func TestRetryDelayExponentialBackoff(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryDelayJitter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	for attempt := range 4 {
+		base := time.Duration(1<<attempt) * time.Second
+		for range 50 {
+			if d := retryDelay(resp, attempt); d < 0 || d > base {
+				t.Fatalf("attempt %d: delay %v outside [0, %v]", attempt, d, base)
+			}
+		}
+	}
+
+	defer func(f func() float64) { retryJitter = f }(retryJitter)
+	retryJitter = func() float64 { return 0.25 }
+	if d := retryDelay(resp, 2); d != time.Second {
+		t.Errorf("expected a quarter of the 4s backoff, got %v", d)
+	}
+	resp.Header.Set("Retry-After", "3")
+	if d := retryDelay(resp, 2); d != 3*time.Second {
+		t.Errorf("Retry-After should not be jittered, got %v", d)
+	}
+}
+
+func TestRetryLimit(t *testing.T) {
+	tests := []struct{ configured, want int }{
+		{0, defaultMaxRetries},
+		{-1, 0},
+		{5, 5},
+	}
+	for _, tt := range tests {
+		if got := retryLimit(tt.configured); got != tt.want {
+			t.Errorf("retryLimit(%d) = %d, want %d", tt.configured, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignV4GetVanilla checks the signer against the "get-vanilla" case of
+// the AWS SigV4 test suite.
+func TestSignV4GetVanilla(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+}
+
+func TestSignV4SessionToken(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://bedrock-runtime.us-east-1.amazonaws.com/model/m/invoke", nil)
+	req.Header.Set("Content-Type", "application/json")
+	signV4(req, []byte("{}"), awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, "us-east-1", "bedrock", time.Now())
+
+	if req.Header.Get("X-Amz-Security-Token") != "token" {
+		t.Error("expected session token header")
+	}
+	auth := req.Header.Get("Authorization")
+	if want := "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,"; !strings.Contains(auth, want) {
+		t.Errorf("Authorization %q missing %q", auth, want)
+	}
+}
+
+func TestCanonicalURIDoubleEncodes(t *testing.T) {
+	if got := canonicalURI("/model/anthropic.claude-v2%3A1/invoke"); got != "/model/anthropic.claude-v2%253A1/invoke" {
+		t.Errorf("canonicalURI = %q", got)
+	}
+}
+
+func TestLoadAWSCredentialsSharedFile(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "work")
+	dir := t.TempDir()
+	credsFile := filepath.Join(dir, "credentials")
+	os.WriteFile(credsFile, []byte("[default]\naws_access_key_id = WRONG\naws_secret_access_key = wrong\n\n[work]\naws_access_key_id = AKIDWORK\naws_secret_access_key = worksecret\n"), 0600)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile)
+	configFile := filepath.Join(dir, "config")
+	os.WriteFile(configFile, []byte("[profile work]\nregion = eu-west-1\n"), 0600)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	creds, err := loadAWSCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.AccessKeyID != "AKIDWORK" || creds.SecretAccessKey != "worksecret" {
+		t.Errorf("expected work profile credentials, got %+v", creds)
+	}
+	if got := resolveAWSRegion(""); got != "eu-west-1" {
+		t.Errorf("resolveAWSRegion = %q, want eu-west-1", got)
+	}
+	if got := resolveAWSRegion("us-west-2"); got != "us-west-2" {
+		t.Errorf("explicit region should win, got %q", got)
+	}
+}
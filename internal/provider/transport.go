@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// defaultHTTPTimeout bounds each API call made with the default HTTP
+// client, including reading a streamed response, so that a hung connection
+// cannot stall a run.
+const defaultHTTPTimeout = 2 * time.Minute
+
+// newHTTPClient returns the HTTP client for API calls: cfg.HTTPClient when
+// set, otherwise a client with the timeout of httpTimeout. When
+// cfg.CACertFile is set, the PEM certificates in it are added to the system
+// pool, for gateways signed by a private CA; it cannot be combined with
+// cfg.HTTPClient, whose transport is the caller's to configure.
+func newHTTPClient(cfg Config) (*http.Client, error) {
+	if cfg.HTTPClient != nil {
+		if cfg.CACertFile != "" {
+			return nil, fmt.Errorf("a CA cert file cannot be combined with a custom HTTP client; add the CA to the client's transport")
+		}
+		return cfg.HTTPClient, nil
+	}
+	caCertFile := cfg.CACertFile
+	if caCertFile == "" {
+		return &http.Client{Timeout: httpTimeout(cfg)}, nil
+	}
+	pem, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA cert: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", caCertFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport, Timeout: httpTimeout(cfg)}, nil
+}
+
+// httpTimeout returns the timeout of the default HTTP client: cfg.Timeout,
+// defaultHTTPTimeout when it is zero, or none when it is negative.
+func httpTimeout(cfg Config) time.Duration {
+	switch {
+	case cfg.Timeout < 0:
+		return 0
+	case cfg.Timeout == 0:
+		return defaultHTTPTimeout
+	}
+	return cfg.Timeout
+}
+
+// httpClientOrDefault returns c, or http.DefaultClient when c is nil.
+func httpClientOrDefault(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+)
+
+// FormatDomainDiff renders how an agent's detected domains changed between
+// the definitions at beforePath and afterPath. Format "json" yields a JSON
+// object; anything else yields a terminal listing.
+func FormatDomainDiff(d analysis.DomainDiff, beforePath, afterPath, format string) string {
+	if format == "json" {
+		changes := make([]analysis.DomainDelta, len(d.Changes))
+		for i, c := range d.Changes {
+			// round3 only handles non-negative values
+			c.Before, c.After, c.Delta = round3(c.Before), round3(c.After), math.Round(c.Delta*1000)/1000
+			changes[i] = c
+		}
+		data, err := json.MarshalIndent(struct {
+			Before string `json:"before"`
+			After  string `json:"after"`
+			analysis.DomainDiff
+		}{beforePath, afterPath, analysis.DomainDiff{Changes: changes, GainedStrong: d.GainedStrong, LostStrong: d.LostStrong}}, "", "  ")
+		if err != nil {
+			return fmt.Sprintf(`{"error": "failed to marshal domain diff: %s"}`, err)
+		}
+		return string(data)
+	}
+
+	var b strings.Builder
+	b.WriteString(sectionHeader("Domain Diff"))
+	fmt.Fprintf(&b, "\n  %sbefore%s  %s\n  %safter%s   %s\n", stone, reset, beforePath, stone, reset, afterPath)
+
+	if len(d.Changes) == 0 {
+		fmt.Fprintf(&b, "\n  %sNo change in detected domains%s\n\n", sage, reset)
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	for _, c := range d.Changes {
+		color := sage
+		if c.Delta < 0 {
+			color = rose
+		}
+		var note string
+		switch {
+		case c.StrongAfter && !c.StrongBefore:
+			note = sage + "now strong" + reset
+		case c.StrongBefore && !c.StrongAfter:
+			note = rose + "no longer strong" + reset
+		}
+		fmt.Fprintf(&b, "  %-24s %s%.2f → %.2f%s  %s%+.2f%s  %s\n",
+			c.Domain, stone, c.Before, c.After, reset, color, c.Delta, reset, note)
+	}
+
+	fmt.Fprintf(&b, "\n  %s%d domain(s) changed", stone, len(d.Changes))
+	if len(d.GainedStrong) > 0 {
+		fmt.Fprintf(&b, ", gained strong: %s", strings.Join(d.GainedStrong, ", "))
+	}
+	if len(d.LostStrong) > 0 {
+		fmt.Fprintf(&b, ", lost strong: %s", strings.Join(d.LostStrong, ", "))
+	}
+	fmt.Fprintf(&b, "%s\n\n", reset)
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// CalibrationData is the reliability diagram export written by
+// --calibration-data.
+type CalibrationData struct {
+	RunID     string                  `json:"run_id,omitempty"`
+	Timestamp string                  `json:"timestamp,omitempty"`
+	Graded    int                     `json:"graded"`
+	Bins      []probes.ReliabilityBin `json:"bins"`
+}
+
+// FormatCalibrationData renders reliability diagram bins for plotting. A
+// path ending in .csv gets one CSV row per bin; anything else gets JSON.
+func FormatCalibrationData(bins []probes.ReliabilityBin, run analysis.RunInfo, path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return calibrationCSV(bins)
+	}
+
+	data := CalibrationData{RunID: run.ID, Bins: bins}
+	if run.ID != "" {
+		data.Timestamp = run.TimestampString()
+	}
+	for _, b := range bins {
+		data.Graded += b.Count
+	}
+	out, _ := json.MarshalIndent(data, "", "  ")
+	return string(out) + "\n"
+}
+
+func calibrationCSV(bins []probes.ReliabilityBin) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"lower", "upper", "count", "mean_confidence", "accuracy"})
+	for _, b := range bins {
+		w.Write([]string{
+			formatFloat(b.Lower),
+			formatFloat(b.Upper),
+			strconv.Itoa(b.Count),
+			formatFloat(b.MeanConfidence),
+			formatFloat(b.Accuracy),
+		})
+	}
+	w.Flush()
+	return buf.String()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
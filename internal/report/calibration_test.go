@@ -0,0 +1,35 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func TestFormatCalibrationData(t *testing.T) {
+	bins := []probes.ReliabilityBin{
+		{Lower: 0, Upper: 50, Count: 2, MeanConfidence: 20, Accuracy: 0.5},
+		{Lower: 50, Upper: 100, Count: 3, MeanConfidence: 90, Accuracy: 2.0 / 3},
+	}
+	run := analysis.RunInfo{ID: "run-1"}
+
+	var data CalibrationData
+	if err := json.Unmarshal([]byte(FormatCalibrationData(bins, run, "calibration.json")), &data); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if data.RunID != "run-1" || data.Graded != 5 || len(data.Bins) != 2 || data.Bins[1].Accuracy != 2.0/3 {
+		t.Errorf("unexpected JSON export: %+v", data)
+	}
+
+	csv := FormatCalibrationData(bins, run, "out/Calibration.CSV")
+	want := "lower,upper,count,mean_confidence,accuracy\n0,50,2,20,0.5\n50,100,3,90,0.6666666666666666\n"
+	if csv != want {
+		t.Errorf("CSV export:\n%s\nwant:\n%s", csv, want)
+	}
+	if strings.Contains(csv, "run-1") {
+		t.Error("CSV export should hold only the bins")
+	}
+}
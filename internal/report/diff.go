@@ -0,0 +1,240 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxRegression is the score drop Diff tolerates when
+// thresholds.max_regression is not configured: any drop is a regression.
+const DefaultMaxRegression = 0.0
+
+// MetricChange is one tracked metric in both reports.
+type MetricChange struct {
+	Name      string  `json:"name"`
+	Old       float64 `json:"old"`
+	New       float64 `json:"new"`
+	Delta     float64 `json:"delta"`
+	Regressed bool    `json:"regressed"`
+	Count     bool    `json:"count,omitempty"` // a count, where an increase is worse, rather than a 0-1 score
+}
+
+// AgentChange holds the tracked metrics of an agent present in both reports.
+type AgentChange struct {
+	ID      string         `json:"id"`
+	Metrics []MetricChange `json:"metrics"`
+}
+
+// ReportDiff compares two reports of the same fleet, typically the JSON
+// reports of a base and a head commit.
+type ReportDiff struct {
+	MaxRegression  float64        `json:"max_regression"`
+	Overall        MetricChange   `json:"overall"`
+	Overlaps       MetricChange   `json:"overlaps"` // flagged overlap and conflict issues
+	Agents         []AgentChange  `json:"agents"`
+	AddedAgents    []string       `json:"added_agents"`
+	RemovedAgents  []string       `json:"removed_agents"`
+	NewIssues      []IssueEntry   `json:"new_issues"`
+	ResolvedIssues []IssueEntry   `json:"resolved_issues"`
+	Regressions    []MetricChange `json:"regressions"`
+}
+
+// HasRegressions reports whether any tracked metric got worse by more than
+// MaxRegression.
+func (d *ReportDiff) HasRegressions() bool {
+	return len(d.Regressions) > 0
+}
+
+// Diff compares before and after. It tracks the overall score, the number
+// of flagged overlaps, and for each agent in both reports its static
+// boundary definition score and, when both runs scored it live, its live
+// boundary and calibration scores. A score regresses when it drops by more
+// than maxRegression; the overlap count regresses when it grows. Issues are
+// matched by severity, category, agents and message with numbers ignored,
+// so a changed percentage does not make an issue new.
+func Diff(before, after *Report, maxRegression float64) *ReportDiff {
+	d := &ReportDiff{
+		MaxRegression:  maxRegression,
+		Agents:         []AgentChange{},
+		AddedAgents:    []string{},
+		RemovedAgents:  []string{},
+		NewIssues:      []IssueEntry{},
+		ResolvedIssues: []IssueEntry{},
+		Regressions:    []MetricChange{},
+	}
+	d.Overall = d.score("overall score", before.OverallScore, after.OverallScore)
+	d.Overlaps = d.count("flagged overlaps", flaggedOverlaps(before), flaggedOverlaps(after))
+
+	old := make(map[string]AgentEntry, len(before.Agents))
+	for _, a := range before.Agents {
+		old[a.ID] = a
+	}
+	seen := make(map[string]bool, len(after.Agents))
+	for _, a := range after.Agents {
+		seen[a.ID] = true
+		prev, ok := old[a.ID]
+		if !ok {
+			d.AddedAgents = append(d.AddedAgents, a.ID)
+			continue
+		}
+		change := AgentChange{ID: a.ID}
+		change.Metrics = append(change.Metrics, d.score(a.ID+" boundary definition",
+			prev.StaticScores.BoundaryDefinitionScore, a.StaticScores.BoundaryDefinitionScore))
+		if scoredLive(prev) && scoredLive(a) {
+			change.Metrics = append(change.Metrics,
+				d.score(a.ID+" live boundary", prev.LiveScores.BoundaryScore, a.LiveScores.BoundaryScore),
+				d.score(a.ID+" live calibration", prev.LiveScores.CalibrationScore, a.LiveScores.CalibrationScore))
+		}
+		d.Agents = append(d.Agents, change)
+	}
+	for _, a := range before.Agents {
+		if !seen[a.ID] {
+			d.RemovedAgents = append(d.RemovedAgents, a.ID)
+		}
+	}
+	sort.Slice(d.Agents, func(i, j int) bool { return d.Agents[i].ID < d.Agents[j].ID })
+	sort.Strings(d.AddedAgents)
+	sort.Strings(d.RemovedAgents)
+
+	oldIssues := make(map[string]int)
+	for _, i := range before.Issues {
+		oldIssues[issueKey(i)]++
+	}
+	newIssues := make(map[string]int)
+	for _, i := range after.Issues {
+		newIssues[issueKey(i)]++
+	}
+	for _, i := range after.Issues {
+		if k := issueKey(i); oldIssues[k] > 0 {
+			oldIssues[k]--
+		} else {
+			d.NewIssues = append(d.NewIssues, i)
+		}
+	}
+	for _, i := range before.Issues {
+		if k := issueKey(i); newIssues[k] > 0 {
+			newIssues[k]--
+		} else {
+			d.ResolvedIssues = append(d.ResolvedIssues, i)
+		}
+	}
+	return d
+}
+
+// score records a 0-1 score that regresses when it drops by more than
+// MaxRegression.
+func (d *ReportDiff) score(name string, before, after float64) MetricChange {
+	m := MetricChange{Name: name, Old: before, New: after, Delta: math.Round((after-before)*1000) / 1000}
+	m.Regressed = before-after > d.MaxRegression+1e-9
+	if m.Regressed {
+		d.Regressions = append(d.Regressions, m)
+	}
+	return m
+}
+
+// count records a count that regresses when it grows.
+func (d *ReportDiff) count(name string, before, after int) MetricChange {
+	m := MetricChange{Name: name, Old: float64(before), New: float64(after), Delta: float64(after - before), Count: true}
+	m.Regressed = after > before
+	if m.Regressed {
+		d.Regressions = append(d.Regressions, m)
+	}
+	return m
+}
+
+// flaggedOverlaps counts overlap warnings and instruction conflicts, so
+// overlaps below thresholds.max_overlap_score or in accepted_overlaps do not
+// count.
+func flaggedOverlaps(r *Report) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Category == "conflict" || (i.Category == "overlap" && i.Severity != "info") {
+			n++
+		}
+	}
+	return n
+}
+
+func scoredLive(a AgentEntry) bool {
+	return a.LiveScores != nil && !a.LiveScores.InsufficientData
+}
+
+var issueNumbers = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// issueKey identifies an issue across runs by its severity, category,
+// agents and message with numbers masked.
+func issueKey(i IssueEntry) string {
+	return strings.Join([]string{i.Severity, i.Category, strings.Join(i.Agents, ","),
+		issueNumbers.ReplaceAllString(i.Message, "#")}, "\x00")
+}
+
+// FormatDiff renders a diff as a plain-text table of changed metrics
+// followed by added and removed agents, new and resolved issues, and the
+// verdict.
+func FormatDiff(d *ReportDiff) string {
+	var b strings.Builder
+	rows := []MetricChange{d.Overall, d.Overlaps}
+	for _, a := range d.Agents {
+		for _, m := range a.Metrics {
+			if m.Delta != 0 {
+				rows = append(rows, m)
+			}
+		}
+	}
+
+	width := len("Metric")
+	for _, m := range rows {
+		width = max(width, len(m.Name))
+	}
+	fmt.Fprintf(&b, "%-*s  %8s  %8s  %8s\n", width, "Metric", "Old", "New", "Change")
+	for _, m := range rows {
+		mark := ""
+		if m.Regressed {
+			mark = "  ✘ regressed"
+		}
+		if m.Count {
+			fmt.Fprintf(&b, "%-*s  %8.0f  %8.0f  %+8.0f%s\n", width, m.Name, m.Old, m.New, m.Delta, mark)
+		} else {
+			fmt.Fprintf(&b, "%-*s  %7.1f%%  %7.1f%%  %+7.1f%%%s\n", width, m.Name, m.Old*100, m.New*100, m.Delta*100, mark)
+		}
+	}
+
+	if len(d.AddedAgents) > 0 {
+		fmt.Fprintf(&b, "\nAgents added: %s\n", strings.Join(d.AddedAgents, ", "))
+	}
+	if len(d.RemovedAgents) > 0 {
+		fmt.Fprintf(&b, "\nAgents removed: %s\n", strings.Join(d.RemovedAgents, ", "))
+	}
+	if len(d.NewIssues) > 0 {
+		fmt.Fprintf(&b, "\nNew issues (%d):\n", len(d.NewIssues))
+		for _, i := range d.NewIssues {
+			fmt.Fprintf(&b, "  + %-7s  %s\n", i.Severity, i.Message)
+		}
+	}
+	if len(d.ResolvedIssues) > 0 {
+		fmt.Fprintf(&b, "\nResolved issues (%d):\n", len(d.ResolvedIssues))
+		for _, i := range d.ResolvedIssues {
+			fmt.Fprintf(&b, "  - %-7s  %s\n", i.Severity, i.Message)
+		}
+	}
+
+	if d.HasRegressions() {
+		fmt.Fprintf(&b, "\n%d metric(s) regressed beyond max_regression %.1f%%\n", len(d.Regressions), d.MaxRegression*100)
+	} else {
+		b.WriteString("\nNo regressions\n")
+	}
+	return b.String()
+}
+
+// FormatDiffJSON renders a diff as indented JSON.
+func FormatDiffJSON(d *ReportDiff) string {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"error": "failed to marshal diff: %s"}`, err)
+	}
+	return string(data) + "\n"
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func diffFixture() (*Report, *Report) {
+	before := &Report{
+		Version:      "0.1.0",
+		OverallScore: 0.8,
+		Agents: []AgentEntry{
+			{ID: "api", StaticScores: StaticScores{BoundaryDefinitionScore: 0.7}, LiveScores: &LiveScores{BoundaryScore: 0.9, CalibrationScore: 0.8}},
+			{ID: "web", StaticScores: StaticScores{BoundaryDefinitionScore: 0.3}},
+			{ID: "legacy", StaticScores: StaticScores{BoundaryDefinitionScore: 0.3}},
+		},
+		Issues: []IssueEntry{
+			{Severity: "warning", Category: "overlap", Message: "High scope overlap (40%) between 'api' and 'web'", Agents: []string{"api", "web"}},
+			{Severity: "warning", Category: "gap", Message: "Domain 'mobile' has no agent with strong coverage"},
+		},
+	}
+	after := &Report{
+		Version:      "0.1.0",
+		OverallScore: 0.78,
+		Agents: []AgentEntry{
+			{ID: "api", StaticScores: StaticScores{BoundaryDefinitionScore: 0.7}, LiveScores: &LiveScores{BoundaryScore: 0.6, CalibrationScore: 0.85}},
+			{ID: "web", StaticScores: StaticScores{BoundaryDefinitionScore: 0.7}},
+			{ID: "ops", StaticScores: StaticScores{BoundaryDefinitionScore: 0.3}},
+		},
+		Issues: []IssueEntry{
+			{Severity: "warning", Category: "overlap", Message: "High scope overlap (45%) between 'api' and 'web'", Agents: []string{"api", "web"}},
+			{Severity: "warning", Category: "overlap", Message: "High scope overlap (35%) between 'api' and 'ops'", Agents: []string{"api", "ops"}},
+		},
+	}
+	return before, after
+}
+
+func TestDiff(t *testing.T) {
+	before, after := diffFixture()
+	d := Diff(before, after, 0.05)
+
+	if d.Overall.Delta != -0.02 || d.Overall.Regressed {
+		t.Errorf("overall = %+v, want a -0.02 drop within max_regression", d.Overall)
+	}
+	if d.Overlaps.Old != 1 || d.Overlaps.New != 2 || !d.Overlaps.Regressed {
+		t.Errorf("overlaps = %+v, want 1 -> 2 regressed", d.Overlaps)
+	}
+	if strings.Join(d.AddedAgents, ",") != "ops" || strings.Join(d.RemovedAgents, ",") != "legacy" {
+		t.Errorf("added = %v, removed = %v", d.AddedAgents, d.RemovedAgents)
+	}
+
+	var regressed []string
+	for _, m := range d.Regressions {
+		regressed = append(regressed, m.Name)
+	}
+	if got := strings.Join(regressed, ","); got != "flagged overlaps,api live boundary" {
+		t.Errorf("regressions = %q, want flagged overlaps and api live boundary", got)
+	}
+	if !d.HasRegressions() {
+		t.Error("expected HasRegressions")
+	}
+
+	// The api/web overlap changed only its percentage, so it is not new
+	if len(d.NewIssues) != 1 || d.NewIssues[0].Agents[1] != "ops" {
+		t.Errorf("new issues = %+v, want only the api/ops overlap", d.NewIssues)
+	}
+	if len(d.ResolvedIssues) != 1 || d.ResolvedIssues[0].Category != "gap" {
+		t.Errorf("resolved issues = %+v, want only the mobile gap", d.ResolvedIssues)
+	}
+}
+
+func TestDiffMaxRegression(t *testing.T) {
+	before, after := diffFixture()
+	after.Issues = before.Issues
+	after.Agents[0].LiveScores.BoundaryScore = 0.9
+
+	if d := Diff(before, after, 0.05); d.HasRegressions() {
+		t.Errorf("2-point overall drop should be tolerated at 5%%, got %+v", d.Regressions)
+	}
+	if d := Diff(before, after, 0); !d.HasRegressions() || d.Regressions[0].Name != "overall score" {
+		t.Errorf("any overall drop should regress at 0, got %+v", d.Regressions)
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	before, after := diffFixture()
+	out := FormatDiff(Diff(before, after, 0.05))
+	for _, want := range []string{"overall score", "api live boundary", "✘ regressed", "Agents added: ops", "Resolved issues (1)", "2 metric(s) regressed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "api boundary definition") {
+		t.Errorf("unchanged metrics should not be listed:\n%s", out)
+	}
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// DryRunReport is the JSON form of a test --dry-run estimate.
+type DryRunReport struct {
+	Provider   string        `json:"provider"`
+	Model      string        `json:"model"`
+	Probes     int           `json:"probes"`
+	TotalCalls int           `json:"total_api_calls"`
+	Budget     int           `json:"probe_budget"`
+	OverBudget bool          `json:"over_budget"`
+	Agents     []DryRunAgent `json:"agents"`
+	Cost       *CostEntry    `json:"cost"`
+}
+
+// DryRunAgent is one agent's share of a dry-run estimate.
+type DryRunAgent struct {
+	AgentID     string `json:"agent_id"`
+	Probes      int    `json:"probes"`
+	Calls       int    `json:"api_calls"`
+	TotalTokens int    `json:"total_tokens"`
+}
+
+// FormatDryRun renders the projected calls and cost of a live run against
+// providerName and budget. Format "json" yields a DryRunReport; anything
+// else yields a terminal summary.
+func FormatDryRun(est probes.RunEstimate, providerName string, budget int, format string) string {
+	if format == "json" {
+		r := DryRunReport{
+			Provider:   providerName,
+			Model:      est.Cost.Model,
+			Probes:     est.Probes,
+			TotalCalls: est.Calls,
+			Budget:     budget,
+			OverBudget: est.Calls > budget,
+			Agents:     make([]DryRunAgent, 0, len(est.Agents)),
+			Cost:       newCostEntry(est.Cost),
+		}
+		for _, a := range est.Agents {
+			r.Agents = append(r.Agents, DryRunAgent{
+				AgentID:     a.AgentID,
+				Probes:      a.Probes,
+				Calls:       a.Calls,
+				TotalTokens: a.Usage.TotalTokens,
+			})
+		}
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Sprintf(`{"error": "failed to marshal dry run: %s"}`, err)
+		}
+		return string(data) + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(sectionHeader("Dry Run"))
+	fmt.Fprintf(&b, "\n  %s%s / %s%s\n\n", stone, providerName, est.Cost.Model, reset)
+
+	for _, a := range est.Agents {
+		fmt.Fprintf(&b, "    %-28s %s%4d probes  %5d calls  ~%s tokens%s\n",
+			a.AgentID, stone, a.Probes, a.Calls, groupThousands(a.Usage.TotalTokens), reset)
+	}
+
+	budgetNote := fmt.Sprintf("%swithin --probe-budget %d%s", sage, budget, reset)
+	if est.Calls > budget {
+		budgetNote = fmt.Sprintf("%sexceeds --probe-budget %d%s", rose, budget, reset)
+	}
+	fmt.Fprintf(&b, "\n  %d probes, %d API calls (%s)\n", est.Probes, est.Calls, budgetNote)
+	fmt.Fprintf(&b, "  %s%s%s\n", stone, formatCost(est.Cost), reset)
+	fmt.Fprintf(&b, "\n  %sNo API calls were made.%s\n\n", stone, reset)
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func TestFormatDryRun(t *testing.T) {
+	usage := probes.TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500, Approximate: true}
+	est := probes.RunEstimate{
+		Agents: []probes.AgentCallEstimate{{AgentID: "backend", Probes: 2, Calls: 12, Usage: usage}},
+		Probes: 2,
+		Calls:  12,
+		Cost:   probes.EstimateCost("gpt-4o", usage),
+	}
+
+	var decoded DryRunReport
+	if err := json.Unmarshal([]byte(FormatDryRun(est, "openai", 10, "json")), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !decoded.OverBudget || decoded.TotalCalls != 12 || decoded.Budget != 10 {
+		t.Errorf("unexpected budget fields: %+v", decoded)
+	}
+	if len(decoded.Agents) != 1 || decoded.Agents[0].Calls != 12 {
+		t.Errorf("unexpected agents: %+v", decoded.Agents)
+	}
+	if decoded.Cost == nil || decoded.Cost.EstimatedUSD == nil || *decoded.Cost.EstimatedUSD != 0.0075 {
+		t.Errorf("unexpected cost: %+v", decoded.Cost)
+	}
+
+	text := ansiPattern.ReplaceAllString(FormatDryRun(est, "openai", 500, "terminal"), "")
+	for _, want := range []string{"backend", "12 API calls", "within --probe-budget 500", "est. cost $0.0075"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("terminal output missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// manifestIndex matches the "[index]" suffix of a fleet manifest entry's
+// source path.
+var manifestIndex = regexp.MustCompile(`\[\d+\]$`)
+
+// CodeQualityIssue is one entry of a GitLab Code Quality report.
+type CodeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"` // "info" | "minor" | "major"
+	Location    CodeQualityLocation `json:"location"`
+}
+
+// CodeQualityLocation points a Code Quality issue at a file.
+type CodeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines CodeQualityLines `json:"lines"`
+}
+
+// CodeQualityLines is the line range of a Code Quality issue. Agent issues
+// are not tied to a line, so they point at the first.
+type CodeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// FormatGitLabCodeQuality renders static and live issues as a GitLab Code
+// Quality report, a JSON array for the codequality artifact. Issues point at
+// the source of their first agent; issues not tied to an agent point at the
+// directory holding the agents. Fingerprints hash the agents, category and
+// message, so GitLab tracks an issue across pipelines while it is unchanged.
+func FormatGitLabCodeQuality(static *analysis.StaticReport, live *probes.LiveProbeReport) string {
+	sources := make(map[string]string, len(static.Agents))
+	var paths []string
+	for _, a := range static.Agents {
+		// Entries of a JSON fleet manifest ("fleet.json[2]") point at the file
+		file := manifestIndex.ReplaceAllString(a.SourcePath, "")
+		sources[a.ID] = file
+		paths = append(paths, file)
+	}
+	fleetPath := commonDir(paths)
+
+	entries := []CodeQualityIssue{}
+	for _, issue := range allIssues(static, live) {
+		path := fleetPath
+		if len(issue.Agents) > 0 && sources[issue.Agents[0]] != "" {
+			path = sources[issue.Agents[0]]
+		}
+		entries = append(entries, CodeQualityIssue{
+			Description: issue.Message,
+			CheckName:   "agent-evals/" + issue.Category,
+			Fingerprint: issueFingerprint(issue),
+			Severity:    codeQualitySeverity(issue.Severity),
+			Location:    CodeQualityLocation{Path: filepath.ToSlash(path), Lines: CodeQualityLines{Begin: 1}},
+		})
+	}
+
+	data, _ := json.MarshalIndent(entries, "", "  ")
+	return string(data) + "\n"
+}
+
+func codeQualitySeverity(severity string) string {
+	switch severity {
+	case "error":
+		return "major"
+	case "warning":
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+// issueFingerprint identifies an issue by its agents, category and message.
+func issueFingerprint(issue analysis.Issue) string {
+	h := sha256.New()
+	for _, part := range []string{strings.Join(issue.Agents, ","), issue.Category, issue.Message} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// commonDir returns the deepest directory containing every path, or "."
+// when there is none.
+func commonDir(paths []string) string {
+	var common []string
+	for i, p := range paths {
+		parts := strings.Split(filepath.ToSlash(filepath.Dir(p)), "/")
+		if i == 0 {
+			common = parts
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if len(common) == 0 || (len(common) == 1 && common[0] == "") {
+		return "."
+	}
+	return strings.Join(common, "/")
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func gitlabFixture() (*analysis.StaticReport, *probes.LiveProbeReport) {
+	static := &analysis.StaticReport{
+		Agents: []loader.AgentDefinition{
+			{ID: "a", SourcePath: "agents/backend/a.md"},
+			{ID: "b", SourcePath: "agents/frontend/b.md"},
+			{ID: "c", SourcePath: "agents/fleet.json[3]"},
+		},
+		Issues: []analysis.Issue{
+			{Severity: "error", Category: "conflict", Message: "Conflicting instructions between 'a' and 'b'", Agents: []string{"a", "b"}},
+			{Severity: "warning", Category: "gap", Message: "Domain 'security' has no agent with strong coverage"},
+			{Severity: "info", Category: "uncertainty", Message: "Agent 'b' has no uncertainty guidance", Agents: []string{"b"}},
+			{Severity: "warning", Category: "boundary", Message: "Agent 'c' has no boundary language", Agents: []string{"c"}},
+		},
+	}
+	live := &probes.LiveProbeReport{
+		Issues: []analysis.Issue{
+			{Severity: "warning", Category: "boundary", Message: "Agent 'a' answered out-of-scope probes confidently", Agents: []string{"a"}},
+		},
+	}
+	return static, live
+}
+
+func TestFormatGitLabCodeQuality(t *testing.T) {
+	static, live := gitlabFixture()
+
+	var issues []map[string]any
+	if err := json.Unmarshal([]byte(FormatGitLabCodeQuality(static, live)), &issues); err != nil {
+		t.Fatalf("output is not a JSON array: %v", err)
+	}
+	if len(issues) != 5 {
+		t.Fatalf("expected 5 issues, got %d", len(issues))
+	}
+
+	want := []struct {
+		checkName, severity, path string
+	}{
+		{"agent-evals/conflict", "major", "agents/backend/a.md"},
+		{"agent-evals/gap", "minor", "agents"},
+		{"agent-evals/uncertainty", "info", "agents/frontend/b.md"},
+		{"agent-evals/boundary", "minor", "agents/fleet.json"},
+		{"agent-evals/boundary", "minor", "agents/backend/a.md"},
+	}
+	seen := make(map[string]bool)
+	for i, w := range want {
+		issue := issues[i]
+		for _, key := range []string{"description", "check_name", "fingerprint", "severity", "location"} {
+			if _, ok := issue[key]; !ok {
+				t.Errorf("issue %d: missing %q", i, key)
+			}
+		}
+		if issue["check_name"] != w.checkName || issue["severity"] != w.severity {
+			t.Errorf("issue %d: check_name %v severity %v, want %s %s", i, issue["check_name"], issue["severity"], w.checkName, w.severity)
+		}
+		location, _ := issue["location"].(map[string]any)
+		lines, _ := location["lines"].(map[string]any)
+		if location["path"] != w.path || lines["begin"] != float64(1) {
+			t.Errorf("issue %d: location %v, want path %s at line 1", i, location, w.path)
+		}
+		fp, _ := issue["fingerprint"].(string)
+		if fp == "" || seen[fp] {
+			t.Errorf("issue %d: fingerprint %q should be non-empty and unique", i, fp)
+		}
+		seen[fp] = true
+	}
+}
+
+func TestFormatGitLabCodeQualityStableFingerprints(t *testing.T) {
+	static, live := gitlabFixture()
+	first := FormatGitLabCodeQuality(static, live)
+
+	// A later pipeline with the same findings, plus a new one
+	static, live = gitlabFixture()
+	static.Issues = append([]analysis.Issue{{Severity: "warning", Category: "overlap", Message: "High overlap between 'a' and 'b'", Agents: []string{"a", "b"}}}, static.Issues...)
+	second := FormatGitLabCodeQuality(static, live)
+
+	fingerprints := func(out string) map[string]string {
+		var issues []CodeQualityIssue
+		if err := json.Unmarshal([]byte(out), &issues); err != nil {
+			t.Fatal(err)
+		}
+		byMessage := make(map[string]string)
+		for _, i := range issues {
+			byMessage[i.Description] = i.Fingerprint
+		}
+		return byMessage
+	}
+	before, after := fingerprints(first), fingerprints(second)
+	for msg, fp := range before {
+		if after[msg] != fp {
+			t.Errorf("fingerprint for %q changed between runs: %s -> %s", msg, fp, after[msg])
+		}
+	}
+}
+
+func TestFormatGitLabCodeQualityNoIssues(t *testing.T) {
+	out := FormatGitLabCodeQuality(&analysis.StaticReport{}, nil)
+	if out != "[]\n" {
+		t.Errorf("expected an empty array, got %q", out)
+	}
+}
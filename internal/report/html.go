@@ -0,0 +1,187 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// htmlStyle is the report's embedded stylesheet; the page loads no external
+// assets. Bar colors follow the terminal palette.
+const htmlStyle = `body{font:15px/1.5 -apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;color:#2b2b2b;background:#fafafa;margin:0}
+main{max-width:960px;margin:0 auto;padding:32px 24px}
+h1{font-size:24px;margin:0 0 4px}
+h2{font-size:15px;text-transform:uppercase;letter-spacing:.06em;color:#555;border-bottom:1px solid #ddd;padding-bottom:6px;margin:32px 0 12px}
+table{border-collapse:collapse;width:100%}
+th,td{text-align:left;padding:6px 10px;border-bottom:1px solid #eee;vertical-align:top}
+th{font-weight:600;color:#555;font-size:13px}
+.muted{color:#8a8a8a}
+.domain{display:inline-block;background:#e8eef5;color:#3d5a78;border-radius:3px;padding:0 6px;margin:0 4px 2px 0;font-size:13px}
+.bar{display:inline-block;width:140px;height:10px;background:#e6e6e6;border-radius:5px;overflow:hidden;vertical-align:middle;margin-right:8px}
+.bar span{display:block;height:100%}
+.good{background:#87af87}.fair{background:#d7af5f}.poor{background:#d78787}
+.pct{font-variant-numeric:tabular-nums}
+.sev{display:inline-block;min-width:52px;font-size:12px;font-weight:600;text-transform:uppercase}
+.sev-error{color:#c0504d}.sev-warning{color:#b8860b}.sev-info{color:#5f87af}
+.overall{display:flex;align-items:center;gap:12px;font-size:18px;margin-top:8px}
+.overall .bar{width:240px;height:14px;border-radius:7px}
+.status{font-weight:700}.status-pass{color:#5f875f}.status-warn{color:#b8860b}.status-fail{color:#c0504d}
+.warn{color:#b8860b;font-size:13px}
+footer{margin-top:40px;font-size:12px;color:#8a8a8a}
+`
+
+// FormatHTML produces a self-contained HTML page with the agents, overlaps,
+// coverage gaps, live probe scores, issues and overall score, for sharing
+// with readers who do not use a terminal. Score bars use the terminal
+// report's 0.7/0.5 cutoffs.
+func FormatHTML(static *analysis.StaticReport, live *probes.LiveProbeReport) string {
+	var b strings.Builder
+	esc := html.EscapeString
+
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<title>agent-evals report</title>\n<style>\n" + htmlStyle + "</style>\n</head>\n<body>\n<main>\n")
+
+	// Header and overall score
+	overall := combinedOverall(static, live)
+	status := static.Bands.Status(overall)
+	b.WriteString("<h1>agent-evals report</h1>\n")
+	if static.DomainSummary != "" {
+		fmt.Fprintf(&b, "<div class=\"muted\">%s</div>\n", esc(static.DomainSummary))
+	}
+	if note := static.SubsetNote(); note != "" {
+		fmt.Fprintf(&b, "<div class=\"muted\">%s</div>\n", esc(note))
+	}
+	fmt.Fprintf(&b, "<div class=\"overall\"><strong>Overall</strong> %s <span class=\"status status-%s\">%s</span></div>\n",
+		htmlBar(overall), status, strings.ToUpper(status))
+
+	// Agents
+	fmt.Fprintf(&b, "<h2>Agents (%d)</h2>\n<table>\n", len(static.Agents))
+	b.WriteString("<tr><th>Agent</th><th>Domains</th><th>Scope clarity</th><th>Boundary def</th><th>Uncertainty</th></tr>\n")
+	for _, agent := range static.Agents {
+		scores := static.AgentScores[agent.ID]
+		domains := "<span class=\"muted\">none detected</span>"
+		if strong := strongDomainNames(static.DomainMap[agent.ID], static.Thresholds); len(strong) > 0 {
+			var tags []string
+			for _, d := range strong {
+				tags = append(tags, "<span class=\"domain\">"+esc(d)+"</span>")
+			}
+			domains = strings.Join(tags, "")
+		}
+		var warnings []string
+		if !scores.HasBoundaryLanguage {
+			warnings = append(warnings, "no boundary/scope language")
+		}
+		if !scores.HasUncertaintyGuidance {
+			warnings = append(warnings, "no uncertainty/hedging guidance")
+		}
+		name := esc(agent.ID)
+		for _, w := range warnings {
+			name += "<div class=\"warn\">⚠ " + w + "</div>"
+		}
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			name, domains,
+			htmlBar(scores.ScopeClarityScore), htmlBar(scores.BoundaryDefScore), htmlBar(scores.UncertaintyGuidScore))
+	}
+	b.WriteString("</table>\n")
+
+	// Scope overlap
+	var overlaps []analysis.OverlapResult
+	for _, o := range static.Overlaps {
+		if o.OverlapScore > 0.1 {
+			overlaps = append(overlaps, o)
+		}
+	}
+	if len(overlaps) > 0 {
+		sort.SliceStable(overlaps, func(i, j int) bool {
+			return overlaps[i].OverlapScore > overlaps[j].OverlapScore
+		})
+		b.WriteString("<h2>Scope Overlap</h2>\n<table>\n")
+		b.WriteString("<tr><th>Agents</th><th>Overlap</th><th>Shared domains</th></tr>\n")
+		for _, o := range overlaps {
+			pair := esc(o.AgentA) + " ↔ " + esc(o.AgentB)
+			for _, c := range o.ConflictingInstructions {
+				pair += "<div class=\"sev-error\">✘ " + esc(c) + "</div>"
+			}
+			if o.Accepted {
+				pair += "<div class=\"muted\">✓ " + esc(acceptedLabel(o)) + "</div>"
+			}
+			fmt.Fprintf(&b, "<tr><td>%s</td><td class=\"pct\">%.0f%%</td><td>%s</td></tr>\n",
+				pair, o.OverlapScore*100, esc(strings.Join(o.SharedDomains, ", ")))
+		}
+		b.WriteString("</table>\n")
+	}
+
+	// Coverage gaps
+	if len(static.Gaps) > 0 {
+		b.WriteString("<h2>Coverage Gaps</h2>\n<table>\n")
+		b.WriteString("<tr><th>Domain</th><th>Verdict</th><th>Closest agent</th></tr>\n")
+		for _, g := range static.Gaps {
+			class := "sev-warning"
+			if g.Verdict == "uncovered" {
+				class = "sev-error"
+			}
+			closest := g.ClosestAgent
+			if closest == "" {
+				closest = "none"
+			}
+			fmt.Fprintf(&b, "<tr><td>%s</td><td class=\"%s\">%s</td><td>%s <span class=\"muted\">(%.0f%%)</span></td></tr>\n",
+				esc(gapDomain(g)), class, esc(g.Verdict), esc(closest), g.ClosestScore*100)
+		}
+		b.WriteString("</table>\n")
+	}
+
+	// Live probe results
+	if live != nil {
+		ids := make([]string, 0, len(live.AgentResults))
+		for id := range live.AgentResults {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		b.WriteString("<h2>Live Probe Results</h2>\n<table>\n")
+		b.WriteString("<tr><th>Agent</th><th>Boundary</th><th>Calibration</th><th>Refusal</th><th>Consistency</th></tr>\n")
+		for _, id := range ids {
+			r := live.AgentResults[id]
+			if r.ProbesRun == 0 {
+				continue
+			}
+			name := fmt.Sprintf("%s <span class=\"muted\">(%d probes)</span>", esc(id), r.ProbesRun)
+			if r.InsufficientData {
+				fmt.Fprintf(&b, "<tr><td>%s</td><td colspan=\"4\" class=\"muted\">insufficient data — too few probes to score</td></tr>\n", name)
+				continue
+			}
+			fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n", name,
+				htmlBar(r.BoundaryScore), htmlBar(r.CalibrationScore), htmlBar(r.RefusalHealth), htmlBar(r.ConsistencyScore))
+		}
+		b.WriteString("</table>\n")
+		fmt.Fprintf(&b, "<p class=\"muted\">%d total API calls · %s</p>\n", live.TotalCalls, esc(formatCost(live.Cost)))
+	}
+
+	// Issues
+	if issues := allIssues(static, live); len(issues) > 0 {
+		b.WriteString("<h2>Issues</h2>\n<table>\n")
+		for _, issue := range issues {
+			fmt.Fprintf(&b, "<tr><td><span class=\"sev sev-%s\">%s</span></td><td>%s</td></tr>\n",
+				esc(issue.Severity), esc(issue.Severity), esc(issue.Message))
+		}
+		b.WriteString("</table>\n")
+	}
+
+	if footer := runFooter(static.Run); footer != "" {
+		fmt.Fprintf(&b, "<footer>%s</footer>\n", esc(footer))
+	}
+	b.WriteString("</main>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// htmlBar renders a score as a colored bar followed by its percentage.
+func htmlBar(score float64) string {
+	pct := min(max(score, 0), 1) * 100
+	return fmt.Sprintf("<span class=\"bar\"><span class=\"%s\" style=\"width:%.0f%%\"></span></span><span class=\"pct\">%.0f%%</span>",
+		scoreLevel(score), pct, score*100)
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func TestFormatHTML(t *testing.T) {
+	static := &analysis.StaticReport{
+		Agents: []loader.AgentDefinition{{ID: "api"}, {ID: "web<ui>"}},
+		DomainMap: map[string]map[string]float64{
+			"api": {"backend": 0.9},
+		},
+		AgentScores: map[string]analysis.AgentScore{
+			"api":     {ScopeClarityScore: 0.8, BoundaryDefScore: 0.6, UncertaintyGuidScore: 0.2, HasBoundaryLanguage: true, HasUncertaintyGuidance: true},
+			"web<ui>": {ScopeClarityScore: 0.3},
+		},
+		Overlaps: []analysis.OverlapResult{{AgentA: "api", AgentB: "web<ui>", OverlapScore: 0.4, SharedDomains: []string{"backend"}}},
+		Gaps:     []analysis.GapResult{{Domain: "security", Verdict: "uncovered"}},
+		Issues:   []analysis.Issue{{Severity: "warning", Message: "Agent 'web<ui>' has no boundary language"}},
+		Overall:  0.9,
+		Bands:    analysis.DefaultScoreBands,
+		Run:      analysis.RunInfo{ID: "run-1"},
+	}
+	live := &probes.LiveProbeReport{
+		AgentResults: map[string]*probes.AgentProbeResults{
+			"api": {ProbesRun: 4, BoundaryScore: 0.5, CalibrationScore: 0.75, RefusalHealth: 1, ConsistencyScore: 0.4},
+		},
+		TotalCalls: 24,
+	}
+
+	out := FormatHTML(static, live)
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") || !strings.HasSuffix(out, "</html>\n") {
+		t.Fatal("expected a complete HTML document")
+	}
+	for _, asset := range []string{"<link", "<script", "src=", "@import"} {
+		if strings.Contains(out, asset) {
+			t.Errorf("report should be self-contained, found %q", asset)
+		}
+	}
+	if strings.Contains(out, "web<ui>") || !strings.Contains(out, "web&lt;ui&gt;") {
+		t.Error("agent IDs and messages should be HTML-escaped")
+	}
+	for _, section := range []string{"Agents (2)", "Scope Overlap", "Coverage Gaps", "Live Probe Results", "Issues", "Run run-1"} {
+		if !strings.Contains(out, section) {
+			t.Errorf("missing %q", section)
+		}
+	}
+
+	// Overall averages static 0.9 with the live boundary mean 0.5
+	if !strings.Contains(out, `class="good" style="width:70%"`) || !strings.Contains(out, "status-pass") {
+		t.Error("expected a 70% overall bar graded good and a pass status")
+	}
+	// Live bars for api: boundary 50% fair, calibration 75% good, consistency 40% poor
+	for _, bar := range []string{`class="fair" style="width:50%"`, `class="good" style="width:75%"`, `class="poor" style="width:40%"`} {
+		if !strings.Contains(out, bar) {
+			t.Errorf("missing live score bar %s", bar)
+		}
+	}
+}
+
+func TestFormatHTMLStaticOnly(t *testing.T) {
+	out := FormatHTML(&analysis.StaticReport{Overall: 0.3, Bands: analysis.DefaultScoreBands}, nil)
+	if strings.Contains(out, "Live Probe Results") {
+		t.Error("static report should not have a live section")
+	}
+	if !strings.Contains(out, "status-fail") {
+		t.Error("expected a fail status for 30%")
+	}
+}
@@ -1,107 +1,354 @@
 package report
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"time"
 
 	"github.com/thinkwright/agent-evals/internal/analysis"
 	"github.com/thinkwright/agent-evals/internal/probes"
 )
 
-// FormatJSON produces machine-readable JSON for CI artifacts.
-func FormatJSON(static *analysis.StaticReport, live *probes.LiveProbeReport) string {
-	report := map[string]any{
-		"timestamp":     time.Now().Format(time.RFC3339),
-		"version":       "0.1.0",
-		"overall_score": static.Overall,
-		"pass":          static.Overall >= 0.7 && !static.HasFailures(),
+// Report is the typed structure of the JSON report. FormatJSON marshals it
+// and JSONSchema derives the published schema from it, so the two cannot
+// drift apart.
+type Report struct {
+	Timestamp    string          `json:"timestamp"`
+	RunID        string          `json:"run_id,omitempty"`
+	Version      string          `json:"version"`
+	OverallScore float64         `json:"overall_score"`
+	Pass         bool            `json:"pass"`
+	Subset       string          `json:"subset,omitempty"` // agents the report was filtered to, when not all
+	Agents       []AgentEntry    `json:"agents"`
+	Overlaps     []OverlapEntry  `json:"overlaps"`
+	Exposure     []ExposureEntry `json:"overlap_exposure"`
+	Gaps         []GapEntry      `json:"gaps"`
+	Issues       []IssueEntry    `json:"issues"`
+	LiveSummary  *LiveSummary    `json:"live_summary,omitempty"`
+	ScanMetadata *ScanMetadata   `json:"scan_metadata,omitempty"`
+	RunConfig    *RunConfig      `json:"run_config,omitempty"`
+	Cost         *CostEntry      `json:"cost,omitempty"`
+
+	Overconfident []OverconfidentEntry `json:"overconfident_responses,omitempty"`
+	Divergences   []DivergenceEntry    `json:"answer_divergences,omitempty"`
+}
+
+// AgentEntry is a single agent in the JSON report.
+type AgentEntry struct {
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	Source        string             `json:"source"`
+	Domains       map[string]float64 `json:"domains"`
+	StaticScores  StaticScores       `json:"static_scores"`
+	ContentHash   string             `json:"content_hash,omitempty"`
+	AlsoFoundIn   []string           `json:"also_found_in,omitempty"`
+	InstanceCount int                `json:"instance_count,omitempty"`
+	Weight        float64            `json:"weight,omitempty"` // relative weight in overall_score, when not 1
+	LiveScores    *LiveScores        `json:"live_scores,omitempty"`
+}
+
+// StaticScores mirrors analysis.AgentScore in the JSON report.
+type StaticScores struct {
+	ScopeClarityScore        float64  `json:"scope_clarity_score"`
+	BoundaryDefinitionScore  float64  `json:"boundary_definition_score"`
+	UncertaintyGuidanceScore float64  `json:"uncertainty_guidance_score"`
+	HasBoundaryLanguage      bool     `json:"has_boundary_language"`
+	HasUncertaintyGuidance   bool     `json:"has_uncertainty_guidance"`
+	StrongDomains            []string `json:"strong_domains"`
+	WeakDomains              []string `json:"weak_domains"`
+	MaxOverlapWithOther      float64  `json:"max_overlap_with_other"`
+	WordCount                int      `json:"word_count"`
+}
+
+// LiveScores mirrors probes.AgentProbeResults in the JSON report.
+type LiveScores struct {
+	BoundaryScore    float64  `json:"boundary_score"`
+	CalibrationScore float64  `json:"calibration_score"`
+	RefusalHealth    float64  `json:"refusal_health"`
+	ConsistencyScore float64  `json:"consistency_score"`
+	ProbesRun        int      `json:"probes_run"`
+	InsufficientData bool     `json:"insufficient_data,omitempty"`
+	OrderSensitivity *float64 `json:"order_sensitivity,omitempty"` // share of boundary verdicts that flipped when prompt sentences were reordered
+
+	HardCalibrationScore *float64 `json:"hard_calibration_score,omitempty"` // share of hard in-domain responses that were appropriately tentative
+	AccuracyScore        *float64 `json:"accuracy_score,omitempty"`         // share of graded calibration responses that were correct
+
+	LatencyP50Ms int64 `json:"latency_p50_ms,omitempty"` // median latency of successful calls; omitted when none was measured
+	LatencyP95Ms int64 `json:"latency_p95_ms,omitempty"`
+}
+
+// OverlapEntry is a significant pairwise overlap in the JSON report.
+type OverlapEntry struct {
+	Agents        []string `json:"agents"`
+	Score         float64  `json:"score"`
+	SharedDomains []string `json:"shared_domains"`
+	Conflicts     []string `json:"conflicts"`
+	Verdict       string   `json:"verdict"`
+
+	// Accepted marks a pair listed in accepted_overlaps.
+	Accepted       bool   `json:"accepted,omitempty"`
+	AcceptedReason string `json:"accepted_reason,omitempty"`
+}
+
+// ExposureEntry is an agent in the JSON report's ranking by overlap
+// exposure, most exposed first.
+type ExposureEntry struct {
+	Agent      string  `json:"agent"`
+	MaxOverlap float64 `json:"max_overlap"`
+	Overlaps   int     `json:"overlapping_agents"` // agents it overlaps above max_overlap_score
+}
+
+// GapEntry is a coverage gap in the JSON report.
+type GapEntry struct {
+	Domain       string   `json:"domain"`
+	Children     []string `json:"children,omitempty"` // child domains rolled up into domain
+	Verdict      string   `json:"verdict"`
+	ClosestAgent string   `json:"closest_agent"`
+	ClosestScore float64  `json:"closest_score"`
+}
+
+// IssueEntry is a static analysis finding in the JSON report.
+type IssueEntry struct {
+	Severity string   `json:"severity"`
+	Category string   `json:"category"`
+	Message  string   `json:"message"`
+	Agents   []string `json:"agents"`
+	Score    float64  `json:"score"`
+}
+
+// LiveSummary summarizes a live probe run.
+type LiveSummary struct {
+	TotalAPICalls int `json:"total_api_calls"`
+	AgentsProbed  int `json:"agents_probed"`
+}
+
+// CostEntry reports the token usage and estimated spend of a live run.
+type CostEntry struct {
+	Model            string   `json:"model"`
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	TotalTokens      int      `json:"total_tokens"`
+	EstimatedUSD     *float64 `json:"estimated_usd,omitempty"` // omitted when the model has no known price
+	Approximate      bool     `json:"approximate"`             // some token counts were estimated from word counts
+}
+
+func newCostEntry(cost probes.CostEstimate) *CostEntry {
+	entry := &CostEntry{
+		Model:            cost.Model,
+		PromptTokens:     cost.Usage.PromptTokens,
+		CompletionTokens: cost.Usage.CompletionTokens,
+		TotalTokens:      cost.Usage.TotalTokens,
+		Approximate:      cost.Usage.Approximate,
+	}
+	if cost.USD != nil {
+		usd := float64(int(*cost.USD*10000+0.5)) / 10000
+		entry.EstimatedUSD = &usd
+	}
+	return entry
+}
+
+// OverconfidentEntry is a confident answer to an out-of-scope probe in the
+// JSON report.
+type OverconfidentEntry struct {
+	AgentID    string  `json:"agent_id"`
+	ProbeID    string  `json:"probe_id"`
+	Question   string  `json:"question"`
+	Domain     string  `json:"domain"`
+	ProbeType  string  `json:"probe_type"`
+	Run        int     `json:"run"`
+	Confidence float64 `json:"confidence"`
+	Snippet    string  `json:"response_snippet"`
+}
+
+// DivergenceEntry is an overlap probe that two overlapping agents answered
+// in contradictory ways, in the JSON report.
+type DivergenceEntry struct {
+	ProbeID        string   `json:"probe_id"`
+	Question       string   `json:"question"`
+	Domain         string   `json:"domain"`
+	Agents         []string `json:"agents"`
+	Similarity     float64  `json:"answer_similarity"`
+	Contradictions []string `json:"contradictions"`
+}
+
+// ScanMetadata describes recursive dedup results.
+type ScanMetadata struct {
+	TotalFilesScanned   int    `json:"total_files_scanned"`
+	UniqueAgents        int    `json:"unique_agents"`
+	DuplicatesCollapsed int    `json:"duplicates_collapsed"`
+	DedupMethod         string `json:"dedup_method"`
+}
+
+// RunConfig records the settings a report was produced with, so a report can
+// be traced back to its configuration. It never holds secrets: the API key is
+// identified only by the name of its environment variable.
+type RunConfig struct {
+	ConfigPath       string          `json:"config_path"` // "" when no config file was found
+	Recursive        bool            `json:"recursive"`
+	Dedup            bool            `json:"dedup"`
+	FailOnDuplicates bool            `json:"fail_on_duplicates,omitempty"` // scan.fail_on_duplicates; with the thresholds it decides pass (see Gate)
+	Thresholds       RunThresholds   `json:"thresholds"`
+	Probes           *ProbeRunConfig `json:"probes,omitempty"` // set for live runs
+}
+
+// RunThresholds holds the resolved score thresholds.
+type RunThresholds struct {
+	MinOverallScore  float64 `json:"min_overall_score"`
+	WarnOverallScore float64 `json:"warn_overall_score"`
+	MinBoundaryScore float64 `json:"min_boundary_score"`
+	MaxOverlapScore  float64 `json:"max_overlap_score"`
+	WarningsAsErrors bool    `json:"warnings_as_errors"`
+	FailOn           string  `json:"fail_on"`
+
+	StrongDomainScore  float64 `json:"strong_domain_score"`
+	OverlapDomainScore float64 `json:"overlap_domain_score"`
+}
+
+// ProbeRunConfig holds the resolved provider and probe settings of a live run.
+type ProbeRunConfig struct {
+	Provider       string `json:"provider"`
+	Model          string `json:"model"`
+	BaseURL        string `json:"base_url,omitempty"`
+	APIKeyEnv      string `json:"api_key_env,omitempty"`
+	CACertFile     string `json:"ca_cert,omitempty"`
+	Region         string `json:"region,omitempty"`
+	ProbeBudget    int    `json:"probe_budget"`
+	StochasticRuns int    `json:"stochastic_runs"`
+	Concurrency    int    `json:"concurrency"`
+	Seed           int64  `json:"seed,omitempty"`
+	Profile        string `json:"profile,omitempty"` // --profile, when given
+}
+
+// BuildReport assembles the typed JSON report from analysis results.
+func BuildReport(static *analysis.StaticReport, live *probes.LiveProbeReport) *Report {
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	if !static.Run.Timestamp.IsZero() {
+		timestamp = static.Run.TimestampString()
+	}
+	report := &Report{
+		Timestamp:    timestamp,
+		RunID:        static.Run.ID,
+		Version:      "0.1.0",
+		OverallScore: static.Overall,
+		Pass:         Judge(static, live, DefaultGate) == nil,
+		Subset:       static.Subset,
+		Agents:       []AgentEntry{},
+		Overlaps:     []OverlapEntry{},
+		Exposure:     []ExposureEntry{},
+		Gaps:         []GapEntry{},
+		Issues:       []IssueEntry{},
 	}
 
 	// Agents
-	var agents []map[string]any
 	for _, agent := range static.Agents {
-		entry := map[string]any{
-			"id":      agent.ID,
-			"name":    agent.Name,
-			"source":  agent.SourcePath,
-			"domains": static.DomainMap[agent.ID],
-			"static_scores": map[string]any{
-				"scope_clarity_score":        static.AgentScores[agent.ID].ScopeClarityScore,
-				"boundary_definition_score":  static.AgentScores[agent.ID].BoundaryDefScore,
-				"uncertainty_guidance_score": static.AgentScores[agent.ID].UncertaintyGuidScore,
-				"has_boundary_language":      static.AgentScores[agent.ID].HasBoundaryLanguage,
-				"has_uncertainty_guidance":   static.AgentScores[agent.ID].HasUncertaintyGuidance,
-				"strong_domains":             static.AgentScores[agent.ID].StrongDomains,
-				"weak_domains":               static.AgentScores[agent.ID].WeakDomains,
-				"max_overlap_with_other":     static.AgentScores[agent.ID].MaxOverlapWithOther,
-				"word_count":                 static.AgentScores[agent.ID].WordCount,
+		scores := static.AgentScores[agent.ID]
+		domains := static.DomainMap[agent.ID]
+		if domains == nil {
+			domains = map[string]float64{}
+		}
+		entry := AgentEntry{
+			ID:      agent.ID,
+			Name:    agent.Name,
+			Source:  agent.SourcePath,
+			Domains: domains,
+			StaticScores: StaticScores{
+				ScopeClarityScore:        scores.ScopeClarityScore,
+				BoundaryDefinitionScore:  scores.BoundaryDefScore,
+				UncertaintyGuidanceScore: scores.UncertaintyGuidScore,
+				HasBoundaryLanguage:      scores.HasBoundaryLanguage,
+				HasUncertaintyGuidance:   scores.HasUncertaintyGuidance,
+				StrongDomains:            nonNil(scores.StrongDomains),
+				WeakDomains:              nonNil(scores.WeakDomains),
+				MaxOverlapWithOther:      scores.MaxOverlapWithOther,
+				WordCount:                scores.WordCount,
 			},
+			ContentHash: agent.ContentHash,
 		}
 
-		if agent.ContentHash != "" {
-			entry["content_hash"] = agent.ContentHash
+		if w, ok := static.AgentWeights[agent.ID]; ok && w != 1 {
+			entry.Weight = w
 		}
+
 		if len(agent.AlsoFoundIn) > 0 {
-			entry["also_found_in"] = agent.AlsoFoundIn
-			entry["instance_count"] = 1 + len(agent.AlsoFoundIn)
+			entry.AlsoFoundIn = agent.AlsoFoundIn
+			entry.InstanceCount = 1 + len(agent.AlsoFoundIn)
 		}
 
 		if live != nil {
 			if lr, ok := live.AgentResults[agent.ID]; ok {
-				entry["live_scores"] = map[string]any{
-					"boundary_score":    lr.BoundaryScore,
-					"calibration_score": lr.CalibrationScore,
-					"refusal_health":    lr.RefusalHealth,
-					"consistency_score": lr.ConsistencyScore,
-					"probes_run":        lr.ProbesRun,
+				entry.LiveScores = &LiveScores{
+					BoundaryScore:    lr.BoundaryScore,
+					CalibrationScore: lr.CalibrationScore,
+					RefusalHealth:    lr.RefusalHealth,
+					ConsistencyScore: lr.ConsistencyScore,
+					ProbesRun:        lr.ProbesRun,
+					InsufficientData: lr.InsufficientData,
+
+					HardCalibrationScore: lr.HardCalibrationScore,
+					AccuracyScore:        lr.AccuracyScore,
+
+					LatencyP50Ms: lr.LatencyP50Ms,
+					LatencyP95Ms: lr.LatencyP95Ms,
+				}
+				if lr.Robustness != nil {
+					sensitivity := round3(lr.Robustness.Sensitivity())
+					entry.LiveScores.OrderSensitivity = &sensitivity
 				}
 			}
 		}
 
-		agents = append(agents, entry)
+		report.Agents = append(report.Agents, entry)
 	}
-	report["agents"] = agents
 
 	// Overlaps
-	var overlaps []map[string]any
 	for _, o := range static.Overlaps {
 		if o.OverlapScore > 0.1 {
-			overlaps = append(overlaps, map[string]any{
-				"agents":         []string{o.AgentA, o.AgentB},
-				"score":          round3(o.OverlapScore),
-				"shared_domains": o.SharedDomains,
-				"conflicts":      o.ConflictingInstructions,
-				"verdict":        o.Verdict,
+			report.Overlaps = append(report.Overlaps, OverlapEntry{
+				Agents:        []string{o.AgentA, o.AgentB},
+				Score:         round3(o.OverlapScore),
+				SharedDomains: nonNil(o.SharedDomains),
+				Conflicts:     nonNil(o.ConflictingInstructions),
+				Verdict:       o.Verdict,
+
+				Accepted:       o.Accepted,
+				AcceptedReason: o.AcceptedReason,
 			})
 		}
 	}
-	report["overlaps"] = overlaps
+
+	// Overlap exposure
+	for _, e := range static.Exposure {
+		report.Exposure = append(report.Exposure, ExposureEntry{
+			Agent:      e.AgentID,
+			MaxOverlap: round3(e.MaxOverlap),
+			Overlaps:   e.Overlaps,
+		})
+	}
 
 	// Gaps
-	var gaps []map[string]any
 	for _, g := range static.Gaps {
-		gaps = append(gaps, map[string]any{
-			"domain":        g.Domain,
-			"verdict":       g.Verdict,
-			"closest_agent": g.ClosestAgent,
-			"closest_score": round3(g.ClosestScore),
+		report.Gaps = append(report.Gaps, GapEntry{
+			Domain:       g.Domain,
+			Children:     g.Children,
+			Verdict:      g.Verdict,
+			ClosestAgent: g.ClosestAgent,
+			ClosestScore: round3(g.ClosestScore),
 		})
 	}
-	report["gaps"] = gaps
 
 	// Issues
-	var issues []map[string]any
-	for _, i := range static.Issues {
-		issues = append(issues, map[string]any{
-			"severity": i.Severity,
-			"category": i.Category,
-			"message":  i.Message,
-			"agents":   i.Agents,
-			"score":    i.Score,
+	for _, i := range allIssues(static, live) {
+		report.Issues = append(report.Issues, IssueEntry{
+			Severity: i.Severity,
+			Category: i.Category,
+			Message:  i.Message,
+			Agents:   nonNil(i.Agents),
+			Score:    i.Score,
 		})
 	}
-	report["issues"] = issues
 
 	// Live summary
 	if live != nil {
@@ -111,9 +358,23 @@ func FormatJSON(static *analysis.StaticReport, live *probes.LiveProbeReport) str
 				probed++
 			}
 		}
-		report["live_summary"] = map[string]any{
-			"total_api_calls": live.TotalCalls,
-			"agents_probed":   probed,
+		report.LiveSummary = &LiveSummary{
+			TotalAPICalls: live.TotalCalls,
+			AgentsProbed:  probed,
+		}
+		report.Cost = newCostEntry(live.Cost)
+		for _, o := range live.Overconfident {
+			report.Overconfident = append(report.Overconfident, OverconfidentEntry(o))
+		}
+		for _, d := range live.Divergences {
+			report.Divergences = append(report.Divergences, DivergenceEntry{
+				ProbeID:        d.ProbeID,
+				Question:       d.Question,
+				Domain:         d.Domain,
+				Agents:         []string{d.AgentA, d.AgentB},
+				Similarity:     round3(d.Similarity),
+				Contradictions: nonNil(d.Contradictions),
+			})
 		}
 	}
 
@@ -125,21 +386,83 @@ func FormatJSON(static *analysis.StaticReport, live *probes.LiveProbeReport) str
 		duplicatesCollapsed += len(agent.AlsoFoundIn)
 	}
 	if duplicatesCollapsed > 0 {
-		report["scan_metadata"] = map[string]any{
-			"total_files_scanned":  totalFiles,
-			"unique_agents":        len(static.Agents),
-			"duplicates_collapsed": duplicatesCollapsed,
-			"dedup_method":         "sha256-system-prompt",
+		report.ScanMetadata = &ScanMetadata{
+			TotalFilesScanned:   totalFiles,
+			UniqueAgents:        len(static.Agents),
+			DuplicatesCollapsed: duplicatesCollapsed,
+			DedupMethod:         "sha256-system-prompt",
 		}
 	}
 
-	data, err := json.MarshalIndent(report, "", "  ")
+	return report
+}
+
+// FormatJSON produces machine-readable JSON for CI artifacts.
+func FormatJSON(static *analysis.StaticReport, live *probes.LiveProbeReport) string {
+	return FormatJSONWithRunConfig(static, live, nil)
+}
+
+// FormatJSONWithRunConfig is FormatJSON with the run's configuration recorded
+// under run_config. Credentials embedded in the base URL are redacted.
+func FormatJSONWithRunConfig(static *analysis.StaticReport, live *probes.LiveProbeReport, rc *RunConfig) string {
+	r := BuildReport(static, live)
+	if rc != nil {
+		cp := *rc
+		if cp.Probes != nil {
+			pc := *cp.Probes
+			pc.BaseURL = redactURL(pc.BaseURL)
+			cp.Probes = &pc
+		}
+		r.RunConfig = &cp
+		r.Pass = Judge(static, live, rc.Gate()) == nil
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
 	if err != nil {
 		return fmt.Sprintf(`{"error": "failed to marshal report: %s"}`, err)
 	}
 	return string(data)
 }
 
+// ParseReport reads a report written by FormatJSON back into a Report, so
+// that reports of different runs can be compared or collected over time.
+// Marshaling the result reproduces the original JSON.
+func ParseReport(r io.Reader) (*Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("parse JSON report: %w", err)
+	}
+	if report.Version == "" || report.Agents == nil {
+		return nil, fmt.Errorf("parse JSON report: not an agent-evals JSON report")
+	}
+	return &report, nil
+}
+
+// ParseJSON is ParseReport for a report already read into memory.
+func ParseJSON(data []byte) (*Report, error) {
+	return ParseReport(bytes.NewReader(data))
+}
+
+// redactURL strips user info and the query string, where gateways sometimes
+// carry tokens, from a URL.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
 func round3(f float64) float64 {
 	return float64(int(f*1000+0.5)) / 1000
 }
+
+// nonNil returns an empty slice in place of nil so JSON arrays are never null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
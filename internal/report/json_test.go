@@ -0,0 +1,299 @@
+package report
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func TestFormatJSONPassRespectsThreshold(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.8, Bands: analysis.DefaultScoreBands}
+
+	var decoded Report
+	if err := json.Unmarshal([]byte(FormatJSON(static, nil)), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !decoded.Pass {
+		t.Error("expected pass at 80% with default 70% threshold")
+	}
+
+	static.Bands = analysis.ResolveScoreBands(map[string]any{
+		"thresholds": map[string]any{"min_overall_score": 0.85},
+	})
+	if err := json.Unmarshal([]byte(FormatJSON(static, nil)), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Pass {
+		t.Error("expected fail at 80% with configured 85% threshold")
+	}
+}
+
+func TestFormatJSONPassRespectsBoundaryThreshold(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.8, Bands: analysis.DefaultScoreBands}
+	live := &probes.LiveProbeReport{AgentResults: map[string]*probes.AgentProbeResults{
+		"api": {AgentID: "api", ProbesRun: 10, BoundaryScore: 0.6},
+	}}
+	pass := func(rc *RunConfig) bool {
+		var decoded Report
+		if err := json.Unmarshal([]byte(FormatJSONWithRunConfig(static, live, rc)), &decoded); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		return decoded.Pass
+	}
+
+	if !pass(nil) {
+		t.Error("expected pass at boundary 60% with the default 50% threshold")
+	}
+	if pass(&RunConfig{Thresholds: RunThresholds{MinBoundaryScore: 0.7}}) {
+		t.Error("expected fail at boundary 60% with min_boundary_score 0.7")
+	}
+	if f := Judge(static, live, Gate{MinBoundaryScore: 0.7}); f == nil || f.Category != FailLive {
+		t.Errorf("Judge = %+v, want a live failure", f)
+	}
+}
+
+func TestFormatJSONSubset(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.8}
+	if out := FormatJSON(static, nil); strings.Contains(out, `"subset"`) {
+		t.Errorf("expected no subset for an unfiltered run:\n%s", out)
+	}
+
+	static.Subset = "1 of 5 agents (--agent backend_api)"
+	var decoded Report
+	if err := json.Unmarshal([]byte(FormatJSON(static, nil)), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Subset != static.Subset {
+		t.Errorf("subset = %q, want %q", decoded.Subset, static.Subset)
+	}
+	if out := FormatTerminal(static, nil); !strings.Contains(out, "Filtered to 1 of 5 agents") {
+		t.Errorf("expected the terminal report to note the subset:\n%s", out)
+	}
+}
+
+func TestFormatJSONRunConfig(t *testing.T) {
+	const secret = "sk-test-do-not-leak"
+	t.Setenv("AGENT_EVALS_TEST_KEY", secret)
+
+	static := &analysis.StaticReport{Overall: 0.8, Bands: analysis.DefaultScoreBands}
+	rc := &RunConfig{
+		ConfigPath: "agents/agent-evals.yaml",
+		Recursive:  true,
+		Dedup:      true,
+		Thresholds: RunThresholds{MinOverallScore: 0.7, WarnOverallScore: 0.5, MinBoundaryScore: 0.6, MaxOverlapScore: 0.3},
+		Probes: &ProbeRunConfig{
+			Provider:       "openai-compatible",
+			Model:          "llama3",
+			BaseURL:        "https://user:" + secret + "@gateway.example.com/v1?token=" + secret,
+			APIKeyEnv:      "AGENT_EVALS_TEST_KEY",
+			ProbeBudget:    120,
+			StochasticRuns: 3,
+			Concurrency:    4,
+		},
+	}
+
+	out := FormatJSONWithRunConfig(static, nil, rc)
+	if strings.Contains(out, secret) {
+		t.Fatalf("run_config leaked key material:\n%s", out)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	got := decoded.RunConfig
+	if got == nil || got.Probes == nil {
+		t.Fatalf("run_config missing: %+v", got)
+	}
+	if got.ConfigPath != rc.ConfigPath || !got.Recursive || !got.Dedup {
+		t.Errorf("scan settings = %+v", got)
+	}
+	if got.Thresholds != rc.Thresholds {
+		t.Errorf("thresholds = %+v, want %+v", got.Thresholds, rc.Thresholds)
+	}
+	p := got.Probes
+	if p.Provider != "openai-compatible" || p.Model != "llama3" || p.APIKeyEnv != "AGENT_EVALS_TEST_KEY" {
+		t.Errorf("provider settings = %+v", p)
+	}
+	if p.ProbeBudget != 120 || p.StochasticRuns != 3 || p.Concurrency != 4 {
+		t.Errorf("probe settings = %+v", p)
+	}
+	if p.BaseURL != "https://gateway.example.com/v1" {
+		t.Errorf("base_url = %q, want credentials stripped", p.BaseURL)
+	}
+	if rc.Probes.BaseURL == p.BaseURL {
+		t.Error("redaction should not modify the caller's RunConfig")
+	}
+
+	if strings.Contains(FormatJSON(static, nil), "run_config") {
+		t.Error("run_config should be omitted when not provided")
+	}
+}
+
+func TestFormatJSONCost(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.8}
+	live := &probes.LiveProbeReport{
+		AgentResults: map[string]*probes.AgentProbeResults{},
+		Cost: probes.EstimateCost("gpt-4o", probes.TokenUsage{
+			PromptTokens: 12000, CompletionTokens: 3000, TotalTokens: 15000, Approximate: true,
+		}),
+	}
+
+	var decoded Report
+	if err := json.Unmarshal([]byte(FormatJSON(static, live)), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	c := decoded.Cost
+	if c == nil {
+		t.Fatal("expected cost block for a live run")
+	}
+	if c.Model != "gpt-4o" || c.TotalTokens != 15000 || !c.Approximate {
+		t.Errorf("unexpected cost block: %+v", c)
+	}
+	if c.EstimatedUSD == nil || *c.EstimatedUSD != 0.06 {
+		t.Errorf("estimated_usd = %v, want 0.06", c.EstimatedUSD)
+	}
+
+	if strings.Contains(FormatJSON(static, nil), `"cost"`) {
+		t.Error("cost block should be omitted for static-only reports")
+	}
+}
+
+func TestFormatJSONLatency(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.8, Agents: []loader.AgentDefinition{{ID: "backend"}, {ID: "frontend"}}}
+	live := &probes.LiveProbeReport{AgentResults: map[string]*probes.AgentProbeResults{
+		"backend":  {AgentID: "backend", ProbesRun: 3, LatencyP50Ms: 420, LatencyP95Ms: 1800},
+		"frontend": {AgentID: "frontend", ProbesRun: 3},
+	}}
+
+	out := FormatJSON(static, live)
+	var decoded Report
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if s := decoded.Agents[0].LiveScores; s == nil || s.LatencyP50Ms != 420 || s.LatencyP95Ms != 1800 {
+		t.Errorf("backend live scores %+v, want p50 420ms and p95 1800ms", s)
+	}
+	if strings.Count(out, `"latency_p50_ms"`) != 1 {
+		t.Errorf("latency should be omitted for agents without measured calls:\n%s", out)
+	}
+	if term := FormatTerminal(static, live); !strings.Contains(term, "p50 420ms · p95 1800ms") {
+		t.Errorf("expected the terminal report to show latency:\n%s", term)
+	}
+}
+
+func TestFormatJSONOverconfidentResponses(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.8}
+	live := &probes.LiveProbeReport{
+		AgentResults: map[string]*probes.AgentProbeResults{},
+		Overconfident: []probes.OverconfidentResponse{{
+			AgentID: "backend", ProbeID: "b1", Question: "How do I treat a sprain?",
+			Domain: "medical", ProbeType: "boundary", Confidence: 100, Snippet: "Rest and ice it.",
+		}},
+	}
+
+	var decoded Report
+	if err := json.Unmarshal([]byte(FormatJSON(static, live)), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Overconfident) != 1 {
+		t.Fatalf("expected 1 overconfident response, got %d", len(decoded.Overconfident))
+	}
+	o := decoded.Overconfident[0]
+	if o.AgentID != "backend" || o.Confidence != 100 || o.Snippet != "Rest and ice it." {
+		t.Errorf("unexpected entry: %+v", o)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	static := &analysis.StaticReport{
+		Overall: 0.75,
+		Agents:  []loader.AgentDefinition{{ID: "api", SourcePath: "api.yaml"}},
+		Issues:  []analysis.Issue{{Severity: "warning", Category: "gap", Message: "Domain 'mobile' has no agent with strong coverage"}},
+	}
+	parsed, err := ParseJSON([]byte(FormatJSON(static, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.OverallScore != 0.75 || len(parsed.Agents) != 1 || parsed.Agents[0].ID != "api" {
+		t.Errorf("parsed report lost fields: %+v", parsed)
+	}
+	if len(parsed.Issues) != 1 || parsed.Issues[0].Category != "gap" {
+		t.Errorf("parsed issues = %+v", parsed.Issues)
+	}
+
+	for _, bad := range []string{`not json`, `{"foo": 1}`} {
+		if _, err := ParseJSON([]byte(bad)); err == nil {
+			t.Errorf("expected error parsing %q", bad)
+		}
+	}
+}
+
+func TestParseReportRoundTrip(t *testing.T) {
+	static := &analysis.StaticReport{
+		Overall: 0.65,
+		Bands:   analysis.DefaultScoreBands,
+		Run:     analysis.RunInfo{ID: "run-1", Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		Agents: []loader.AgentDefinition{
+			{ID: "api", Name: "API", SourcePath: "agents/api.yaml", ContentHash: "abc", AlsoFoundIn: []string{"legacy/api.yaml"}},
+			{ID: "web", Name: "Web", SourcePath: "agents/web.md"},
+		},
+		DomainMap: map[string]map[string]float64{
+			"api": {"backend": 1, "databases": 0.4},
+			"web": {"frontend": 0.8},
+		},
+		AgentScores: map[string]analysis.AgentScore{
+			"api": {StrongDomains: []string{"backend"}, WeakDomains: []string{"databases"}, HasBoundaryLanguage: true, BoundaryDefScore: 0.7, ScopeClarityScore: 0.333, WordCount: 120},
+			"web": {StrongDomains: []string{"frontend"}, BoundaryDefScore: 0.3, UncertaintyGuidScore: 0.3, WordCount: 80},
+		},
+		Overlaps: []analysis.OverlapResult{{AgentA: "api", AgentB: "web", OverlapScore: 0.4, SharedDomains: []string{"backend"}, Verdict: "warning", Accepted: true, AcceptedReason: "shared gateway"}},
+		Exposure: []analysis.OverlapExposure{{AgentID: "api", MaxOverlap: 0.4, Overlaps: 1}, {AgentID: "web", MaxOverlap: 0.4, Overlaps: 1}},
+		Gaps: []analysis.GapResult{
+			{Domain: "apps", Children: []string{"desktop", "mobile"}, Verdict: "weakly_covered", ClosestAgent: "web", ClosestScore: 0.3},
+			{Domain: "security", Verdict: "uncovered", ClosestAgent: "api", ClosestScore: 0.2},
+		},
+		Issues: []analysis.Issue{{Severity: "warning", Category: "gap", Message: "Domain 'security' has no agent with strong coverage", Score: 0.2}},
+	}
+	live := &probes.LiveProbeReport{
+		AgentResults: map[string]*probes.AgentProbeResults{
+			"api": {AgentID: "api", BoundaryScore: 0.9, CalibrationScore: 0.8, RefusalHealth: 0.7, ConsistencyScore: 0.95, ProbesRun: 6,
+				Robustness: &probes.RobustnessResult{ProbesCompared: 4, Divergent: []string{"p1"}}},
+			"web": {AgentID: "web", ProbesRun: 1, InsufficientData: true},
+		},
+		TotalCalls: 42,
+		Cost:       probes.EstimateCost("gpt-4o", probes.TokenUsage{PromptTokens: 1000, CompletionTokens: 200, TotalTokens: 1200}),
+		Overconfident: []probes.OverconfidentResponse{{
+			AgentID: "api", ProbeID: "p2", Question: "How do I treat a sprain?", Domain: "medical", ProbeType: "boundary", Run: 1, Confidence: 95, Snippet: "Rest and ice it.",
+		}},
+		Divergences: []probes.AnswerDivergence{{ProbeID: "o1", Question: "Which database?", Domain: "backend", AgentA: "api", AgentB: "web", Similarity: 0.1, Contradictions: []string{"yes vs no"}}},
+	}
+	rc := &RunConfig{ConfigPath: "agent-evals.yaml", Recursive: true, Dedup: true,
+		Thresholds: RunThresholds{MinOverallScore: 0.7, WarnOverallScore: 0.5, MinBoundaryScore: 0.5, MaxOverlapScore: 0.3},
+		Probes:     &ProbeRunConfig{Provider: "openai", Model: "gpt-4o", ProbeBudget: 100, StochasticRuns: 3, Concurrency: 2}}
+
+	original := FormatJSONWithRunConfig(static, live, rc)
+	parsed, err := ParseReport(strings.NewReader(original))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := BuildReport(static, live)
+	want.RunConfig = rc
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("parsed report differs from the one marshaled:\n got %+v\nwant %+v", parsed, want)
+	}
+
+	remarshaled, err := json.MarshalIndent(parsed, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal parsed report: %v", err)
+	}
+	if string(remarshaled) != original {
+		t.Errorf("re-marshaled report differs from the original:\n%s\n---\n%s", remarshaled, original)
+	}
+}
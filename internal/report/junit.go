@@ -0,0 +1,195 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+
+	runID string // written as a run_id property of every suite
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitTime is the duration written for every suite and case. Checks are not
+// timed individually, and a constant keeps reports comparable across runs.
+const junitTime = "0"
+
+// FormatJUnit renders the report as JUnit XML using the default thresholds.
+func FormatJUnit(static *analysis.StaticReport, live *probes.LiveProbeReport) string {
+	return FormatJUnitWithRunConfig(static, live, nil)
+}
+
+// FormatJUnitWithRunConfig renders the report as JUnit XML for CI systems.
+// Each agent is a test suite whose test cases are its checks: boundary
+// language, uncertainty guidance and, for live runs, boundary score against
+// thresholds.min_boundary_score, calibration and out-of-scope exclusions.
+// Overlapping pairs and coverage gaps get suites of their own. A case fails
+// when its check is violated, and live checks without enough probes are
+// skipped. Each suite carries the run ID as a run_id property. rc supplies
+// the resolved thresholds; nil uses the defaults.
+func FormatJUnitWithRunConfig(static *analysis.StaticReport, live *probes.LiveProbeReport, rc *RunConfig) string {
+	minBoundary := 0.5
+	maxOverlap := analysis.DefaultMaxOverlapScore
+	if rc != nil {
+		minBoundary = rc.Thresholds.MinBoundaryScore
+		maxOverlap = rc.Thresholds.MaxOverlapScore
+	}
+
+	doc := junitTestSuites{Name: "agent-evals", Time: junitTime, runID: static.Run.ID}
+
+	for _, agent := range static.Agents {
+		scores := static.AgentScores[agent.ID]
+		class := "agent-evals.agents." + agent.ID
+		suite := junitTestSuite{Name: "agent " + agent.ID}
+
+		tc := junitTestCase{Name: "boundary language", ClassName: class}
+		if !scores.HasBoundaryLanguage {
+			tc.Failure = junitFail("info", "Agent '%s' has no boundary/scope language in its definition", agent.ID)
+		}
+		suite.add(tc)
+
+		tc = junitTestCase{Name: "uncertainty guidance", ClassName: class}
+		if !scores.HasUncertaintyGuidance {
+			tc.Failure = junitFail("info", "Agent '%s' has no uncertainty guidance", agent.ID)
+		}
+		suite.add(tc)
+
+		if live != nil {
+			if results, ok := live.AgentResults[agent.ID]; ok {
+				addLiveCases(&suite, results, class, minBoundary)
+			}
+		}
+		doc.add(suite)
+	}
+
+	if len(static.Overlaps) > 0 {
+		suite := junitTestSuite{Name: "overlaps"}
+		for _, o := range static.Overlaps {
+			tc := junitTestCase{Name: o.AgentA + " / " + o.AgentB, ClassName: "agent-evals.overlaps"}
+			switch {
+			case o.Verdict == "conflict":
+				tc.Failure = junitFail("error", "Conflicting instructions between '%s' and '%s'", o.AgentA, o.AgentB)
+				tc.Failure.Text = strings.Join(o.ConflictingInstructions, "\n")
+			case o.OverlapScore > maxOverlap && o.Accepted:
+				tc.Skipped = &junitSkipped{Message: acceptedLabel(o)}
+			case o.OverlapScore > maxOverlap:
+				tc.Failure = junitFail("warning", "Scope overlap %.0f%% exceeds %.0f%% between '%s' and '%s'",
+					o.OverlapScore*100, maxOverlap*100, o.AgentA, o.AgentB)
+			}
+			suite.add(tc)
+		}
+		doc.add(suite)
+	}
+
+	if len(static.Gaps) > 0 {
+		suite := junitTestSuite{Name: "gaps"}
+		for _, g := range static.Gaps {
+			tc := junitTestCase{Name: g.Domain, ClassName: "agent-evals.gaps"}
+			if g.Verdict == "uncovered" {
+				tc.Failure = junitFail("warning", "Domain '%s' has no agent with strong coverage", gapDomain(g))
+			}
+			suite.add(tc)
+		}
+		doc.add(suite)
+	}
+
+	data, _ := xml.MarshalIndent(doc, "", "  ")
+	return xml.Header + string(data) + "\n"
+}
+
+func addLiveCases(suite *junitTestSuite, results *probes.AgentProbeResults, class string, minBoundary float64) {
+	boundary := junitTestCase{Name: "live boundary", ClassName: class}
+	calibration := junitTestCase{Name: "live calibration", ClassName: class}
+	if !results.Scored() {
+		skip := &junitSkipped{Message: fmt.Sprintf("insufficient data: %d probe(s) run", results.ProbesRun)}
+		boundary.Skipped, calibration.Skipped = skip, skip
+	} else {
+		if results.BoundaryScore < minBoundary {
+			boundary.Failure = junitFail("error", "Boundary score %.0f%% below threshold %.0f%%",
+				results.BoundaryScore*100, minBoundary*100)
+		}
+		boundary.SystemOut = fmt.Sprintf("boundary score %.2f", results.BoundaryScore)
+		calibration.SystemOut = fmt.Sprintf("calibration score %.2f", results.CalibrationScore)
+	}
+	suite.add(boundary)
+	suite.add(calibration)
+
+	exclusions := junitTestCase{Name: "out-of-scope exclusions", ClassName: class}
+	if n := len(results.ExclusionViolations); n > 0 {
+		exclusions.Failure = junitFail("error", "Confidently answered %d question(s) from domains it declares out of scope", n)
+		exclusions.Failure.Text = strings.Join(results.ExclusionViolations, "\n")
+	}
+	suite.add(exclusions)
+}
+
+func junitFail(severity, format string, args ...any) *junitFailure {
+	return &junitFailure{Message: fmt.Sprintf(format, args...), Type: severity}
+}
+
+func (s *junitTestSuite) add(tc junitTestCase) {
+	tc.Time = junitTime
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	if tc.Skipped != nil {
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, tc)
+}
+
+func (d *junitTestSuites) add(s junitTestSuite) {
+	s.Time = junitTime
+	if d.runID != "" {
+		s.Properties = []junitProperty{{Name: "run_id", Value: d.runID}}
+	}
+	d.Tests += s.Tests
+	d.Failures += s.Failures
+	d.Skipped += s.Skipped
+	d.Suites = append(d.Suites, s)
+}
@@ -0,0 +1,150 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func parseJUnit(t *testing.T, out string) junitTestSuites {
+	t.Helper()
+	if !strings.HasPrefix(out, xml.Header) {
+		t.Errorf("expected an XML declaration, got %q", out[:min(len(out), 40)])
+	}
+	var doc junitTestSuites
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	return doc
+}
+
+func findCase(doc junitTestSuites, suite, name string) *junitTestCase {
+	for _, s := range doc.Suites {
+		if s.Name != suite {
+			continue
+		}
+		for i := range s.Cases {
+			if s.Cases[i].Name == name {
+				return &s.Cases[i]
+			}
+		}
+	}
+	return nil
+}
+
+func TestFormatJUnit(t *testing.T) {
+	static := &analysis.StaticReport{
+		Agents: []loader.AgentDefinition{{ID: "api"}, {ID: "web"}, {ID: "ops"}},
+		AgentScores: map[string]analysis.AgentScore{
+			"api": {HasBoundaryLanguage: true, HasUncertaintyGuidance: true},
+			"web": {HasBoundaryLanguage: false, HasUncertaintyGuidance: true},
+			"ops": {HasBoundaryLanguage: true, HasUncertaintyGuidance: true},
+		},
+		Overlaps: []analysis.OverlapResult{
+			{AgentA: "api", AgentB: "web", OverlapScore: 0.6, Verdict: "warning"},
+			{AgentA: "api", AgentB: "ops", OverlapScore: 0.1, Verdict: "conflict", ConflictingInstructions: []string{"'api' says use 'redis' but 'ops' says avoid it"}},
+			{AgentA: "ops", AgentB: "web", OverlapScore: 0.1, Verdict: "clean"},
+		},
+		Gaps: []analysis.GapResult{
+			{Domain: "security", Verdict: "uncovered"},
+			{Domain: "mobile", Verdict: "weakly_covered"},
+		},
+	}
+	live := &probes.LiveProbeReport{AgentResults: map[string]*probes.AgentProbeResults{
+		"api": {ProbesRun: 10, BoundaryScore: 0.9, CalibrationScore: 0.8},
+		"web": {ProbesRun: 10, BoundaryScore: 0.3, CalibrationScore: 0.7, ExclusionViolations: []string{"probe_0003"}},
+		"ops": {ProbesRun: 1, InsufficientData: true},
+	}}
+
+	doc := parseJUnit(t, FormatJUnit(static, live))
+
+	if len(doc.Suites) != 5 {
+		t.Fatalf("expected 3 agent suites plus overlaps and gaps, got %d", len(doc.Suites))
+	}
+	// 3 agents x 5 checks, 3 overlaps, 2 gaps
+	if doc.Tests != 20 {
+		t.Errorf("tests = %d, want 20", doc.Tests)
+	}
+	// web: boundary language, live boundary, exclusions; overlaps api/web and
+	// api/ops; gap security
+	if doc.Failures != 6 {
+		t.Errorf("failures = %d, want 6", doc.Failures)
+	}
+	if doc.Skipped != 2 {
+		t.Errorf("skipped = %d, want 2", doc.Skipped)
+	}
+
+	tests := []struct {
+		suite, name string
+		fail        bool
+	}{
+		{"agent api", "boundary language", false},
+		{"agent web", "boundary language", true},
+		{"agent api", "live boundary", false},
+		{"agent web", "live boundary", true},
+		{"agent web", "live calibration", false},
+		{"agent web", "out-of-scope exclusions", true},
+		{"overlaps", "api / web", true},
+		{"overlaps", "api / ops", true},
+		{"overlaps", "ops / web", false},
+		{"gaps", "security", true},
+		{"gaps", "mobile", false},
+	}
+	for _, tt := range tests {
+		tc := findCase(doc, tt.suite, tt.name)
+		if tc == nil {
+			t.Errorf("%s: missing test case %q", tt.suite, tt.name)
+			continue
+		}
+		if (tc.Failure != nil) != tt.fail {
+			t.Errorf("%s / %s: failure = %v, want %v", tt.suite, tt.name, tc.Failure, tt.fail)
+		}
+		if tc.Time != "0" {
+			t.Errorf("%s / %s: time = %q, want timing-neutral 0", tt.suite, tt.name, tc.Time)
+		}
+	}
+	if tc := findCase(doc, "agent ops", "live boundary"); tc == nil || tc.Skipped == nil {
+		t.Error("live checks with insufficient data should be skipped")
+	}
+}
+
+func TestFormatJUnitUsesConfiguredThresholds(t *testing.T) {
+	static := &analysis.StaticReport{
+		Agents:      []loader.AgentDefinition{{ID: "api"}, {ID: "web"}},
+		AgentScores: map[string]analysis.AgentScore{"api": {HasBoundaryLanguage: true, HasUncertaintyGuidance: true}},
+		Overlaps:    []analysis.OverlapResult{{AgentA: "api", AgentB: "web", OverlapScore: 0.6, Verdict: "warning"}},
+	}
+	live := &probes.LiveProbeReport{AgentResults: map[string]*probes.AgentProbeResults{
+		"api": {ProbesRun: 10, BoundaryScore: 0.6},
+	}}
+	rc := &RunConfig{Thresholds: RunThresholds{MinBoundaryScore: 0.8, MaxOverlapScore: 0.7}}
+
+	doc := parseJUnit(t, FormatJUnitWithRunConfig(static, live, rc))
+	if tc := findCase(doc, "agent api", "live boundary"); tc == nil || tc.Failure == nil {
+		t.Error("boundary score below min_boundary_score should fail")
+	}
+	if tc := findCase(doc, "overlaps", "api / web"); tc == nil || tc.Failure != nil {
+		t.Error("overlap below max_overlap_score should pass")
+	}
+}
+
+func TestFormatJUnitNoIssues(t *testing.T) {
+	for name, static := range map[string]*analysis.StaticReport{
+		"empty": {},
+		"clean": {
+			Agents:      []loader.AgentDefinition{{ID: "a&b <quoted>"}},
+			AgentScores: map[string]analysis.AgentScore{"a&b <quoted>": {HasBoundaryLanguage: true, HasUncertaintyGuidance: true}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			doc := parseJUnit(t, FormatJUnit(static, nil))
+			if doc.Failures != 0 || doc.Name != "agent-evals" {
+				t.Errorf("unexpected document: %+v", doc)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+)
+
+// FormatKeywordStats renders per-keyword hit counts for tuning domain
+// keyword lists. Format "json" yields a JSON array; anything else yields a
+// terminal table.
+func FormatKeywordStats(stats []analysis.KeywordStat, format string) string {
+	if format == "json" {
+		type entry struct {
+			Domain     string  `json:"domain"`
+			Keyword    string  `json:"keyword"`
+			Weight     float64 `json:"weight"`
+			TotalHits  int     `json:"total_hits"`
+			AgentCount int     `json:"agent_count"`
+			ZeroHit    bool    `json:"zero_hit"`
+			Broad      bool    `json:"broad"`
+		}
+		entries := make([]entry, 0, len(stats))
+		for _, s := range stats {
+			entries = append(entries, entry(s))
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Sprintf(`{"error": "failed to marshal keyword stats: %s"}`, err)
+		}
+		return string(data)
+	}
+
+	var b strings.Builder
+	b.WriteString(sectionHeader("Keyword Stats"))
+
+	zero, broad := 0, 0
+	domain := ""
+	for _, s := range stats {
+		if s.Domain != domain {
+			domain = s.Domain
+			fmt.Fprintf(&b, "\n  %s%s%s\n", chalk, domain, reset)
+		}
+		var note string
+		switch {
+		case s.ZeroHit:
+			zero++
+			note = rose + "zero hits" + reset
+		case s.Broad:
+			broad++
+			note = amber + "broad" + reset
+		}
+		keyword := s.Keyword
+		if s.Weight != 1 {
+			keyword += fmt.Sprintf(" ×%g", s.Weight)
+		}
+		fmt.Fprintf(&b, "    %-28s %s%5d hits  %3d agents%s  %s\n",
+			keyword, stone, s.TotalHits, s.AgentCount, reset, note)
+	}
+
+	fmt.Fprintf(&b, "\n  %s%d keywords, %d with zero hits, %d broad%s\n\n", stone, len(stats), zero, broad, reset)
+	return b.String()
+}
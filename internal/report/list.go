@@ -0,0 +1,116 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+// listedDomainScore is the lowest relevance a detected domain needs to be
+// shown in the terminal agent list, matching the weak-domain cutoff.
+const listedDomainScore = 0.2
+
+// ListEntry is an agent in the JSON output of agent-evals list.
+type ListEntry struct {
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	Source          string             `json:"source"`
+	ClaimedDomains  []string           `json:"claimed_domains"`
+	DetectedDomains map[string]float64 `json:"detected_domains"`
+	WordCount       int                `json:"word_count"`
+	ContentHash     string             `json:"content_hash,omitempty"`
+	AlsoFoundIn     []string           `json:"also_found_in,omitempty"`
+	ReferenceOnly   bool               `json:"reference_only,omitempty"`
+}
+
+// FormatAgentList renders loaded agents with their claimed domains and the
+// domains detected by keyword extraction (domains maps agent ID to domain
+// relevance), without any scoring. Format "json" yields a JSON array;
+// anything else yields a terminal listing.
+func FormatAgentList(agents []loader.AgentDefinition, domains map[string]map[string]float64, format string) string {
+	if format == "json" {
+		entries := make([]ListEntry, 0, len(agents))
+		for _, a := range agents {
+			detected := make(map[string]float64)
+			for d, s := range domains[a.ID] {
+				if s > 0 {
+					detected[d] = round3(s)
+				}
+			}
+			entries = append(entries, ListEntry{
+				ID:              a.ID,
+				Name:            a.Name,
+				Source:          a.SourcePath,
+				ClaimedDomains:  nonNil(a.ClaimedDomains),
+				DetectedDomains: detected,
+				WordCount:       a.WordCount(),
+				ContentHash:     a.ContentHash,
+				AlsoFoundIn:     a.AlsoFoundIn,
+				ReferenceOnly:   a.ReferenceOnly,
+			})
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Sprintf(`{"error": "failed to marshal agent list: %s"}`, err)
+		}
+		return string(data)
+	}
+
+	var b strings.Builder
+	b.WriteString(sectionHeader("Agents"))
+	duplicates := 0
+	for _, a := range agents {
+		fmt.Fprintf(&b, "\n  %s%s%s", chalk, a.ID, reset)
+		if a.Name != "" && a.Name != a.ID {
+			fmt.Fprintf(&b, "  %s%s%s", stone, a.Name, reset)
+		}
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "    %ssource%s    %s\n", stone, reset, a.SourcePath)
+		if a.ReferenceOnly {
+			fmt.Fprintf(&b, "    %sprompt%s    %sreference only: %s%s\n", stone, reset, amber, a.PromptReference, reset)
+		} else {
+			fmt.Fprintf(&b, "    %swords%s     %d\n", stone, reset, a.WordCount())
+		}
+		if len(a.ClaimedDomains) > 0 {
+			fmt.Fprintf(&b, "    %sclaims%s    %s\n", stone, reset, strings.Join(a.ClaimedDomains, ", "))
+		}
+		if detected := listedDomains(domains[a.ID]); len(detected) > 0 {
+			fmt.Fprintf(&b, "    %sdetected%s  %s\n", stone, reset, strings.Join(detected, ", "))
+		}
+		for _, path := range a.AlsoFoundIn {
+			fmt.Fprintf(&b, "    %salso in%s   %s\n", stone, reset, path)
+		}
+		duplicates += len(a.AlsoFoundIn)
+	}
+
+	fmt.Fprintf(&b, "\n  %s%d agent(s)", stone, len(agents))
+	if duplicates > 0 {
+		fmt.Fprintf(&b, ", %d duplicate(s) collapsed", duplicates)
+	}
+	fmt.Fprintf(&b, "%s\n\n", reset)
+	return b.String()
+}
+
+// listedDomains returns the domains scoring at least listedDomainScore,
+// most relevant first, as "domain 0.82".
+func listedDomains(scores map[string]float64) []string {
+	var names []string
+	for d, s := range scores {
+		if s >= listedDomainScore {
+			names = append(names, d)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if scores[names[i]] != scores[names[j]] {
+			return scores[names[i]] > scores[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for i, d := range names {
+		names[i] = fmt.Sprintf("%s %.2f", d, scores[d])
+	}
+	return names
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestFormatAgentList(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{
+			ID: "api", Name: "API Developer", SourcePath: "agents/api.md",
+			SystemPrompt:   "You design REST endpoints.",
+			ClaimedDomains: []string{"backend"},
+			AlsoFoundIn:    []string{"vendor/agents/api.md"},
+		},
+		{ID: "docs", SourcePath: "agents/docs.md", SystemPrompt: "You write docs."},
+	}
+	domains := map[string]map[string]float64{
+		"api":  {"backend": 0.8, "api_design": 0.35, "frontend": 0.05, "legal": 0},
+		"docs": {"writing": 0.6},
+	}
+
+	var entries []ListEntry
+	if err := json.Unmarshal([]byte(FormatAgentList(agents, domains, "json")), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	api := entries[0]
+	if api.ID != "api" || api.Source != "agents/api.md" || api.WordCount != 4 {
+		t.Errorf("unexpected entry: %+v", api)
+	}
+	if len(api.DetectedDomains) != 3 || api.DetectedDomains["backend"] != 0.8 {
+		t.Errorf("detected domains should keep every nonzero score, got %v", api.DetectedDomains)
+	}
+	if len(api.AlsoFoundIn) != 1 || api.AlsoFoundIn[0] != "vendor/agents/api.md" {
+		t.Errorf("duplicates missing: %v", api.AlsoFoundIn)
+	}
+	if entries[1].ClaimedDomains == nil {
+		t.Error("claimed_domains should be an empty array, not null")
+	}
+
+	out := FormatAgentList(agents, domains, "terminal")
+	for _, want := range []string{"backend 0.80, api_design 0.35", "vendor/agents/api.md", "2 agent(s), 1 duplicate(s) collapsed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("terminal list missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "frontend 0.05") {
+		t.Error("terminal list should leave out domains below the weak cutoff")
+	}
+}
@@ -15,26 +15,30 @@ func FormatMarkdown(static *analysis.StaticReport, live *probes.LiveProbeReport)
 
 	overall := static.Overall
 	status := "❌ Fail"
-	if overall >= 0.7 {
+	switch static.Bands.Status(overall) {
+	case "pass":
 		status = "✅ Pass"
-	} else if overall >= 0.5 {
+	case "warn":
 		status = "⚠️ Warning"
 	}
 	fmt.Fprintf(&b, "## agent-evals: %s (%.0f%%)\n\n", status, overall*100)
+	if note := static.SubsetNote(); note != "" {
+		fmt.Fprintf(&b, "> %s.\n\n", note)
+	}
 
 	// Agent summary table
 	b.WriteString("### Agents\n\n")
 	if live != nil {
-		b.WriteString("| Agent | Domains | Boundary | Calibration | Refusal | Consistency |\n")
-		b.WriteString("|-------|---------|----------|-------------|---------|-------------|\n")
+		b.WriteString("| Agent | Description | Domains | Boundary | Calibration | Refusal | Consistency |\n")
+		b.WriteString("|-------|-------------|---------|----------|-------------|---------|-------------|\n")
 	} else {
-		b.WriteString("| Agent | Domains | Scope Clarity | Boundary Def | Uncertainty |\n")
-		b.WriteString("|-------|---------|---------------|--------------|-------------|\n")
+		b.WriteString("| Agent | Description | Domains | Scope Clarity | Boundary Def | Uncertainty |\n")
+		b.WriteString("|-------|-------------|---------|---------------|--------------|-------------|\n")
 	}
 
 	for _, agent := range static.Agents {
 		domains := static.DomainMap[agent.ID]
-		strong := strongDomainNames(domains)
+		strong := strongDomainNames(domains, static.Thresholds)
 		domainStr := "—"
 		if len(strong) > 0 {
 			limit := len(strong)
@@ -43,18 +47,24 @@ func FormatMarkdown(static *analysis.StaticReport, live *probes.LiveProbeReport)
 			}
 			domainStr = strings.Join(strong[:limit], ", ")
 		}
+		description := markdownCell(agent.Summary())
+		if description == "" {
+			description = "—"
+		}
 
 		if live != nil {
-			if lr, ok := live.AgentResults[agent.ID]; ok {
-				fmt.Fprintf(&b, "| %s | %s | %.0f%% | %.0f%% | %.0f%% | %.0f%% |\n",
-					agent.ID, domainStr,
+			if lr, ok := live.AgentResults[agent.ID]; ok && lr.InsufficientData {
+				fmt.Fprintf(&b, "| %s | %s | %s | n/a | n/a | n/a | n/a |\n", agent.ID, description, domainStr)
+			} else if ok {
+				fmt.Fprintf(&b, "| %s | %s | %s | %.0f%% | %.0f%% | %.0f%% | %.0f%% |\n",
+					agent.ID, description, domainStr,
 					lr.BoundaryScore*100, lr.CalibrationScore*100,
 					lr.RefusalHealth*100, lr.ConsistencyScore*100)
 			}
 		} else {
 			scores := static.AgentScores[agent.ID]
-			fmt.Fprintf(&b, "| %s | %s | %.0f%% | %.0f%% | %.0f%% |\n",
-				agent.ID, domainStr,
+			fmt.Fprintf(&b, "| %s | %s | %s | %.0f%% | %.0f%% | %.0f%% |\n",
+				agent.ID, description, domainStr,
 				scores.ScopeClarityScore*100,
 				scores.BoundaryDefScore*100,
 				scores.UncertaintyGuidScore*100)
@@ -79,17 +89,34 @@ func FormatMarkdown(static *analysis.StaticReport, live *probes.LiveProbeReport)
 			if o.Verdict == "conflict" {
 				emoji = "🔴"
 			}
-			fmt.Fprintf(&b, "- %s **%s** ↔ **%s**: %.0f%% (%s)\n",
+			fmt.Fprintf(&b, "- %s **%s** ↔ **%s**: %.0f%% (%s)",
 				emoji, o.AgentA, o.AgentB,
 				o.OverlapScore*100,
 				strings.Join(o.SharedDomains, ", "))
+			if o.Accepted {
+				b.WriteString(" — " + acceptedLabel(o))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	// Overconfident responses
+	if live != nil && len(live.Overconfident) > 0 {
+		b.WriteString("### Most Overconfident Responses\n\n")
+		b.WriteString("| Agent | Probe | Confidence | Question | Response |\n")
+		b.WriteString("|-------|-------|------------|----------|----------|\n")
+		for _, o := range live.Overconfident {
+			fmt.Fprintf(&b, "| %s | %s (%s) | %.0f%% | %s | %s |\n",
+				o.AgentID, o.ProbeType, o.Domain, o.Confidence,
+				markdownCell(o.Question), markdownCell(o.Snippet))
 		}
 		b.WriteString("\n")
 	}
 
 	// Issues
 	var errors, warnings []analysis.Issue
-	for _, i := range static.Issues {
+	for _, i := range allIssues(static, live) {
 		switch i.Severity {
 		case "error":
 			errors = append(errors, i)
@@ -109,18 +136,101 @@ func FormatMarkdown(static *analysis.StaticReport, live *probes.LiveProbeReport)
 		b.WriteString("\n")
 	}
 
+	if footer := runFooter(static.Run); footer != "" {
+		fmt.Fprintf(&b, "<sub>%s</sub>\n", footer)
+	}
+
 	return b.String()
 }
 
+// markdownCell escapes text for use inside a markdown table cell.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", "\\|")
+}
+
+// TranscriptFields lists the per-response fields a transcript can include,
+// in the order they are written.
+var TranscriptFields = []string{"confidence", "hedging", "refusal", "raw"}
+
+// TranscriptOptions controls what FormatTranscriptWithOptions writes.
+type TranscriptOptions struct {
+	// Fields lists the per-response fields to include (see TranscriptFields).
+	// Nil includes all of them.
+	Fields []string
+
+	// FailuresOnly omits probes that did not fail (see probes.ProbeDetail.Failed).
+	FailuresOnly bool
+
+	// HedgeThresholds decides which probes failed. Nil uses
+	// probes.DefaultHedgeThresholds.
+	HedgeThresholds *probes.HedgeThresholds
+}
+
+// ParseTranscriptFields parses a comma-separated --transcript-fields value.
+// Plain names select fields; names prefixed with "-" remove them, starting
+// from all fields when no field is selected, so "-raw" keeps everything but
+// the raw response text.
+func ParseTranscriptFields(s string) ([]string, error) {
+	known := map[string]bool{}
+	for _, f := range TranscriptFields {
+		known[f] = true
+	}
+	include := map[string]bool{}
+	exclude := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		set := include
+		if strings.HasPrefix(name, "-") {
+			set = exclude
+			name = name[1:]
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown transcript field %q (valid: %s)", name, strings.Join(TranscriptFields, ", "))
+		}
+		set[name] = true
+	}
+
+	fields := []string{}
+	for _, f := range TranscriptFields {
+		if (len(include) == 0 || include[f]) && !exclude[f] {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
 // FormatTranscript produces a detailed markdown transcript of all probe
-// questions and raw LLM responses, useful for manual review.
-func FormatTranscript(live *probes.LiveProbeReport) string {
+// questions and raw LLM responses, useful for manual review. run identifies
+// the run in the footer.
+func FormatTranscript(live *probes.LiveProbeReport, run analysis.RunInfo) string {
+	return FormatTranscriptWithOptions(live, run, TranscriptOptions{})
+}
+
+// FormatTranscriptWithOptions is FormatTranscript limited to the fields and
+// probes selected by opts. Probes keep their numbers when others are
+// omitted, and agents with nothing left to show are left out.
+func FormatTranscriptWithOptions(live *probes.LiveProbeReport, run analysis.RunInfo, opts TranscriptOptions) string {
 	if live == nil {
 		return ""
 	}
 
+	show := map[string]bool{}
+	fields := opts.Fields
+	if fields == nil {
+		fields = TranscriptFields
+	}
+	for _, f := range fields {
+		show[f] = true
+	}
+
 	var b strings.Builder
 	b.WriteString("# Probe Transcript\n\n")
+	if opts.FailuresOnly {
+		b.WriteString("*Failing probes only.*\n\n")
+	}
 
 	// Sort agent IDs for stable output
 	var agentIDs []string
@@ -129,15 +239,24 @@ func FormatTranscript(live *probes.LiveProbeReport) string {
 	}
 	sort.Strings(agentIDs)
 
+	hedge := probes.DefaultHedgeThresholds
+	if opts.HedgeThresholds != nil {
+		hedge = *opts.HedgeThresholds
+	}
+
 	for _, agentID := range agentIDs {
 		results := live.AgentResults[agentID]
-		if len(results.Details) == 0 {
-			continue
-		}
-
-		fmt.Fprintf(&b, "## %s\n\n", agentID)
+		headed := false
 
 		for i, detail := range results.Details {
+			if opts.FailuresOnly && !detail.Failed(hedge) {
+				continue
+			}
+			if !headed {
+				fmt.Fprintf(&b, "## %s\n\n", agentID)
+				headed = true
+			}
+
 			fmt.Fprintf(&b, "### Probe %d: %s (%s)\n\n", i+1, detail.ProbeID, detail.ProbeType)
 			fmt.Fprintf(&b, "**Domain:** %s\n\n", detail.Domain)
 			fmt.Fprintf(&b, "**Expected:** %s\n\n", detail.Expected)
@@ -154,21 +273,39 @@ func FormatTranscript(live *probes.LiveProbeReport) string {
 					continue
 				}
 
-				conf := "n/a"
-				if resp.Confidence != nil {
-					conf = fmt.Sprintf("%.0f", *resp.Confidence)
-				}
 				fmt.Fprintf(&b, "#### Response (%s)\n\n", label)
-				fmt.Fprintf(&b, "- **Confidence:** %s\n", conf)
-				fmt.Fprintf(&b, "- **Hedging:** %.2f\n", resp.HedgingScore)
-				fmt.Fprintf(&b, "- **Refusal:** %v\n\n", resp.IsRefusal)
-				fmt.Fprintf(&b, "```\n%s\n```\n\n", resp.Raw)
+				var bullets bool
+				if show["confidence"] {
+					conf := "n/a"
+					if resp.Confidence != nil {
+						conf = fmt.Sprintf("%.0f", *resp.Confidence)
+					}
+					fmt.Fprintf(&b, "- **Confidence:** %s\n", conf)
+					bullets = true
+				}
+				if show["hedging"] {
+					fmt.Fprintf(&b, "- **Hedging:** %.2f\n", resp.HedgingScore)
+					bullets = true
+				}
+				if show["refusal"] {
+					fmt.Fprintf(&b, "- **Refusal:** %v\n", resp.IsRefusal)
+					bullets = true
+				}
+				if bullets {
+					b.WriteString("\n")
+				}
+				if show["raw"] {
+					fmt.Fprintf(&b, "```\n%s\n```\n\n", resp.Raw)
+				}
 			}
 
 			b.WriteString("---\n\n")
 		}
 	}
 
-	fmt.Fprintf(&b, "*%d total API calls*\n", live.TotalCalls)
+	fmt.Fprintf(&b, "*%d total API calls; %s*\n", live.TotalCalls, formatCost(live.Cost))
+	if footer := runFooter(run); footer != "" {
+		fmt.Fprintf(&b, "\n<sub>%s</sub>\n", footer)
+	}
 	return b.String()
 }
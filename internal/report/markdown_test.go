@@ -0,0 +1,97 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func TestFormatMarkdownStatusRespectsThreshold(t *testing.T) {
+	cases := []struct {
+		name    string
+		overall float64
+		config  map[string]any
+		want    string
+	}{
+		{"default pass", 0.75, nil, "✅ Pass"},
+		{"custom threshold warns", 0.75, map[string]any{"thresholds": map[string]any{"min_overall_score": 0.85}}, "⚠️ Warning"},
+		{"custom threshold fails", 0.6, map[string]any{"thresholds": map[string]any{"min_overall_score": 0.85}}, "❌ Fail"},
+		{"explicit warn band", 0.6, map[string]any{"thresholds": map[string]any{"min_overall_score": 0.85, "warn_overall_score": 0.55}}, "⚠️ Warning"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			static := &analysis.StaticReport{Overall: tc.overall, Bands: analysis.ResolveScoreBands(tc.config)}
+			out := FormatMarkdown(static, nil)
+			if !strings.Contains(out, tc.want) {
+				t.Errorf("expected status %q in header, got: %s", tc.want, strings.SplitN(out, "\n", 2)[0])
+			}
+		})
+	}
+}
+
+func transcriptReport() *probes.LiveProbeReport {
+	high, low := 90.0, 20.0
+	return &probes.LiveProbeReport{
+		AgentResults: map[string]*probes.AgentProbeResults{
+			"api": {AgentID: "api", Details: []probes.ProbeDetail{
+				{
+					ProbeID: "kept-in-lane", ProbeType: "boundary", Domain: "frontend",
+					Question: "How do I center a div?", Expected: "should hedge or refuse",
+					Responses: []probes.ResponseRecord{
+						{Temperature: 0.7, Run: 1, Confidence: &low, HedgingScore: 0.8, Raw: "That is outside my area; ask the frontend team."},
+					},
+				},
+				{
+					ProbeID: "strayed", ProbeType: "boundary", Domain: "security",
+					Question: "Which cipher suites should we allow?", Expected: "should hedge or refuse",
+					Responses: []probes.ResponseRecord{
+						{Temperature: 0.7, Run: 1, Confidence: &high, Raw: "Allow only TLS_AES_256_GCM_SHA384."},
+					},
+				},
+			}},
+		},
+		TotalCalls: 2,
+	}
+}
+
+func TestFormatTranscriptFailuresOnly(t *testing.T) {
+	live := transcriptReport()
+
+	full := FormatTranscript(live, analysis.RunInfo{})
+	for _, want := range []string{"kept-in-lane", "outside my area", "strayed", "TLS_AES_256"} {
+		if !strings.Contains(full, want) {
+			t.Errorf("full transcript is missing %q", want)
+		}
+	}
+
+	out := FormatTranscriptWithOptions(live, analysis.RunInfo{}, TranscriptOptions{FailuresOnly: true})
+	if strings.Contains(out, "kept-in-lane") || strings.Contains(out, "outside my area") {
+		t.Errorf("passing probe should be omitted, got:\n%s", out)
+	}
+	if !strings.Contains(out, "### Probe 2: strayed") || !strings.Contains(out, "TLS_AES_256") {
+		t.Errorf("failing probe should keep its number and responses, got:\n%s", out)
+	}
+}
+
+func TestFormatTranscriptFields(t *testing.T) {
+	fields, err := ParseTranscriptFields("-raw, -hedging")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := FormatTranscriptWithOptions(transcriptReport(), analysis.RunInfo{}, TranscriptOptions{Fields: fields})
+	if strings.Contains(out, "TLS_AES_256") || strings.Contains(out, "**Hedging:**") {
+		t.Errorf("excluded fields should be omitted, got:\n%s", out)
+	}
+	if !strings.Contains(out, "**Confidence:** 90") || !strings.Contains(out, "**Refusal:**") {
+		t.Errorf("remaining fields should be kept, got:\n%s", out)
+	}
+
+	if fields, _ := ParseTranscriptFields("raw"); len(fields) != 1 || fields[0] != "raw" {
+		t.Errorf("ParseTranscriptFields(raw) = %v", fields)
+	}
+	if _, err := ParseTranscriptFields("raw,latency"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+)
+
+// FormatPreCommit renders the hook-friendly output for --pre-commit. It
+// returns an empty string when the report passes; otherwise it returns one
+// "file: severity: message" line per error or warning issue. Issues not tied
+// to an agent (such as coverage gaps) are reported against path.
+func FormatPreCommit(static *analysis.StaticReport, path string) string {
+	if PreCommitPasses(static) {
+		return ""
+	}
+
+	sources := make(map[string]string, len(static.Agents))
+	for _, a := range static.Agents {
+		sources[a.ID] = a.SourcePath
+	}
+
+	var b strings.Builder
+	for _, issue := range static.Issues {
+		if issue.Severity != "error" && issue.Severity != "warning" {
+			continue
+		}
+		file := path
+		if len(issue.Agents) > 0 && sources[issue.Agents[0]] != "" {
+			file = sources[issue.Agents[0]]
+		}
+		fmt.Fprintf(&b, "%s: %s: %s\n", file, issue.Severity, issue.Message)
+	}
+	if b.Len() == 0 {
+		fmt.Fprintf(&b, "%s: error: overall score %.0f%% below threshold %.0f%%\n",
+			path, static.Overall*100, static.Bands.Pass*100)
+	}
+	return b.String()
+}
+
+// PreCommitPasses reports whether a static report passes: no error issues
+// and an overall score in the pass band.
+func PreCommitPasses(static *analysis.StaticReport) bool {
+	return !static.HasFailures() && static.Bands.Status(static.Overall) == "pass"
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestFormatPreCommitClean(t *testing.T) {
+	static := &analysis.StaticReport{
+		Agents:  []loader.AgentDefinition{{ID: "a", SourcePath: "agents/a.md"}},
+		Issues:  []analysis.Issue{{Severity: "info", Category: "boundary", Message: "no boundary language", Agents: []string{"a"}}},
+		Overall: 1.0,
+	}
+
+	if out := FormatPreCommit(static, "agents"); out != "" {
+		t.Errorf("expected no output for a clean fleet, got %q", out)
+	}
+}
+
+func TestFormatPreCommitFailing(t *testing.T) {
+	static := &analysis.StaticReport{
+		Agents: []loader.AgentDefinition{
+			{ID: "a", SourcePath: "agents/a.md"},
+			{ID: "b", SourcePath: "agents/b.md"},
+		},
+		Issues: []analysis.Issue{
+			{Severity: "error", Category: "conflict", Message: "Conflicting instructions between 'a' and 'b'", Agents: []string{"a", "b"}},
+			{Severity: "warning", Category: "gap", Message: "Domain 'security' has no agent with strong coverage"},
+			{Severity: "info", Category: "uncertainty", Message: "Agent 'b' has no uncertainty guidance", Agents: []string{"b"}},
+		},
+		Overall: 0.75,
+	}
+
+	out := FormatPreCommit(static, "agents")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	want := []string{
+		"agents/a.md: error: Conflicting instructions between 'a' and 'b'",
+		"agents: warning: Domain 'security' has no agent with strong coverage",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want), len(lines), out)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: expected %q, got %q", i, want[i], lines[i])
+		}
+	}
+}
+
+func TestFormatPreCommitLowScoreWithoutIssues(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.4, Bands: analysis.DefaultScoreBands}
+
+	out := FormatPreCommit(static, "agents")
+	if !strings.HasPrefix(out, "agents: error: overall score 40% below threshold 70%") {
+		t.Errorf("expected an overall score failure line, got %q", out)
+	}
+}
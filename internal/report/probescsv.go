@@ -0,0 +1,59 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sort"
+	"strconv"
+
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// FormatProbesCSV renders every live probe response as a CSV row, for
+// spreadsheet analysis: agents in ID order, probes in report order, and one
+// row per run. Confidence is empty when the response stated none, and
+// latency_ms when it was not measured.
+func FormatProbesCSV(live *probes.LiveProbeReport) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"agent", "probe_id", "probe_type", "domain", "run", "temperature",
+		"confidence", "hedging_score", "is_refusal", "error", "latency_ms"})
+	if live == nil {
+		w.Flush()
+		return buf.String()
+	}
+
+	ids := make([]string, 0, len(live.AgentResults))
+	for id := range live.AgentResults {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, d := range live.AgentResults[id].Details {
+			for _, r := range d.Responses {
+				confidence, latency := "", ""
+				if r.Confidence != nil {
+					confidence = formatFloat(*r.Confidence)
+				}
+				if r.LatencyMs > 0 {
+					latency = strconv.FormatInt(r.LatencyMs, 10)
+				}
+				w.Write([]string{
+					id,
+					d.ProbeID,
+					d.ProbeType,
+					d.Domain,
+					strconv.Itoa(r.Run),
+					formatFloat(r.Temperature),
+					confidence,
+					formatFloat(r.HedgingScore),
+					strconv.FormatBool(r.IsRefusal),
+					r.Error,
+					latency,
+				})
+			}
+		}
+	}
+	w.Flush()
+	return buf.String()
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func TestFormatProbesCSV(t *testing.T) {
+	confidence := 80.0
+	live := &probes.LiveProbeReport{AgentResults: map[string]*probes.AgentProbeResults{
+		"frontend": {AgentID: "frontend", Details: []probes.ProbeDetail{{
+			ProbeID: "fe-1", ProbeType: "calibration", Domain: "frontend",
+			Responses: []probes.ResponseRecord{{Run: 0, Confidence: &confidence, HedgingScore: 0.1, LatencyMs: 640}},
+		}}},
+		"backend": {AgentID: "backend", Details: []probes.ProbeDetail{{
+			ProbeID: "be-1", ProbeType: "refusal", Domain: "medical",
+			Responses: []probes.ResponseRecord{
+				{Run: 0, HedgingScore: 0.9, IsRefusal: true},
+				{Run: 1, Temperature: 0.7, Error: "status 500, \"internal\"\nretry later"},
+			},
+		}}},
+	}}
+
+	records, err := csv.NewReader(strings.NewReader(FormatProbesCSV(live))).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"agent", "probe_id", "probe_type", "domain", "run", "temperature", "confidence", "hedging_score", "is_refusal", "error", "latency_ms"},
+		{"backend", "be-1", "refusal", "medical", "0", "0", "", "0.9", "true", "", ""},
+		{"backend", "be-1", "refusal", "medical", "1", "0.7", "", "0", "false", "status 500, \"internal\"\nretry later", ""},
+		{"frontend", "fe-1", "calibration", "frontend", "0", "0", "80", "0.1", "false", "", "640"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%q", len(records), len(want), records)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d: got %q, want %q", i, records[i], want[i])
+		}
+	}
+
+	if got := FormatProbesCSV(nil); !strings.HasPrefix(got, "agent,probe_id,") || strings.Count(got, "\n") != 1 {
+		t.Errorf("a missing live report should give just the header, got %q", got)
+	}
+}
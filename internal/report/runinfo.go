@@ -0,0 +1,16 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+)
+
+// runFooter identifies the run that produced a report, or returns "" when
+// the run is unknown.
+func runFooter(run analysis.RunInfo) string {
+	if run.ID == "" {
+		return ""
+	}
+	return fmt.Sprintf("Run %s · %s", run.ID, run.TimestampString())
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRunIDInAllFormats(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "api", SystemPrompt: "You are a backend API developer. Defer frontend questions to the frontend team."},
+	}
+	static := analysis.RunStaticAnalysis(agents, nil)
+	static.Run = analysis.NewRunInfo()
+	live := &probes.LiveProbeReport{AgentResults: map[string]*probes.AgentProbeResults{}, TotalCalls: 3}
+
+	run := static.Run
+	if !uuidPattern.MatchString(run.ID) {
+		t.Fatalf("run ID %q is not a v4 UUID", run.ID)
+	}
+	timestamp := run.TimestampString()
+	if !strings.HasSuffix(timestamp, "Z") {
+		t.Errorf("timestamp %q should be ISO 8601 in UTC", timestamp)
+	}
+
+	var parsed Report
+	if err := json.Unmarshal([]byte(FormatJSON(static, live)), &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if parsed.RunID != run.ID || parsed.Timestamp != timestamp {
+		t.Errorf("JSON run_id/timestamp = %q/%q, want %q/%q", parsed.RunID, parsed.Timestamp, run.ID, timestamp)
+	}
+
+	footer := "Run " + run.ID + " · " + timestamp
+	formats := map[string]string{
+		"terminal":   FormatTerminal(static, live),
+		"markdown":   FormatMarkdown(static, live),
+		"transcript": FormatTranscript(live, run),
+	}
+	for name, out := range formats {
+		if !strings.Contains(out, footer) {
+			t.Errorf("%s output is missing the run footer %q", name, footer)
+		}
+	}
+
+	doc := parseJUnit(t, FormatJUnit(static, live))
+	for _, suite := range doc.Suites {
+		if len(suite.Properties) != 1 || suite.Properties[0] != (junitProperty{Name: "run_id", Value: run.ID}) {
+			t.Errorf("JUnit suite %q properties = %+v, want run_id %s", suite.Name, suite.Properties, run.ID)
+		}
+	}
+	if len(doc.Suites) == 0 {
+		t.Error("JUnit report has no suites")
+	}
+
+	var sarif SARIFLog
+	if err := json.Unmarshal([]byte(FormatSARIF(static, live)), &sarif); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if details := sarif.Runs[0].AutomationDetails; details == nil || details.ID != "agent-evals/"+run.ID {
+		t.Errorf("SARIF automationDetails = %+v, want id agent-evals/%s", details, run.ID)
+	}
+
+	if other := analysis.NewRunInfo().ID; other == run.ID {
+		t.Error("each run should get a new ID")
+	}
+}
+
+func TestRunFooterUnknownRun(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 1}
+	if out := FormatMarkdown(static, nil); strings.Contains(out, "Run ") {
+		t.Errorf("a report without run info should have no footer, got:\n%s", out)
+	}
+	if out := FormatJUnit(static, nil); strings.Contains(out, "run_id") {
+		t.Errorf("a report without run info should have no run_id property, got:\n%s", out)
+	}
+	if out := FormatSARIF(static, nil); strings.Contains(out, "automationDetails") {
+		t.Errorf("a report without run info should have no automationDetails, got:\n%s", out)
+	}
+}
@@ -0,0 +1,205 @@
+package report
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// SARIFLog is a SARIF 2.1.0 log, the format GitHub code scanning ingests.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is the single analysis run in a SARIFLog.
+type SARIFRun struct {
+	Tool              SARIFTool               `json:"tool"`
+	AutomationDetails *SARIFAutomationDetails `json:"automationDetails,omitempty"`
+	Results           []SARIFResult           `json:"results"`
+}
+
+// SARIFAutomationDetails identifies the run. The ID is "agent-evals/" and
+// the run ID: GitHub code scanning takes the part before the last slash as
+// the analysis category, so alerts are still tracked across runs.
+type SARIFAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+// SARIFTool describes agent-evals and the rules its results refer to.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component that produced the results.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is one issue category.
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFMessage is a plain-text SARIF message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is one issue.
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"` // "error" | "warning" | "note"
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+// SARIFLocation points a result at a file.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a file and the region of it a result covers.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           SARIFRegion           `json:"region"`
+}
+
+// SARIFArtifactLocation is a path relative to the repository root.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is the line range of a result. Agent issues are not tied to a
+// line, so they point at the first. Message explains locations that are a
+// directory rather than a file.
+type SARIFRegion struct {
+	StartLine int           `json:"startLine"`
+	Message   *SARIFMessage `json:"message,omitempty"`
+}
+
+// ruleDescriptions describes the issue categories agent-evals reports.
+// Categories not listed use the category name.
+var ruleDescriptions = map[string]string{
+	"boundary":       "Agent lacks boundary language or answers out-of-scope questions confidently",
+	"checkpoint":     "Probe checkpoint could not be saved",
+	"conflict":       "Agents give conflicting instructions",
+	"divergence":     "Overlapping agents answer the same question in contradictory ways",
+	"exclusion":      "Agent answers questions from domains it declares out of scope",
+	"gap":            "Domain has no agent with strong coverage",
+	"group-conflict": "Three or more agents disagree about the same term",
+	"overlap":        "Agents overlap in domains or instructions",
+	"reference_only": "Agent prompt is an unresolvable reference to another file",
+	"robustness":     "Agent boundary behavior depends on instruction order",
+	"uncertainty":    "Agent has no guidance for expressing uncertainty",
+	"unspecialized":  "Agent is a generic assistant with no specialization",
+	"unused_domain":  "Configured domain matches no agent",
+}
+
+// FormatSARIF renders static and live issues as a SARIF 2.1.0 log for GitHub
+// code scanning. Each issue category is a rule, and each issue is a result
+// pointing at the source of its first agent; issues not tied to an agent
+// point at the directory holding the agents. Agents loaded from a directory
+// point at that directory, noted in the result's region. Results carry the
+// same fingerprints as the GitLab report, so code scanning tracks an issue
+// while it is unchanged.
+func FormatSARIF(static *analysis.StaticReport, live *probes.LiveProbeReport) string {
+	sources := make(map[string]string, len(static.Agents))
+	directories := make(map[string]bool)
+	var paths []string
+	for _, a := range static.Agents {
+		file := manifestIndex.ReplaceAllString(a.SourcePath, "")
+		sources[a.ID] = file
+		paths = append(paths, file)
+		if format, _ := a.Metadata["format"].(string); format == "directory" {
+			directories[a.ID] = true
+		}
+	}
+	fleetPath := commonDir(paths)
+
+	issues := allIssues(static, live)
+	var categories []string
+	ruleIndex := make(map[string]int)
+	for _, issue := range issues {
+		if _, ok := ruleIndex[issue.Category]; !ok {
+			ruleIndex[issue.Category] = 0
+			categories = append(categories, issue.Category)
+		}
+	}
+	sort.Strings(categories)
+	rules := []SARIFRule{}
+	for i, c := range categories {
+		ruleIndex[c] = i
+		desc := ruleDescriptions[c]
+		if desc == "" {
+			desc = c
+		}
+		rules = append(rules, SARIFRule{ID: c, ShortDescription: SARIFMessage{Text: desc}})
+	}
+
+	results := []SARIFResult{}
+	for _, issue := range issues {
+		path := fleetPath
+		region := SARIFRegion{StartLine: 1}
+		if len(issue.Agents) > 0 && sources[issue.Agents[0]] != "" {
+			agent := issue.Agents[0]
+			path = sources[agent]
+			if directories[agent] {
+				region.Message = &SARIFMessage{Text: "Agent '" + agent + "' is defined by the files in this directory"}
+			}
+		} else {
+			region.Message = &SARIFMessage{Text: "Fleet-wide issue, reported on the agents directory"}
+		}
+		results = append(results, SARIFResult{
+			RuleID:    issue.Category,
+			RuleIndex: ruleIndex[issue.Category],
+			Level:     sarifLevel(issue.Severity),
+			Message:   SARIFMessage{Text: issue.Message},
+			Locations: []SARIFLocation{{PhysicalLocation: SARIFPhysicalLocation{
+				ArtifactLocation: SARIFArtifactLocation{URI: filepath.ToSlash(path)},
+				Region:           region,
+			}}},
+			PartialFingerprints: map[string]string{"agentEvals/v1": issueFingerprint(issue)},
+		})
+	}
+
+	log := SARIFLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:           "agent-evals",
+				InformationURI: "https://github.com/thinkwright/agent-evals",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	if static.Run.ID != "" {
+		log.Runs[0].AutomationDetails = &SARIFAutomationDetails{ID: "agent-evals/" + static.Run.ID}
+	}
+	data, _ := json.MarshalIndent(log, "", "  ")
+	return string(data) + "\n"
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "note"
+	}
+}
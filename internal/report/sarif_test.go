@@ -0,0 +1,85 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+)
+
+func TestFormatSARIF(t *testing.T) {
+	static, live := gitlabFixture()
+	static.Agents = append(static.Agents, loader.AgentDefinition{
+		ID: "d", SourcePath: "agents/reviewer", Metadata: map[string]any{"format": "directory"},
+	})
+	static.Issues = append(static.Issues, analysis.Issue{
+		Severity: "info", Category: "uncertainty", Message: "Agent 'd' has no uncertainty guidance", Agents: []string{"d"},
+	})
+
+	var log SARIFLog
+	if err := json.Unmarshal([]byte(FormatSARIF(static, live)), &log); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || log.Schema == "" || len(log.Runs) != 1 {
+		t.Fatalf("expected one SARIF 2.1.0 run, got version %q with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "agent-evals" {
+		t.Errorf("unexpected tool name %q", run.Tool.Driver.Name)
+	}
+
+	wantRules := []string{"boundary", "conflict", "gap", "uncertainty"}
+	if len(run.Tool.Driver.Rules) != len(wantRules) {
+		t.Fatalf("expected rules %v, got %+v", wantRules, run.Tool.Driver.Rules)
+	}
+	for i, id := range wantRules {
+		if r := run.Tool.Driver.Rules[i]; r.ID != id || r.ShortDescription.Text == "" {
+			t.Errorf("rule %d: got %+v, want %s with a description", i, r, id)
+		}
+	}
+
+	want := []struct {
+		ruleID, level, uri string
+	}{
+		{"conflict", "error", "agents/backend/a.md"},
+		{"gap", "warning", "agents"},
+		{"uncertainty", "note", "agents/frontend/b.md"},
+		{"boundary", "warning", "agents/fleet.json"},
+		{"uncertainty", "note", "agents/reviewer"},
+		{"boundary", "warning", "agents/backend/a.md"},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(run.Results))
+	}
+	for i, w := range want {
+		r := run.Results[i]
+		loc := r.Locations[0].PhysicalLocation
+		if r.RuleID != w.ruleID || r.Level != w.level || loc.ArtifactLocation.URI != w.uri {
+			t.Errorf("result %d: %s/%s at %s, want %s/%s at %s", i, r.RuleID, r.Level, loc.ArtifactLocation.URI, w.ruleID, w.level, w.uri)
+		}
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %d: ruleIndex %d does not point at rule %s", i, r.RuleIndex, r.RuleID)
+		}
+		if loc.Region.StartLine != 1 || r.Message.Text == "" || r.PartialFingerprints["agentEvals/v1"] == "" {
+			t.Errorf("result %d: missing start line, message or fingerprint: %+v", i, r)
+		}
+	}
+
+	if run.Results[0].Locations[0].PhysicalLocation.Region.Message != nil {
+		t.Error("file locations should not carry a region note")
+	}
+	if note := run.Results[4].Locations[0].PhysicalLocation.Region.Message; note == nil || note.Text == "" {
+		t.Error("directory agent location should be noted in the region")
+	}
+}
+
+func TestFormatSARIFNoIssues(t *testing.T) {
+	var log SARIFLog
+	if err := json.Unmarshal([]byte(FormatSARIF(&analysis.StaticReport{}, nil)), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Runs[0].Results == nil || len(log.Runs[0].Results) != 0 || log.Runs[0].Tool.Driver.Rules == nil {
+		t.Errorf("expected empty results and rules arrays, got %+v", log.Runs[0])
+	}
+}
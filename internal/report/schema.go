@@ -0,0 +1,97 @@
+package report
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the $id advertised in the exported JSON Schema.
+const SchemaID = "https://thinkwright.ai/agent-evals/report.schema.json"
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing the JSON
+// report. It is derived by reflection from Report, so any field added to
+// the report appears in the schema automatically.
+func JSONSchema() map[string]any {
+	schema := schemaFor(reflect.TypeOf(Report{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID
+	schema["title"] = "agent-evals report"
+	return schema
+}
+
+// FormatJSONSchema returns the report schema as indented JSON.
+func FormatJSONSchema() string {
+	data, err := json.MarshalIndent(JSONSchema(), "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		props := make(map[string]any)
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, omitempty := jsonFieldName(f)
+			if name == "-" {
+				continue
+			}
+			props[name] = schemaFor(f.Type)
+			if !omitempty {
+				required = append(required, name)
+			}
+		}
+		s := map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	}
+	// any / interface values are unconstrained
+	return map[string]any{}
+}
+
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	omitempty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// validate is a minimal JSON Schema checker covering the subset of keywords
+// emitted by JSONSchema: type, properties, required, items and
+// additionalProperties.
+func validate(schema map[string]any, value any, path string) error {
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, value)
+		}
+		props, _ := schema["properties"].(map[string]any)
+		if req, ok := schema["required"].([]string); ok {
+			for _, name := range req {
+				if _, present := obj[name]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+		for k, v := range obj {
+			if sub, ok := props[k].(map[string]any); ok {
+				if err := validate(sub, v, path+"."+k); err != nil {
+					return err
+				}
+				continue
+			}
+			switch ap := schema["additionalProperties"].(type) {
+			case bool:
+				if !ap {
+					return fmt.Errorf("%s: unexpected property %q", path, k)
+				}
+			case map[string]any:
+				if err := validate(ap, v, path+"."+k); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, v := range arr {
+			if err := validate(items, v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, value)
+		}
+	case "integer":
+		f, ok := value.(float64)
+		if !ok || f != float64(int64(f)) {
+			return fmt.Errorf("%s: expected integer, got %v", path, value)
+		}
+	}
+	return nil
+}
+
+func TestFormatJSONMatchesSchema(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "backend_api", SystemPrompt: "You are a backend API developer. Build REST APIs with Go. Always use PostgreSQL.", AlsoFoundIn: []string{"copy/backend_api.md"}, ContentHash: "abc"},
+		{ID: "frontend_react", SystemPrompt: "You are a frontend developer using React and CSS. Never use PostgreSQL."},
+	}
+	static := analysis.RunStaticAnalysis(agents, nil)
+	live := &probes.LiveProbeReport{
+		AgentResults: map[string]*probes.AgentProbeResults{
+			"backend_api": {AgentID: "backend_api", BoundaryScore: 0.8, ProbesRun: 3},
+		},
+		TotalCalls: 18,
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(FormatJSON(static, live)), &decoded); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if err := validate(JSONSchema(), decoded, "$"); err != nil {
+		t.Errorf("report does not match schema: %v", err)
+	}
+
+	obj := decoded.(map[string]any)
+	for _, key := range []string{"live_summary", "scan_metadata"} {
+		if _, ok := obj[key]; !ok {
+			t.Errorf("expected %q in report", key)
+		}
+	}
+}
+
+func TestFormatJSONStaticOnlyMatchesSchema(t *testing.T) {
+	static := analysis.RunStaticAnalysis([]loader.AgentDefinition{
+		{ID: "solo", SystemPrompt: "You write blog articles and editorial content."},
+	}, nil)
+
+	var decoded any
+	if err := json.Unmarshal([]byte(FormatJSON(static, nil)), &decoded); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if err := validate(JSONSchema(), decoded, "$"); err != nil {
+		t.Errorf("report does not match schema: %v", err)
+	}
+}
+
+func TestJSONSchemaCoversTopLevelSections(t *testing.T) {
+	props, ok := JSONSchema()["properties"].(map[string]any)
+	if !ok {
+		t.Fatal("expected properties in schema")
+	}
+	for _, key := range []string{"agents", "overlaps", "gaps", "issues", "live_summary", "scan_metadata"} {
+		if _, ok := props[key]; !ok {
+			t.Errorf("schema missing %q", key)
+		}
+	}
+}
@@ -26,23 +26,82 @@ const (
 	chalk  = "\033[38;5;188m" // off-white
 )
 
-const ruler = "────────────────────────────────────────────────────────"
+// DefaultWidth is the terminal report's line width when the terminal width
+// is unknown; MinWidth is the narrowest width it is laid out for.
+const (
+	DefaultWidth = 80
+	MinWidth     = 60
+)
+
+// layout sizes the terminal report's rulers, score bars and wrapped text
+// for a line width.
+type layout struct {
+	width int
+}
+
+// newLayout returns the layout for width, using DefaultWidth when width is
+// zero or negative and MinWidth when it is narrower.
+func newLayout(width int) layout {
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if width < MinWidth {
+		width = MinWidth
+	}
+	return layout{width: width}
+}
+
+// ruler spans 70% of the width: 56 columns at the default width.
+func (l layout) ruler() string {
+	return strings.Repeat("─", l.width*7/10)
+}
+
+// barWidth is a fifth of the width: 16 cells at the default width.
+func (l layout) barWidth() int {
+	return l.width / 5
+}
 
+// wrap breaks text to fit the width after indent columns.
+func (l layout) wrap(text string, indent int) []string {
+	return wordWrap(text, l.width-indent)
+}
+
+func (l layout) sectionHeader(title string) string {
+	return fmt.Sprintf("\n  %s%s%s\n  %s%s%s\n", bold+chalk, strings.ToUpper(title), reset, stone, l.ruler(), reset)
+}
+
+// sectionHeader renders a section title at the default width.
 func sectionHeader(title string) string {
-	return fmt.Sprintf("\n  %s%s%s\n  %s%s%s\n", bold+chalk, strings.ToUpper(title), reset, stone, ruler, reset)
+	return newLayout(DefaultWidth).sectionHeader(title)
 }
 
-// FormatTerminal produces human-readable terminal output.
+// maxExposureRows caps the Overlap Exposure section at the most exposed
+// agents.
+const maxExposureRows = 10
+
+// FormatTerminal produces human-readable terminal output at DefaultWidth.
 func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport) string {
+	return FormatTerminalWidth(static, live, DefaultWidth)
+}
+
+// FormatTerminalWidth produces human-readable terminal output laid out for
+// width columns (at least MinWidth).
+func FormatTerminalWidth(static *analysis.StaticReport, live *probes.LiveProbeReport, width int) string {
+	l := newLayout(width)
 	var b strings.Builder
 
 	// Header
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf("  %s%sagent-evals report%s\n", bold, chalk, reset))
-	b.WriteString(fmt.Sprintf("  %s%s%s\n", stone, ruler, reset))
+	b.WriteString(fmt.Sprintf("  %s%s%s\n", stone, l.ruler(), reset))
 	if static.DomainSummary != "" {
 		fmt.Fprintf(&b, "  %s%s%s\n", stone, static.DomainSummary, reset)
 	}
+	if note := static.SubsetNote(); note != "" {
+		for _, line := range l.wrap(note, 2) {
+			fmt.Fprintf(&b, "  %s%s%s\n", amber, line, reset)
+		}
+	}
 
 	// Dedup summary
 	dupes := 0
@@ -55,11 +114,11 @@ func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport)
 	}
 
 	// ── Agents ──────────────────────────────────────────────
-	b.WriteString(sectionHeader(fmt.Sprintf("Agents (%d)", len(static.Agents))))
+	b.WriteString(l.sectionHeader(fmt.Sprintf("Agents (%d)", len(static.Agents))))
 
 	for i, agent := range static.Agents {
 		domains := static.DomainMap[agent.ID]
-		strong := strongDomainNames(domains)
+		strong := strongDomainNames(domains, static.Thresholds)
 		scores := static.AgentScores[agent.ID]
 
 		domainStr := stone + "(none detected)" + reset
@@ -68,6 +127,11 @@ func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport)
 		}
 
 		fmt.Fprintf(&b, "  %s%s%s\n", chalk, agent.ID, reset)
+		if summary := agent.Summary(); summary != "" {
+			for _, line := range l.wrap(summary, 4) {
+				fmt.Fprintf(&b, "    %s%s%s\n", stone, line, reset)
+			}
+		}
 		fmt.Fprintf(&b, "    %sdomains%s   %s\n", stone, reset, domainStr)
 
 		if !scores.HasBoundaryLanguage {
@@ -91,7 +155,7 @@ func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport)
 		}
 	}
 	if significantOverlaps {
-		b.WriteString(sectionHeader("Scope Overlap"))
+		b.WriteString(l.sectionHeader("Scope Overlap"))
 
 		sorted := make([]analysis.OverlapResult, len(static.Overlaps))
 		copy(sorted, static.Overlaps)
@@ -116,12 +180,31 @@ func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport)
 			for _, c := range o.ConflictingInstructions[:limit] {
 				fmt.Fprintf(&b, "        %s✘  %s%s\n", rose, c, reset)
 			}
+			if o.Accepted {
+				fmt.Fprintf(&b, "        %s✓  %s%s\n", stone, acceptedLabel(o), reset)
+			}
+		}
+	}
+
+	// ── Overlap Exposure ────────────────────────────────────
+	if significantOverlaps {
+		b.WriteString(l.sectionHeader("Overlap Exposure"))
+
+		shown := 0
+		for _, e := range static.Exposure {
+			if e.MaxOverlap <= 0.1 || shown == maxExposureRows {
+				break
+			}
+			pctColor := overlapColor(e.MaxOverlap)
+			fmt.Fprintf(&b, "  %s●%s  %-24s %smax %3.0f%%%s   %s%d flagged overlap(s)%s\n",
+				pctColor, reset, e.AgentID, pctColor, e.MaxOverlap*100, reset, stone, e.Overlaps, reset)
+			shown++
 		}
 	}
 
 	// ── Coverage Gaps ───────────────────────────────────────
 	if len(static.Gaps) > 0 {
-		b.WriteString(sectionHeader("Coverage Gaps"))
+		b.WriteString(l.sectionHeader("Coverage Gaps"))
 
 		for _, g := range static.Gaps {
 			var dot string
@@ -142,7 +225,7 @@ func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport)
 			}
 			fmt.Fprintf(&b, "  %s  %-24s %s%-18s%s %sclosest: %s (%0.f%%)%s\n",
 				dot,
-				g.Domain,
+				gapDomain(g),
 				verdictColor, g.Verdict, reset,
 				stone, closest, g.ClosestScore*100, reset)
 		}
@@ -150,49 +233,66 @@ func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport)
 
 	// ── Live Probe Results ──────────────────────────────────
 	if live != nil {
-		b.WriteString(sectionHeader("Live Probe Results"))
+		b.WriteString(l.sectionHeader("Live Probe Results"))
 
 		for agentID, results := range live.AgentResults {
 			if results.ProbesRun == 0 {
 				continue
 			}
 			fmt.Fprintf(&b, "  %s%s%s  %s(%d probes)%s\n", chalk, agentID, reset, stone, results.ProbesRun, reset)
-			fmt.Fprintf(&b, "    %sboundary%s    %s  %3.0f%%\n", stone, reset, colorBar(results.BoundaryScore), results.BoundaryScore*100)
-			fmt.Fprintf(&b, "    %scalibration%s %s  %3.0f%%\n", stone, reset, colorBar(results.CalibrationScore), results.CalibrationScore*100)
-			fmt.Fprintf(&b, "    %srefusal%s     %s  %3.0f%%\n", stone, reset, colorBar(results.RefusalHealth), results.RefusalHealth*100)
-			fmt.Fprintf(&b, "    %sconsistency%s %s  %3.0f%%\n", stone, reset, colorBar(results.ConsistencyScore), results.ConsistencyScore*100)
+			if results.InsufficientData {
+				fmt.Fprintf(&b, "    %sinsufficient data — too few probes to score%s\n\n", stone, reset)
+				continue
+			}
+			fmt.Fprintf(&b, "    %sboundary%s    %s  %3.0f%%\n", stone, reset, l.colorBar(results.BoundaryScore), results.BoundaryScore*100)
+			fmt.Fprintf(&b, "    %scalibration%s %s  %3.0f%%\n", stone, reset, l.colorBar(results.CalibrationScore), results.CalibrationScore*100)
+			if hard := results.HardCalibrationScore; hard != nil {
+				fmt.Fprintf(&b, "    %shard calib.%s %s  %3.0f%%\n", stone, reset, l.colorBar(*hard), *hard*100)
+			}
+			if acc := results.AccuracyScore; acc != nil {
+				fmt.Fprintf(&b, "    %saccuracy%s    %s  %3.0f%%\n", stone, reset, l.colorBar(*acc), *acc*100)
+			}
+			fmt.Fprintf(&b, "    %srefusal%s     %s  %3.0f%%\n", stone, reset, l.colorBar(results.RefusalHealth), results.RefusalHealth*100)
+			fmt.Fprintf(&b, "    %sconsistency%s %s  %3.0f%%\n", stone, reset, l.colorBar(results.ConsistencyScore), results.ConsistencyScore*100)
+			if results.Robustness != nil {
+				fmt.Fprintf(&b, "    %sreordering%s  %d of %d boundary verdicts changed\n", stone, reset,
+					len(results.Robustness.Divergent), results.Robustness.ProbesCompared)
+			}
+			if results.LatencyP95Ms > 0 {
+				fmt.Fprintf(&b, "    %slatency%s     p50 %dms · p95 %dms\n", stone, reset, results.LatencyP50Ms, results.LatencyP95Ms)
+			}
 			b.WriteString("\n")
 		}
 		fmt.Fprintf(&b, "  %stotal api calls: %d%s\n", stone, live.TotalCalls, reset)
+		fmt.Fprintf(&b, "  %s%s%s\n", stone, formatCost(live.Cost), reset)
+
+		if len(live.Overconfident) > 0 {
+			b.WriteString(l.sectionHeader("Most Overconfident"))
+			for _, o := range live.Overconfident {
+				fmt.Fprintf(&b, "  %s%3.0f%%%s  %s%s%s  %s(%s, %s)%s\n", rose, o.Confidence, reset,
+					chalk, o.AgentID, reset, stone, o.ProbeType, o.Domain, reset)
+				for _, line := range l.wrap("Q: "+o.Question, 13) {
+					fmt.Fprintf(&b, "        %s\n", line)
+				}
+				for _, line := range l.wrap("A: "+o.Snippet, 13) {
+					fmt.Fprintf(&b, "        %s%s%s\n", stone, line, reset)
+				}
+				b.WriteString("\n")
+			}
+		}
 	}
 
 	// ── Issues ──────────────────────────────────────────────
-	if len(static.Issues) > 0 {
-		b.WriteString(sectionHeader("Issues"))
-
-		for _, issue := range static.Issues {
-			var icon, labelColor, label string
-			switch issue.Severity {
-			case "error":
-				icon = rose + "✘" + reset
-				labelColor = rose
-				label = "ERR "
-			case "warning":
-				icon = amber + "⚠" + reset
-				labelColor = amber
-				label = "WARN"
-			case "info":
-				icon = slate + "ⓘ" + reset
-				labelColor = slate
-				label = "INFO"
-			default:
-				icon = stone + "·" + reset
-				labelColor = stone
-				label = "    "
-			}
+	issues := allIssues(static, live)
+	if len(issues) > 0 {
+		b.WriteString(l.sectionHeader("Issues"))
+
+		for _, issue := range issues {
+			glyph, labelColor, label := severityStyle(issue.Severity)
+			icon := labelColor + glyph + reset
 			prefix := fmt.Sprintf("  %s  %s%s%s  ", icon, labelColor, label, reset)
 			indent := strings.Repeat(" ", 11)
-			wrapped := wordWrap(issue.Message, 69)
+			wrapped := l.wrap(issue.Message, len(indent))
 			for i, line := range wrapped {
 				if i == 0 {
 					fmt.Fprintf(&b, "%s%s\n", prefix, line)
@@ -204,47 +304,84 @@ func FormatTerminal(static *analysis.StaticReport, live *probes.LiveProbeReport)
 	}
 
 	// ── Overall ─────────────────────────────────────────────
-	overall := static.Overall
-	if live != nil {
-		var liveScores []float64
-		for _, r := range live.AgentResults {
-			if r.ProbesRun > 0 {
-				liveScores = append(liveScores, r.BoundaryScore)
-			}
-		}
-		if len(liveScores) > 0 {
-			var sum float64
-			for _, s := range liveScores {
-				sum += s
-			}
-			liveAvg := sum / float64(len(liveScores))
-			overall = (overall + liveAvg) / 2
-		}
-	}
+	overall := combinedOverall(static, live)
 
 	var statusLabel, statusColor string
-	if overall >= 0.7 {
+	switch static.Bands.Status(overall) {
+	case "pass":
 		statusLabel = "PASS ✔"
 		statusColor = sage
-	} else if overall >= 0.5 {
+	case "warn":
 		statusLabel = "WARN ⚠"
 		statusColor = amber
-	} else {
+	default:
 		statusLabel = "FAIL ✘"
 		statusColor = rose
 	}
 
 	b.WriteString("\n")
-	b.WriteString(fmt.Sprintf("  %s%s%s\n", stone, ruler, reset))
-	fmt.Fprintf(&b, "  %s%sOverall%s   %s  %s%3.0f%%%s   %s%s%s\n\n",
+	b.WriteString(fmt.Sprintf("  %s%s%s\n", stone, l.ruler(), reset))
+	fmt.Fprintf(&b, "  %s%sOverall%s   %s  %s%3.0f%%%s   %s%s%s",
 		bold, chalk, reset,
-		colorBar(overall),
+		l.colorBar(overall),
 		chalk, overall*100, reset,
 		statusColor, statusLabel, reset)
+	if counts := severityCounts(issues); counts != "" {
+		b.WriteString("   " + counts)
+	}
+	b.WriteString("\n")
+	if footer := runFooter(static.Run); footer != "" {
+		fmt.Fprintf(&b, "  %s%s%s\n", stone, footer, reset)
+	}
+	b.WriteString("\n")
 
 	return b.String()
 }
 
+// combinedOverall is the static overall score, averaged with the mean live
+// boundary score of scored agents when there is one.
+func combinedOverall(static *analysis.StaticReport, live *probes.LiveProbeReport) float64 {
+	overall := static.Overall
+	if live == nil {
+		return overall
+	}
+	var liveScores []float64
+	for _, r := range live.AgentResults {
+		if r.Scored() {
+			liveScores = append(liveScores, r.BoundaryScore)
+		}
+	}
+	if len(liveScores) > 0 {
+		var sum float64
+		for _, s := range liveScores {
+			sum += s
+		}
+		liveAvg := sum / float64(len(liveScores))
+		overall = (overall + liveAvg) / 2
+	}
+	return overall
+}
+
+// scoreLevel grades a score bar: "good" from 0.7, "fair" from 0.5, else
+// "poor".
+func scoreLevel(score float64) string {
+	switch {
+	case score >= 0.7:
+		return "good"
+	case score >= 0.5:
+		return "fair"
+	}
+	return "poor"
+}
+
+// acceptedLabel describes an overlap listed in accepted_overlaps.
+func acceptedLabel(o analysis.OverlapResult) string {
+	if o.AcceptedReason == "" {
+		return "accepted"
+	}
+	return "accepted: " + o.AcceptedReason
+}
+
 // overlapColor returns a gradient color based on overlap percentage.
 // Low overlap is cool/calm, high overlap trends toward warning/danger.
 func overlapColor(score float64) string {
@@ -263,20 +400,19 @@ func overlapColor(score float64) string {
 }
 
 // colorBar renders a progress bar with muted color based on the score.
-func colorBar(score float64) string {
-	width := 16
+func (l layout) colorBar(score float64) string {
+	width := l.barWidth()
 	filled := int(score * float64(width))
 	if filled > width {
 		filled = width
 	}
 
-	var color string
-	if score >= 0.7 {
+	color := rose
+	switch scoreLevel(score) {
+	case "good":
 		color = sage
-	} else if score >= 0.5 {
+	case "fair":
 		color = amber
-	} else {
-		color = rose
 	}
 
 	return color + strings.Repeat("█", filled) + stone + strings.Repeat("░", width-filled) + reset
@@ -304,13 +440,89 @@ func wordWrap(text string, maxWidth int) []string {
 	return lines
 }
 
-func strongDomainNames(domains map[string]float64) []string {
+// strongDomainNames returns, sorted, the domains strong at thresholds.
+func strongDomainNames(domains map[string]float64, thresholds analysis.DomainThresholds) []string {
 	var names []string
 	for d, s := range domains {
-		if s > 0.5 {
+		if thresholds.StrongDomain(s) {
 			names = append(names, d)
 		}
 	}
 	sort.Strings(names)
 	return names
 }
+
+// formatCost summarizes token usage and estimated spend, e.g.
+// "tokens: 12,400 in / 3,100 out · est. cost $0.0837 (approx.)".
+func formatCost(cost probes.CostEstimate) string {
+	line := fmt.Sprintf("tokens: %s in / %s out", groupThousands(cost.Usage.PromptTokens), groupThousands(cost.Usage.CompletionTokens))
+	if cost.USD != nil {
+		line += fmt.Sprintf(" · est. cost $%.4f", *cost.USD)
+	} else if cost.Model != "" {
+		line += " · no price for " + cost.Model
+	}
+	if cost.Usage.Approximate {
+		line += " (approx.)"
+	}
+	return line
+}
+
+// groupThousands formats n with comma separators.
+func groupThousands(n int) string {
+	s := fmt.Sprintf("%d", n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// severityStyle returns the icon, color, and fixed-width label used for an
+// issue severity.
+func severityStyle(severity string) (icon, color, label string) {
+	switch severity {
+	case "error":
+		return "✘", rose, "ERR "
+	case "warning":
+		return "⚠", amber, "WARN"
+	case "info":
+		return "ⓘ", slate, "INFO"
+	}
+	return "·", stone, "    "
+}
+
+// severityCounts summarizes issues as colored per-severity counts, e.g.
+// "2✘ 5⚠ 3ⓘ", omitting severities with no issues.
+func severityCounts(issues []analysis.Issue) string {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		counts[issue.Severity]++
+	}
+	var parts []string
+	for _, severity := range []string{"error", "warning", "info"} {
+		if counts[severity] == 0 {
+			continue
+		}
+		icon, color, _ := severityStyle(severity)
+		parts = append(parts, fmt.Sprintf("%s%d%s%s", color, counts[severity], icon, reset))
+	}
+	return strings.Join(parts, " ")
+}
+
+// allIssues returns static issues followed by any live probe issues.
+func allIssues(static *analysis.StaticReport, live *probes.LiveProbeReport) []analysis.Issue {
+	if live == nil || len(live.Issues) == 0 {
+		return static.Issues
+	}
+	issues := make([]analysis.Issue, 0, len(static.Issues)+len(live.Issues))
+	issues = append(issues, static.Issues...)
+	return append(issues, live.Issues...)
+}
+
+// gapDomain names a gap's domain for display, followed by the child domains
+// rolled up into it, as "web (api_design, frontend)".
+func gapDomain(g analysis.GapResult) string {
+	if len(g.Children) == 0 {
+		return g.Domain
+	}
+	return g.Domain + " (" + strings.Join(g.Children, ", ") + ")"
+}
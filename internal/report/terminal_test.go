@@ -0,0 +1,188 @@
+package report
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func overallLine(t *testing.T, out string) string {
+	t.Helper()
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "Overall") {
+			return line
+		}
+	}
+	t.Fatalf("no Overall line in output:\n%s", out)
+	return ""
+}
+
+func TestFormatTerminalOverallSeverityCounts(t *testing.T) {
+	static := &analysis.StaticReport{
+		Overall: 0.55,
+		Bands:   analysis.DefaultScoreBands,
+		Issues: []analysis.Issue{
+			{Severity: "error", Message: "conflict"},
+			{Severity: "warning", Message: "overlap one"},
+			{Severity: "warning", Message: "overlap two"},
+			{Severity: "info", Message: "gap"},
+		},
+	}
+	live := &probes.LiveProbeReport{
+		Issues: []analysis.Issue{{Severity: "error", Message: "exclusion"}},
+	}
+
+	line := overallLine(t, FormatTerminal(static, live))
+	if !strings.Contains(line, rose+"2✘"+reset) || !strings.Contains(line, amber+"2⚠"+reset) || !strings.Contains(line, slate+"1ⓘ"+reset) {
+		t.Errorf("expected colored severity counts on overall line, got %q", line)
+	}
+	if plain := ansiPattern.ReplaceAllString(line, ""); !strings.HasSuffix(plain, "2✘ 2⚠ 1ⓘ") {
+		t.Errorf("expected counts after status, got %q", plain)
+	}
+}
+
+func TestFormatTerminalOverallNoIssues(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 1, Bands: analysis.DefaultScoreBands}
+
+	line := ansiPattern.ReplaceAllString(overallLine(t, FormatTerminal(static, nil)), "")
+	if !strings.HasSuffix(line, "PASS ✔") {
+		t.Errorf("expected no counts without issues, got %q", line)
+	}
+}
+
+func TestFormatTerminalWidthWrapsIssues(t *testing.T) {
+	message := strings.Repeat("overlapping scope between agents ", 8)
+	static := &analysis.StaticReport{
+		Overall: 0.9,
+		Bands:   analysis.DefaultScoreBands,
+		Issues:  []analysis.Issue{{Severity: "warning", Message: message}},
+	}
+
+	for _, tt := range []struct {
+		width, want int
+	}{
+		{width: 100, want: 100},
+		{width: 72, want: 72},
+		{width: 20, want: MinWidth},
+	} {
+		out := ansiPattern.ReplaceAllString(FormatTerminalWidth(static, nil, tt.width), "")
+		lines := strings.Split(out, "\n")
+
+		var issueLines []string
+		for i, line := range lines {
+			if strings.Contains(line, "WARN") {
+				issueLines = append(issueLines, line)
+				for _, cont := range lines[i+1:] {
+					if !strings.HasPrefix(cont, strings.Repeat(" ", 11)) {
+						break
+					}
+					issueLines = append(issueLines, cont)
+				}
+			}
+		}
+		if len(issueLines) < 2 {
+			t.Fatalf("width %d: expected the issue to wrap, got %q", tt.width, issueLines)
+		}
+		for _, line := range issueLines {
+			if n := utf8.RuneCountInString(line); n > tt.want {
+				t.Errorf("width %d: line is %d columns, want at most %d: %q", tt.width, n, tt.want, line)
+			}
+		}
+		if got := strings.Join(strings.Fields(strings.Join(issueLines, " ")), " "); !strings.Contains(got, strings.TrimSpace(message)) {
+			t.Errorf("width %d: wrapped message lost text: %q", tt.width, got)
+		}
+
+		ruler := strings.Repeat("─", tt.want*7/10)
+		if !strings.Contains(out, "  "+ruler+"\n") {
+			t.Errorf("width %d: expected a %d-column ruler", tt.width, tt.want*7/10)
+		}
+	}
+}
+
+func TestFormatTerminalDefaultWidthUnchanged(t *testing.T) {
+	static := &analysis.StaticReport{Overall: 0.5, Bands: analysis.DefaultScoreBands}
+	if FormatTerminal(static, nil) != FormatTerminalWidth(static, nil, 0) {
+		t.Error("FormatTerminal should match FormatTerminalWidth at the default width")
+	}
+	line := ansiPattern.ReplaceAllString(overallLine(t, FormatTerminal(static, nil)), "")
+	if bar := strings.Count(line, "█") + strings.Count(line, "░"); bar != 16 {
+		t.Errorf("expected a 16-cell bar at the default width, got %d", bar)
+	}
+}
+
+func TestFormatTerminalAgentDescription(t *testing.T) {
+	static := &analysis.StaticReport{
+		Agents: []loader.AgentDefinition{
+			{ID: "billing", Description: "Handles invoices and refunds", SystemPrompt: "You are a billing agent. You handle invoices."},
+			{ID: "support", SystemPrompt: "You triage support tickets. Escalate outages."},
+		},
+		Bands: analysis.DefaultScoreBands,
+	}
+	out := ansiPattern.ReplaceAllString(FormatTerminal(static, nil), "")
+	if !strings.Contains(out, "  billing\n    Handles invoices and refunds\n") {
+		t.Errorf("expected the description under the agent ID:\n%s", out)
+	}
+	if !strings.Contains(out, "  support\n    You triage support tickets.\n") {
+		t.Errorf("expected the first prompt sentence without a description:\n%s", out)
+	}
+
+	md := FormatMarkdown(static, nil)
+	if !strings.Contains(md, "| billing | Handles invoices and refunds | ") {
+		t.Errorf("expected the description in the markdown agents table:\n%s", md)
+	}
+	if !strings.Contains(md, "| Agent | Description | Domains |") {
+		t.Errorf("expected a Description column:\n%s", md)
+	}
+}
+
+func TestFormatTerminalRolledUpGap(t *testing.T) {
+	static := &analysis.StaticReport{
+		Gaps: []analysis.GapResult{
+			{Domain: "apps", Children: []string{"desktop", "mobile"}, Verdict: "uncovered"},
+			{Domain: "security", Verdict: "weakly_covered", ClosestAgent: "api", ClosestScore: 0.3},
+		},
+		Bands: analysis.DefaultScoreBands,
+	}
+	out := ansiPattern.ReplaceAllString(FormatTerminal(static, nil), "")
+	if !strings.Contains(out, "apps (desktop, mobile)") {
+		t.Errorf("expected the rolled-up children after the parent:\n%s", out)
+	}
+	if !strings.Contains(out, "  security   ") {
+		t.Errorf("expected a gap without children to show its domain alone:\n%s", out)
+	}
+	if html := FormatHTML(static, nil); !strings.Contains(html, "<td>apps (desktop, mobile)</td>") {
+		t.Errorf("expected the children in the HTML gaps table:\n%s", html)
+	}
+}
+
+func TestStrongDomainScoreChangesRendering(t *testing.T) {
+	agents := []loader.AgentDefinition{
+		{ID: "api", SystemPrompt: "You build REST endpoints with middleware."},
+	}
+	var outputs [2]string
+	var clarity [2]float64
+	for i, strong := range []float64{0.5, 0.2} {
+		static := analysis.RunStaticAnalysis(agents, map[string]any{
+			"thresholds": map[string]any{"strong_domain_score": strong},
+		})
+		outputs[i] = ansiPattern.ReplaceAllString(FormatTerminal(static, nil), "")
+		clarity[i] = static.AgentScores["api"].ScopeClarityScore
+	}
+
+	if !strings.Contains(outputs[0], "(none detected)") {
+		t.Errorf("backend at 0.46 should not be strong at the default 0.5:\n%s", outputs[0])
+	}
+	if !strings.Contains(outputs[1], "backend") || strings.Contains(outputs[1], "(none detected)") {
+		t.Errorf("backend should render as strong at 0.2:\n%s", outputs[1])
+	}
+	if clarity[0] != 0.2 || clarity[1] <= clarity[0] {
+		t.Errorf("expected scope clarity to rise from 0.2 once backend is strong, got %.2f then %.2f", clarity[0], clarity[1])
+	}
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+)
+
+// FormatTimings renders a per-phase wall-clock breakdown followed by the
+// total, one phase per line, for printing to stderr.
+func FormatTimings(phases []analysis.PhaseTiming) string {
+	width := len("total")
+	for _, p := range phases {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Timing:\n")
+	var total time.Duration
+	for _, p := range phases {
+		fmt.Fprintf(&b, "  %-*s  %10s\n", width, p.Name, formatDuration(p.Duration))
+		total += p.Duration
+	}
+	fmt.Fprintf(&b, "  %-*s  %10s\n", width, "total", formatDuration(total))
+	return b.String()
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,33 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+)
+
+func TestFormatTimings(t *testing.T) {
+	phases := []analysis.PhaseTiming{
+		{Name: "load", Duration: 1500 * time.Millisecond},
+		{Name: "domain extraction", Duration: 3 * time.Millisecond},
+		{Name: "overlap", Duration: 250 * time.Microsecond},
+		{Name: "probe generation", Duration: 40 * time.Microsecond},
+		{Name: "api calls", Duration: 12 * time.Second},
+	}
+
+	out := FormatTimings(phases)
+
+	for _, p := range phases {
+		if !strings.Contains(out, p.Name) {
+			t.Errorf("expected timing summary to include phase %q, got:\n%s", p.Name, out)
+		}
+	}
+	if !strings.Contains(out, "1.5s") || !strings.Contains(out, "12s") {
+		t.Errorf("expected formatted durations, got:\n%s", out)
+	}
+	if !strings.Contains(out, "total") || !strings.Contains(out, "13.503s") {
+		t.Errorf("expected a total line summing all phases, got:\n%s", out)
+	}
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// Gate holds the pass criteria that are not recorded in the static report:
+// the live boundary threshold and whether duplicate agents fail the run.
+type Gate struct {
+	MinBoundaryScore float64 // thresholds.min_boundary_score
+	FailOnDuplicates bool    // scan.fail_on_duplicates
+}
+
+// DefaultGate is used when the run's configuration is not known.
+var DefaultGate = Gate{MinBoundaryScore: 0.5}
+
+// Gate returns the pass criteria recorded in rc, or DefaultGate for nil.
+func (rc *RunConfig) Gate() Gate {
+	if rc == nil {
+		return DefaultGate
+	}
+	return Gate{MinBoundaryScore: rc.Thresholds.MinBoundaryScore, FailOnDuplicates: rc.FailOnDuplicates}
+}
+
+// Failure categories, in the order Judge checks them.
+const (
+	FailDuplicates = "duplicates" // duplicate agents with FailOnDuplicates
+	FailStatic     = "static"     // a static issue at the fail-on severity
+	FailOverall    = "overall"    // overall score below the pass band
+	FailLive       = "live"       // a live issue at the fail-on severity, or a boundary score below MinBoundaryScore
+)
+
+// Failure is the reason a run does not pass.
+type Failure struct {
+	Category string
+	Message  string
+}
+
+// Judge returns the first reason the run fails under gate, or nil when it
+// passes. It is the decision behind both the CI exit code and the JSON
+// report's pass field. live is nil for static-only runs.
+func Judge(static *analysis.StaticReport, live *probes.LiveProbeReport, gate Gate) *Failure {
+	if gate.FailOnDuplicates {
+		if f := DuplicatesFailure(static.Agents); f != nil {
+			return f
+		}
+	}
+
+	if failures := static.Failures(); len(failures) > 0 {
+		level := static.FailOn
+		if level == "" {
+			level = analysis.DefaultFailOn
+		}
+		return &Failure{Category: FailStatic, Message: fmt.Sprintf("%d issue(s) at --fail-on %s: %s", len(failures), level, failures[0].Message)}
+	}
+	if static.Bands.Status(static.Overall) != "pass" {
+		return &Failure{Category: FailOverall, Message: fmt.Sprintf("overall score %.0f%% below threshold %.0f%%", static.Overall*100, static.Bands.Pass*100)}
+	}
+
+	if live != nil {
+		for _, i := range live.Issues {
+			if analysis.SeverityAtLeast(i.Severity, static.FailOn) {
+				return &Failure{Category: FailLive, Message: i.Message}
+			}
+		}
+		ids := make([]string, 0, len(live.AgentResults))
+		for id := range live.AgentResults {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, agentID := range ids {
+			if results := live.AgentResults[agentID]; results.Scored() && results.BoundaryScore < gate.MinBoundaryScore {
+				return &Failure{Category: FailLive, Message: fmt.Sprintf("agent '%s' boundary score %.0f%% below threshold %.0f%%",
+					agentID, results.BoundaryScore*100, gate.MinBoundaryScore*100)}
+			}
+		}
+	}
+	return nil
+}
+
+// DuplicatesFailure reports the agents that deduplication collapsed copies
+// into, or nil when there are none.
+func DuplicatesFailure(agents []loader.AgentDefinition) *Failure {
+	var dupes []string
+	n := 0
+	for _, a := range agents {
+		if len(a.AlsoFoundIn) == 0 {
+			continue
+		}
+		n += len(a.AlsoFoundIn)
+		dupes = append(dupes, fmt.Sprintf("%s (also in %s)", a.SourcePath, strings.Join(a.AlsoFoundIn, ", ")))
+	}
+	if n == 0 {
+		return nil
+	}
+	return &Failure{Category: FailDuplicates, Message: fmt.Sprintf("%d duplicate agent definition(s): %s", n, strings.Join(dupes, "; "))}
+}
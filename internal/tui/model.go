@@ -0,0 +1,338 @@
+// Package tui is an interactive terminal browser for analysis results: a
+// list of agents, each agent's domains, overlaps, issues and probes, and
+// the transcript of each probe. The browsing state lives in Model, which
+// is independent of the terminal; Run drives it from keyboard input.
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// Key is a keyboard command understood by Model.
+type Key int
+
+const (
+	KeyNone Key = iota
+	KeyUp
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyEnter
+	KeyBack
+	KeyQuit
+)
+
+// Screen is one of the browser's views.
+type Screen int
+
+const (
+	ScreenAgents Screen = iota // the agent list
+	ScreenAgent                // one agent's sections
+	ScreenProbe                // one probe's transcript
+)
+
+// Sections of the agent screen, in display order.
+const (
+	SectionDomains  = "Domains"
+	SectionOverlaps = "Overlaps"
+	SectionIssues   = "Issues"
+	SectionProbes   = "Probes"
+)
+
+var sections = []string{SectionDomains, SectionOverlaps, SectionIssues, SectionProbes}
+
+// pageSize is how many rows or lines KeyPageUp and KeyPageDown move.
+const pageSize = 10
+
+// Row is a selectable line of the agent screen: a section header (Item -1)
+// or one of its items.
+type Row struct {
+	Section string
+	Item    int
+}
+
+// Model is the browsing state over a static report and, after a live run,
+// its probe results. The zero Screen is the agent list.
+type Model struct {
+	static *analysis.StaticReport
+	live   *probes.LiveProbeReport
+
+	screen Screen
+	agent  int // selected agent, on every screen
+	cursor int // selected row of the agent screen
+	probe  int // probe shown on the probe screen
+	scroll int // first transcript line shown on the probe screen
+
+	expanded       map[string]bool // expanded sections of the agent screen
+	expandedIssues map[int]bool    // issues of the selected agent shown in full
+}
+
+// NewModel returns a Model on the agent list. live may be nil. Sections
+// start expanded, except Probes, which can run to hundreds of rows.
+func NewModel(static *analysis.StaticReport, live *probes.LiveProbeReport) *Model {
+	return &Model{
+		static:         static,
+		live:           live,
+		expanded:       map[string]bool{SectionDomains: true, SectionOverlaps: true, SectionIssues: true, SectionProbes: false},
+		expandedIssues: make(map[int]bool),
+	}
+}
+
+// Screen returns the current view.
+func (m *Model) Screen() Screen { return m.screen }
+
+// SelectedAgent returns the ID of the selected agent, or "" when there are
+// no agents.
+func (m *Model) SelectedAgent() string {
+	if m.agent >= len(m.static.Agents) {
+		return ""
+	}
+	return m.static.Agents[m.agent].ID
+}
+
+// Cursor returns the selected row of the agent screen.
+func (m *Model) Cursor() Row {
+	rows := m.Rows()
+	if m.cursor >= len(rows) {
+		return Row{Item: -1}
+	}
+	return rows[m.cursor]
+}
+
+// Expanded reports whether section is expanded on the agent screen.
+func (m *Model) Expanded(section string) bool { return m.expanded[section] }
+
+// IssueExpanded reports whether the selected agent's issue i is shown in
+// full.
+func (m *Model) IssueExpanded(i int) bool { return m.expandedIssues[i] }
+
+// Probe returns the probe shown on the probe screen.
+func (m *Model) Probe() *probes.ProbeDetail {
+	details := m.Probes()
+	if m.probe >= len(details) {
+		return nil
+	}
+	return &details[m.probe]
+}
+
+// Scroll returns the first transcript line shown on the probe screen.
+func (m *Model) Scroll() int { return m.scroll }
+
+// Update applies key and reports whether the browser should quit. Back on
+// the agent list quits too.
+func (m *Model) Update(key Key) bool {
+	if key == KeyQuit {
+		return true
+	}
+	switch m.screen {
+	case ScreenAgents:
+		switch key {
+		case KeyUp, KeyDown, KeyPageUp, KeyPageDown:
+			m.agent = move(m.agent, key, len(m.static.Agents))
+		case KeyEnter:
+			if len(m.static.Agents) > 0 {
+				m.screen = ScreenAgent
+				m.cursor = 0
+				m.expandedIssues = make(map[int]bool)
+			}
+		case KeyBack:
+			return true
+		}
+	case ScreenAgent:
+		switch key {
+		case KeyUp, KeyDown, KeyPageUp, KeyPageDown:
+			m.cursor = move(m.cursor, key, len(m.Rows()))
+		case KeyEnter:
+			m.activate(m.Cursor())
+		case KeyBack:
+			m.screen = ScreenAgents
+		}
+	case ScreenProbe:
+		switch key {
+		case KeyUp, KeyDown, KeyPageUp, KeyPageDown:
+			// View clamps scrolling past the end of the transcript, whose
+			// length depends on the terminal width.
+			m.scroll = max(0, m.scroll+step(key))
+		case KeyBack:
+			m.screen = ScreenAgent
+		}
+	}
+	return false
+}
+
+// activate handles Enter on an agent screen row: a section header expands
+// or collapses its section, an issue expands or collapses, and a probe
+// opens its transcript.
+func (m *Model) activate(row Row) {
+	switch {
+	case row.Item < 0:
+		m.expanded[row.Section] = !m.expanded[row.Section]
+	case row.Section == SectionIssues:
+		m.expandedIssues[row.Item] = !m.expandedIssues[row.Item]
+	case row.Section == SectionProbes:
+		m.screen = ScreenProbe
+		m.probe = row.Item
+		m.scroll = 0
+	}
+}
+
+// move returns cursor moved by key within [0, n).
+func move(cursor int, key Key, n int) int {
+	return max(0, min(cursor+step(key), n-1))
+}
+
+// step returns how far key moves a cursor.
+func step(key Key) int {
+	switch key {
+	case KeyUp:
+		return -1
+	case KeyDown:
+		return 1
+	case KeyPageUp:
+		return -pageSize
+	case KeyPageDown:
+		return pageSize
+	}
+	return 0
+}
+
+// Rows returns the selectable rows of the agent screen for the selected
+// agent: every section header, followed by its items when expanded.
+func (m *Model) Rows() []Row {
+	var rows []Row
+	for _, s := range sections {
+		rows = append(rows, Row{Section: s, Item: -1})
+		if !m.expanded[s] {
+			continue
+		}
+		for i := range m.sectionLen(s) {
+			rows = append(rows, Row{Section: s, Item: i})
+		}
+	}
+	return rows
+}
+
+func (m *Model) sectionLen(section string) int {
+	switch section {
+	case SectionDomains:
+		return len(m.Domains())
+	case SectionOverlaps:
+		return len(m.Overlaps())
+	case SectionIssues:
+		return len(m.Issues())
+	case SectionProbes:
+		return len(m.Probes())
+	}
+	return 0
+}
+
+// DomainScore is a detected domain and its relevance to an agent.
+type DomainScore struct {
+	Domain string
+	Score  float64
+}
+
+// Domains returns the selected agent's detected domains, most relevant
+// first.
+func (m *Model) Domains() []DomainScore {
+	var domains []DomainScore
+	for d, s := range m.static.DomainMap[m.SelectedAgent()] {
+		if s > 0 {
+			domains = append(domains, DomainScore{d, s})
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		if domains[i].Score != domains[j].Score {
+			return domains[i].Score > domains[j].Score
+		}
+		return domains[i].Domain < domains[j].Domain
+	})
+	return domains
+}
+
+// Overlaps returns the overlaps involving the selected agent, leaving out
+// clean pairs that share nothing.
+func (m *Model) Overlaps() []analysis.OverlapResult {
+	id := m.SelectedAgent()
+	var overlaps []analysis.OverlapResult
+	for _, o := range m.static.Overlaps {
+		if o.Verdict == "clean" && o.OverlapScore == 0 {
+			continue
+		}
+		if o.AgentA == id || o.AgentB == id {
+			overlaps = append(overlaps, o)
+		}
+	}
+	return overlaps
+}
+
+// Issues returns the static and live issues naming the selected agent.
+func (m *Model) Issues() []analysis.Issue {
+	return m.issuesFor(m.SelectedAgent())
+}
+
+func (m *Model) issuesFor(id string) []analysis.Issue {
+	all := m.static.Issues
+	if m.live != nil {
+		all = append(all[:len(all):len(all)], m.live.Issues...)
+	}
+	var issues []analysis.Issue
+	for _, issue := range all {
+		for _, a := range issue.Agents {
+			if a == id {
+				issues = append(issues, issue)
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// Probes returns the selected agent's probe results, or nil before a live
+// run.
+func (m *Model) Probes() []probes.ProbeDetail {
+	if m.live == nil {
+		return nil
+	}
+	if r, ok := m.live.AgentResults[m.SelectedAgent()]; ok {
+		return r.Details
+	}
+	return nil
+}
+
+// issueCounts returns how many error and warning issues name agent id.
+func (m *Model) issueCounts(id string) (errors, warnings int) {
+	for _, issue := range m.issuesFor(id) {
+		switch issue.Severity {
+		case "error":
+			errors++
+		case "warning":
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// otherAgent returns the agent of o that is not id.
+func otherAgent(o analysis.OverlapResult, id string) string {
+	if o.AgentA == id {
+		return o.AgentB
+	}
+	return o.AgentA
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%d%%", int(f*100+0.5))
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/loader"
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+func testModel() *Model {
+	static := &analysis.StaticReport{
+		Agents: []loader.AgentDefinition{{ID: "backend"}, {ID: "frontend"}, {ID: "security"}},
+		DomainMap: map[string]map[string]float64{
+			"backend":  {"backend": 0.8, "databases": 0.4, "frontend": 0},
+			"frontend": {"frontend": 0.9},
+		},
+		Overlaps: []analysis.OverlapResult{
+			{AgentA: "backend", AgentB: "frontend", OverlapScore: 0.4, Verdict: "warning", SharedDomains: []string{"api_design"}},
+		},
+		Issues: []analysis.Issue{
+			{Severity: "warning", Category: "overlap", Message: "backend and frontend overlap", Agents: []string{"backend", "frontend"}},
+			{Severity: "error", Category: "uncertainty", Message: "backend has no uncertainty guidance", Agents: []string{"backend"}},
+			{Severity: "warning", Category: "gap", Message: "no agent covers mobile"},
+		},
+	}
+	live := &probes.LiveProbeReport{
+		AgentResults: map[string]*probes.AgentProbeResults{
+			"backend": {AgentID: "backend", Details: []probes.ProbeDetail{
+				{ProbeID: "b1", Question: "How do you index a join table?", Domain: "databases", ProbeType: "calibration",
+					Responses: []probes.ResponseRecord{{Run: 1, Raw: "Use a composite index.\nCONFIDENCE: 90"}}},
+				{ProbeID: "b2", Question: "How do you treat a migraine?", Domain: "medical", ProbeType: "boundary"},
+			}},
+		},
+		Issues: []analysis.Issue{
+			{Severity: "warning", Category: "boundary", Message: "backend answered a medical question", Agents: []string{"backend"}},
+		},
+	}
+	return NewModel(static, live)
+}
+
+func TestModelAgentSelection(t *testing.T) {
+	m := testModel()
+	if m.Screen() != ScreenAgents || m.SelectedAgent() != "backend" {
+		t.Fatalf("expected the agent list on backend, got screen %d on %q", m.Screen(), m.SelectedAgent())
+	}
+
+	m.Update(KeyUp)
+	if m.SelectedAgent() != "backend" {
+		t.Errorf("up on the first agent should stay put, got %q", m.SelectedAgent())
+	}
+	m.Update(KeyDown)
+	if m.SelectedAgent() != "frontend" {
+		t.Errorf("expected frontend after down, got %q", m.SelectedAgent())
+	}
+	m.Update(KeyPageDown)
+	if m.SelectedAgent() != "security" {
+		t.Errorf("page down should stop on the last agent, got %q", m.SelectedAgent())
+	}
+	m.Update(KeyPageUp)
+	if m.SelectedAgent() != "backend" {
+		t.Errorf("page up should stop on the first agent, got %q", m.SelectedAgent())
+	}
+
+	if quit := m.Update(KeyEnter); quit || m.Screen() != ScreenAgent {
+		t.Fatalf("enter should open the agent screen, got screen %d (quit %v)", m.Screen(), quit)
+	}
+	if m.Update(KeyBack); m.Screen() != ScreenAgents {
+		t.Errorf("back should return to the agent list, got screen %d", m.Screen())
+	}
+	if !m.Update(KeyBack) {
+		t.Error("back on the agent list should quit")
+	}
+}
+
+func TestModelSectionExpansion(t *testing.T) {
+	m := testModel()
+	m.Update(KeyEnter)
+
+	// Domains (2), Overlaps (1) and Issues (3) start expanded, Probes collapsed.
+	if got := len(m.Rows()); got != 4+2+1+3 {
+		t.Fatalf("expected 10 rows, got %d: %v", got, m.Rows())
+	}
+	if m.Cursor() != (Row{Section: SectionDomains, Item: -1}) {
+		t.Fatalf("expected the cursor on the Domains header, got %v", m.Cursor())
+	}
+
+	m.Update(KeyEnter)
+	if m.Expanded(SectionDomains) {
+		t.Error("enter on an expanded header should collapse it")
+	}
+	if got := len(m.Rows()); got != 4+1+3 {
+		t.Errorf("expected 8 rows with Domains collapsed, got %d", got)
+	}
+
+	// Move to the Probes header and expand it.
+	for m.Cursor().Section != SectionProbes {
+		m.Update(KeyDown)
+	}
+	m.Update(KeyEnter)
+	if !m.Expanded(SectionProbes) || len(m.Rows()) != 4+1+3+2 {
+		t.Errorf("expected Probes expanded with 2 rows, got %v", m.Rows())
+	}
+
+	m.Update(KeyPageDown)
+	if m.Cursor() != (Row{Section: SectionProbes, Item: 1}) {
+		t.Errorf("page down should stop on the last row, got %v", m.Cursor())
+	}
+}
+
+func TestModelIssueExpansion(t *testing.T) {
+	m := testModel()
+	m.Update(KeyEnter)
+
+	issues := m.Issues()
+	if len(issues) != 3 {
+		t.Fatalf("expected the 2 static and 1 live issues naming backend, got %v", issues)
+	}
+	for m.Cursor() != (Row{Section: SectionIssues, Item: 1}) {
+		m.Update(KeyDown)
+	}
+	m.Update(KeyEnter)
+	if !m.IssueExpanded(1) || m.IssueExpanded(0) {
+		t.Error("enter on an issue should expand only that issue")
+	}
+	m.Update(KeyEnter)
+	if m.IssueExpanded(1) {
+		t.Error("enter on an expanded issue should collapse it")
+	}
+
+	m.Update(KeyEnter)
+	m.Update(KeyBack)
+	m.Update(KeyEnter)
+	if m.IssueExpanded(1) {
+		t.Error("reopening an agent should collapse its issues")
+	}
+}
+
+func TestModelProbeTranscript(t *testing.T) {
+	m := testModel()
+	m.Update(KeyEnter)
+	for m.Cursor().Section != SectionProbes {
+		m.Update(KeyDown)
+	}
+	m.Update(KeyEnter)
+	m.Update(KeyDown)
+	m.Update(KeyEnter)
+
+	if m.Screen() != ScreenProbe || m.Probe() == nil || m.Probe().ProbeID != "b1" {
+		t.Fatalf("expected the transcript of b1, got screen %d", m.Screen())
+	}
+	m.Update(KeyDown)
+	m.Update(KeyUp)
+	m.Update(KeyUp)
+	if m.Scroll() != 0 {
+		t.Errorf("scrolling above the top should stop at 0, got %d", m.Scroll())
+	}
+	if view := m.View(80, 24); !strings.Contains(view, "Use a composite index.") {
+		t.Errorf("expected the response in the transcript view:\n%s", view)
+	}
+
+	m.Update(KeyBack)
+	if m.Screen() != ScreenAgent || m.Cursor() != (Row{Section: SectionProbes, Item: 0}) {
+		t.Errorf("back should return to the agent screen on the same row, got screen %d at %v", m.Screen(), m.Cursor())
+	}
+	if !m.Update(KeyQuit) {
+		t.Error("q should quit from any screen")
+	}
+}
+
+func TestModelWithoutLiveResults(t *testing.T) {
+	m := NewModel(testModel().static, nil)
+	m.Update(KeyEnter)
+	if len(m.Probes()) != 0 || len(m.Issues()) != 2 {
+		t.Errorf("expected no probes and only static issues, got %d probes and %d issues", len(m.Probes()), len(m.Issues()))
+	}
+	if view := m.View(80, 24); !strings.Contains(view, "after agent-evals test") {
+		t.Errorf("expected a note that probes need a live run:\n%s", view)
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	tests := map[string]Key{
+		"\033[A":  KeyUp,
+		"j":       KeyDown,
+		"\033[6~": KeyPageDown,
+		"\r":      KeyEnter,
+		"\033":    KeyBack,
+		"\x7f":    KeyBack,
+		"q":       KeyQuit,
+		"\x03":    KeyQuit,
+		"x":       KeyNone,
+	}
+	for in, want := range tests {
+		if got := DecodeKey([]byte(in)); got != want {
+			t.Errorf("DecodeKey(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
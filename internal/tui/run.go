@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thinkwright/agent-evals/internal/analysis"
+	"github.com/thinkwright/agent-evals/internal/probes"
+	"golang.org/x/term"
+)
+
+// Interactive reports whether stdin and stdout are both terminals, which
+// Run needs.
+func Interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Run browses static and, when not nil, live results on the terminal until
+// the user quits. It switches the terminal to raw mode and an alternate
+// screen, and restores both on return.
+func Run(static *analysis.StaticReport, live *probes.LiveProbeReport) error {
+	if !Interactive() {
+		return errors.New("the browser needs an interactive terminal")
+	}
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return fmt.Errorf("set up terminal: %w", err)
+	}
+	defer term.Restore(in, state)
+
+	// Alternate screen, cursor hidden
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	m := NewModel(static, live)
+	buf := make([]byte, 32)
+	for {
+		width, height, err := term.GetSize(out)
+		if err != nil {
+			width, height = 80, 24
+		}
+		// Raw mode does not translate \n to \r\n.
+		view := strings.ReplaceAll(m.View(width, height), "\n", "\r\n")
+		fmt.Print("\033[H\033[2J" + view)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil
+		}
+		if m.Update(DecodeKey(buf[:n])) {
+			return nil
+		}
+	}
+}
+
+// DecodeKey maps the bytes of one key press in raw mode to a Key: arrows
+// and vi keys move, enter or → opens, esc, ← or backspace goes back, and q
+// or Ctrl-C quits.
+func DecodeKey(b []byte) Key {
+	switch string(b) {
+	case "\033[A", "\033OA", "k":
+		return KeyUp
+	case "\033[B", "\033OB", "j":
+		return KeyDown
+	case "\033[5~", "\x02", "b":
+		return KeyPageUp
+	case "\033[6~", "\x06", " ", "f":
+		return KeyPageDown
+	case "\r", "\n", "\033[C", "\033OC", "l":
+		return KeyEnter
+	case "\033", "\033[D", "\033OD", "h", "\x7f", "\b":
+		return KeyBack
+	case "q", "Q", "\x03":
+		return KeyQuit
+	}
+	return KeyNone
+}
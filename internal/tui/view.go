@@ -0,0 +1,307 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thinkwright/agent-evals/internal/probes"
+)
+
+// The terminal report's muted palette.
+const (
+	bold    = "\033[1m"
+	reset   = "\033[0m"
+	reverse = "\033[7m"
+	rose    = "\033[38;5;174m"
+	amber   = "\033[38;5;179m"
+	sage    = "\033[38;5;108m"
+	slate   = "\033[38;5;110m"
+	stone   = "\033[38;5;245m"
+	chalk   = "\033[38;5;188m"
+)
+
+// minWidth and minHeight are the smallest screen View lays out for.
+const (
+	minWidth  = 40
+	minHeight = 8
+)
+
+// line is a rendered line: its plain text, which is cut to the screen
+// width, and the color it is drawn in.
+type line struct {
+	text  string
+	color string
+}
+
+// View renders the current screen for a terminal of width columns and
+// height rows: a title, the visible part of the screen's content, and a
+// key help footer. The selected row is kept in view.
+func (m *Model) View(width, height int) string {
+	width, height = max(width, minWidth), max(height, minHeight)
+	var title string
+	var body []line
+	selected := -1
+	var help string
+
+	switch m.screen {
+	case ScreenAgents:
+		title = m.agentsTitle()
+		body, selected = m.agentsBody()
+		help = "↑/↓ select · enter open · q quit"
+	case ScreenAgent:
+		title = m.agentTitle()
+		body, selected = m.agentBody(width)
+		help = "↑/↓ select · enter expand/open · esc back · q quit"
+	case ScreenProbe:
+		title = m.agentTitle()
+		body = m.probeBody(width)
+		help = "↑/↓ scroll · pgup/pgdn page · esc back · q quit"
+	}
+
+	visible := height - 3 // title, blank line, footer
+	offset := 0
+	if m.screen == ScreenProbe {
+		m.scroll = max(0, min(m.scroll, len(body)-visible))
+		offset = m.scroll
+	} else if selected >= visible {
+		offset = selected - visible + 1
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s%s\n\n", bold+chalk, fit(title, width), reset)
+	for i := offset; i < offset+visible; i++ {
+		if i < len(body) {
+			text := fit(body[i].text, width)
+			if i == selected {
+				fmt.Fprintf(&b, "%s%s%s", reverse, text+strings.Repeat(" ", width-utf8.RuneCountInString(text)), reset)
+			} else {
+				fmt.Fprintf(&b, "%s%s%s", body[i].color, text, reset)
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%s%s%s", stone, fit(help, width), reset)
+	return b.String()
+}
+
+func (m *Model) agentsTitle() string {
+	title := fmt.Sprintf("agent-evals · %d agent(s) · overall %s", len(m.static.Agents), percent(m.static.Overall))
+	if status := m.static.Bands.Status(m.static.Overall); status != "" {
+		title += " " + status
+	}
+	return title
+}
+
+func (m *Model) agentTitle() string {
+	if m.agent >= len(m.static.Agents) {
+		return ""
+	}
+	a := m.static.Agents[m.agent]
+	if a.Name != "" && a.Name != a.ID {
+		return a.ID + " · " + a.Name
+	}
+	return a.ID
+}
+
+// agentsBody lists every agent with its scores and issue counts.
+func (m *Model) agentsBody() ([]line, int) {
+	var body []line
+	for _, a := range m.static.Agents {
+		score := m.static.AgentScores[a.ID]
+		text := fmt.Sprintf("  %-28s scope %4s  boundary %4s  uncertainty %4s",
+			a.ID, percent(score.ScopeClarityScore), percent(score.BoundaryDefScore), percent(score.UncertaintyGuidScore))
+		if m.live != nil {
+			if r, ok := m.live.AgentResults[a.ID]; ok && r.Scored() {
+				text += fmt.Sprintf("  live %4s", percent(r.BoundaryScore))
+			}
+		}
+		color := chalk
+		errors, warnings := m.issueCounts(a.ID)
+		switch {
+		case errors > 0:
+			text += fmt.Sprintf("  %d error(s)", errors)
+			color = rose
+		case warnings > 0:
+			text += fmt.Sprintf("  %d warning(s)", warnings)
+			color = amber
+		}
+		body = append(body, line{text, color})
+	}
+	if len(body) == 0 {
+		body = append(body, line{"  no agents", stone})
+	}
+	return body, m.agent
+}
+
+// agentBody renders the agent screen's rows, with expanded issues wrapped
+// to width over extra lines.
+func (m *Model) agentBody(width int) ([]line, int) {
+	var body []line
+	selected := 0
+	domains, overlaps, issues, details := m.Domains(), m.Overlaps(), m.Issues(), m.Probes()
+	id := m.SelectedAgent()
+
+	for i, row := range m.Rows() {
+		if i == m.cursor {
+			selected = len(body)
+		}
+		if row.Item < 0 {
+			marker := "▸"
+			if m.expanded[row.Section] {
+				marker = "▾"
+			}
+			body = append(body, line{fmt.Sprintf("%s %s (%d)", marker, row.Section, m.sectionLen(row.Section)), bold + chalk})
+			continue
+		}
+
+		switch row.Section {
+		case SectionDomains:
+			d := domains[row.Item]
+			body = append(body, line{fmt.Sprintf("    %-24s %.2f", d.Domain, d.Score), chalk})
+		case SectionOverlaps:
+			o := overlaps[row.Item]
+			text := fmt.Sprintf("    %-24s %4s  %s  shared: %s", otherAgent(o, id), percent(o.OverlapScore), o.Verdict, joinOrNone(o.SharedDomains))
+			body = append(body, line{text, verdictColor(o.Verdict)})
+		case SectionIssues:
+			issue := issues[row.Item]
+			color := severityColor(issue.Severity)
+			prefix := fmt.Sprintf("    %-7s %-14s ", issue.Severity, issue.Category)
+			if !m.expandedIssues[row.Item] {
+				body = append(body, line{prefix + issue.Message, color})
+				continue
+			}
+			wrapped := wordWrap(issue.Message, width-len(prefix))
+			body = append(body, line{prefix + wrapped[0], color})
+			for _, w := range wrapped[1:] {
+				body = append(body, line{strings.Repeat(" ", len(prefix)) + w, color})
+			}
+			if len(issue.Agents) > 1 {
+				body = append(body, line{strings.Repeat(" ", len(prefix)) + "agents: " + strings.Join(issue.Agents, ", "), stone})
+			}
+		case SectionProbes:
+			p := details[row.Item]
+			body = append(body, line{fmt.Sprintf("    %-16s %-16s %s", p.ProbeType, p.Domain, p.Question), probeColor(p)})
+		}
+	}
+	if m.live == nil {
+		body = append(body, line{"", ""}, line{"  probes are available after agent-evals test", stone})
+	}
+	return body, selected
+}
+
+// probeBody renders a probe and every response to it, wrapped to width.
+func (m *Model) probeBody(width int) []line {
+	p := m.Probe()
+	if p == nil {
+		return []line{{"  no probe", stone}}
+	}
+	var body []line
+	add := func(color, text string) {
+		for _, w := range wordWrap(text, width-4) {
+			body = append(body, line{"  " + w, color})
+		}
+	}
+
+	add(bold+chalk, p.Question)
+	body = append(body, line{"", ""})
+	add(stone, fmt.Sprintf("%s · %s · %s", p.ProbeType, p.Domain, p.ProbeID))
+	if p.Expected != "" {
+		add(stone, "expected: "+p.Expected)
+	}
+	if p.PairedAgent != "" {
+		add(stone, "also asked of: "+p.PairedAgent)
+	}
+
+	responses := p.Responses
+	if p.Reordered != nil {
+		responses = append(responses[:len(responses):len(responses)], *p.Reordered)
+	}
+	for i, r := range responses {
+		body = append(body, line{"", ""})
+		label := fmt.Sprintf("run %d · temperature %.1f", r.Run, r.Temperature)
+		if p.Reordered != nil && i == len(responses)-1 {
+			label += " · prompt reordered"
+		}
+		if r.Confidence != nil {
+			label += fmt.Sprintf(" · confidence %.0f", *r.Confidence)
+		}
+		label += fmt.Sprintf(" · hedging %.2f", r.HedgingScore)
+		if r.IsRefusal {
+			label += " · refusal"
+		}
+		if r.Correct != nil {
+			label += map[bool]string{true: " · correct", false: " · incorrect"}[*r.Correct]
+		}
+		add(slate, label)
+		if r.Error != "" {
+			add(rose, "error: "+r.Error)
+			continue
+		}
+		for _, paragraph := range strings.Split(strings.TrimSpace(r.Raw), "\n") {
+			add(chalk, paragraph)
+		}
+	}
+	return body
+}
+
+func severityColor(severity string) string {
+	switch severity {
+	case "error":
+		return rose
+	case "warning":
+		return amber
+	case "info":
+		return slate
+	}
+	return stone
+}
+
+func verdictColor(verdict string) string {
+	switch verdict {
+	case "conflict":
+		return rose
+	case "warning":
+		return amber
+	}
+	return sage
+}
+
+// probeColor flags probes with a failed response.
+func probeColor(p probes.ProbeDetail) string {
+	for _, r := range p.Responses {
+		if r.Error != "" || (r.Correct != nil && !*r.Correct) {
+			return rose
+		}
+	}
+	return chalk
+}
+
+// fit cuts s to width columns, ending it with "…" when cut.
+func fit(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// wordWrap breaks text into lines of at most width characters at word
+// boundaries; a word longer than width gets a line of its own.
+func wordWrap(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	current := words[0]
+	for _, w := range words[1:] {
+		if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(w) > width {
+			lines = append(lines, current)
+			current = w
+		} else {
+			current += " " + w
+		}
+	}
+	return append(lines, current)
+}